@@ -5,8 +5,10 @@ import (
 	"encoding/hex"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/evergreen-ci/evergreen"
@@ -132,10 +134,14 @@ type Agent struct {
 
 	// APILogger is a slogger.Appender which sends log messages
 	// to the API server.
-	APILogger *comm.APILogger
+	APILogger comm.LogSender
 
-	// Holds the current command being executed by the agent.
+	// Holds the current command being executed by the agent, its position
+	// in its command list, and the error returned by the last command that
+	// failed the task.
 	currentCommand      model.PluginCommandConf
+	currentPosition     commandPosition
+	commandErr          error
 	currentCommandMutex sync.RWMutex
 
 	// taskConfig holds the project, distro and task objects for the agent's
@@ -153,6 +159,19 @@ type Agent struct {
 	opts Options
 }
 
+// commandPosition identifies a command within the list of commands being run.
+type commandPosition struct {
+	// name is the full plugin command name, e.g. "shell.exec".
+	name string
+	// function is the name of the function the command belongs to, if any.
+	function string
+	// index is the 1-based position of the command in its list.
+	index int
+	// functionIndex is the 1-based position of the command within its
+	// function, if it belongs to one.
+	functionIndex int
+}
+
 // finishAndAwaitCleanup sends the returned TaskEndResponse and error
 // for processing by the main agent loop.
 func (agt *Agent) finishAndAwaitCleanup(status string) (*apimodels.EndTaskResponse, error) {
@@ -168,9 +187,20 @@ func (agt *Agent) finishAndAwaitCleanup(status string) (*apimodels.EndTaskRespon
 		detail = agt.getTaskEndDetail()
 	}
 	if status == evergreen.TaskSucceeded {
-		detail.Status = evergreen.TaskSucceeded
+		detail = &apimodels.TaskEndDetail{
+			Status:      evergreen.TaskSucceeded,
+			Type:        detail.Type,
+			Description: detail.Description,
+		}
 		agt.logger.LogTask(slogger.INFO, "Task completed - SUCCESS.")
 	} else {
+		if shell.TaskCgroupOOMKilled(agt.GetCurrentTaskId()) {
+			detail.OOMKilled = true
+			agt.logger.LogTask(slogger.ERROR, "A task process was killed for exceeding the task's memory limit.")
+		}
+		if detail.Status == evergreen.TaskFailed {
+			detail.FailureType = classifyFailure(detail)
+		}
 		agt.logger.LogTask(slogger.INFO, "Task completed - FAILURE.")
 	}
 
@@ -187,6 +217,9 @@ func (agt *Agent) finishAndAwaitCleanup(status string) (*apimodels.EndTaskRespon
 		agt.logger.LogTask(slogger.INFO, "Finished running post-task commands in %v.", time.Since(start).String())
 	}
 	agt.cleanup(agt.GetCurrentTaskId())
+	if err := shell.RemoveTaskCgroup(agt.GetCurrentTaskId()); err != nil {
+		agt.logger.LogExecution(slogger.ERROR, "Error removing task cgroup: %v", err)
+	}
 
 	err := agt.removeTaskDirectory()
 	if err != nil {
@@ -194,7 +227,7 @@ func (agt *Agent) finishAndAwaitCleanup(status string) (*apimodels.EndTaskRespon
 	}
 
 	agt.logger.LogExecution(slogger.INFO, "Sending final status as: %v", detail.Status)
-	agt.APILogger.FlushAndWait()
+	grip.Warning(agt.APILogger.Close())
 
 	return agt.End(detail)
 
@@ -206,11 +239,60 @@ func (agt *Agent) getTaskEndDetail() *apimodels.TaskEndDetail {
 	cmd := agt.GetCurrentCommand()
 	prj := agt.taskConfig.Project
 
-	return &apimodels.TaskEndDetail{
-		Type:        cmd.GetType(prj),
-		Status:      evergreen.TaskFailed,
-		Description: cmd.GetDisplayName(),
+	agt.currentCommandMutex.RLock()
+	pos := agt.currentPosition
+	cmdErr := agt.commandErr
+	agt.currentCommandMutex.RUnlock()
+
+	detail := &apimodels.TaskEndDetail{
+		Type:          cmd.GetType(prj),
+		Status:        evergreen.TaskFailed,
+		Description:   cmd.GetDisplayName(),
+		FailedCommand: pos.name,
+		FunctionName:  pos.function,
+		CommandIndex:  pos.index,
+	}
+	if pos.function != "" {
+		detail.FunctionCommandIndex = pos.functionIndex
+	}
+	detail.ExitCode, detail.Signal = exitStatus(cmdErr)
+
+	return detail
+}
+
+// classifyFailure returns the failure type of a failed task based on the
+// details of its end state.
+func classifyFailure(detail *apimodels.TaskEndDetail) string {
+	switch {
+	case detail.OOMKilled:
+		return apimodels.FailureTypeOOM
+	case detail.TimedOut:
+		return apimodels.FailureTypeTimeout
+	case detail.Type == model.SystemCommandType:
+		return apimodels.FailureTypeSystem
+	case detail.Type == model.SetupCommandType:
+		return apimodels.FailureTypeSetup
+	default:
+		return apimodels.FailureTypeTest
+	}
+}
+
+// exitStatus returns the exit code and, if the process was killed by one, the
+// signal of the process whose exit caused err. It returns zero values if err
+// does not come from an exited process.
+func exitStatus(err error) (int, string) {
+	exitErr, ok := errors.Cause(err).(*exec.ExitError)
+	if !ok {
+		return 0, ""
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok {
+		return 0, ""
 	}
+	if status.Signaled() {
+		return status.ExitStatus(), status.Signal().String()
+	}
+	return status.ExitStatus(), ""
 }
 
 // makeChannels allocates async channels for each background process.
@@ -264,6 +346,7 @@ func (sh *SignalHandler) HandleSignals(agt *Agent) {
 	case comm.IdleTimeout:
 		agt.logger.LogTask(slogger.ERROR, "Task timed out: '%v'", detail.Description)
 		detail.TimedOut = true
+		agt.collectTimeoutDiagnostics()
 		if agt.taskConfig.Project.Timeout != nil {
 			agt.logger.LogTask(slogger.INFO, "Running task-timeout commands.")
 			start := time.Now()
@@ -293,6 +376,22 @@ func (agt *Agent) GetCurrentCommand() model.PluginCommandConf {
 	return agt.currentCommand
 }
 
+// setCurrentPosition records the position of the command that is about to run.
+func (agt *Agent) setCurrentPosition(pos commandPosition) {
+	agt.currentCommandMutex.Lock()
+	defer agt.currentCommandMutex.Unlock()
+
+	agt.currentPosition = pos
+}
+
+// setCommandError records the error of a command that failed the task.
+func (agt *Agent) setCommandError(err error) {
+	agt.currentCommandMutex.Lock()
+	defer agt.currentCommandMutex.Unlock()
+
+	agt.commandErr = err
+}
+
 // CheckIn updates the agent's execution stage and current timeout duration,
 // and resets its timer back to zero.
 func (agt *Agent) CheckIn(command model.PluginCommandConf, duration time.Duration) {
@@ -352,6 +451,10 @@ type Options struct {
 	Certificate string
 	LogPrefix   string
 	StatusPort  int
+
+	// StreamLogs sends task logs over a persistent connection to the API
+	// server instead of in batches of requests.
+	StreamLogs bool
 }
 
 // Setup initializes all the signal chans and loggers that are used during one run of the agent.
@@ -373,7 +476,12 @@ func (agt *Agent) Setup() error {
 	agt.idleTimeoutWatcher = idleTimeoutWatcher
 
 	// Loggers
-	apiLogger := comm.NewAPILogger(agt.TaskCommunicator)
+	var apiLogger comm.LogSender
+	if httpComm, ok := agt.TaskCommunicator.(*comm.HTTPCommunicator); ok && agt.opts.StreamLogs {
+		apiLogger = httpComm.NewLogStreamer()
+	} else {
+		apiLogger = comm.NewAPILogger(agt.TaskCommunicator)
+	}
 	agt.APILogger = apiLogger
 
 	// set up timeout logger, local and API logger streams
@@ -399,6 +507,11 @@ func (agt *Agent) Setup() error {
 		stop: agt.KillChan,
 	}
 	agt.endChan = make(chan *apimodels.TaskEndDetail, 1)
+
+	agt.currentCommandMutex.Lock()
+	agt.currentPosition = commandPosition{}
+	agt.commandErr = nil
+	agt.currentCommandMutex.Unlock()
 	return nil
 }
 
@@ -593,6 +706,15 @@ func (agt *Agent) RunTask() (*apimodels.EndTaskResponse, error) {
 	}
 	taskConfig.Expansions.Put("workdir", taskConfig.WorkDir)
 
+	limits := taskConfig.Distro.ResourceLimits.Merge(pt.ResourceLimits)
+	if !limits.IsZero() {
+		agt.logger.LogExecution(slogger.INFO, "Creating cgroup with resource limits: "+
+			"memory=%vMB, cpu=%v%%, pids=%v", limits.MemoryLimitMB, limits.CPUPercent, limits.PidsLimit)
+		if err = shell.CreateTaskCgroup(taskConfig.Task.Id, limits); err != nil {
+			agt.logger.LogExecution(slogger.ERROR, "Error creating cgroup, resource limits will not be enforced: %v", err)
+		}
+	}
+
 	// notify API server that the task has been started.
 	agt.logger.LogExecution(slogger.INFO, "Reporting task started.")
 	if err = agt.Start(); err != nil {
@@ -716,6 +838,12 @@ func (agt *Agent) RunCommands(commands []model.PluginCommandConf, returnOnError
 			pluginCom := &comm.TaskJSONCommunicator{PluginName: cmd.Plugin(),
 				TaskCommunicator: agt.TaskCommunicator}
 
+			agt.setCurrentPosition(commandPosition{
+				name:          cmd.Plugin() + "." + cmd.Name(),
+				function:      commandInfo.Function,
+				index:         i + 1,
+				functionIndex: j + 1,
+			})
 			agt.CheckIn(parsedCommand, timeoutPeriod)
 
 			start := time.Now()
@@ -726,6 +854,7 @@ func (agt *Agent) RunCommands(commands []model.PluginCommandConf, returnOnError
 			if err != nil {
 				agt.logger.LogTask(slogger.ERROR, "Command failed: %v", err)
 				if returnOnError {
+					agt.setCommandError(err)
 					return err
 				}
 				continue
@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os/exec"
 	"testing"
 
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/agent/comm"
 	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/task"
 	"github.com/evergreen-ci/evergreen/plugin"
 	"github.com/evergreen-ci/evergreen/util"
@@ -179,3 +181,63 @@ func TestAgentEndTask(t *testing.T) {
 	})
 
 }
+
+func TestTaskEndDetail(t *testing.T) {
+	Convey("With an agent whose current command failed", t, func() {
+		testAgent := &Agent{
+			taskConfig:     &model.TaskConfig{Project: &model.Project{}},
+			currentCommand: model.PluginCommandConf{Command: "shell.exec"},
+		}
+
+		Convey("a command in a function should report its position within the function", func() {
+			testAgent.setCurrentPosition(commandPosition{name: "shell.exec", function: "compile", index: 2, functionIndex: 3})
+			detail := testAgent.getTaskEndDetail()
+			So(detail.FailedCommand, ShouldEqual, "shell.exec")
+			So(detail.FunctionName, ShouldEqual, "compile")
+			So(detail.CommandIndex, ShouldEqual, 2)
+			So(detail.FunctionCommandIndex, ShouldEqual, 3)
+		})
+		Convey("a command outside a function should only report its position in the task", func() {
+			testAgent.setCurrentPosition(commandPosition{name: "shell.exec", index: 2, functionIndex: 1})
+			detail := testAgent.getTaskEndDetail()
+			So(detail.CommandIndex, ShouldEqual, 2)
+			So(detail.FunctionCommandIndex, ShouldEqual, 0)
+		})
+	})
+}
+
+func TestClassifyFailure(t *testing.T) {
+	Convey("When classifying a failed task", t, func() {
+		Convey("an OOM kill should take precedence over a timeout", func() {
+			detail := &apimodels.TaskEndDetail{Type: model.TestCommandType, TimedOut: true, OOMKilled: true}
+			So(classifyFailure(detail), ShouldEqual, apimodels.FailureTypeOOM)
+		})
+		Convey("a timed out command should be classified as a timeout", func() {
+			detail := &apimodels.TaskEndDetail{Type: model.SystemCommandType, TimedOut: true}
+			So(classifyFailure(detail), ShouldEqual, apimodels.FailureTypeTimeout)
+		})
+		Convey("the command type should classify other failures", func() {
+			So(classifyFailure(&apimodels.TaskEndDetail{Type: model.SystemCommandType}),
+				ShouldEqual, apimodels.FailureTypeSystem)
+			So(classifyFailure(&apimodels.TaskEndDetail{Type: model.SetupCommandType}),
+				ShouldEqual, apimodels.FailureTypeSetup)
+			So(classifyFailure(&apimodels.TaskEndDetail{Type: model.TestCommandType}),
+				ShouldEqual, apimodels.FailureTypeTest)
+		})
+	})
+}
+
+func TestExitStatus(t *testing.T) {
+	Convey("When extracting the exit status of a failed command", t, func() {
+		Convey("the exit code of an exited process should be returned", func() {
+			code, sig := exitStatus(exec.Command("sh", "-c", "exit 3").Run())
+			So(code, ShouldEqual, 3)
+			So(sig, ShouldEqual, "")
+		})
+		Convey("errors not caused by a process exit should return zero values", func() {
+			code, sig := exitStatus(fmt.Errorf("Shell command interrupted."))
+			So(code, ShouldEqual, 0)
+			So(sig, ShouldEqual, "")
+		})
+	})
+}
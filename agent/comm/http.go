@@ -7,7 +7,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"time"
@@ -21,12 +23,16 @@ import (
 	"github.com/evergreen-ci/evergreen/util"
 	"github.com/mongodb/grip/slogger"
 	"github.com/pkg/errors"
+	"golang.org/x/net/websocket"
 )
 
 const httpMaxAttempts = 10
 
 var HeartbeatTimeout = time.Minute
 
+// LogStreamDialTimeout bounds how long opening a log stream may take.
+var LogStreamDialTimeout = 30 * time.Second
+
 var HTTPConflictError = errors.New("Conflict")
 
 // HTTPCommunicator handles communication with the API server. An HTTPCommunicator
@@ -154,13 +160,19 @@ func (h *HTTPCommunicator) End(detail *apimodels.TaskEndDetail) (*apimodels.EndT
 
 // Log sends a batch of log messages for the task's logs to the API server.
 func (h *HTTPCommunicator) Log(messages []model.LogMessage) error {
-
-	outgoingData := model.TaskLog{
-		TaskId:       h.TaskId,
+	return h.LogChunk(model.TaskLog{
 		Timestamp:    time.Now(),
 		MessageCount: len(messages),
 		Messages:     messages,
-	}
+	})
+}
+
+// LogChunk sends a chunk of the task's log to the API server. Chunks of a log
+// stream keep their sequence number, so that the server only stores those it
+// has not already received over the stream.
+func (h *HTTPCommunicator) LogChunk(chunk model.TaskLog) error {
+	outgoingData := chunk
+	outgoingData.TaskId = h.TaskId
 
 	retriableLog := util.RetriableFunc(
 		func() error {
@@ -184,6 +196,74 @@ func (h *HTTPCommunicator) Log(messages []model.LogMessage) error {
 	return err
 }
 
+// DialLogStream opens a websocket connection to the API server over which the
+// task's log messages are streamed.
+func (h *HTTPCommunicator) DialLogStream() (*websocket.Conn, error) {
+	origin, err := url.Parse(fmt.Sprintf("%s/%s", h.ServerURLRoot, h.getTaskPath("log_stream")))
+	if err != nil {
+		return nil, errors.Wrap(err, "problem parsing log stream url")
+	}
+	location := *origin
+	defaultPort := "80"
+	switch origin.Scheme {
+	case "http":
+		location.Scheme = "ws"
+	case "https":
+		location.Scheme = "wss"
+		defaultPort = "443"
+	default:
+		return nil, errors.Errorf("unsupported api server scheme '%s'", origin.Scheme)
+	}
+
+	config, err := websocket.NewConfig(location.String(), origin.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "problem configuring log stream")
+	}
+	config.Header.Add(evergreen.TaskSecretHeader, h.TaskSecret)
+	config.Header.Add(evergreen.HostHeader, h.HostId)
+	config.Header.Add(evergreen.HostSecretHeader, h.HostSecret)
+
+	address := location.Host
+	if _, _, err = net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, defaultPort)
+	}
+
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: LogStreamDialTimeout}
+	if location.Scheme == "wss" {
+		tlsConfig := &tls.Config{ServerName: location.Hostname()}
+		if h.HttpsCert != "" {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM([]byte(h.HttpsCert)) {
+				return nil, errors.New("failed to append HttpsCert to new cert pool")
+			}
+			tlsConfig.RootCAs = pool
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "problem connecting to log stream")
+	}
+
+	// bound the handshake as well as the connection
+	if err = conn.SetDeadline(time.Now().Add(LogStreamDialTimeout)); err != nil {
+		conn.Close()
+		return nil, errors.WithStack(err)
+	}
+	ws, err := websocket.NewClient(config, conn)
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "problem opening log stream")
+	}
+	if err = conn.SetDeadline(time.Time{}); err != nil {
+		ws.Close()
+		return nil, errors.WithStack(err)
+	}
+	return ws, nil
+}
+
 // GetTask returns the communicator's task.
 func (h *HTTPCommunicator) GetTask() (*task.Task, error) {
 	task := &task.Task{}
@@ -435,6 +515,14 @@ func (h *HTTPCommunicator) Reset(commSignal chan Signal, timeoutWatcher *Timeout
 
 }
 
+// NewLogStreamer returns a LogStreamer for the communicator's current task.
+// It keeps its own copy of the communicator, so that it continues to send to
+// the same task after the communicator moves on to the next one.
+func (h *HTTPCommunicator) NewLogStreamer() *LogStreamer {
+	taskComm := *h
+	return NewLogStreamer(taskComm.DialLogStream, &taskComm)
+}
+
 // getTaskPath is a helper to create a path that can be used for task specific calls
 func (h *HTTPCommunicator) getTaskPath(path string) string {
 	return fmt.Sprintf("task/%v/%v", h.TaskId, path)
@@ -21,6 +21,7 @@ type TaskCommunicator interface {
 	GetDistro() (*distro.Distro, error)
 	GetVersion() (*version.Version, error)
 	Log([]model.LogMessage) error
+	LogChunk(model.TaskLog) error
 	Heartbeat() (bool, error)
 	FetchExpansionVars() (*apimodels.ExpansionVars, error)
 	GetNextTask() (*apimodels.NextTaskResponse, error)
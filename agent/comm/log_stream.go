@@ -0,0 +1,317 @@
+package comm
+
+import (
+	"sync"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/slogger"
+	"github.com/pkg/errors"
+	"golang.org/x/net/websocket"
+)
+
+// LogStreamer is a LogSender that streams log messages to the API server over
+// a persistent websocket connection. Messages are sent as soon as the
+// connection is free, in chunks that carry a sequence number. The server
+// acknowledges each chunk once it is stored, and chunks are kept until they
+// are acknowledged, so that they can be resent if the connection drops. When
+// a connection is opened, the server reports the last chunk it stored, so
+// that chunks are never stored twice.
+//
+// If the stream cannot be opened MaxDialAttempts times in a row, pending
+// messages are sent with the communicator's Log and LogChunk methods instead.
+type LogStreamer struct {
+	// MaxChunkMessages is the most messages sent in one chunk.
+	MaxChunkMessages int
+
+	// MaxDialAttempts is the number of consecutive failures to open the
+	// stream after which pending messages are sent with the fallback.
+	MaxDialAttempts int
+
+	// ReconnectInterval is how long to wait between attempts to open the stream.
+	ReconnectInterval time.Duration
+
+	// FlushTimeout bounds how long FlushAndWait waits for messages to be
+	// acknowledged before it sends them with the fallback.
+	FlushTimeout time.Duration
+
+	dial     func() (*websocket.Conn, error)
+	fallback TaskCommunicator
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	queue    []model.LogMessage
+	unacked  []model.TaskLog
+	sent     int
+	lastSeq  int64
+	broken   bool
+	closed   bool
+	started  bool
+	stopped  chan struct{}
+	timedOut bool
+}
+
+// NewLogStreamer returns a LogStreamer that opens connections with dial and
+// falls back to sending logs with the given communicator. The stream is opened
+// when the first message is logged.
+func NewLogStreamer(dial func() (*websocket.Conn, error), fallback TaskCommunicator) *LogStreamer {
+	s := &LogStreamer{
+		MaxChunkMessages:  500,
+		MaxDialAttempts:   5,
+		ReconnectInterval: 3 * time.Second,
+		FlushTimeout:      time.Minute,
+		dial:              dial,
+		fallback:          fallback,
+		stopped:           make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// Append (to satisfy the Appender interface) queues a log message to be sent.
+func (s *LogStreamer) Append(log *slogger.Log) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errors.New("log stream is closed")
+	}
+
+	s.queue = append(s.queue, newLogMessage(log))
+	if !s.started {
+		s.started = true
+		go s.run()
+	}
+	s.cond.Broadcast()
+	return nil
+}
+
+// Flush is a noop, since messages are sent as soon as they are logged.
+func (s *LogStreamer) Flush() {}
+
+// FlushAndWait blocks until every logged message has been acknowledged by the
+// server. If that takes longer than FlushTimeout, the remaining messages are
+// sent with the fallback. It returns the number of messages that were pending.
+func (s *LogStreamer) FlushAndWait() int {
+	s.mu.Lock()
+	pending := len(s.queue)
+	for _, chunk := range s.unacked {
+		pending += len(chunk.Messages)
+	}
+	if pending == 0 || !s.started {
+		s.mu.Unlock()
+		return pending
+	}
+
+	s.timedOut = false
+	timer := time.AfterFunc(s.FlushTimeout, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.timedOut = true
+		s.cond.Broadcast()
+	})
+	defer timer.Stop()
+
+	for !s.idleLocked() && !s.timedOut {
+		s.cond.Wait()
+	}
+	timedOut := !s.idleLocked()
+	s.mu.Unlock()
+
+	if timedOut {
+		grip.Warningf("log stream did not acknowledge messages within %s", s.FlushTimeout)
+		s.sendFallback()
+	}
+	return pending
+}
+
+// Close flushes pending messages and closes the stream.
+func (s *LogStreamer) Close() error {
+	s.FlushAndWait()
+
+	s.mu.Lock()
+	started := s.started
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	if started {
+		<-s.stopped
+	}
+	return nil
+}
+
+// run opens the stream and reopens it whenever it breaks, until the streamer
+// is closed.
+func (s *LogStreamer) run() {
+	defer close(s.stopped)
+
+	failures := 0
+	for {
+		s.mu.Lock()
+		closed := s.closed
+		s.mu.Unlock()
+		if closed {
+			return
+		}
+
+		conn, err := s.dial()
+		if err != nil {
+			failures++
+			grip.Warningf("problem opening log stream (attempt %d): %+v", failures, err)
+			if failures >= s.MaxDialAttempts {
+				s.sendFallback()
+				failures = 0
+			}
+			time.Sleep(s.ReconnectInterval)
+			continue
+		}
+		failures = 0
+
+		if err = s.stream(conn); err != nil {
+			grip.Warningf("log stream interrupted: %+v", err)
+		}
+	}
+}
+
+// stream sends chunks over the connection until it breaks or the streamer is
+// closed and every chunk has been acknowledged.
+func (s *LogStreamer) stream(conn *websocket.Conn) error {
+	defer conn.Close()
+
+	// the server starts by reporting the last chunk it stored
+	ack := &apimodels.LogStreamAck{}
+	if err := websocket.JSON.Receive(conn, ack); err != nil {
+		return errors.Wrap(err, "problem reading log stream handshake")
+	}
+
+	s.mu.Lock()
+	s.ackLocked(ack.Sequence)
+	s.sent = 0
+	s.broken = false
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go s.readAcks(conn, done)
+	defer func() {
+		conn.Close()
+		<-done
+	}()
+
+	for {
+		s.mu.Lock()
+		for !s.broken && s.sent == len(s.unacked) && len(s.queue) == 0 && !(s.closed && s.idleLocked()) {
+			s.cond.Wait()
+		}
+		if s.broken {
+			s.mu.Unlock()
+			return errors.New("log stream connection closed")
+		}
+		if s.closed && s.idleLocked() {
+			s.mu.Unlock()
+			return nil
+		}
+		if s.sent == len(s.unacked) {
+			s.chunkLocked()
+		}
+		chunk := s.unacked[s.sent]
+		s.sent++
+		s.mu.Unlock()
+
+		if err := websocket.JSON.Send(conn, chunk); err != nil {
+			return errors.Wrap(err, "problem sending log chunk")
+		}
+	}
+}
+
+// readAcks processes acknowledgements until the connection breaks.
+func (s *LogStreamer) readAcks(conn *websocket.Conn, done chan struct{}) {
+	defer close(done)
+	for {
+		ack := &apimodels.LogStreamAck{}
+		err := websocket.JSON.Receive(conn, ack)
+
+		s.mu.Lock()
+		if err != nil {
+			s.broken = true
+			s.cond.Broadcast()
+			s.mu.Unlock()
+			return
+		}
+		s.ackLocked(ack.Sequence)
+		s.mu.Unlock()
+	}
+}
+
+// sendFallback sends every pending message with the fallback communicator.
+// Chunks that were sent over the stream but not acknowledged may already be
+// stored, so they are sent with their sequence numbers and the server stores
+// only those it does not have. Messages that were never put in a chunk are
+// sent as they are.
+func (s *LogStreamer) sendFallback() {
+	s.mu.Lock()
+	chunks := s.unacked
+	messages := s.queue
+	s.unacked = nil
+	s.queue = nil
+	s.sent = 0
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	count := len(messages)
+	catcher := grip.NewCatcher()
+	for _, chunk := range chunks {
+		count += len(chunk.Messages)
+		catcher.Add(s.fallback.LogChunk(chunk))
+	}
+	if len(messages) > 0 {
+		catcher.Add(s.fallback.Log(messages))
+	}
+	if count == 0 {
+		return
+	}
+	grip.CatchError(catcher.Resolve())
+	grip.Infof("sent %d log messages to api server without log stream", count)
+}
+
+// chunkLocked moves queued messages into a new chunk. The caller must hold s.mu.
+func (s *LogStreamer) chunkLocked() {
+	size := len(s.queue)
+	if size > s.MaxChunkMessages {
+		size = s.MaxChunkMessages
+	}
+
+	s.lastSeq++
+	s.unacked = append(s.unacked, model.TaskLog{
+		Sequence:     s.lastSeq,
+		Timestamp:    time.Now(),
+		MessageCount: size,
+		Messages:     s.queue[:size:size],
+	})
+	s.queue = s.queue[size:]
+}
+
+// ackLocked discards chunks up to and including seq. The caller must hold s.mu.
+func (s *LogStreamer) ackLocked(seq int64) {
+	acked := 0
+	for acked < len(s.unacked) && s.unacked[acked].Sequence <= seq {
+		acked++
+	}
+	s.unacked = s.unacked[acked:]
+	s.sent -= acked
+	if s.sent < 0 {
+		s.sent = 0
+	}
+	// after a restart of the task the server may know of later chunks
+	if seq > s.lastSeq {
+		s.lastSeq = seq
+	}
+	s.cond.Broadcast()
+}
+
+// idleLocked returns true if there is nothing left to send. The caller must hold s.mu.
+func (s *LogStreamer) idleLocked() bool {
+	return len(s.queue) == 0 && len(s.unacked) == 0
+}
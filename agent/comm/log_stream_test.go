@@ -0,0 +1,199 @@
+package comm
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/mongodb/grip/message"
+	"github.com/mongodb/grip/send"
+	"github.com/mongodb/grip/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/net/websocket"
+)
+
+// fakeLogStreamServer stores log chunks the way the API server does, and
+// can drop a connection after a number of chunks to simulate a network failure.
+type fakeLogStreamServer struct {
+	mu         sync.Mutex
+	messages   []string
+	last       int64
+	dropAfter  int
+	dropped    bool
+	noAcks     bool
+	connection int
+}
+
+func (s *fakeLogStreamServer) handle(ws *websocket.Conn) {
+	defer ws.Close()
+
+	s.mu.Lock()
+	s.connection++
+	last := s.last
+	s.mu.Unlock()
+
+	if err := websocket.JSON.Send(ws, apimodels.LogStreamAck{Sequence: last}); err != nil {
+		return
+	}
+
+	received := 0
+	for {
+		chunk := &model.TaskLog{}
+		if err := websocket.JSON.Receive(ws, chunk); err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if chunk.Sequence > s.last {
+			for _, msg := range chunk.Messages {
+				s.messages = append(s.messages, msg.Message)
+			}
+			s.last = chunk.Sequence
+		}
+		last = s.last
+		received++
+		drop := !s.dropped && s.dropAfter > 0 && received == s.dropAfter
+		if drop {
+			s.dropped = true
+		}
+		noAcks := s.noAcks
+		s.mu.Unlock()
+
+		// dropping the connection after storing a chunk but before
+		// acknowledging it makes the agent resend the chunk
+		if drop {
+			return
+		}
+		if noAcks {
+			continue
+		}
+		if err := websocket.JSON.Send(ws, apimodels.LogStreamAck{Sequence: last}); err != nil {
+			return
+		}
+	}
+}
+
+// stored returns the stored messages without the prefix added by the logger.
+func (s *fakeLogStreamServer) stored() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []string{}
+	for _, msg := range s.messages {
+		out = append(out, msg[strings.Index(msg, "message"):])
+	}
+	return out
+}
+
+func (s *fakeLogStreamServer) connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connection
+}
+
+func TestLogStreamer(t *testing.T) {
+	Convey("With a log streamer connected to a log stream server", t, func() {
+		server := &fakeLogStreamServer{}
+		httpServer := httptest.NewServer(websocket.Server{Handler: server.handle})
+		defer httpServer.Close()
+		url := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+
+		fallback := &MockCommunicator{LogChan: make(chan []model.LogMessage, 100)}
+		streamer := NewLogStreamer(func() (*websocket.Conn, error) {
+			return websocket.Dial(url, "", httpServer.URL)
+		}, fallback)
+		streamer.MaxChunkMessages = 3
+		streamer.ReconnectInterval = 10 * time.Millisecond
+
+		logger := slogger.Logger{
+			Name:      "",
+			Appenders: []send.Sender{slogger.WrapAppender(streamer)},
+		}
+
+		Convey("every message should be stored in order after a flush", func() {
+			expected := []string{}
+			for i := 0; i < 20; i++ {
+				logger.Logf(slogger.INFO, "message %d", i)
+				expected = append(expected, fmt.Sprintf("message %d", i))
+			}
+			So(streamer.FlushAndWait(), ShouldBeGreaterThanOrEqualTo, 0)
+			So(server.stored(), ShouldResemble, expected)
+			So(streamer.Close(), ShouldBeNil)
+			So(len(fallback.LogChan), ShouldEqual, 0)
+		})
+
+		Convey("chunks resent after a dropped connection should be stored once", func() {
+			server.mu.Lock()
+			server.dropAfter = 2
+			server.mu.Unlock()
+			expected := []string{}
+			for i := 0; i < 20; i++ {
+				logger.Logf(slogger.INFO, "message %d", i)
+				expected = append(expected, fmt.Sprintf("message %d", i))
+			}
+			streamer.FlushAndWait()
+			So(server.stored(), ShouldResemble, expected)
+			So(server.connections(), ShouldBeGreaterThan, 1)
+			So(streamer.Close(), ShouldBeNil)
+		})
+
+		Convey("chunks that were sent but not acknowledged should keep their sequence numbers in the fallback", func() {
+			server.mu.Lock()
+			server.noAcks = true
+			server.mu.Unlock()
+			fallback.LogChunkChan = make(chan model.TaskLog, 100)
+			streamer.FlushTimeout = 100 * time.Millisecond
+
+			expected := []string{}
+			for i := 0; i < 5; i++ {
+				logger.Logf(slogger.INFO, "message %d", i)
+				expected = append(expected, fmt.Sprintf("message %d", i))
+			}
+			So(streamer.FlushAndWait(), ShouldEqual, 5)
+			So(streamer.Close(), ShouldBeNil)
+
+			So(server.stored(), ShouldResemble, expected)
+			So(len(fallback.LogChan), ShouldEqual, 0)
+			resent := 0
+			for len(fallback.LogChunkChan) > 0 {
+				chunk := <-fallback.LogChunkChan
+				So(chunk.Sequence, ShouldBeGreaterThan, 0)
+				resent += len(chunk.Messages)
+			}
+			So(resent, ShouldEqual, 5)
+		})
+
+		Convey("logging after the streamer is closed should fail", func() {
+			So(streamer.Close(), ShouldBeNil)
+			So(streamer.Append(slogger.NewLog(message.NewString("too late"))), ShouldNotBeNil)
+		})
+	})
+
+	Convey("With a log streamer that cannot connect", t, func() {
+		fallback := &MockCommunicator{LogChan: make(chan []model.LogMessage, 100)}
+		streamer := NewLogStreamer(func() (*websocket.Conn, error) {
+			return nil, errors.New("connection refused")
+		}, fallback)
+		streamer.MaxDialAttempts = 2
+		streamer.ReconnectInterval = 10 * time.Millisecond
+
+		Convey("messages should be sent with the fallback", func() {
+			logger := slogger.Logger{
+				Name:      "",
+				Appenders: []send.Sender{slogger.WrapAppender(streamer)},
+			}
+			logger.Logf(slogger.INFO, "fallback message")
+			streamer.FlushAndWait()
+
+			msgs := <-fallback.LogChan
+			So(len(msgs), ShouldEqual, 1)
+			So(msgs[0].Message, ShouldEndWith, "fallback message")
+			So(streamer.Close(), ShouldBeNil)
+		})
+	})
+}
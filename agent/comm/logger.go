@@ -151,7 +151,7 @@ func (cmdLgr *CommandLogger) Flush() {
 
 // NewStreamLogger creates a StreamLogger wrapper for the apiLogger with a given timeoutWatcher.
 // Any logged messages on the StreamLogger will reset the TimeoutWatcher.
-func NewStreamLogger(timeoutWatcher *TimeoutWatcher, apiLgr *APILogger) (*StreamLogger, error) {
+func NewStreamLogger(timeoutWatcher *TimeoutWatcher, apiLgr LogSender) (*StreamLogger, error) {
 	defaultLoggers := []send.Sender{slogger.WrapAppender(apiLgr), grip.GetSender()}
 	timeoutLogger := slogger.WrapAppender(&TimeoutResetLogger{timeoutWatcher, apiLgr})
 
@@ -182,14 +182,32 @@ func NewStreamLogger(timeoutWatcher *TimeoutWatcher, apiLgr *APILogger) (*Stream
 // each time any log message is appended to it.
 type TimeoutResetLogger struct {
 	*TimeoutWatcher
-	*APILogger
+	LogSender
 }
 
 // Append passes the message to the underlying appender, and resets the timeout
 func (trLgr *TimeoutResetLogger) Append(log *slogger.Log) error {
 	trLgr.TimeoutWatcher.CheckIn()
 
-	return trLgr.APILogger.Append(log)
+	return trLgr.LogSender.Append(log)
+}
+
+// LogSender is a slogger.Appender that sends the task's log messages to the
+// API server. APILogger sends them in batches of HTTP requests, and
+// LogStreamer streams them over a persistent connection.
+type LogSender interface {
+	slogger.Appender
+
+	// Flush triggers sending buffered messages without waiting for them
+	// to be received.
+	Flush()
+
+	// FlushAndWait blocks until buffered messages have been sent, and
+	// returns the number of messages it flushed.
+	FlushAndWait() int
+
+	// Close flushes buffered messages and releases the sender's resources.
+	Close() error
 }
 
 // APILogger is a slogger.Appender which makes a call to the
@@ -242,24 +260,11 @@ func NewAPILogger(tc TaskCommunicator) *APILogger {
 // buffer, and translates the log message into a format that is used by the
 // remote endpoint.
 func (apiLgr *APILogger) Append(log *slogger.Log) error {
-	message := strings.TrimRight(log.Message(), "\r\t")
-
-	// MCI-972: ensure message is valid UTF-8
-	if !utf8.ValidString(message) {
-		message = strconv.QuoteToASCII(message)
-	}
-
-	logMessage := &model.LogMessage{
-		Timestamp: log.Timestamp,
-		Severity:  levelToString(log.Level),
-		Type:      log.Prefix,
-		Version:   evergreen.LogmessageCurrentVersion,
-		Message:   message,
-	}
+	logMessage := newLogMessage(log)
 
 	apiLgr.appendLock.Lock()
 	defer apiLgr.appendLock.Unlock()
-	apiLgr.messages = append(apiLgr.messages, *logMessage)
+	apiLgr.messages = append(apiLgr.messages, logMessage)
 
 	if len(apiLgr.messages) < apiLgr.SendAfterLines ||
 		time.Since(apiLgr.lastFlush) < apiLgr.SendAfterDuration {
@@ -319,6 +324,31 @@ func (apiLgr *APILogger) Flush() {
 	apiLgr.flushInternal()
 }
 
+// Close flushes any buffered messages.
+func (apiLgr *APILogger) Close() error {
+	apiLgr.FlushAndWait()
+	return nil
+}
+
+// newLogMessage translates a log message into the format that is used by the
+// remote endpoint.
+func newLogMessage(log *slogger.Log) model.LogMessage {
+	message := strings.TrimRight(log.Message(), "\r\t")
+
+	// MCI-972: ensure message is valid UTF-8
+	if !utf8.ValidString(message) {
+		message = strconv.QuoteToASCII(message)
+	}
+
+	return model.LogMessage{
+		Timestamp: log.Timestamp,
+		Severity:  levelToString(log.Level),
+		Type:      log.Prefix,
+		Version:   evergreen.LogmessageCurrentVersion,
+		Message:   message,
+	}
+}
+
 func levelToString(level slogger.Level) string {
 	switch level {
 	case slogger.DEBUG:
@@ -22,6 +22,7 @@ type MockCommunicator struct {
 	TaskId              string
 	TaskSecret          string
 	LogChan             chan []model.LogMessage
+	LogChunkChan        chan model.TaskLog
 	Posts               map[string][]interface{}
 	sync.RWMutex
 }
@@ -146,6 +147,21 @@ func (mc *MockCommunicator) Log(logMessages []model.LogMessage) error {
 	return nil
 }
 
+func (mc *MockCommunicator) LogChunk(chunk model.TaskLog) error {
+	if mc.LogChunkChan == nil {
+		return mc.Log(chunk.Messages)
+	}
+
+	mc.RLock()
+	defer mc.RUnlock()
+
+	if mc.shouldFailEnd {
+		return errors.New("failed to end")
+	}
+	mc.LogChunkChan <- chunk
+	return nil
+}
+
 func (mc *MockCommunicator) Heartbeat() (bool, error) {
 	mc.RLock()
 	defer mc.RUnlock()
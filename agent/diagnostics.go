@@ -0,0 +1,160 @@
+package agent
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/agent/comm"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/mongodb/grip/message"
+	"github.com/mongodb/grip/slogger"
+	"github.com/pkg/errors"
+)
+
+// DumpGracePeriod is how long the agent waits after asking the task's
+// processes to dump their state before it continues handling a timeout.
+var DumpGracePeriod = 10 * time.Second
+
+// procStateFiles are the files under /proc/<pid> that are collected for each
+// process when a task times out. They are only available on Linux.
+var procStateFiles = []string{"status", "wchan", "stack"}
+
+// diagnostic is a named piece of diagnostic output collected when a task times out.
+type diagnostic struct {
+	name  string
+	lines []string
+}
+
+// collectTimeoutDiagnostics gathers the state of the task's processes after a
+// command times out, before they are killed, and attaches it to the task as
+// files. If the project sets dump_on_timeout, the processes are also asked to
+// dump their stacks or cores.
+func (agt *Agent) collectTimeoutDiagnostics() {
+	agt.logger.LogExecution(slogger.INFO, "Collecting diagnostics for timed out task.")
+	start := time.Now()
+
+	procs := collectProcessTree(int32(os.Getpid()))
+	diags := []diagnostic{
+		processListDiagnostic(procs),
+		processStateDiagnostic(procs),
+		goroutineDiagnostic(),
+	}
+
+	if agt.taskConfig.Project.DumpOnTimeout {
+		agt.dumpProcesses(procs)
+	}
+
+	if err := agt.attachDiagnostics(diags); err != nil {
+		agt.logger.LogExecution(slogger.ERROR, "Error attaching timeout diagnostics: %v", err)
+	}
+	agt.logger.LogExecution(slogger.INFO, "Finished collecting timeout diagnostics in %v.", time.Since(start).String())
+}
+
+// dumpProcesses signals every process spawned by the agent to dump its state,
+// and waits for DumpGracePeriod so that the output reaches the task logs.
+func (agt *Agent) dumpProcesses(procs []*message.ProcessInfo) {
+	signaled := 0
+	for _, proc := range procs {
+		if int(proc.Pid) == os.Getpid() {
+			continue
+		}
+		if err := signalDump(int(proc.Pid)); err != nil {
+			agt.logger.LogExecution(slogger.WARN, "Error signaling process %v to dump its state: %v", proc.Pid, err)
+			continue
+		}
+		signaled++
+	}
+
+	if signaled > 0 {
+		agt.logger.LogTask(slogger.INFO, "Signaled %v processes to dump their state, waiting %v.",
+			signaled, DumpGracePeriod.String())
+		time.Sleep(DumpGracePeriod)
+	}
+}
+
+// attachDiagnostics stores each diagnostic as a test log and links it from
+// the task's files.
+func (agt *Agent) attachDiagnostics(diags []diagnostic) error {
+	pluginCom := &comm.TaskJSONCommunicator{TaskCommunicator: agt.TaskCommunicator}
+
+	files := []*artifact.File{}
+	for _, diag := range diags {
+		if len(diag.lines) == 0 {
+			continue
+		}
+
+		logId, err := pluginCom.TaskPostTestLog(&model.TestLog{
+			Name:          "timeout-diagnostics-" + strings.Replace(diag.name, " ", "-", -1),
+			Task:          agt.taskConfig.Task.Id,
+			TaskExecution: agt.taskConfig.Task.Execution,
+			Lines:         diag.lines,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "problem posting %s", diag.name)
+		}
+
+		files = append(files, &artifact.File{
+			Name:       "Timeout diagnostics: " + diag.name,
+			Link:       "/test_log/" + logId,
+			Visibility: artifact.Private,
+		})
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+	return errors.Wrap(pluginCom.PostTaskFiles(files), "problem attaching diagnostic files")
+}
+
+// processListDiagnostic renders the resource usage of each process.
+func processListDiagnostic(procs []*message.ProcessInfo) diagnostic {
+	diag := diagnostic{name: "process list"}
+	for _, proc := range procs {
+		diag.lines = append(diag.lines, fmt.Sprintf("pid=%d ppid=%d threads=%d cmd=%s",
+			proc.Pid, proc.Parent, proc.Threads, proc.Command))
+		diag.lines = append(diag.lines, "    "+proc.String())
+	}
+	return diag
+}
+
+// processStateDiagnostic reads the kernel's view of each process from /proc,
+// which shows what a hung process is blocked on. It is empty on systems
+// without a Linux-style /proc.
+func processStateDiagnostic(procs []*message.ProcessInfo) diagnostic {
+	diag := diagnostic{name: "process state"}
+	for _, proc := range procs {
+		header := false
+		for _, name := range procStateFiles {
+			data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/%s", proc.Pid, name))
+			if err != nil || len(data) == 0 {
+				continue
+			}
+			if !header {
+				diag.lines = append(diag.lines, fmt.Sprintf("=== pid %d: %s ===", proc.Pid, proc.Command))
+				header = true
+			}
+			diag.lines = append(diag.lines, fmt.Sprintf("--- %s ---", name))
+			diag.lines = append(diag.lines, strings.Split(strings.TrimRight(string(data), "\n"), "\n")...)
+		}
+	}
+	return diag
+}
+
+// goroutineDiagnostic dumps the stacks of the agent's own goroutines, in
+// case the agent rather than the task is what is hung.
+func goroutineDiagnostic() diagnostic {
+	buf := &bytes.Buffer{}
+	if err := pprof.Lookup("goroutine").WriteTo(buf, 2); err != nil {
+		return diagnostic{name: "agent goroutines", lines: []string{err.Error()}}
+	}
+	return diagnostic{
+		name:  "agent goroutines",
+		lines: strings.Split(strings.TrimRight(buf.String(), "\n"), "\n"),
+	}
+}
@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTimeoutDiagnostics(t *testing.T) {
+	Convey("With a child process of a child process of the agent", t, func() {
+		if runtime.GOOS == "windows" {
+			SkipConvey("process tree tests require a posix shell", func() {})
+			return
+		}
+
+		cmd := exec.Command("sh", "-c", "sleep 30 & wait")
+		So(cmd.Start(), ShouldBeNil)
+		defer func() {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		}()
+
+		Convey("the process tree should include the agent and all descendants", func() {
+			procs := collectProcessTree(int32(os.Getpid()))
+			So(len(procs), ShouldBeGreaterThanOrEqualTo, 1)
+			So(int(procs[0].Pid), ShouldEqual, os.Getpid())
+
+			foundShell := false
+			for _, proc := range procs {
+				if int(proc.Pid) == cmd.Process.Pid {
+					foundShell = true
+				}
+			}
+			So(foundShell, ShouldBeTrue)
+
+			Convey("and the process list should have an entry for each process", func() {
+				diag := processListDiagnostic(procs)
+				So(diag.name, ShouldEqual, "process list")
+				So(len(diag.lines), ShouldEqual, 2*len(procs))
+				So(diag.lines[0], ShouldStartWith, "pid=")
+			})
+
+			Convey("and the process state should be read from /proc where available", func() {
+				diag := processStateDiagnostic(procs)
+				if _, err := os.Stat("/proc/self/status"); err != nil {
+					So(len(diag.lines), ShouldEqual, 0)
+					return
+				}
+				So(len(diag.lines), ShouldBeGreaterThan, 0)
+				So(diag.lines[0], ShouldStartWith, "=== pid ")
+			})
+		})
+	})
+
+	Convey("The agent's goroutine dump should include the current goroutine", t, func() {
+		diag := goroutineDiagnostic()
+		So(diag.name, ShouldEqual, "agent goroutines")
+		So(strings.Join(diag.lines, "\n"), ShouldContainSubstring, "TestTimeoutDiagnostics")
+	})
+}
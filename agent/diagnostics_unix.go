@@ -0,0 +1,13 @@
+//go:build !windows
+// +build !windows
+
+package agent
+
+import "syscall"
+
+// signalDump asks a process to dump its state. SIGQUIT makes the JVM print
+// thread stacks, Go programs print goroutine stacks and exit, and most other
+// programs dump core.
+func signalDump(pid int) error {
+	return syscall.Kill(pid, syscall.SIGQUIT)
+}
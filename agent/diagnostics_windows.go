@@ -0,0 +1,9 @@
+//go:build windows
+// +build windows
+
+package agent
+
+// signalDump is a noop on windows, which has no equivalent of SIGQUIT.
+func signalDump(pid int) error {
+	return nil
+}
@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	localResultsFile = "results.json"
+	localFilesFile   = "files.json"
+	localTestLogDir  = "test_logs"
+	localPluginDir   = "plugins"
+)
+
+// LocalCommunicator is a stand-in for the API server that is used when
+// running a task locally. Data that plugins would send to the server is
+// written to files under OutputDir instead. Requests for data from the
+// server fail, since there is no server to provide it.
+type LocalCommunicator struct {
+	PluginName string
+	OutputDir  string
+
+	// mu is shared between the communicators created for each plugin so
+	// that appending to the results and files documents is serialized.
+	mu *sync.Mutex
+}
+
+// NewLocalCommunicator returns a LocalCommunicator that writes into outputDir.
+func NewLocalCommunicator(outputDir string) *LocalCommunicator {
+	return &LocalCommunicator{OutputDir: outputDir, mu: &sync.Mutex{}}
+}
+
+// ForPlugin returns a communicator for the named plugin that shares its
+// output with this one.
+func (lc *LocalCommunicator) ForPlugin(name string) *LocalCommunicator {
+	return &LocalCommunicator{PluginName: name, OutputDir: lc.OutputDir, mu: lc.mu}
+}
+
+// TaskPostJSON writes data to plugins/<plugin>/<endpoint>.json.
+func (lc *LocalCommunicator) TaskPostJSON(endpoint string, data interface{}) (*http.Response, error) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	path := filepath.Join(lc.OutputDir, localPluginDir, lc.PluginName, localFileName(endpoint)+".json")
+	if err := writeJSONFile(path, data); err != nil {
+		return nil, err
+	}
+	return localResponse(http.StatusOK, ""), nil
+}
+
+// TaskGetJSON always returns a 404, since there is no server to hold data.
+func (lc *LocalCommunicator) TaskGetJSON(endpoint string) (*http.Response, error) {
+	return localResponse(http.StatusNotFound,
+		fmt.Sprintf("%s/%s is not available when running locally", lc.PluginName, endpoint)), nil
+}
+
+// TaskPostResults appends the test results to results.json.
+func (lc *LocalCommunicator) TaskPostResults(results *task.TestResults) error {
+	if results == nil || len(results.Results) == 0 {
+		return nil
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	path := filepath.Join(lc.OutputDir, localResultsFile)
+	existing := &task.TestResults{}
+	if err := readJSONFile(path, existing); err != nil {
+		return err
+	}
+	existing.Results = append(existing.Results, results.Results...)
+	return writeJSONFile(path, existing)
+}
+
+// TaskPostTestLog writes the log's lines to test_logs/<name>.log and returns
+// the path of the file as the log's id.
+func (lc *LocalCommunicator) TaskPostTestLog(log *model.TestLog) (string, error) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	dir := filepath.Join(lc.OutputDir, localTestLogDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.Wrap(err, "problem creating test log directory")
+	}
+
+	path := filepath.Join(dir, localFileName(log.Name)+".log")
+	content := strings.Join(log.Lines, "\n") + "\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", errors.Wrapf(err, "problem writing test log %s", log.Name)
+	}
+	return path, nil
+}
+
+// PostTaskFiles appends the files to files.json.
+func (lc *LocalCommunicator) PostTaskFiles(files []*artifact.File) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	path := filepath.Join(lc.OutputDir, localFilesFile)
+	existing := []*artifact.File{}
+	if err := readJSONFile(path, &existing); err != nil {
+		return err
+	}
+	return writeJSONFile(path, append(existing, files...))
+}
+
+// localFileName turns an endpoint or log name into a file name.
+func localFileName(name string) string {
+	return util.CleanName(strings.Replace(name, "/", "_", -1))
+}
+
+func localResponse(status int, body string) *http.Response {
+	return &http.Response{
+		Status:     http.StatusText(status),
+		StatusCode: status,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+	}
+}
+
+func readJSONFile(path string, out interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "problem reading %s", path)
+	}
+	return errors.Wrapf(json.Unmarshal(data, out), "problem parsing %s", path)
+}
+
+func writeJSONFile(path string, data interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrapf(err, "problem creating directory for %s", path)
+	}
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "problem marshaling %s", path)
+	}
+	return errors.Wrapf(ioutil.WriteFile(path, out, 0644), "problem writing %s", path)
+}
@@ -0,0 +1,271 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/agent/comm"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/send"
+	"github.com/mongodb/grip/slogger"
+	"github.com/pkg/errors"
+)
+
+// localTaskLogFile is the file, relative to a LocalRunner's OutputDir, that
+// holds the task's logs.
+const localTaskLogFile = "task.log"
+
+// LocalRunner runs a single task from a project config on the local machine
+// without an API server. Commands are run with the real plugin registry, and
+// anything they would send to the server is written to OutputDir.
+type LocalRunner struct {
+	Project    *model.Project
+	Variant    string
+	Task       string
+	Expansions map[string]string
+	WorkDir    string
+	OutputDir  string
+
+	registry plugin.Registry
+	logger   *comm.StreamLogger
+	comm     *LocalCommunicator
+	config   *model.TaskConfig
+}
+
+// Run runs the project's pre commands, the task's commands and the project's
+// post commands, in the same way that the agent does. It returns the error
+// from the first task command that fails. Closing stop interrupts the running
+// pre or task command; post is still run, until the project's callback
+// timeout.
+func (lr *LocalRunner) Run(stop chan bool) error {
+	if err := lr.setup(); err != nil {
+		return err
+	}
+	defer lr.closeLoggers()
+
+	projectTask := lr.Project.FindProjectTask(lr.Task)
+	start := time.Now()
+	lr.logger.LogExecution(slogger.INFO, "Running task %s on variant %s in %s",
+		lr.Task, lr.Variant, lr.WorkDir)
+
+	if lr.Project.Pre != nil {
+		lr.logger.LogExecution(slogger.INFO, "Running pre-task commands.")
+		if err := lr.runCommands(lr.Project.Pre.List(), false, stop); err != nil {
+			lr.logger.LogExecution(slogger.ERROR, "Running pre-task script failed: %v", err)
+		}
+	}
+
+	taskErr := lr.runCommands(projectTask.Commands, true, stop)
+
+	if lr.Project.Post != nil {
+		lr.logger.LogExecution(slogger.INFO, "Running post-task commands.")
+		if err := lr.runCommands(lr.Project.Post.List(), false, lr.callbackTimeoutSignal()); err != nil {
+			lr.logger.LogExecution(slogger.ERROR, "Error running post-task command: %v", err)
+		}
+	}
+
+	status := evergreen.TaskSucceeded
+	if taskErr != nil {
+		status = evergreen.TaskFailed
+	}
+	lr.logger.LogExecution(slogger.INFO, "Task %s %s in %v; output is in %s",
+		lr.Task, status, time.Since(start).String(), lr.OutputDir)
+
+	return taskErr
+}
+
+// callbackTimeoutSignal returns a channel that is closed once the project's
+// callback timeout has passed, in the same way as the agent's.
+func (lr *LocalRunner) callbackTimeoutSignal() chan bool {
+	timeout := DefaultCallbackCmdTimeout
+	if lr.Project.CallbackTimeout != 0 {
+		timeout = time.Duration(lr.Project.CallbackTimeout) * time.Second
+	}
+	stop := make(chan bool)
+	go func() {
+		time.Sleep(timeout)
+		close(stop)
+	}()
+	return stop
+}
+
+// setup validates the runner's task and builds the task config, logger and
+// plugin registry that commands are run with.
+func (lr *LocalRunner) setup() error {
+	if lr.Project == nil {
+		return errors.New("no project specified")
+	}
+	if lr.Project.FindBuildVariant(lr.Variant) == nil {
+		return errors.Errorf("build variant '%s' does not exist in the project", lr.Variant)
+	}
+	if lr.Project.FindProjectTask(lr.Task) == nil {
+		return errors.Errorf("task '%s' does not exist in the project", lr.Task)
+	}
+	if lr.Project.FindTaskForVariant(lr.Task, lr.Variant) == nil {
+		return errors.Errorf("task '%s' is not run on build variant '%s'", lr.Task, lr.Variant)
+	}
+
+	var err error
+	if lr.WorkDir == "" {
+		if lr.WorkDir, err = os.Getwd(); err != nil {
+			return errors.Wrap(err, "problem finding working directory")
+		}
+	}
+	if lr.OutputDir == "" {
+		lr.OutputDir = filepath.Join(lr.WorkDir, "evergreen-local",
+			util.CleanName(fmt.Sprintf("%s_%s", lr.Variant, lr.Task)))
+	}
+	if err = os.MkdirAll(lr.OutputDir, 0755); err != nil {
+		return errors.Wrapf(err, "problem creating output directory %s", lr.OutputDir)
+	}
+
+	if lr.config, err = lr.taskConfig(); err != nil {
+		return errors.Wrap(err, "problem creating task config")
+	}
+	if lr.logger, err = lr.makeLogger(); err != nil {
+		return errors.Wrap(err, "problem creating task logger")
+	}
+
+	lr.comm = NewLocalCommunicator(lr.OutputDir)
+	lr.registry = plugin.NewSimpleRegistry()
+	return errors.WithStack(registerPlugins(lr.registry, plugin.CommandPlugins, lr.logger))
+}
+
+// taskConfig builds the config for a task that is not stored in the
+// database, using the same expansions the server would provide.
+func (lr *LocalRunner) taskConfig() (*model.TaskConfig, error) {
+	revision := lr.Expansions["revision"]
+	d := &distro.Distro{Id: "local", WorkDir: lr.WorkDir}
+	v := &version.Version{
+		Id:         "local",
+		Identifier: lr.Project.Identifier,
+		Branch:     lr.Project.Branch,
+		Author:     os.Getenv("USER"),
+		Revision:   revision,
+		Requester:  evergreen.RepotrackerVersionRequester,
+	}
+	t := &task.Task{
+		Id:           util.CleanName(fmt.Sprintf("local_%s_%s", lr.Variant, lr.Task)),
+		DisplayName:  lr.Task,
+		BuildVariant: lr.Variant,
+		BuildId:      util.CleanName(fmt.Sprintf("local_%s", lr.Variant)),
+		Version:      v.Id,
+		Project:      lr.Project.Identifier,
+		Revision:     revision,
+		Requester:    evergreen.RepotrackerVersionRequester,
+	}
+	ref := &model.ProjectRef{
+		Owner:       lr.Project.Owner,
+		Repo:        lr.Project.Repo,
+		Branch:      lr.Project.Branch,
+		RepoKind:    lr.Project.RepoKind,
+		RemotePath:  lr.Project.RemotePath,
+		Identifier:  lr.Project.Identifier,
+		DisplayName: lr.Project.DisplayName,
+		Enabled:     true,
+	}
+
+	config, err := model.NewTaskConfig(d, v, lr.Project, t, ref)
+	if err != nil {
+		return nil, err
+	}
+	config.Expansions.Update(lr.Expansions)
+	return config, nil
+}
+
+// makeLogger returns a logger that writes the task, execution and system
+// logs to standard output and to the task log file in the output directory.
+func (lr *LocalRunner) makeLogger() (*comm.StreamLogger, error) {
+	path := filepath.Join(lr.OutputDir, localTaskLogFile)
+	file, err := send.MakeFileLogger(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem opening %s", path)
+	}
+	stdout := send.MakeNative()
+
+	for _, sender := range []send.Sender{file, stdout} {
+		sender.SetName("run-local")
+	}
+
+	return &comm.StreamLogger{
+		Local:     &slogger.Logger{Name: "local", Appenders: []send.Sender{file}},
+		System:    &slogger.Logger{Name: model.SystemLogPrefix, Appenders: []send.Sender{file, stdout}},
+		Task:      &slogger.Logger{Name: model.TaskLogPrefix, Appenders: []send.Sender{file, stdout}},
+		Execution: &slogger.Logger{Name: model.AgentLogPrefix, Appenders: []send.Sender{file, stdout}},
+	}, nil
+}
+
+func (lr *LocalRunner) closeLoggers() {
+	for _, sender := range lr.logger.Task.Appenders {
+		grip.Warning(sender.Close())
+	}
+}
+
+// runCommands runs the commands with the task's config, mirroring
+// Agent.RunCommands but without heartbeats or timeouts.
+func (lr *LocalRunner) runCommands(commands []model.PluginCommandConf, returnOnError bool, stop chan bool) error {
+	for i, commandInfo := range commands {
+		parsedCommands, err := lr.registry.ParseCommandConf(commandInfo, lr.Project.Functions)
+		if err != nil {
+			lr.logger.LogTask(slogger.ERROR, "Couldn't parse plugin command '%v': %v", commandInfo.Command, err)
+			if returnOnError {
+				return err
+			}
+			continue
+		}
+
+		cmds, err := lr.registry.GetCommands(commandInfo, lr.Project.Functions)
+		if err != nil {
+			lr.logger.LogTask(slogger.ERROR, "Don't know how to run plugin action %s: %v", commandInfo.Command, err)
+			if returnOnError {
+				return err
+			}
+			continue
+		}
+
+		for j, cmd := range cmds {
+			fullCommandName := fmt.Sprintf("'%v'", cmd.Plugin()+"."+cmd.Name())
+			if commandInfo.Function != "" {
+				fullCommandName = fmt.Sprintf(`%v in "%v"`, fullCommandName, commandInfo.Function)
+			}
+
+			if !commandInfo.RunOnVariant(lr.Variant) || !parsedCommands[j].RunOnVariant(lr.Variant) {
+				lr.logger.LogTask(slogger.INFO, "Skipping command %v on variant %v (step %v of %v)",
+					fullCommandName, lr.Variant, i+1, len(commands))
+				continue
+			}
+			lr.logger.LogTask(slogger.INFO, "Running command %v (step %v.%v of %v)", fullCommandName, i+1, j+1, len(commands))
+
+			for key, val := range commandInfo.Vars {
+				var newVal string
+				newVal, err = lr.config.Expansions.ExpandString(val)
+				if err != nil {
+					return errors.Wrapf(err, "Can't expand '%v'", val)
+				}
+				lr.config.Expansions.Put(key, newVal)
+			}
+
+			start := time.Now()
+			err = cmd.Execute(comm.NewCommandLogger(fullCommandName, lr.logger),
+				lr.comm.ForPlugin(cmd.Plugin()), lr.config, stop)
+			lr.logger.LogExecution(slogger.INFO, "Finished %v in %v", fullCommandName, time.Since(start).String())
+
+			if err != nil {
+				lr.logger.LogTask(slogger.ERROR, "Command failed: %v", err)
+				if returnOnError {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/model/task"
+	_ "github.com/evergreen-ci/evergreen/plugin/config"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const localRunnerTestConfig = `
+functions:
+  write:
+    command: shell.exec
+    params:
+      script: echo "${greeting} ${task_name}" > greeting.txt
+post:
+  - command: shell.exec
+    params:
+      script: touch post-ran
+tasks:
+  - name: pass
+    commands:
+      - func: write
+  - name: fail
+    commands:
+      - command: shell.exec
+        params:
+          script: exit 1
+      - command: shell.exec
+        params:
+          script: touch should-not-exist
+buildvariants:
+  - name: linux
+    tasks:
+      - name: pass
+      - name: fail
+`
+
+func TestLocalCommunicator(t *testing.T) {
+	Convey("With a local communicator writing to a scratch directory", t, func() {
+		dir, err := ioutil.TempDir("", "local-comm")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		com := NewLocalCommunicator(dir).ForPlugin("myplugin")
+
+		Convey("posted results should be appended to the results file", func() {
+			So(com.TaskPostResults(&task.TestResults{Results: []task.TestResult{{TestFile: "a"}}}), ShouldBeNil)
+			So(com.TaskPostResults(&task.TestResults{Results: []task.TestResult{{TestFile: "b"}}}), ShouldBeNil)
+
+			results := &task.TestResults{}
+			So(readJSONFile(filepath.Join(dir, localResultsFile), results), ShouldBeNil)
+			So(len(results.Results), ShouldEqual, 2)
+			So(results.Results[1].TestFile, ShouldEqual, "b")
+		})
+
+		Convey("posted files should be appended to the files file", func() {
+			So(com.PostTaskFiles([]*artifact.File{{Name: "one", Link: "a"}}), ShouldBeNil)
+			So(com.PostTaskFiles([]*artifact.File{{Name: "two", Link: "b"}}), ShouldBeNil)
+
+			files := []*artifact.File{}
+			So(readJSONFile(filepath.Join(dir, localFilesFile), &files), ShouldBeNil)
+			So(len(files), ShouldEqual, 2)
+			So(files[0].Name, ShouldEqual, "one")
+		})
+
+		Convey("a posted test log should be written as a file named by its id", func() {
+			id, err := com.TaskPostTestLog(&model.TestLog{Name: "my test", Lines: []string{"line 1", "line 2"}})
+			So(err, ShouldBeNil)
+			So(id, ShouldEqual, filepath.Join(dir, localTestLogDir, "my_test.log"))
+			data, err := ioutil.ReadFile(id)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "line 1\nline 2\n")
+		})
+
+		Convey("posted plugin data should be written under the plugin's directory", func() {
+			resp, err := com.TaskPostJSON("data/thing", map[string]string{"key": "value"})
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+
+			data, err := ioutil.ReadFile(filepath.Join(dir, localPluginDir, "myplugin", "data_thing.json"))
+			So(err, ShouldBeNil)
+			out := map[string]string{}
+			So(json.Unmarshal(data, &out), ShouldBeNil)
+			So(out["key"], ShouldEqual, "value")
+		})
+
+		Convey("fetching data should report that it is not found", func() {
+			resp, err := com.TaskGetJSON("data/thing")
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusNotFound)
+		})
+	})
+}
+
+func TestLocalRunner(t *testing.T) {
+	Convey("With a project config and a scratch working directory", t, func() {
+		dir, err := ioutil.TempDir("", "local-runner")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		project := &model.Project{}
+		So(model.LoadProjectInto([]byte(localRunnerTestConfig), "", project), ShouldBeNil)
+		runner := &LocalRunner{
+			Project:    project,
+			Variant:    "linux",
+			Expansions: map[string]string{"greeting": "hello"},
+			WorkDir:    dir,
+		}
+
+		Convey("a passing task should run its functions with the local expansions", func() {
+			runner.Task = "pass"
+			So(runner.Run(make(chan bool)), ShouldBeNil)
+
+			data, err := ioutil.ReadFile(filepath.Join(dir, "greeting.txt"))
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "hello pass\n")
+
+			Convey("and write its logs to the output directory", func() {
+				_, err := os.Stat(filepath.Join(runner.OutputDir, localTaskLogFile))
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("a failing task should stop at the first failed command", func() {
+			runner.Task = "fail"
+			So(runner.Run(make(chan bool)), ShouldNotBeNil)
+			_, err := os.Stat(filepath.Join(dir, "should-not-exist"))
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+
+		Convey("an interrupted task should still run post", func() {
+			runner.Task = "pass"
+			stop := make(chan bool)
+			close(stop)
+			runner.Run(stop)
+			_, err := os.Stat(filepath.Join(dir, "post-ran"))
+			So(err, ShouldBeNil)
+		})
+
+		Convey("a task that is not run on the variant should be rejected", func() {
+			runner.Task = "nonexistent"
+			So(runner.Run(make(chan bool)), ShouldNotBeNil)
+		})
+	})
+}
@@ -60,6 +60,7 @@ func main() {
 	httpsCertFile := flag.String("https_cert", "", "path to a self-signed private cert")
 	logPrefix := flag.String("log_prefix", "", "prefix for the agent's log filename")
 	port := flag.Int("status_port", statsPort, "port to run the status server on")
+	streamLogs := flag.Bool("stream_logs", false, "stream task logs to the API server over a persistent connection")
 	flag.Parse()
 
 	grip.CatchEmergencyFatal(agent.SetupLogging("agent-startup"))
@@ -80,6 +81,7 @@ func main() {
 		HostSecret:  *hostSecret,
 		StatusPort:  *port,
 		LogPrefix:   *logPrefix,
+		StreamLogs:  *streamLogs,
 	}
 
 	agt, err := agent.New(initialOptions)
@@ -107,3 +107,23 @@ func (c *metricsCollector) sysInfoCollector(interval time.Duration) {
 		}
 	}
 }
+
+// collectProcessTree returns information about the process with the given
+// pid and all of its descendants. Unlike CollectProcessInfoWithChildren,
+// which only reports direct children, this includes the processes started by
+// the shells that commands run in.
+func collectProcessTree(pid int32) []*message.ProcessInfo {
+	out := []*message.ProcessInfo{}
+	for idx, msg := range message.CollectProcessInfoWithChildren(pid) {
+		info, ok := msg.(*message.ProcessInfo)
+		if !ok {
+			continue
+		}
+		if idx == 0 {
+			out = append(out, info)
+			continue
+		}
+		out = append(out, collectProcessTree(info.Pid)...)
+	}
+	return out
+}
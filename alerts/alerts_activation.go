@@ -77,6 +77,41 @@ func RunTaskFailureTriggers(taskId string) error {
 	return nil
 }
 
+// RunPerfRegressionTriggers queues an alert for the task whose performance results
+// regressed. The display string describes the regressions for the alert's recipients.
+func RunPerfRegressionTriggers(t *task.Task, display string) error {
+	ctx := triggerContext{task: t}
+	for _, trigger := range AvailableTaskPerfTriggers {
+		shouldExec, err := trigger.ShouldExecute(ctx)
+		if err != nil {
+			return err
+		}
+		if !shouldExec {
+			continue
+		}
+		err = alert.EnqueueAlertRequest(&alert.AlertRequest{
+			Id:        bson.NewObjectId(),
+			Trigger:   trigger.Id(),
+			TaskId:    t.Id,
+			HostId:    t.HostId,
+			Execution: t.Execution,
+			BuildId:   t.BuildId,
+			VersionId: t.Version,
+			ProjectId: t.Project,
+			Display:   display,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		err = storeTriggerBookkeeping(ctx, []Trigger{trigger})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func RunHostProvisionFailTriggers(h *host.Host) error {
 	ctx := triggerContext{host: h}
 	trigger := &ProvisionFailed{}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/alertrecord"
 	"github.com/evergreen-ci/render"
@@ -134,6 +135,8 @@ func getTemplate(alertCtx AlertContext) string {
 		fallthrough
 	case alertrecord.SpawnHostTwelveHourWarning:
 		return "email/host_spawn.html"
+	case alertrecord.PerfRegressionId:
+		return "email/perf_regression.html"
 	default:
 		return "email/task_fail.html"
 	}
@@ -163,10 +166,14 @@ func taskFailureSubject(ctx AlertContext) string {
 		}
 	}
 	switch {
+	case ctx.Task.Details.OOMKilled:
+		subj.WriteString("Task Out of Memory: ")
 	case ctx.Task.Details.TimedOut:
 		subj.WriteString("Task Timed Out: ")
 	case ctx.Task.Details.Type == model.SystemCommandType:
 		subj.WriteString("Task System Failure: ")
+	case ctx.Task.Details.FailureType == apimodels.FailureTypeSetup:
+		subj.WriteString("Task Setup Failure: ")
 	case len(failed) == 1:
 		subj.WriteString("Test Failure: ")
 	case len(failed) > 1:
@@ -214,6 +221,13 @@ func getSubject(alertCtx AlertContext) string {
 		return fmt.Sprintf("Your %s host (%s) will expire in twelve hours.",
 			alertCtx.Host.Distro, alertCtx.Host.Id)
 		// TODO(EVG-224) alertrecord.SpawnHostExpired:
+	case alertrecord.PerfRegressionId:
+		return fmt.Sprintf("Performance Regression: %s on %s (%s) // %s @ %s",
+			alertCtx.Task.DisplayName,
+			alertCtx.Build.DisplayName,
+			alertCtx.AlertRequest.Display,
+			alertCtx.ProjectRef.DisplayName,
+			alertCtx.Version.Revision[0:8])
 	}
 	return taskFailureSubject(alertCtx)
 }
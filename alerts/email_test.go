@@ -56,6 +56,25 @@ func TestEmailSubject(t *testing.T) {
 				So(subj, ShouldContainSubstring, ProjectName)
 			})
 		})
+		Convey("a task that ran out of memory should return a subject", func() {
+			ctx.Task.Details.TimedOut = true
+			ctx.Task.Details.OOMKilled = true
+			subj := getSubject(ctx)
+			Convey("denoting the out of memory failure", func() {
+				So(subj, ShouldContainSubstring, "Out of Memory")
+				So(subj, ShouldNotContainSubstring, "Timed Out")
+				So(subj, ShouldContainSubstring, TaskName)
+			})
+		})
+		Convey("a task that failed during setup should return a subject", func() {
+			ctx.Task.Details.Type = model.SetupCommandType
+			ctx.Task.Details.FailureType = apimodels.FailureTypeSetup
+			subj := getSubject(ctx)
+			Convey("denoting the setup failure", func() {
+				So(subj, ShouldContainSubstring, "Setup Failure")
+				So(subj, ShouldContainSubstring, TaskName)
+			})
+		})
 		Convey("a task that failed on a system command should return a subject", func() {
 			ctx.Task.Details.Type = model.SystemCommandType
 			subj := getSubject(ctx)
@@ -7,7 +7,9 @@ import (
 	"text/template"
 
 	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
 	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/alertrecord"
 	"github.com/evergreen-ci/evergreen/model/build"
 	"github.com/evergreen-ci/evergreen/model/host"
 	"github.com/evergreen-ci/evergreen/model/task"
@@ -27,6 +29,15 @@ Commit: [diff|https://github.com/{{.Project.Owner}}/commit/{{.Version.Revision}}
 {{range .Tests}}*{{.Name}}* - [Logs|{{.URL}}] | [History|{{.HistoryURL}}]
 {{end}}
 `
+// PerfDescriptionTemplateString defines the content of the alert ticket for a
+// performance regression.
+const PerfDescriptionTemplateString = `
+h2. [{{.Task.DisplayName}} regressed on {{.Build.DisplayName}}|{{.UIRoot}}/task/{{.Task.Id}}/{{.Task.Execution}}]
+Regressions: {{.Regressions}}
+Project: [{{.Project.DisplayName}}|{{.UIRoot}}/waterfall/{{.Project.Identifier}}]
+Commit: [diff|https://github.com/{{.Project.Owner}}/commit/{{.Version.Revision}}]: {{.Version.Message}}
+`
+
 const (
 	jiraFailingTasksField     = "customfield_12950"
 	jiraFailingVariantField   = "customfield_14277"
@@ -40,6 +51,9 @@ var supportedJiraProjects = []string{"BFG", "BF", "EVG", "MAKE", "BUILD"}
 // DescriptionTemplate is filled to create a JIRA alert ticket. Panics at start if invalid.
 var DescriptionTemplate = template.Must(template.New("Desc").Parse(DescriptionTemplateString))
 
+// PerfDescriptionTemplate is filled to create a JIRA ticket for a performance regression.
+var PerfDescriptionTemplate = template.Must(template.New("PerfDesc").Parse(PerfDescriptionTemplateString))
+
 // jiraTestFailure contains the required fields for generating a failure report.
 type jiraTestFailure struct {
 	Name       string
@@ -104,6 +118,11 @@ func (jd *jiraDeliverer) Deliver(ctx AlertContext, alertConf model.AlertConfig)
 //  Failures: Task_name on Variant (test1, test2) [ProjectName @ githash]
 // based on the given AlertContext.
 func getSummary(ctx AlertContext) string {
+	if isPerfRegression(ctx) {
+		return fmt.Sprintf("Performance Regression: %s on %s (%s) [%s @ %s]",
+			ctx.Task.DisplayName, ctx.Build.DisplayName, ctx.AlertRequest.Display,
+			ctx.ProjectRef.DisplayName, ctx.Version.Revision[0:8])
+	}
 	subj := &bytes.Buffer{}
 	failed := []string{}
 	for _, test := range ctx.Task.TestResults {
@@ -112,10 +131,14 @@ func getSummary(ctx AlertContext) string {
 		}
 	}
 	switch {
+	case ctx.Task.Details.OOMKilled:
+		subj.WriteString("Out of Memory: ")
 	case ctx.Task.Details.TimedOut:
 		subj.WriteString("Timed Out: ")
 	case ctx.Task.Details.Type == model.SystemCommandType:
 		subj.WriteString("System Failure: ")
+	case ctx.Task.Details.FailureType == apimodels.FailureTypeSetup:
+		subj.WriteString("Setup Failure: ")
 	case len(failed) == 1:
 		subj.WriteString("Failure: ")
 	case len(failed) > 1:
@@ -158,6 +181,10 @@ func logURL(test task.TestResult, root string) string {
 
 // getDescription returns the body of the JIRA ticket, with links.
 func getDescription(ctx AlertContext, uiRoot string) (string, error) {
+	if isPerfRegression(ctx) {
+		return getPerfDescription(ctx, uiRoot)
+	}
+
 	// build a list of all failed tests to include
 	tests := []jiraTestFailure{}
 	for _, test := range ctx.Task.TestResults {
@@ -185,3 +212,26 @@ func getDescription(ctx AlertContext, uiRoot string) (string, error) {
 	}
 	return buf.String(), nil
 }
+
+// isPerfRegression returns true if the alert is for a performance regression
+// rather than a task failure.
+func isPerfRegression(ctx AlertContext) bool {
+	return ctx.AlertRequest != nil && ctx.AlertRequest.Trigger == alertrecord.PerfRegressionId
+}
+
+// getPerfDescription returns the body of the JIRA ticket for a performance regression.
+func getPerfDescription(ctx AlertContext, uiRoot string) (string, error) {
+	args := struct {
+		Task        *task.Task
+		Build       *build.Build
+		Project     *model.ProjectRef
+		Version     *version.Version
+		Regressions string
+		UIRoot      string
+	}{ctx.Task, ctx.Build, ctx.ProjectRef, ctx.Version, ctx.AlertRequest.Display, uiRoot}
+	buf := &bytes.Buffer{}
+	if err := PerfDescriptionTemplate.Execute(buf, args); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
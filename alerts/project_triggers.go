@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/alertrecord"
 	"github.com/evergreen-ci/evergreen/model/task"
@@ -28,6 +29,61 @@ func (trig TaskFailed) ShouldExecute(ctx triggerContext) (bool, error) {
 
 func (trig TaskFailed) CreateAlertRecord(_ triggerContext) *alertrecord.AlertRecord { return nil }
 
+// TaskSystemFailure is a trigger that queues an alert whenever a task fails because of a
+// system command, a problem with the agent, or a process exceeding the task's memory limit.
+type TaskSystemFailure struct{}
+
+func (trig TaskSystemFailure) Id() string      { return alertrecord.TaskSystemFailureId }
+func (trig TaskSystemFailure) Display() string { return "a task has a system failure" }
+
+func (trig TaskSystemFailure) ShouldExecute(ctx triggerContext) (bool, error) {
+	if ctx.task.Status != evergreen.TaskFailed {
+		return false, nil
+	}
+	failureType := ctx.task.Details.FailureType
+	return failureType == apimodels.FailureTypeSystem || failureType == apimodels.FailureTypeOOM, nil
+}
+
+func (trig TaskSystemFailure) CreateAlertRecord(_ triggerContext) *alertrecord.AlertRecord {
+	return nil
+}
+
+// TaskSetupFailure is a trigger that queues an alert whenever a task fails in a command
+// of type "setup".
+type TaskSetupFailure struct{}
+
+func (trig TaskSetupFailure) Id() string      { return alertrecord.TaskSetupFailureId }
+func (trig TaskSetupFailure) Display() string { return "a task fails during setup" }
+
+func (trig TaskSetupFailure) ShouldExecute(ctx triggerContext) (bool, error) {
+	if ctx.task.Status != evergreen.TaskFailed {
+		return false, nil
+	}
+	return ctx.task.Details.FailureType == apimodels.FailureTypeSetup, nil
+}
+
+func (trig TaskSetupFailure) CreateAlertRecord(_ triggerContext) *alertrecord.AlertRecord {
+	return nil
+}
+
+// TaskTimedOut is a trigger that queues an alert whenever a task fails because one of
+// its commands timed out.
+type TaskTimedOut struct{}
+
+func (trig TaskTimedOut) Id() string      { return alertrecord.TaskTimedOutId }
+func (trig TaskTimedOut) Display() string { return "a task times out" }
+
+func (trig TaskTimedOut) ShouldExecute(ctx triggerContext) (bool, error) {
+	if ctx.task.Status != evergreen.TaskFailed {
+		return false, nil
+	}
+	return ctx.task.Details.TimedOut, nil
+}
+
+func (trig TaskTimedOut) CreateAlertRecord(_ triggerContext) *alertrecord.AlertRecord {
+	return nil
+}
+
 // FirstFailureInVersion is a trigger that queues an alert whenever a task fails for the first time
 // within a version. After one failure has triggered an alert for this event, subsequent failures
 // will not trigger additional alerts.
@@ -215,3 +271,29 @@ func (lrnf LastRevisionNotFound) CreateAlertRecord(ctx triggerContext) *alertrec
 	rec := newAlertRecord(ctx, alertrecord.LastRevisionNotFound)
 	return rec
 }
+
+// PerfRegression is a trigger that queues an alert when the performance results
+// of a task regress from those of the previous commits. The results are checked
+// when they are sent, so the trigger only has to make sure one alert is queued
+// per task.
+type PerfRegression struct{}
+
+func (pr PerfRegression) Id() string      { return alertrecord.PerfRegressionId }
+func (pr PerfRegression) Display() string { return "a task's performance regresses" }
+
+func (pr PerfRegression) ShouldExecute(ctx triggerContext) (bool, error) {
+	if ctx.task == nil {
+		return false, nil
+	}
+	rec, err := alertrecord.FindOne(alertrecord.ByTaskAlertRecordType(ctx.task.Id, alertrecord.PerfRegressionId))
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return rec == nil, nil
+}
+
+func (pr PerfRegression) CreateAlertRecord(ctx triggerContext) *alertrecord.AlertRecord {
+	rec := newAlertRecord(ctx, alertrecord.PerfRegressionId)
+	rec.TaskId = ctx.task.Id
+	return rec
+}
@@ -50,8 +50,15 @@ var (
 		FirstFailureInVariant{},
 		FirstFailureInTaskType{},
 		TaskFailTransition{},
+		TaskSystemFailure{},
+		TaskSetupFailure{},
+		TaskTimedOut{},
 	}
 
+	// AvailableTaskPerfTriggers are the task triggers that are checked when a task sends
+	// performance results, rather than when it finishes.
+	AvailableTaskPerfTriggers = []Trigger{PerfRegression{}}
+
 	AvailableProjectTriggers = []Trigger{
 		LastRevisionNotFound{},
 	}
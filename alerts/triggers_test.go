@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
 	"github.com/evergreen-ci/evergreen/db"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/alertrecord"
@@ -310,3 +311,42 @@ func TestReachedFailureLimit(t *testing.T) {
 		})
 	})
 }
+
+func TestFailureTypeTriggers(t *testing.T) {
+	Convey("With a failed task", t, func() {
+		failed := &task.Task{Id: "failed", Status: evergreen.TaskFailed}
+		ctx := triggerContext{task: failed}
+
+		Convey("a system failure should trigger only TaskSystemFailure", func() {
+			failed.Details = apimodels.TaskEndDetail{FailureType: apimodels.FailureTypeSystem}
+			So(shouldExecute(TaskSystemFailure{}, ctx), ShouldBeTrue)
+			So(shouldExecute(TaskSetupFailure{}, ctx), ShouldBeFalse)
+			So(shouldExecute(TaskTimedOut{}, ctx), ShouldBeFalse)
+		})
+		Convey("an OOM kill should trigger TaskSystemFailure", func() {
+			failed.Details = apimodels.TaskEndDetail{FailureType: apimodels.FailureTypeOOM, OOMKilled: true}
+			So(shouldExecute(TaskSystemFailure{}, ctx), ShouldBeTrue)
+		})
+		Convey("a setup failure should trigger only TaskSetupFailure", func() {
+			failed.Details = apimodels.TaskEndDetail{FailureType: apimodels.FailureTypeSetup}
+			So(shouldExecute(TaskSystemFailure{}, ctx), ShouldBeFalse)
+			So(shouldExecute(TaskSetupFailure{}, ctx), ShouldBeTrue)
+		})
+		Convey("a timeout should trigger TaskTimedOut", func() {
+			failed.Details = apimodels.TaskEndDetail{FailureType: apimodels.FailureTypeTimeout, TimedOut: true}
+			So(shouldExecute(TaskTimedOut{}, ctx), ShouldBeTrue)
+		})
+		Convey("a successful task should trigger none of them", func() {
+			failed.Status = evergreen.TaskSucceeded
+			failed.Details = apimodels.TaskEndDetail{FailureType: apimodels.FailureTypeSystem, TimedOut: true}
+			So(shouldExecute(TaskSystemFailure{}, ctx), ShouldBeFalse)
+			So(shouldExecute(TaskTimedOut{}, ctx), ShouldBeFalse)
+		})
+	})
+}
+
+func shouldExecute(trigger Trigger, ctx triggerContext) bool {
+	ok, err := trigger.ShouldExecute(ctx)
+	So(err, ShouldBeNil)
+	return ok
+}
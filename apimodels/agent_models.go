@@ -12,6 +12,29 @@ type HeartbeatResponse struct {
 	Abort bool `json:"abort,omitempty"`
 }
 
+// LogStreamAck is sent by the API server over a task's log stream. When the
+// stream is opened it holds the sequence number of the last log chunk the
+// server has stored, and afterwards acknowledges each chunk once it is stored.
+type LogStreamAck struct {
+	Sequence int64 `json:"seq"`
+}
+
+// Failure types classify the cause of a failed task.
+const (
+	// FailureTypeTest is a failure of a command that tests the project.
+	FailureTypeTest = "test"
+	// FailureTypeSetup is a failure of a command that prepares the task
+	// environment, such as fetching sources or dependencies.
+	FailureTypeSetup = "setup"
+	// FailureTypeSystem is a failure of a system command or of the agent itself.
+	FailureTypeSystem = "system"
+	// FailureTypeTimeout is a command that exceeded its timeout.
+	FailureTypeTimeout = "timeout"
+	// FailureTypeOOM is a task process that was killed for exceeding the
+	// task's memory limit.
+	FailureTypeOOM = "oom"
+)
+
 // TaskEndDetail contains data sent from the agent to the
 // API server after each task run.
 type TaskEndDetail struct {
@@ -19,6 +42,26 @@ type TaskEndDetail struct {
 	Type        string `bson:"type,omitempty" json:"type,omitempty"`
 	Description string `bson:"desc,omitempty" json:"desc,omitempty"`
 	TimedOut    bool   `bson:"timed_out,omitempty" json:"timed_out,omitempty"`
+	OOMKilled   bool   `bson:"oom_killed,omitempty" json:"oom_killed,omitempty"`
+
+	// FailureType is one of the FailureType constants; it is only set
+	// for failed tasks.
+	FailureType string `bson:"failure_type,omitempty" json:"failure_type,omitempty"`
+
+	// FailedCommand is the full name (e.g. "shell.exec") of the command
+	// that failed, and CommandIndex its 1-based position in the task's
+	// command list. FunctionName and FunctionCommandIndex, the command's
+	// 1-based position within the function, are set if the command was
+	// run as part of a function.
+	FailedCommand        string `bson:"failed_command,omitempty" json:"failed_command,omitempty"`
+	CommandIndex         int    `bson:"command_index,omitempty" json:"command_index,omitempty"`
+	FunctionName         string `bson:"function,omitempty" json:"function,omitempty"`
+	FunctionCommandIndex int    `bson:"function_command_index,omitempty" json:"function_command_index,omitempty"`
+
+	// ExitCode and Signal describe how the failed command's process
+	// exited, if it ran one.
+	ExitCode int    `bson:"exit_code,omitempty" json:"exit_code,omitempty"`
+	Signal   string `bson:"signal,omitempty" json:"signal,omitempty"`
 }
 
 type TaskEndDetails struct {
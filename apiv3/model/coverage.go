@@ -0,0 +1,148 @@
+package model
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/pkg/errors"
+)
+
+// APICoverage is the model to be returned by the API whenever the coverage
+// of a task is fetched.
+type APICoverage struct {
+	TaskId       APIString          `json:"task_id"`
+	TaskName     APIString          `json:"display_name"`
+	BuildVariant APIString          `json:"build_variant"`
+	Project      APIString          `json:"project_id"`
+	Version      APIString          `json:"version_id"`
+	Revision     APIString          `json:"revision"`
+	Order        int                `json:"order"`
+	IsPatch      bool               `json:"is_patch"`
+	CreateTime   APITime            `json:"create_time"`
+	Covered      int                `json:"covered"`
+	Total        int                `json:"total"`
+	Percent      float64            `json:"percent"`
+	Packages     []APICoverageEntry `json:"packages"`
+	Files        []APICoverageEntry `json:"files,omitempty"`
+	Delta        *APICoverageDelta  `json:"delta,omitempty"`
+}
+
+// APICoverageEntry is the coverage of one package or source file.
+type APICoverageEntry struct {
+	Name    APIString `json:"name"`
+	Package APIString `json:"package,omitempty"`
+	Covered int       `json:"covered"`
+	Total   int       `json:"total"`
+	Percent float64   `json:"percent"`
+}
+
+// APICoverageDelta is how a patch changed the coverage of its base commit.
+type APICoverageDelta struct {
+	BasePercent float64             `json:"base_percent"`
+	Change      float64             `json:"change"`
+	Files       []APICoverageChange `json:"files"`
+}
+
+// APICoverageChange is how a patch changed the coverage of one source file.
+type APICoverageChange struct {
+	Name        APIString `json:"name"`
+	BaseCovered int       `json:"base_covered"`
+	BaseTotal   int       `json:"base_total"`
+	Covered     int       `json:"covered"`
+	Total       int       `json:"total"`
+	Change      float64   `json:"change"`
+}
+
+// BuildFromService converts from a service level task coverage, or adds a
+// service level delta to the model.
+func (ac *APICoverage) BuildFromService(h interface{}) error {
+	switch v := h.(type) {
+	case *coverage.TaskCoverage:
+		*ac = APICoverage{
+			TaskId:       APIString(v.TaskId),
+			TaskName:     APIString(v.TaskName),
+			BuildVariant: APIString(v.BuildVariant),
+			Project:      APIString(v.Project),
+			Version:      APIString(v.Version),
+			Revision:     APIString(v.Revision),
+			Order:        v.RevisionOrderNumber,
+			IsPatch:      v.IsPatch,
+			CreateTime:   APITime(v.CreateTime),
+			Covered:      v.Covered,
+			Total:        v.Total,
+			Percent:      v.Percent(),
+			Packages:     []APICoverageEntry{},
+		}
+		for _, p := range v.Packages {
+			ac.Packages = append(ac.Packages, APICoverageEntry{
+				Name:    APIString(p.Name),
+				Covered: p.Covered,
+				Total:   p.Total,
+				Percent: coverage.Percent(p.Covered, p.Total),
+			})
+		}
+		for _, f := range v.Files {
+			ac.Files = append(ac.Files, APICoverageEntry{
+				Name:    APIString(f.Name),
+				Package: APIString(f.Package),
+				Covered: f.Covered,
+				Total:   f.Total,
+				Percent: coverage.Percent(f.Covered, f.Total),
+			})
+		}
+	case *coverage.Delta:
+		ac.Delta = &APICoverageDelta{
+			BasePercent: v.BasePercent,
+			Change:      v.Change,
+			Files:       []APICoverageChange{},
+		}
+		for _, f := range v.Files {
+			ac.Delta.Files = append(ac.Delta.Files, APICoverageChange{
+				Name:        APIString(f.Name),
+				BaseCovered: f.BaseCovered,
+				BaseTotal:   f.BaseTotal,
+				Covered:     f.Covered,
+				Total:       f.Total,
+				Change:      f.Change,
+			})
+		}
+	default:
+		return errors.New("Incorrect type when creating APICoverage")
+	}
+	return nil
+}
+
+// ToService returns a service layer task coverage using the data from the
+// APICoverage. The delta is left out, since it is computed rather than
+// stored.
+func (ac *APICoverage) ToService() (interface{}, error) {
+	c := &coverage.TaskCoverage{
+		TaskId:              string(ac.TaskId),
+		TaskName:            string(ac.TaskName),
+		BuildVariant:        string(ac.BuildVariant),
+		Project:             string(ac.Project),
+		Version:             string(ac.Version),
+		Revision:            string(ac.Revision),
+		RevisionOrderNumber: ac.Order,
+		IsPatch:             ac.IsPatch,
+		CreateTime:          time.Time(ac.CreateTime),
+		Covered:             ac.Covered,
+		Total:               ac.Total,
+	}
+	for _, p := range ac.Packages {
+		c.Packages = append(c.Packages, coverage.PackageCoverage{
+			Name:    string(p.Name),
+			Covered: p.Covered,
+			Total:   p.Total,
+		})
+	}
+	for _, f := range ac.Files {
+		c.Files = append(c.Files, coverage.FileCoverage{
+			Name:    string(f.Name),
+			Package: string(f.Package),
+			Covered: f.Covered,
+			Total:   f.Total,
+		})
+	}
+	return c, nil
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCoverageBuildFromService(t *testing.T) {
+	Convey("With the coverage of a patch task and its delta", t, func() {
+		c := &coverage.TaskCoverage{
+			TaskId:              "task",
+			TaskName:            "unit",
+			BuildVariant:        "linux",
+			Project:             "evergreen",
+			Version:             "version",
+			Revision:            "abc",
+			RevisionOrderNumber: 4,
+			IsPatch:             true,
+			CreateTime:          time.Unix(12345, 0),
+			Covered:             3,
+			Total:               4,
+			Packages:            []coverage.PackageCoverage{{Name: "util", Covered: 3, Total: 4}},
+			Files:               []coverage.FileCoverage{{Name: "util/a.go", Package: "util", Covered: 3, Total: 4}},
+		}
+		delta := &coverage.Delta{
+			BasePercent: 50,
+			Percent:     75,
+			Change:      25,
+			Files: []coverage.FileDelta{
+				{Name: "util/a.go", BaseCovered: 2, BaseTotal: 4, Covered: 3, Total: 4, Change: 25},
+			},
+		}
+
+		apiCoverage := &APICoverage{}
+		So(apiCoverage.BuildFromService(c), ShouldBeNil)
+		So(apiCoverage.BuildFromService(delta), ShouldBeNil)
+
+		Convey("the model should have the coverage and percentages", func() {
+			So(apiCoverage.TaskId, ShouldEqual, APIString("task"))
+			So(apiCoverage.Order, ShouldEqual, 4)
+			So(apiCoverage.Percent, ShouldAlmostEqual, 75)
+			So(apiCoverage.Packages[0].Percent, ShouldAlmostEqual, 75)
+			So(apiCoverage.Files[0].Package, ShouldEqual, APIString("util"))
+			So(apiCoverage.Delta.BasePercent, ShouldAlmostEqual, 50)
+			So(apiCoverage.Delta.Files[0].BaseCovered, ShouldEqual, 2)
+		})
+
+		Convey("converting back should give the stored coverage", func() {
+			service, err := apiCoverage.ToService()
+			So(err, ShouldBeNil)
+			So(service, ShouldResemble, c)
+		})
+
+		Convey("an unknown type should be an error", func() {
+			So(apiCoverage.BuildFromService("task"), ShouldNotBeNil)
+		})
+	})
+}
@@ -51,10 +51,18 @@ type logLinks struct {
 	SystemLogLink APIString `json:"system_log"`
 }
 type apiTaskEndDetail struct {
-	Status      APIString `json:"status"`
-	Type        APIString `json:"type"`
-	Description APIString `json:"desc"`
-	TimedOut    bool      `json:"timed_out"`
+	Status               APIString `json:"status"`
+	Type                 APIString `json:"type"`
+	Description          APIString `json:"desc"`
+	TimedOut             bool      `json:"timed_out"`
+	OOMKilled            bool      `json:"oom_killed"`
+	FailureType          APIString `json:"failure_type"`
+	FailedCommand        APIString `json:"failed_command"`
+	CommandIndex         int       `json:"command_index"`
+	FunctionName         APIString `json:"function"`
+	FunctionCommandIndex int       `json:"function_command_index"`
+	ExitCode             int       `json:"exit_code"`
+	Signal               APIString `json:"signal"`
 }
 
 // BuildFromService converts from a service level task by loading the data
@@ -85,10 +93,18 @@ func (at *APITask) BuildFromService(t interface{}) error {
 			Execution:     v.Execution,
 			Order:         v.RevisionOrderNumber,
 			Details: apiTaskEndDetail{
-				Status:      APIString(v.Details.Status),
-				Type:        APIString(v.Details.Type),
-				Description: APIString(v.Details.Description),
-				TimedOut:    v.Details.TimedOut,
+				Status:               APIString(v.Details.Status),
+				Type:                 APIString(v.Details.Type),
+				Description:          APIString(v.Details.Description),
+				TimedOut:             v.Details.TimedOut,
+				OOMKilled:            v.Details.OOMKilled,
+				FailureType:          APIString(v.Details.FailureType),
+				FailedCommand:        APIString(v.Details.FailedCommand),
+				CommandIndex:         v.Details.CommandIndex,
+				FunctionName:         APIString(v.Details.FunctionName),
+				FunctionCommandIndex: v.Details.FunctionCommandIndex,
+				ExitCode:             v.Details.ExitCode,
+				Signal:               APIString(v.Details.Signal),
 			},
 			Status:           APIString(v.Status),
 			TimeTaken:        v.TimeTaken,
@@ -142,10 +158,18 @@ func (ad *APITask) ToService() (interface{}, error) {
 		Execution:           ad.Execution,
 		RevisionOrderNumber: ad.Order,
 		Details: apimodels.TaskEndDetail{
-			Status:      string(ad.Details.Status),
-			Type:        string(ad.Details.Type),
-			Description: string(ad.Details.Description),
-			TimedOut:    ad.Details.TimedOut,
+			Status:               string(ad.Details.Status),
+			Type:                 string(ad.Details.Type),
+			Description:          string(ad.Details.Description),
+			TimedOut:             ad.Details.TimedOut,
+			OOMKilled:            ad.Details.OOMKilled,
+			FailureType:          string(ad.Details.FailureType),
+			FailedCommand:        string(ad.Details.FailedCommand),
+			CommandIndex:         ad.Details.CommandIndex,
+			FunctionName:         string(ad.Details.FunctionName),
+			FunctionCommandIndex: ad.Details.FunctionCommandIndex,
+			ExitCode:             ad.Details.ExitCode,
+			Signal:               string(ad.Details.Signal),
 		},
 		Status:           string(ad.Status),
 		TimeTaken:        ad.TimeTaken,
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/pkg/errors"
+)
+
+// APITestFlakiness is the model to be returned by the API whenever the
+// flakiness of a test is fetched.
+type APITestFlakiness struct {
+	TestFile           APIString `json:"test_file"`
+	TaskName           APIString `json:"display_name"`
+	BuildVariant       APIString `json:"build_variant"`
+	Runs               int       `json:"runs"`
+	Failures           int       `json:"failures"`
+	Revisions          int       `json:"revisions"`
+	SameRevisionFlips  int       `json:"same_revision_flips"`
+	Transitions        int       `json:"transitions"`
+	Score              float64   `json:"score"`
+	LastFailedRevision APIString `json:"last_failed_revision"`
+	Quarantined        bool      `json:"quarantined"`
+}
+
+// BuildFromService converts from the service level test flakiness.
+func (af *APITestFlakiness) BuildFromService(h interface{}) error {
+	v, ok := h.(*model.TestFlakiness)
+	if !ok {
+		return errors.New("incorrect type when building APITestFlakiness")
+	}
+	af.TestFile = APIString(v.TestFile)
+	af.TaskName = APIString(v.TaskName)
+	af.BuildVariant = APIString(v.BuildVariant)
+	af.Runs = v.Runs
+	af.Failures = v.Failures
+	af.Revisions = v.Revisions
+	af.SameRevisionFlips = v.SameRevisionFlips
+	af.Transitions = v.Transitions
+	af.Score = v.Score
+	af.LastFailedRevision = APIString(v.LastFailedRevision)
+	af.Quarantined = v.Quarantined
+	return nil
+}
+
+// ToService returns the service level test flakiness.
+func (af *APITestFlakiness) ToService() (interface{}, error) {
+	return &model.TestFlakiness{
+		TestFile:           string(af.TestFile),
+		TaskName:           string(af.TaskName),
+		BuildVariant:       string(af.BuildVariant),
+		Runs:               af.Runs,
+		Failures:           af.Failures,
+		Revisions:          af.Revisions,
+		SameRevisionFlips:  af.SameRevisionFlips,
+		Transitions:        af.Transitions,
+		Score:              af.Score,
+		LastFailedRevision: string(af.LastFailedRevision),
+		Quarantined:        af.Quarantined,
+	}, nil
+}
+
+// APIQuarantinedTest is the model of a test that a project has quarantined.
+type APIQuarantinedTest struct {
+	TestFile   APIString `json:"test_file"`
+	TaskName   APIString `json:"display_name"`
+	Reason     APIString `json:"reason"`
+	Author     APIString `json:"author"`
+	CreateTime APITime   `json:"create_time"`
+}
+
+// BuildFromService converts from the service level quarantined test.
+func (aq *APIQuarantinedTest) BuildFromService(h interface{}) error {
+	v, ok := h.(*model.QuarantinedTest)
+	if !ok {
+		return errors.New("incorrect type when building APIQuarantinedTest")
+	}
+	aq.TestFile = APIString(v.TestFile)
+	aq.TaskName = APIString(v.TaskName)
+	aq.Reason = APIString(v.Reason)
+	aq.Author = APIString(v.Author)
+	aq.CreateTime = NewTime(v.CreateTime)
+	return nil
+}
+
+// ToService returns the service level quarantined test.
+func (aq *APIQuarantinedTest) ToService() (interface{}, error) {
+	return &model.QuarantinedTest{
+		TestFile:   string(aq.TestFile),
+		TaskName:   string(aq.TaskName),
+		Reason:     string(aq.Reason),
+		Author:     string(aq.Author),
+		CreateTime: time.Time(aq.CreateTime),
+	}, nil
+}
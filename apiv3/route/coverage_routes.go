@@ -0,0 +1,179 @@
+package route
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apiv3"
+	"github.com/evergreen-ci/evergreen/apiv3/model"
+	"github.com/evergreen-ci/evergreen/apiv3/servicecontext"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/pkg/errors"
+)
+
+const defaultCoverageHistoryLimit = 50
+
+// getTaskCoverageRouteManager gets the route manager for the
+// GET /tasks/{task_id}/coverage route.
+func getTaskCoverageRouteManager(route string, version int) *RouteManager {
+	tcgh := &taskCoverageGetHandler{}
+	taskCoverageGet := MethodHandler{
+		PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+		Authenticator:     &RequireUserAuthenticator{},
+		RequestHandler:    tcgh.Handler(),
+		MethodType:        evergreen.MethodGet,
+	}
+
+	coverageRoute := RouteManager{
+		Route:   route,
+		Methods: []MethodHandler{taskCoverageGet},
+		Version: version,
+	}
+	return &coverageRoute
+}
+
+// getCoverageHistoryRouteManager gets the route manager for the
+// GET /projects/{project_id}/coverage route.
+func getCoverageHistoryRouteManager(route string, version int) *RouteManager {
+	chgh := &coverageHistoryGetHandler{}
+	coverageHistoryGet := MethodHandler{
+		PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+		Authenticator:     &RequireUserAuthenticator{},
+		RequestHandler:    chgh.Handler(),
+		MethodType:        evergreen.MethodGet,
+	}
+
+	coverageRoute := RouteManager{
+		Route:   route,
+		Methods: []MethodHandler{coverageHistoryGet},
+		Version: version,
+	}
+	return &coverageRoute
+}
+
+// taskCoverageGetHandler implements the route GET /tasks/{task_id}/coverage.
+// It returns the coverage of the task, along with how it changed the
+// coverage of its base commit if the task is in a patch.
+type taskCoverageGetHandler struct {
+	task *task.Task
+}
+
+func (tcgh *taskCoverageGetHandler) Handler() RequestHandler {
+	return &taskCoverageGetHandler{}
+}
+
+// ParseAndValidate fetches the task from the project context.
+func (tcgh *taskCoverageGetHandler) ParseAndValidate(r *http.Request) error {
+	projCtx := MustHaveProjectContext(r)
+	if projCtx.Task == nil {
+		return apiv3.APIError{
+			Message:    "Task not found",
+			StatusCode: http.StatusNotFound,
+		}
+	}
+	tcgh.task = projCtx.Task
+	return nil
+}
+
+// Execute finds the task's coverage and its delta from the base commit.
+func (tcgh *taskCoverageGetHandler) Execute(sc servicecontext.ServiceContext) (ResponseData, error) {
+	c, err := sc.FindCoverageByTaskId(tcgh.task.Id)
+	if err != nil {
+		if _, ok := err.(*apiv3.APIError); !ok {
+			err = errors.Wrap(err, "Database error")
+		}
+		return ResponseData{}, err
+	}
+	delta, err := sc.FindCoverageDelta(tcgh.task)
+	if err != nil {
+		return ResponseData{}, errors.Wrap(err, "Database error")
+	}
+
+	coverageModel := &model.APICoverage{}
+	if err = coverageModel.BuildFromService(c); err != nil {
+		return ResponseData{}, errors.Wrap(err, "API model error")
+	}
+	if delta != nil {
+		if err = coverageModel.BuildFromService(delta); err != nil {
+			return ResponseData{}, errors.Wrap(err, "API model error")
+		}
+	}
+
+	return ResponseData{
+		Result: []model.Model{coverageModel},
+	}, nil
+}
+
+// coverageHistoryGetHandler implements the route
+// GET /projects/{project_id}/coverage. It returns the coverage of a task in a
+// variant on the project's most recent commits, most recent first, without
+// the per-file breakdown. The 'variant' and 'task' query parameters are
+// required, and 'limit' bounds how many commits are returned.
+type coverageHistoryGetHandler struct {
+	projectId string
+	variant   string
+	taskName  string
+	limit     int
+}
+
+func (chgh *coverageHistoryGetHandler) Handler() RequestHandler {
+	return &coverageHistoryGetHandler{}
+}
+
+// ParseAndValidate fetches the project from the project context and the
+// variant, task and limit from the query parameters.
+func (chgh *coverageHistoryGetHandler) ParseAndValidate(r *http.Request) error {
+	projCtx := MustHaveProjectContext(r)
+	if projCtx.ProjectRef == nil {
+		return apiv3.APIError{
+			Message:    "Project not found",
+			StatusCode: http.StatusNotFound,
+		}
+	}
+	chgh.projectId = projCtx.ProjectRef.Identifier
+
+	query := r.URL.Query()
+	chgh.variant = query.Get("variant")
+	chgh.taskName = query.Get("task")
+	if chgh.variant == "" || chgh.taskName == "" {
+		return apiv3.APIError{
+			Message:    "'variant' and 'task' query parameters are required",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	chgh.limit = defaultCoverageHistoryLimit
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		chgh.limit, err = strconv.Atoi(limit)
+		if err != nil || chgh.limit <= 0 {
+			return apiv3.APIError{
+				Message:    "'limit' must be a positive integer",
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+	}
+	return nil
+}
+
+// Execute finds the coverage history.
+func (chgh *coverageHistoryGetHandler) Execute(sc servicecontext.ServiceContext) (ResponseData, error) {
+	history, err := sc.FindCoverageHistory(chgh.projectId, chgh.variant, chgh.taskName, chgh.limit)
+	if err != nil {
+		return ResponseData{}, errors.Wrap(err, "Database error")
+	}
+
+	models := make([]model.Model, len(history))
+	for ix := range history {
+		coverageModel := &model.APICoverage{}
+		if err = coverageModel.BuildFromService(&history[ix]); err != nil {
+			return ResponseData{}, errors.Wrap(err, "API model error")
+		}
+		models[ix] = coverageModel
+	}
+	return ResponseData{
+		Result:   models,
+		Metadata: &ListMetadata{},
+	}, nil
+}
@@ -39,6 +39,11 @@ type ResponseData struct {
 	Metadata interface{}
 }
 
+// ListMetadata is the metadata of a result that is a complete list rather
+// than a page of one. The whole list is written out, even if it is empty,
+// and no pagination headers are set.
+type ListMetadata struct{}
+
 // RequestHandler is an interface that defines how to process an HTTP request
 // against an API resource.
 type RequestHandler interface {
@@ -96,6 +101,8 @@ func makeHandler(methodHandler MethodHandler, sc servicecontext.ServiceContext)
 				return
 			}
 			util.WriteJSON(&w, result.Result, http.StatusOK)
+		case *ListMetadata:
+			util.WriteJSON(&w, result.Result, http.StatusOK)
 		default:
 			if len(result.Result) < 1 {
 				http.Error(w, "{}", http.StatusInternalServerError)
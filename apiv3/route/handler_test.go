@@ -101,6 +101,15 @@ func TestMakeHandler(t *testing.T) {
 			checkResultMatchesBasic(mockMethod, nil,
 				requestHandler.storedModels, http.StatusOK, t)
 		})
+		Convey("And a list result should be returned whole", func() {
+			requestHandler.storedMetadata = &ListMetadata{}
+			requestHandler.storedModels = []model.Model{
+				&model.MockModel{FieldId: "model_1", FieldInt1: 1},
+				&model.MockModel{FieldId: "model_2", FieldInt1: 2},
+			}
+			checkResultMatchesBasic(mockMethod, nil,
+				requestHandler.storedModels, http.StatusOK, t)
+		})
 	})
 }
 
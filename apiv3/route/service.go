@@ -43,6 +43,10 @@ func GetHandler(r *mux.Router, sc servicecontext.ServiceContext) http.Handler {
 	getHostRouteManager("/hosts", 2).Register(r, sc)
 	getTaskRouteManager("/tasks/{task_id}", 2).Register(r, sc)
 	getTestRouteManager("/tasks/{task_id}/tests", 2).Register(r, sc)
+	getTaskCoverageRouteManager("/tasks/{task_id}/coverage", 2).Register(r, sc)
+	getCoverageHistoryRouteManager("/projects/{project_id}/coverage", 2).Register(r, sc)
+	getFlakyTestsRouteManager("/projects/{project_id}/flaky_tests", 2).Register(r, sc)
+	getQuarantinedTestsRouteManager("/projects/{project_id}/quarantined_tests", 2).Register(r, sc)
 	getTasksByProjectAndCommitRouteManager("/projects/{project_id}/revisions/{commit_hash}/tasks", 2).Register(r, sc)
 	getTasksByBuildRouteManager("/builds/{build_id}/tasks", 2).Register(r, sc)
 	getTaskRestartRouteManager("/tasks/{task_id}/restart", 2).Register(r, sc)
@@ -0,0 +1,328 @@
+package route
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apiv3"
+	"github.com/evergreen-ci/evergreen/apiv3/model"
+	"github.com/evergreen-ci/evergreen/apiv3/servicecontext"
+	serviceModel "github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/pkg/errors"
+)
+
+// getFlakyTestsRouteManager gets the route manager for the
+// GET /projects/{project_id}/flaky_tests route.
+func getFlakyTestsRouteManager(route string, version int) *RouteManager {
+	ftgh := &flakyTestsGetHandler{}
+	flakyTestsGet := MethodHandler{
+		PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+		Authenticator:     &RequireUserAuthenticator{},
+		RequestHandler:    ftgh.Handler(),
+		MethodType:        evergreen.MethodGet,
+	}
+
+	flakyTestsRoute := RouteManager{
+		Route:   route,
+		Methods: []MethodHandler{flakyTestsGet},
+		Version: version,
+	}
+	return &flakyTestsRoute
+}
+
+// getQuarantinedTestsRouteManager gets the route manager for the
+// GET, POST and DELETE /projects/{project_id}/quarantined_tests routes.
+func getQuarantinedTestsRouteManager(route string, version int) *RouteManager {
+	qtgh := &quarantinedTestsGetHandler{}
+	quarantinedTestsGet := MethodHandler{
+		PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+		Authenticator:     &RequireUserAuthenticator{},
+		RequestHandler:    qtgh.Handler(),
+		MethodType:        evergreen.MethodGet,
+	}
+
+	qtph := &quarantinedTestPostHandler{}
+	quarantinedTestPost := MethodHandler{
+		PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+		Authenticator:     &ProjectAdminAuthenticator{},
+		RequestHandler:    qtph.Handler(),
+		MethodType:        evergreen.MethodPost,
+	}
+
+	qtdh := &quarantinedTestDeleteHandler{}
+	quarantinedTestDelete := MethodHandler{
+		PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+		Authenticator:     &ProjectAdminAuthenticator{},
+		RequestHandler:    qtdh.Handler(),
+		MethodType:        evergreen.MethodDelete,
+	}
+
+	quarantinedTestsRoute := RouteManager{
+		Route:   route,
+		Methods: []MethodHandler{quarantinedTestsGet, quarantinedTestPost, quarantinedTestDelete},
+		Version: version,
+	}
+	return &quarantinedTestsRoute
+}
+
+// mustHaveProjectRef returns the project from the project context, or an
+// error if there is none.
+func mustHaveProjectRef(r *http.Request) (*serviceModel.ProjectRef, error) {
+	projCtx := MustHaveProjectContext(r)
+	if projCtx.ProjectRef == nil {
+		return nil, apiv3.APIError{
+			Message:    "Project not found",
+			StatusCode: http.StatusNotFound,
+		}
+	}
+	return projCtx.ProjectRef, nil
+}
+
+// splitParam splits a comma separated query parameter, leaving out empty
+// values.
+func splitParam(value string) []string {
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// flakyTestsGetHandler implements the route
+// GET /projects/{project_id}/flaky_tests. It returns the tests that failed on
+// the project's most recent commits, most flaky first. The 'tasks',
+// 'variants' and 'tests' query parameters are comma separated lists that
+// filter the tests, and one of 'tasks' or 'tests' is required. 'revisions'
+// is how many commits are considered, and 'min_score' leaves out tests that
+// are less flaky.
+type flakyTestsGetHandler struct {
+	params serviceModel.TestFlakinessParameters
+}
+
+func (ftgh *flakyTestsGetHandler) Handler() RequestHandler {
+	return &flakyTestsGetHandler{}
+}
+
+// ParseAndValidate fetches the project from the project context and the
+// filters from the query parameters.
+func (ftgh *flakyTestsGetHandler) ParseAndValidate(r *http.Request) error {
+	projectRef, err := mustHaveProjectRef(r)
+	if err != nil {
+		return err
+	}
+	query := r.URL.Query()
+	ftgh.params = serviceModel.TestFlakinessParameters{
+		Project:       projectRef.Identifier,
+		TaskNames:     splitParam(query.Get("tasks")),
+		BuildVariants: splitParam(query.Get("variants")),
+		TestNames:     splitParam(query.Get("tests")),
+	}
+	if len(ftgh.params.TaskNames) == 0 && len(ftgh.params.TestNames) == 0 {
+		return apiv3.APIError{
+			Message:    "'tasks' or 'tests' query parameter is required",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if revisions := query.Get("revisions"); revisions != "" {
+		ftgh.params.Revisions, err = strconv.Atoi(revisions)
+		if err != nil || ftgh.params.Revisions <= 0 {
+			return apiv3.APIError{
+				Message:    "'revisions' must be a positive integer",
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+	}
+	if minScore := query.Get("min_score"); minScore != "" {
+		ftgh.params.MinScore, err = strconv.ParseFloat(minScore, 64)
+		if err != nil || ftgh.params.MinScore < 0 || ftgh.params.MinScore > 1 {
+			return apiv3.APIError{
+				Message:    "'min_score' must be a number between 0 and 1",
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+	}
+	return nil
+}
+
+// Execute computes the flakiness of the tests.
+func (ftgh *flakyTestsGetHandler) Execute(sc servicecontext.ServiceContext) (ResponseData, error) {
+	flakiness, err := sc.FindTestFlakiness(&ftgh.params)
+	if err != nil {
+		return ResponseData{}, errors.Wrap(err, "Database error")
+	}
+
+	models := make([]model.Model, len(flakiness))
+	for ix := range flakiness {
+		flakinessModel := &model.APITestFlakiness{}
+		if err = flakinessModel.BuildFromService(&flakiness[ix]); err != nil {
+			return ResponseData{}, errors.Wrap(err, "API model error")
+		}
+		models[ix] = flakinessModel
+	}
+	return ResponseData{
+		Result:   models,
+		Metadata: &ListMetadata{},
+	}, nil
+}
+
+// quarantinedTestsGetHandler implements the route
+// GET /projects/{project_id}/quarantined_tests. It returns the tests the
+// project has quarantined.
+type quarantinedTestsGetHandler struct {
+	quarantined []serviceModel.QuarantinedTest
+}
+
+func (qtgh *quarantinedTestsGetHandler) Handler() RequestHandler {
+	return &quarantinedTestsGetHandler{}
+}
+
+// ParseAndValidate fetches the project from the project context.
+func (qtgh *quarantinedTestsGetHandler) ParseAndValidate(r *http.Request) error {
+	projectRef, err := mustHaveProjectRef(r)
+	if err != nil {
+		return err
+	}
+	qtgh.quarantined = projectRef.QuarantinedTests
+	return nil
+}
+
+// Execute returns the project's quarantined tests.
+func (qtgh *quarantinedTestsGetHandler) Execute(sc servicecontext.ServiceContext) (ResponseData, error) {
+	models := make([]model.Model, len(qtgh.quarantined))
+	for ix := range qtgh.quarantined {
+		quarantinedModel := &model.APIQuarantinedTest{}
+		if err := quarantinedModel.BuildFromService(&qtgh.quarantined[ix]); err != nil {
+			return ResponseData{}, errors.Wrap(err, "API model error")
+		}
+		models[ix] = quarantinedModel
+	}
+	return ResponseData{
+		Result:   models,
+		Metadata: &ListMetadata{},
+	}, nil
+}
+
+// quarantinedTestPostHandler implements the route
+// POST /projects/{project_id}/quarantined_tests. It quarantines the test in
+// the request body, whose failures are then recorded but do not fail tasks.
+// Only project admins may quarantine tests.
+type quarantinedTestPostHandler struct {
+	projectId string
+	test      serviceModel.QuarantinedTest
+}
+
+func (qtph *quarantinedTestPostHandler) Handler() RequestHandler {
+	return &quarantinedTestPostHandler{}
+}
+
+// ParseAndValidate fetches the project from the project context and the test
+// from the request body.
+func (qtph *quarantinedTestPostHandler) ParseAndValidate(r *http.Request) error {
+	projectRef, err := mustHaveProjectRef(r)
+	if err != nil {
+		return err
+	}
+	qtph.projectId = projectRef.Identifier
+
+	body := util.NewRequestReader(r)
+	defer body.Close()
+	apiTest := &model.APIQuarantinedTest{}
+	if err = json.NewDecoder(body).Decode(apiTest); err != nil {
+		if err == io.EOF {
+			return apiv3.APIError{
+				Message:    "No request body sent",
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+		return apiv3.APIError{
+			Message:    errors.Wrap(err, "JSON unmarshal error").Error(),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	if apiTest.TestFile == "" {
+		return apiv3.APIError{
+			Message:    "Must set 'test_file'",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	test, err := apiTest.ToService()
+	if err != nil {
+		return errors.Wrap(err, "API model error")
+	}
+	qtph.test = *test.(*serviceModel.QuarantinedTest)
+	qtph.test.Author = MustHaveUser(r).Username()
+	qtph.test.CreateTime = time.Now()
+	return nil
+}
+
+// Execute quarantines the test.
+func (qtph *quarantinedTestPostHandler) Execute(sc servicecontext.ServiceContext) (ResponseData, error) {
+	if err := sc.AddQuarantinedTest(qtph.projectId, qtph.test); err != nil {
+		return ResponseData{}, errors.Wrap(err, "Database error")
+	}
+	quarantinedModel := &model.APIQuarantinedTest{}
+	if err := quarantinedModel.BuildFromService(&qtph.test); err != nil {
+		return ResponseData{}, errors.Wrap(err, "API model error")
+	}
+	return ResponseData{
+		Result: []model.Model{quarantinedModel},
+	}, nil
+}
+
+// quarantinedTestDeleteHandler implements the route
+// DELETE /projects/{project_id}/quarantined_tests. It lifts the quarantine
+// of the test named by the 'test' query parameter in the task named by the
+// 'task' query parameter, which is empty for tests quarantined in every
+// task. Only project admins may lift quarantines.
+type quarantinedTestDeleteHandler struct {
+	projectId string
+	taskName  string
+	testFile  string
+}
+
+func (qtdh *quarantinedTestDeleteHandler) Handler() RequestHandler {
+	return &quarantinedTestDeleteHandler{}
+}
+
+// ParseAndValidate fetches the project from the project context and the test
+// and task from the query parameters.
+func (qtdh *quarantinedTestDeleteHandler) ParseAndValidate(r *http.Request) error {
+	projectRef, err := mustHaveProjectRef(r)
+	if err != nil {
+		return err
+	}
+	qtdh.projectId = projectRef.Identifier
+	qtdh.testFile = r.URL.Query().Get("test")
+	qtdh.taskName = r.URL.Query().Get("task")
+	if qtdh.testFile == "" {
+		return apiv3.APIError{
+			Message:    "'test' query parameter is required",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	return nil
+}
+
+// Execute lifts the quarantine.
+func (qtdh *quarantinedTestDeleteHandler) Execute(sc servicecontext.ServiceContext) (ResponseData, error) {
+	if err := sc.RemoveQuarantinedTest(qtdh.projectId, qtdh.taskName, qtdh.testFile); err != nil {
+		if _, ok := err.(*apiv3.APIError); !ok {
+			err = errors.Wrap(err, "Database error")
+		}
+		return ResponseData{}, err
+	}
+	return ResponseData{
+		Result:   []model.Model{},
+		Metadata: &ListMetadata{},
+	}, nil
+}
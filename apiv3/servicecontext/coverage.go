@@ -0,0 +1,93 @@
+package servicecontext
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/evergreen-ci/evergreen/apiv3"
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/evergreen-ci/evergreen/model/task"
+)
+
+// DBCoverageConnector is a struct that implements the Coverage related
+// methods from the ServiceContext through interactions with the backing
+// database.
+type DBCoverageConnector struct{}
+
+// FindCoverageByTaskId returns the coverage attached to the task.
+func (cc *DBCoverageConnector) FindCoverageByTaskId(taskId string) (*coverage.TaskCoverage, error) {
+	c, err := coverage.FindOne(coverage.ByTaskId(taskId))
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, &apiv3.APIError{
+			StatusCode: http.StatusNotFound,
+			Message:    fmt.Sprintf("coverage for task with id '%s' not found", taskId),
+		}
+	}
+	return c, nil
+}
+
+// FindCoverageDelta returns how a patch task changed the coverage of its
+// base commit, or nil if the task is not in a patch or its base has no
+// coverage.
+func (cc *DBCoverageConnector) FindCoverageDelta(t *task.Task) (*coverage.Delta, error) {
+	return coverage.DeltaForTask(t)
+}
+
+// FindCoverageHistory returns the coverage of a task in a variant on up to
+// limit of the project's most recent commits.
+func (cc *DBCoverageConnector) FindCoverageHistory(projectId, variant, taskName string,
+	limit int) ([]coverage.TaskCoverage, error) {
+	return coverage.Find(coverage.History(projectId, variant, taskName, 0, limit))
+}
+
+// MockCoverageConnector stores a cached set of task coverage that is queried
+// against by the implementations of the ServiceContext interface's Coverage
+// related functions.
+type MockCoverageConnector struct {
+	CachedCoverage []coverage.TaskCoverage
+	CachedDelta    *coverage.Delta
+	StoredError    error
+}
+
+// FindCoverageByTaskId returns the cached coverage with the given task id.
+func (mcc *MockCoverageConnector) FindCoverageByTaskId(taskId string) (*coverage.TaskCoverage, error) {
+	if mcc.StoredError != nil {
+		return nil, mcc.StoredError
+	}
+	for _, c := range mcc.CachedCoverage {
+		if c.TaskId == taskId {
+			return &c, nil
+		}
+	}
+	return nil, &apiv3.APIError{
+		StatusCode: http.StatusNotFound,
+		Message:    fmt.Sprintf("coverage for task with id '%s' not found", taskId),
+	}
+}
+
+// FindCoverageDelta returns the cached delta.
+func (mcc *MockCoverageConnector) FindCoverageDelta(t *task.Task) (*coverage.Delta, error) {
+	return mcc.CachedDelta, mcc.StoredError
+}
+
+// FindCoverageHistory returns up to limit of the cached coverage that
+// matches the project, variant and task name, in the order it was cached.
+func (mcc *MockCoverageConnector) FindCoverageHistory(projectId, variant, taskName string,
+	limit int) ([]coverage.TaskCoverage, error) {
+	if mcc.StoredError != nil {
+		return nil, mcc.StoredError
+	}
+	history := []coverage.TaskCoverage{}
+	for _, c := range mcc.CachedCoverage {
+		if len(history) == limit {
+			break
+		}
+		if c.Project == projectId && c.BuildVariant == variant && c.TaskName == taskName && !c.IsPatch {
+			history = append(history, c)
+		}
+	}
+	return history, nil
+}
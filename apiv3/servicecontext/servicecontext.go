@@ -3,6 +3,7 @@ package servicecontext
 import (
 	"github.com/evergreen-ci/evergreen/auth"
 	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/coverage"
 	"github.com/evergreen-ci/evergreen/model/host"
 	"github.com/evergreen-ci/evergreen/model/task"
 )
@@ -48,6 +49,30 @@ type ServiceContext interface {
 	// limit, and sort to provide additional control over the results.
 	FindTestsByTaskId(string, string, string, int, int) ([]task.TestResult, error)
 
+	// FindCoverageByTaskId is a method to find the code coverage attached
+	// to a task.
+	FindCoverageByTaskId(string) (*coverage.TaskCoverage, error)
+
+	// FindCoverageDelta is a method to find how a patch task changed the
+	// code coverage of its base commit.
+	FindCoverageDelta(*task.Task) (*coverage.Delta, error)
+
+	// FindCoverageHistory is a method to find the code coverage of a task
+	// on a project's most recent commits. It takes the projectId, variant,
+	// task name and a limit on the number of commits.
+	FindCoverageHistory(string, string, string, int) ([]coverage.TaskCoverage, error)
+
+	// FindTestFlakiness is a method to compute how flaky a project's tests
+	// have been on its most recent commits.
+	FindTestFlakiness(*model.TestFlakinessParameters) ([]model.TestFlakiness, error)
+
+	// AddQuarantinedTest and RemoveQuarantinedTest are methods to quarantine
+	// a test in a project, so that its failures do not fail tasks, and to
+	// lift the quarantine. RemoveQuarantinedTest takes the projectId, task
+	// name and test file.
+	AddQuarantinedTest(string, model.QuarantinedTest) error
+	RemoveQuarantinedTest(string, string, string) error
+
 	// FindUserById is a method to find a specific user given its ID.
 	FindUserById(string) (auth.APIUser, error)
 
@@ -73,6 +98,8 @@ type DBServiceContext struct {
 	DBContextConnector
 	DBHostConnector
 	DBTestConnector
+	DBCoverageConnector
+	DBTestFlakinessConnector
 }
 
 func (ctx *DBServiceContext) GetSuperUsers() []string {
@@ -104,6 +131,8 @@ type MockServiceContext struct {
 	MockContextConnector
 	MockHostConnector
 	MockTestConnector
+	MockCoverageConnector
+	MockTestFlakinessConnector
 }
 
 func (ctx *MockServiceContext) GetSuperUsers() []string {
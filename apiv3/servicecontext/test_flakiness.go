@@ -0,0 +1,84 @@
+package servicecontext
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/evergreen-ci/evergreen/apiv3"
+	"github.com/evergreen-ci/evergreen/model"
+)
+
+// DBTestFlakinessConnector is a struct that implements the test flakiness
+// and quarantine related methods from the ServiceContext through
+// interactions with the backing database.
+type DBTestFlakinessConnector struct{}
+
+// FindTestFlakiness computes the flakiness of the project's tests from
+// their history.
+func (fc *DBTestFlakinessConnector) FindTestFlakiness(params *model.TestFlakinessParameters) ([]model.TestFlakiness, error) {
+	return model.GetTestFlakiness(params)
+}
+
+// AddQuarantinedTest quarantines a test in the project.
+func (fc *DBTestFlakinessConnector) AddQuarantinedTest(projectId string, test model.QuarantinedTest) error {
+	return model.AddQuarantinedTest(projectId, test)
+}
+
+// RemoveQuarantinedTest lifts the quarantine of a test in the project.
+func (fc *DBTestFlakinessConnector) RemoveQuarantinedTest(projectId, taskName, testFile string) error {
+	return model.RemoveQuarantinedTest(projectId, taskName, testFile)
+}
+
+// MockTestFlakinessConnector stores cached test flakiness and quarantined
+// tests that are queried and updated by the implementations of the
+// ServiceContext interface's test flakiness related functions.
+type MockTestFlakinessConnector struct {
+	CachedFlakiness   []model.TestFlakiness
+	CachedQuarantined map[string][]model.QuarantinedTest
+	StoredError       error
+}
+
+// FindTestFlakiness returns the cached flakiness that is at least as flaky
+// as the minimum score.
+func (mfc *MockTestFlakinessConnector) FindTestFlakiness(params *model.TestFlakinessParameters) ([]model.TestFlakiness, error) {
+	if mfc.StoredError != nil {
+		return nil, mfc.StoredError
+	}
+	flakiness := []model.TestFlakiness{}
+	for _, f := range mfc.CachedFlakiness {
+		if f.Score >= params.MinScore {
+			flakiness = append(flakiness, f)
+		}
+	}
+	return flakiness, nil
+}
+
+// AddQuarantinedTest caches the quarantined test under the project.
+func (mfc *MockTestFlakinessConnector) AddQuarantinedTest(projectId string, test model.QuarantinedTest) error {
+	if mfc.StoredError != nil {
+		return mfc.StoredError
+	}
+	if mfc.CachedQuarantined == nil {
+		mfc.CachedQuarantined = map[string][]model.QuarantinedTest{}
+	}
+	mfc.CachedQuarantined[projectId] = append(mfc.CachedQuarantined[projectId], test)
+	return nil
+}
+
+// RemoveQuarantinedTest removes the cached quarantined test from the project.
+func (mfc *MockTestFlakinessConnector) RemoveQuarantinedTest(projectId, taskName, testFile string) error {
+	if mfc.StoredError != nil {
+		return mfc.StoredError
+	}
+	for i, q := range mfc.CachedQuarantined[projectId] {
+		if q.TestFile == testFile && q.TaskName == taskName {
+			tests := mfc.CachedQuarantined[projectId]
+			mfc.CachedQuarantined[projectId] = append(tests[:i], tests[i+1:]...)
+			return nil
+		}
+	}
+	return &apiv3.APIError{
+		StatusCode: http.StatusNotFound,
+		Message:    fmt.Sprintf("quarantined test '%s' not found", testFile),
+	}
+}
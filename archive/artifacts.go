@@ -3,7 +3,6 @@ package archive
 import (
 	"archive/tar"
 	"compress/gzip"
-	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -21,168 +20,231 @@ type TarContentsFile struct {
 	info os.FileInfo
 }
 
-// BuildArchive reads the rootPath directory into the tar.Writer,
-// taking included and excluded strings into account.
-// Returns the number of files that were added to the archive
-func BuildArchive(tarWriter *tar.Writer, rootPath string, includes []string,
-	excludes []string, log *slogger.Logger) (int, error) {
+// findContents returns the files under rootPath that match the include
+// patterns, in the order the patterns are given. A pattern of "dir/**" matches
+// everything under dir, "dir/**suffix" matches files under dir whose names end
+// in suffix, and any other pattern is matched against the names of the files
+// directly in its directory.
+func findContents(rootPath string, includes []string) ([]TarContentsFile, error) {
+	contents := []TarContentsFile{}
+	for _, includePattern := range includes {
+		dir, filematch := filepath.Split(includePattern)
+		dir = filepath.Join(rootPath, dir)
+		exists, err := util.FileExists(dir)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if !exists {
+			continue
+		}
 
-	pathsToAdd := make(chan TarContentsFile)
-	done := make(chan bool)
-	errChan := make(chan error)
-	numFilesArchived := 0
-	go func(outputChan chan TarContentsFile) {
-		for _, includePattern := range includes {
-			dir, filematch := filepath.Split(includePattern)
-			dir = filepath.Join(rootPath, dir)
-			exists, err := util.FileExists(dir)
-			if err != nil {
-				errChan <- err
+		var walk filepath.WalkFunc
+		var matchErr error
+
+		if filematch == "**" {
+			walk = func(path string, info os.FileInfo, err error) error {
+				if err == nil {
+					contents = append(contents, TarContentsFile{path, info})
+				}
+				return nil
 			}
-			if !exists {
-				continue
+			grip.Warning(filepath.Walk(dir, walk))
+		} else if strings.Contains(filematch, "**") {
+			globSuffix := filematch[2:]
+			walk = func(path string, info os.FileInfo, err error) error {
+				if err == nil && strings.HasSuffix(filepath.Base(path), globSuffix) {
+					contents = append(contents, TarContentsFile{path, info})
+				}
+				return nil
 			}
-
-			var walk filepath.WalkFunc
-
-			if filematch == "**" {
-				walk = func(path string, info os.FileInfo, err error) error {
-					outputChan <- TarContentsFile{path, info}
+			grip.Warning(filepath.Walk(dir, walk))
+		} else {
+			walk = func(path string, info os.FileInfo, err error) error {
+				if err != nil {
 					return nil
 				}
-				grip.Warning(filepath.Walk(dir, walk))
-			} else if strings.Contains(filematch, "**") {
-				globSuffix := filematch[2:]
-				walk = func(path string, info os.FileInfo, err error) error {
-					if strings.HasSuffix(filepath.Base(path), globSuffix) {
-						outputChan <- TarContentsFile{path, info}
+				a, b := filepath.Split(path)
+				if filepath.Clean(a) == filepath.Clean(dir) {
+					match, err := filepath.Match(filematch, b)
+					if err != nil {
+						matchErr = err
+						return err
 					}
-					return nil
-				}
-				grip.Warning(filepath.Walk(dir, walk))
-			} else {
-				walk = func(path string, info os.FileInfo, err error) error {
-					a, b := filepath.Split(path)
-					if filepath.Clean(a) == filepath.Clean(dir) {
-						match, err := filepath.Match(filematch, b)
-						if err != nil {
-							errChan <- err
-						}
-						if match {
-							outputChan <- TarContentsFile{path, info}
-						}
+					if match {
+						contents = append(contents, TarContentsFile{path, info})
 					}
-					return nil
 				}
-				grip.Warning(filepath.Walk(rootPath, walk))
+				return nil
 			}
+			grip.Warning(filepath.Walk(rootPath, walk))
 		}
-		close(outputChan)
-		return
-	}(pathsToAdd)
-
-	go func(inputChan chan TarContentsFile) {
-		processed := map[string]bool{}
-	FileChanLoop:
-		for file := range inputChan {
-			var intarball string
-			// Tarring symlinks doesn't work reliably right now, so if the file is
-			// a symlink, leave intarball path intact but write from the file
-			// underlying the symlink.
-			if file.info.Mode()&os.ModeSymlink > 0 {
+		if matchErr != nil {
+			return nil, errors.Wrapf(matchErr, "invalid include pattern '%s'", includePattern)
+		}
+	}
+	return contents, nil
+}
+
+// archiveWriter adds files found on disk to an archive.
+type archiveWriter interface {
+	// WriteFile adds the regular file at path to the archive under name.
+	WriteFile(name, path string, info os.FileInfo) error
+	// WriteSymlink adds a symlink pointing to target under name.
+	WriteSymlink(name, target string, info os.FileInfo) error
+}
+
+// buildArchive adds the files under rootPath that match the includes and none
+// of the excludes to the archive. Symlinks are either followed, so that the
+// file they point to is archived under their name, or preserved as links.
+func buildArchive(w archiveWriter, rootPath string, includes []string,
+	excludes []string, preserveSymlinks bool, log *slogger.Logger) (int, error) {
+
+	files, err := findContents(rootPath, includes)
+	if err != nil {
+		return 0, err
+	}
+
+	numFilesArchived := 0
+	processed := map[string]bool{}
+	rootPathPrefix := strings.Replace(rootPath, "\\", "/", -1)
+FileLoop:
+	for _, file := range files {
+		inArchive := archivePath(file.path, rootPathPrefix)
+		linkTarget := ""
+		if file.info.Mode()&os.ModeSymlink > 0 {
+			if preserveSymlinks {
+				linkTarget, err = os.Readlink(file.path)
+				if err != nil {
+					log.Logf(slogger.WARN, "Could not read symlink %v, ignoring", file.path)
+					continue
+				}
+			} else {
+				// Tarring symlinks doesn't work reliably right now, so if the
+				// file is a symlink, leave its path in the archive intact but
+				// write from the file underlying the symlink.
 				symlinkPath, err := filepath.EvalSymlinks(file.path)
 				if err != nil {
 					log.Logf(slogger.WARN, "Could not follow symlink %v, ignoring", file.path)
 					continue
-				} else {
-					log.Logf(slogger.INFO, "Following symlink in %v, got: %v", file.path, symlinkPath)
-					symlinkFileInfo, err := os.Stat(symlinkPath)
-					if err != nil {
-						log.Logf(slogger.WARN, "Failed to get underlying file `%v` for symlink %v, ignoring", symlinkPath, file.path)
-						continue
-					}
-
-					intarball = strings.Replace(file.path, "\\", "/", -1)
-					file.path = symlinkPath
-					file.info = symlinkFileInfo
 				}
-			} else {
-				intarball = strings.Replace(file.path, "\\", "/", -1)
-			}
-			rootPathPrefix := strings.Replace(rootPath, "\\", "/", -1)
-			intarball = strings.Replace(intarball, "\\", "/", -1)
-			intarball = strings.Replace(intarball, rootPathPrefix, "", 1)
-			intarball = filepath.Clean(intarball)
-			intarball = strings.Replace(intarball, "\\", "/", -1)
-
-			//strip any leading slash from the tarball header path
-			intarball = strings.TrimLeft(intarball, "/")
-
-			log.Logf(slogger.INFO, "Adding to tarball: %s", intarball)
-			if _, hasKey := processed[intarball]; hasKey {
-				continue
-			} else {
-				processed[intarball] = true
-			}
-			if file.info.IsDir() {
-				continue
-			}
-
-			_, fileName := filepath.Split(file.path)
-			for _, ignore := range excludes {
-				if match, _ := filepath.Match(ignore, fileName); match {
-					continue FileChanLoop
+				log.Logf(slogger.INFO, "Following symlink in %v, got: %v", file.path, symlinkPath)
+				symlinkFileInfo, err := os.Stat(symlinkPath)
+				if err != nil {
+					log.Logf(slogger.WARN, "Failed to get underlying file `%v` for symlink %v, ignoring", symlinkPath, file.path)
+					continue
 				}
+				file.info = symlinkFileInfo
+				file.path = symlinkPath
 			}
+		}
 
-			hdr := new(tar.Header)
-			hdr.Name = strings.TrimPrefix(intarball, rootPathPrefix)
-			hdr.Mode = int64(file.info.Mode())
-			hdr.Size = file.info.Size()
-			hdr.ModTime = file.info.ModTime()
-
-			numFilesArchived++
-			err := tarWriter.WriteHeader(hdr)
-			if err != nil {
-				errChan <- errors.Wrapf(err, "Error writing header for %v", intarball)
-				return
-			}
+		log.Logf(slogger.INFO, "Adding to archive: %s", inArchive)
+		if _, hasKey := processed[inArchive]; hasKey {
+			continue
+		} else {
+			processed[inArchive] = true
+		}
+		if file.info.IsDir() {
+			continue
+		}
 
-			in, err := os.Open(file.path)
-			if err != nil {
-				errChan <- errors.Wrapf(err, "Error opening %v", file.path)
-				return
+		_, fileName := filepath.Split(file.path)
+		for _, ignore := range excludes {
+			if match, _ := filepath.Match(ignore, fileName); match {
+				continue FileLoop
 			}
+		}
 
-			amountWrote, err := io.Copy(tarWriter, in)
-			if err != nil {
-				grip.Debug(in.Close())
-				errChan <- errors.Wrapf(err, "Error writing into tar for %v", file.path)
-				return
-			}
+		numFilesArchived++
+		if linkTarget != "" {
+			err = w.WriteSymlink(inArchive, linkTarget, file.info)
+		} else {
+			err = w.WriteFile(inArchive, file.path, file.info)
+		}
+		if err != nil {
+			return numFilesArchived, err
+		}
+	}
+	return numFilesArchived, nil
+}
+
+// archivePath returns the slash-separated path of a file in an archive rooted
+// at rootPathPrefix.
+func archivePath(path, rootPathPrefix string) string {
+	path = strings.Replace(path, "\\", "/", -1)
+	path = strings.Replace(path, rootPathPrefix, "", 1)
+	path = filepath.Clean(path)
+	path = strings.Replace(path, "\\", "/", -1)
+
+	//strip any leading slash from the archive header path
+	return strings.TrimLeft(path, "/")
+}
+
+// tarArchiveWriter adds files to a tarball.
+type tarArchiveWriter struct {
+	tarWriter *tar.Writer
+}
+
+func (t *tarArchiveWriter) WriteFile(name, path string, info os.FileInfo) error {
+	hdr := new(tar.Header)
+	hdr.Name = name
+	hdr.Mode = int64(info.Mode().Perm())
+	hdr.Size = info.Size()
+	hdr.ModTime = info.ModTime()
+
+	err := t.tarWriter.WriteHeader(hdr)
+	if err != nil {
+		return errors.Wrapf(err, "Error writing header for %v", name)
+	}
+
+	in, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "Error opening %v", path)
+	}
+	defer in.Close()
 
-			if amountWrote != hdr.Size {
-				grip.Debug(in.Close())
-				errChan <- errors.Errorf(`Error writing to archive for %v:
+	amountWrote, err := io.Copy(t.tarWriter, in)
+	if err != nil {
+		return errors.Wrapf(err, "Error writing into tar for %v", path)
+	}
+
+	if amountWrote != hdr.Size {
+		return errors.Errorf(`Error writing to archive for %v:
 					header size %v but wrote %v`,
-					intarball, hdr.Size, amountWrote)
-				return
-			}
-			grip.Debug(in.Close())
-			grip.Warning(tarWriter.Flush())
-		}
-		done <- true
-	}(pathsToAdd)
-
-	select {
-	case <-done:
-		return numFilesArchived, nil
-	case err := <-errChan:
-		return numFilesArchived, err
+			name, hdr.Size, amountWrote)
 	}
+	grip.Warning(t.tarWriter.Flush())
+	return nil
+}
+
+func (t *tarArchiveWriter) WriteSymlink(name, target string, info os.FileInfo) error {
+	hdr := &tar.Header{
+		Typeflag: tar.TypeSymlink,
+		Name:     name,
+		Linkname: target,
+		Mode:     int64(info.Mode().Perm()),
+		ModTime:  info.ModTime(),
+	}
+	return errors.Wrapf(t.tarWriter.WriteHeader(hdr), "Error writing header for %v", name)
+}
+
+// BuildArchive reads the rootPath directory into the tar.Writer,
+// taking included and excluded strings into account.
+// Returns the number of files that were added to the archive
+func BuildArchive(tarWriter *tar.Writer, rootPath string, includes []string,
+	excludes []string, log *slogger.Logger) (int, error) {
+	return buildArchive(&tarArchiveWriter{tarWriter}, rootPath, includes, excludes, false, log)
+}
+
+// BuildArchiveWithSymlinks is BuildArchive, except that symlinks are stored as
+// links rather than replaced by the files they point to.
+func BuildArchiveWithSymlinks(tarWriter *tar.Writer, rootPath string, includes []string,
+	excludes []string, log *slogger.Logger) (int, error) {
+	return buildArchive(&tarArchiveWriter{tarWriter}, rootPath, includes, excludes, true, log)
 }
 
-// Extract unpacks the tar.Reader into rootPath.
+// Extract unpacks the tar.Reader into rootPath. Entries whose paths or link
+// targets would end up outside rootPath are rejected.
 func Extract(tarReader *tar.Reader, rootPath string) error {
 	for {
 		hdr, err := tarReader.Next()
@@ -193,51 +255,163 @@ func Extract(tarReader *tar.Reader, rootPath string) error {
 			return errors.WithStack(err)
 		}
 
-		if hdr.Typeflag == tar.TypeDir {
+		localPath, err := extractPath(rootPath, hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
 			// this tar entry is a directory - need to mkdir it
-			localDir := fmt.Sprintf("%v/%v", rootPath, hdr.Name)
-			err = os.MkdirAll(localDir, 0755)
-			if err != nil {
-				return errors.WithStack(err)
+			if err = extractDir(rootPath, localPath); err != nil {
+				return err
 			}
-		} else if hdr.Typeflag == tar.TypeReg || hdr.Typeflag == tar.TypeRegA {
+		case tar.TypeReg, tar.TypeRegA:
 			// this tar entry is a regular file (not a dir or link)
-			// first, ensure the file's parent directory exists
-			localFile := fmt.Sprintf("%v/%v", rootPath, hdr.Name)
-			dir := filepath.Dir(localFile)
-			err = os.MkdirAll(dir, 0755)
-			if err != nil {
-				return errors.WithStack(err)
+			if err = extractFile(tarReader, localPath, os.FileMode(hdr.Mode).Perm()); err != nil {
+				return err
 			}
+		case tar.TypeSymlink:
+			if err = extractSymlink(rootPath, localPath, hdr.Linkname); err != nil {
+				return err
+			}
+		default:
+			return errors.New("Unknown file type in archive.")
+		}
+	}
+}
 
-			// Now create the file itself, and write in the contents.
+// extractPath returns where an archive entry with the given name is extracted
+// to under rootPath, or an error if that is outside rootPath. Besides the
+// entry's name, the links already in its parent directory are followed, so
+// that an entry can't be written through a link extracted before it.
+func extractPath(rootPath, name string) (string, error) {
+	localPath := filepath.Join(rootPath, filepath.FromSlash(name))
+	if !isWithin(rootPath, localPath) {
+		return "", errors.Errorf("archive entry '%s' is outside the destination directory", name)
+	}
+	if err := checkResolvedWithin(rootPath, filepath.Dir(localPath)); err != nil {
+		return "", errors.Wrapf(err, "archive entry '%s'", name)
+	}
+	return localPath, nil
+}
 
-			// Not using 'defer f.Close()' because this is in a loop,
-			// and we don't want to wait for the whole archive to finish to
-			// close the files - so each is closed explicitly.
+// checkResolvedWithin returns an error if path is outside rootPath once the
+// links in both of them are followed.
+func checkResolvedWithin(rootPath, path string) error {
+	resolvedRoot, err := resolvePath(rootPath)
+	if err != nil {
+		return err
+	}
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return err
+	}
+	if !isWithin(resolvedRoot, resolved) {
+		return errors.Errorf("'%s' leads outside the destination directory", path)
+	}
+	return nil
+}
 
-			f, err := os.Create(localFile)
-			if err != nil {
-				return errors.WithStack(err)
-			}
+// resolvePath returns path with the links in the part of it that already
+// exists followed. The part that doesn't exist yet is kept as it is.
+func resolvePath(path string) (string, error) {
+	existing, rest := filepath.Clean(path), ""
+	for {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			return filepath.Join(resolved, rest), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", errors.WithStack(err)
+		}
+		// a link whose target doesn't exist would be created through
+		if _, err = os.Lstat(existing); err == nil {
+			return "", errors.Errorf("'%s' is a link to a path that doesn't exist", existing)
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return path, nil
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+}
 
-			_, err = io.Copy(f, tarReader)
-			if err != nil {
-				grip.CatchError(f.Close())
-				return errors.WithStack(err)
-			}
+// isWithin returns whether path is rootPath or below it.
+func isWithin(rootPath, path string) bool {
+	rel, err := filepath.Rel(filepath.Clean(rootPath), filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
 
-			// File's permissions should match what was in the archive
-			err = os.Chmod(f.Name(), os.FileMode(int32(hdr.Mode)))
-			if err != nil {
-				grip.CatchError(f.Close())
-				return errors.WithStack(err)
-			}
-			grip.CatchError(f.Close())
-		} else {
-			return errors.New("Unknown file type in archive.")
+// extractDir creates the directory of an archive entry, unless it is a link
+// that leads outside rootPath.
+func extractDir(rootPath, localPath string) error {
+	if err := checkResolvedWithin(rootPath, localPath); err != nil {
+		return err
+	}
+	return errors.WithStack(os.MkdirAll(localPath, 0755))
+}
+
+// extractFile writes the contents of an archive entry to localPath, creating
+// its parent directories, and gives it the entry's permissions.
+func extractFile(in io.Reader, localPath string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// an existing link is replaced rather than written through
+	if info, err := os.Lstat(localPath); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if err = os.Remove(localPath); err != nil {
+			return errors.WithStack(err)
 		}
 	}
+
+	// Not using 'defer f.Close()' because this is called in a loop, and we
+	// don't want to wait for the whole archive to finish to close the files.
+	f, err := os.Create(localPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if _, err = io.Copy(f, in); err != nil {
+		grip.CatchError(f.Close())
+		return errors.WithStack(err)
+	}
+
+	// File's permissions should match what was in the archive
+	if err = os.Chmod(f.Name(), mode); err != nil {
+		grip.CatchError(f.Close())
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(f.Close())
+}
+
+// extractSymlink creates a link at localPath to target, which must be a
+// relative path that resolves to somewhere under rootPath, following the
+// links already in localPath's directory.
+func extractSymlink(rootPath, localPath, target string) error {
+	if filepath.IsAbs(target) || !isWithin(rootPath, filepath.Join(filepath.Dir(localPath), target)) {
+		return errors.Errorf("symlink '%s' to '%s' points outside the destination directory",
+			localPath, target)
+	}
+	dir, err := resolvePath(filepath.Dir(localPath))
+	if err != nil {
+		return err
+	}
+	if err = checkResolvedWithin(rootPath, filepath.Join(dir, target)); err != nil {
+		return errors.Wrapf(err, "symlink '%s' to '%s'", localPath, target)
+	}
+	if err = os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	if err = os.Remove(localPath); err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Symlink(target, localPath))
 }
 
 // TarGzReader returns a file, gzip reader, and tar reader for the given path.
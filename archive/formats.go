@@ -0,0 +1,109 @@
+package archive
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// Archive formats that can be detected from the start of a file.
+const (
+	FormatTar   = "tar"
+	FormatTarGz = "tar.gz"
+	FormatZip   = "zip"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagics = [][]byte{
+		[]byte("PK\x03\x04"),
+		// an empty zip file is just the end of its central directory
+		[]byte("PK\x05\x06"),
+	}
+	tarMagic       = []byte("ustar")
+	tarMagicOffset = 257
+)
+
+// DetectFormat returns the format of the archive at path, from the magic
+// numbers at the start of the file rather than from its name.
+func DetectFormat(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer f.Close()
+
+	header := make([]byte, tarMagicOffset+len(tarMagic))
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", errors.Wrapf(err, "problem reading %s", path)
+	}
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return FormatTarGz, nil
+	case len(header) >= tarMagicOffset+len(tarMagic) &&
+		bytes.Equal(header[tarMagicOffset:], tarMagic):
+		return FormatTar, nil
+	}
+	for _, magic := range zipMagics {
+		if bytes.HasPrefix(header, magic) {
+			return FormatZip, nil
+		}
+	}
+	return "", errors.Errorf("%s is not a tar, gzip or zip archive", path)
+}
+
+// TarReader returns a file, decompression reader, and tar reader for the
+// tarball at path, which may be gzip compressed or not compressed.
+func TarReader(path string) (f, c io.ReadCloser, tarReader *tar.Reader, err error) {
+	format, err := DetectFormat(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	f, err = os.Open(path)
+	if err != nil {
+		return nil, nil, nil, errors.WithStack(err)
+	}
+	switch format {
+	case FormatTar:
+		c = ioutil.NopCloser(bufio.NewReader(f))
+	case FormatTarGz:
+		c, err = gzip.NewReader(f)
+	default:
+		err = errors.Errorf("%s is a %s archive, not a tarball", path, format)
+	}
+	if err != nil {
+		grip.CatchError(f.Close())
+		return nil, nil, nil, errors.WithStack(err)
+	}
+	return f, c, tar.NewReader(c), nil
+}
+
+// ExtractFile unpacks the archive at path into rootPath, whatever its format.
+func ExtractFile(path, rootPath string) error {
+	format, err := DetectFormat(path)
+	if err != nil {
+		return err
+	}
+	if format == FormatZip {
+		return ExtractZipFile(path, rootPath)
+	}
+
+	f, c, tarReader, err := TarReader(path)
+	if err != nil {
+		return errors.Wrapf(err, "problem opening tarball %s", path)
+	}
+	defer f.Close()
+	defer c.Close()
+	return errors.Wrapf(Extract(tarReader, rootPath), "problem extracting %s", path)
+}
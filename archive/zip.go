@@ -0,0 +1,169 @@
+package archive
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/slogger"
+	"github.com/pkg/errors"
+)
+
+// Compression methods for the files in a zip archive.
+const (
+	ZipDeflate = "deflate"
+	ZipStore   = "store"
+)
+
+// ValidZipCompressions are the methods a zip archive can be written with.
+var ValidZipCompressions = []string{ZipDeflate, ZipStore}
+
+// zipArchiveWriter adds files to a zip archive, keeping their permissions
+// and modification times.
+type zipArchiveWriter struct {
+	zipWriter *zip.Writer
+	method    uint16
+}
+
+func (z *zipArchiveWriter) create(name string, info os.FileInfo) (io.Writer, error) {
+	hdr, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error creating header for %v", name)
+	}
+	hdr.Name = name
+	hdr.Method = z.method
+	w, err := z.zipWriter.CreateHeader(hdr)
+	return w, errors.Wrapf(err, "Error writing header for %v", name)
+}
+
+func (z *zipArchiveWriter) WriteFile(name, path string, info os.FileInfo) error {
+	w, err := z.create(name, info)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "Error opening %v", path)
+	}
+	defer in.Close()
+
+	amountWrote, err := io.Copy(w, in)
+	if err != nil {
+		return errors.Wrapf(err, "Error writing into zip for %v", path)
+	}
+	if amountWrote != info.Size() {
+		return errors.Errorf("Error writing to archive for %v: size %v but wrote %v",
+			name, info.Size(), amountWrote)
+	}
+	return nil
+}
+
+func (z *zipArchiveWriter) WriteSymlink(name, target string, info os.FileInfo) error {
+	// a zip file stores a link as an entry whose mode says it is a link and
+	// whose contents are the link's target
+	w, err := z.create(name, info)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, target)
+	return errors.Wrapf(err, "Error writing into zip for %v", name)
+}
+
+// ZipWriter returns a file and zip writer for the path. The zip writer wraps
+// the file.
+func ZipWriter(path string) (f io.WriteCloser, zipWriter *zip.Writer, err error) {
+	f, err = os.Create(path)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return f, zip.NewWriter(f), nil
+}
+
+// BuildZipArchive reads the rootPath directory into the zip.Writer, taking
+// included and excluded strings into account in the same way BuildArchive
+// does. Symlinks are stored as links. Returns the number of files that were
+// added to the archive.
+func BuildZipArchive(zipWriter *zip.Writer, rootPath string, includes []string,
+	excludes []string, compression string, log *slogger.Logger) (int, error) {
+
+	w := &zipArchiveWriter{zipWriter: zipWriter}
+	switch compression {
+	case ZipDeflate, "":
+		w.method = zip.Deflate
+	case ZipStore:
+		w.method = zip.Store
+	default:
+		return 0, errors.Errorf("unknown zip compression '%s'", compression)
+	}
+	return buildArchive(w, rootPath, includes, excludes, true, log)
+}
+
+// ExtractZip unpacks the zip.Reader into rootPath. Entries whose paths or
+// link targets would end up outside rootPath are rejected.
+func ExtractZip(zipReader *zip.Reader, rootPath string) error {
+	for _, file := range zipReader.File {
+		localPath, err := extractPath(rootPath, file.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := file.Mode()
+		if mode.IsDir() {
+			if err = extractDir(rootPath, localPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		in, err := file.Open()
+		if err != nil {
+			return errors.Wrapf(err, "problem reading %s", file.Name)
+		}
+		if mode&os.ModeSymlink != 0 {
+			var target []byte
+			target, err = readSymlinkTarget(in)
+			if err == nil {
+				err = extractSymlink(rootPath, localPath, string(target))
+			}
+		} else {
+			// archives made on Windows have no permissions for their files
+			perm := mode.Perm()
+			if perm == 0 {
+				perm = 0644
+			}
+			err = extractFile(in, localPath, perm)
+		}
+		grip.CatchError(in.Close())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExtractZipFile unpacks the zip file at path into rootPath.
+func ExtractZipFile(path, rootPath string) error {
+	zipReader, err := zip.OpenReader(path)
+	if err != nil {
+		return errors.Wrapf(err, "problem opening zip file %s", path)
+	}
+	defer zipReader.Close()
+	return errors.Wrapf(ExtractZip(&zipReader.Reader, rootPath), "problem extracting %s", path)
+}
+
+// maxSymlinkLength bounds how much of a link entry is read as its target.
+const maxSymlinkLength = 4096
+
+func readSymlinkTarget(in io.Reader) ([]byte, error) {
+	buf := make([]byte, maxSymlinkLength+1)
+	n, err := io.ReadFull(in, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, errors.WithStack(err)
+	}
+	if n > maxSymlinkLength {
+		return nil, errors.New("symlink target is too long")
+	}
+	return buf[:n], nil
+}
@@ -0,0 +1,139 @@
+package archive
+
+import (
+	"archive/tar"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestZipRoundTrip(t *testing.T) {
+	Convey("With a directory holding an executable and a symlink", t, func() {
+		dir, err := ioutil.TempDir("", "archive")
+		testutil.HandleTestingErr(err, t, "Couldn't create temp dir")
+		defer os.RemoveAll(dir)
+
+		source := filepath.Join(dir, "in")
+		So(os.MkdirAll(filepath.Join(source, "bin"), 0755), ShouldBeNil)
+		So(ioutil.WriteFile(filepath.Join(source, "bin", "run.sh"), []byte("#!/bin/sh\n"), 0755), ShouldBeNil)
+		So(ioutil.WriteFile(filepath.Join(source, "bin", "run.pdb"), []byte("symbols"), 0644), ShouldBeNil)
+		So(os.Symlink("run.sh", filepath.Join(source, "bin", "latest")), ShouldBeNil)
+
+		for _, compression := range ValidZipCompressions {
+			Convey("a "+compression+" zip file should keep permissions and links", func() {
+				target := filepath.Join(dir, "out.zip")
+				f, zipWriter, err := ZipWriter(target)
+				So(err, ShouldBeNil)
+				found, err := BuildZipArchive(zipWriter, source, []string{"bin/**"}, []string{"*.pdb"}, compression, logger)
+				So(err, ShouldBeNil)
+				So(found, ShouldEqual, 2)
+				So(zipWriter.Close(), ShouldBeNil)
+				So(f.Close(), ShouldBeNil)
+
+				format, err := DetectFormat(target)
+				So(err, ShouldBeNil)
+				So(format, ShouldEqual, FormatZip)
+
+				output := filepath.Join(dir, "out")
+				So(ExtractFile(target, output), ShouldBeNil)
+
+				info, err := os.Stat(filepath.Join(output, "bin", "run.sh"))
+				So(err, ShouldBeNil)
+				So(info.Mode().Perm(), ShouldEqual, 0755)
+				link, err := os.Readlink(filepath.Join(output, "bin", "latest"))
+				So(err, ShouldBeNil)
+				So(link, ShouldEqual, "run.sh")
+				_, err = os.Stat(filepath.Join(output, "bin", "run.pdb"))
+				So(os.IsNotExist(err), ShouldBeTrue)
+			})
+		}
+
+		Convey("a tarball should be detected and extracted", func() {
+			target := filepath.Join(dir, "out.tgz")
+			f, gz, tarWriter, err := TarGzWriter(target)
+			So(err, ShouldBeNil)
+			found, err := BuildArchiveWithSymlinks(tarWriter, source, []string{"bin/**"}, nil, logger)
+			So(err, ShouldBeNil)
+			So(found, ShouldEqual, 3)
+			So(tarWriter.Close(), ShouldBeNil)
+			So(gz.Close(), ShouldBeNil)
+			So(f.Close(), ShouldBeNil)
+
+			format, err := DetectFormat(target)
+			So(err, ShouldBeNil)
+			So(format, ShouldEqual, FormatTarGz)
+
+			output := filepath.Join(dir, "out")
+			So(ExtractFile(target, output), ShouldBeNil)
+			link, err := os.Readlink(filepath.Join(output, "bin", "latest"))
+			So(err, ShouldBeNil)
+			So(link, ShouldEqual, "run.sh")
+		})
+	})
+}
+
+func TestExtractPathTraversal(t *testing.T) {
+	Convey("With a tarball holding entries that escape its directory", t, func() {
+		dir, err := ioutil.TempDir("", "archive")
+		testutil.HandleTestingErr(err, t, "Couldn't create temp dir")
+		defer os.RemoveAll(dir)
+
+		writeTarball := func(headers ...*tar.Header) string {
+			target := filepath.Join(dir, "evil.tgz")
+			f, gz, tarWriter, err := TarGzWriter(target)
+			So(err, ShouldBeNil)
+			for _, hdr := range headers {
+				So(tarWriter.WriteHeader(hdr), ShouldBeNil)
+			}
+			So(tarWriter.Close(), ShouldBeNil)
+			So(gz.Close(), ShouldBeNil)
+			So(f.Close(), ShouldBeNil)
+			return target
+		}
+		output := filepath.Join(dir, "out")
+
+		Convey("a file above the destination should be rejected", func() {
+			target := writeTarball(&tar.Header{Name: "../escaped.txt", Mode: 0644, Typeflag: tar.TypeReg})
+			So(ExtractFile(target, output), ShouldNotBeNil)
+			_, err := os.Stat(filepath.Join(dir, "escaped.txt"))
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+
+		Convey("a symlink out of the destination should be rejected", func() {
+			target := writeTarball(&tar.Header{Name: "link", Linkname: "../..", Typeflag: tar.TypeSymlink})
+			So(ExtractFile(target, output), ShouldNotBeNil)
+
+			target = writeTarball(&tar.Header{Name: "link", Linkname: "/etc", Typeflag: tar.TypeSymlink})
+			So(ExtractFile(target, output), ShouldNotBeNil)
+		})
+
+		Convey("an entry written through links extracted before it should be rejected", func() {
+			target := writeTarball(
+				&tar.Header{Name: "sub", Linkname: ".", Typeflag: tar.TypeSymlink},
+				&tar.Header{Name: "sub/up", Linkname: "..", Typeflag: tar.TypeSymlink},
+				&tar.Header{Name: "up/escaped.txt", Mode: 0644, Typeflag: tar.TypeReg},
+			)
+			So(ExtractFile(target, output), ShouldNotBeNil)
+			_, err := os.Stat(filepath.Join(dir, "escaped.txt"))
+			So(os.IsNotExist(err), ShouldBeTrue)
+
+			target = writeTarball(
+				&tar.Header{Name: "sub", Linkname: ".", Typeflag: tar.TypeSymlink},
+				&tar.Header{Name: "sub/up", Linkname: "..", Typeflag: tar.TypeSymlink},
+				&tar.Header{Name: "up/escaped/", Mode: 0755, Typeflag: tar.TypeDir},
+			)
+			So(ExtractFile(target, output), ShouldNotBeNil)
+			_, err = os.Stat(filepath.Join(dir, "escaped"))
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+
+		Convey("a symlink within the destination should be extracted", func() {
+			target := writeTarball(&tar.Header{Name: "a/link", Linkname: "../b", Typeflag: tar.TypeSymlink})
+			So(ExtractFile(target, output), ShouldBeNil)
+		})
+	})
+}
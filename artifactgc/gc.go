@@ -0,0 +1,117 @@
+// Package artifactgc deletes task artifacts from s3 once their expiration
+// class no longer keeps them.
+package artifactgc
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultBatchSize is used when the settings do not set a batch size.
+	DefaultBatchSize = 500
+
+	// retryDelay is how long a failed deletion waits before it is retried,
+	// doubling with each failure up to maxRetryDelay.
+	retryDelay    = time.Hour
+	maxRetryDelay = 7 * 24 * time.Hour
+)
+
+// Collector deletes expired files and marks their artifact entries.
+type Collector struct {
+	// Delete removes an object from an s3 bucket.
+	Delete func(bucket, key string) error
+	// Buckets are the only buckets objects are deleted from. Files in other
+	// buckets are marked expired, but their objects are left alone.
+	Buckets   []string
+	BatchSize int
+}
+
+// Stats counts the files a collection expired, and the s3 objects it deleted.
+// An object is only deleted once no file that is still kept links to it.
+type Stats struct {
+	Files   int
+	Objects int
+}
+
+// Collect expires the files that should have been deleted by now.
+func (c *Collector) Collect(now time.Time) (Stats, error) {
+	stats := Stats{}
+	entries, err := artifact.FindAll(artifact.ByExpiringFiles(now).Limit(c.BatchSize))
+	if err != nil {
+		return stats, errors.Wrap(err, "error finding expired artifacts")
+	}
+
+	catcher := grip.NewCatcher()
+	for _, entry := range entries {
+		for _, file := range entry.Files {
+			if file.Expired || file.ExpireAt.IsZero() || file.ExpireAt.After(now) ||
+				file.ExpireRetryAt.After(now) {
+				continue
+			}
+			deleted, err := c.expireFile(entry.TaskId, file, now)
+			if err != nil {
+				catcher.Add(errors.Wrapf(err, "error expiring %s of task %s", file.Link, entry.TaskId))
+				// back off the file, so that it does not keep taking up
+				// space in every batch
+				retryAt := now.Add(backoff(file.ExpireAttempts))
+				catcher.Add(errors.Wrapf(artifact.MarkExpireFailed(entry.TaskId, file.Link, retryAt),
+					"error recording failed expiration of %s of task %s", file.Link, entry.TaskId))
+				continue
+			}
+			stats.Files++
+			if deleted {
+				stats.Objects++
+			}
+		}
+	}
+	return stats, catcher.Resolve()
+}
+
+// expireFile deletes a file's s3 object unless another file still links to
+// it or the server did not record its upload, then marks the file expired. It returns whether the object was deleted.
+func (c *Collector) expireFile(taskId string, file artifact.File, now time.Time) (bool, error) {
+	deleted := false
+	if file.Bucket != "" && file.FileKey != "" && !util.SliceContains(c.Buckets, file.Bucket) {
+		grip.Warningf("not deleting %s/%s of task %s: bucket is not allowed for artifact collection",
+			file.Bucket, file.FileKey, taskId)
+	} else if file.Bucket != "" && file.FileKey != "" {
+		upload, err := artifact.FindUpload(file.Bucket, file.FileKey)
+		if err != nil {
+			return false, errors.Wrap(err, "error finding the upload of the file")
+		}
+		if upload == nil {
+			grip.Warningf("not deleting %s/%s of task %s: no upload of it was recorded",
+				file.Bucket, file.FileKey, taskId)
+			return false, errors.WithStack(artifact.MarkExpired(taskId, file.Link))
+		}
+		links, err := artifact.Count(artifact.ByLiveS3File(file.Bucket, file.FileKey, now))
+		if err != nil {
+			return false, errors.Wrap(err, "error counting links to the file")
+		}
+		if links == 0 {
+			if err = c.Delete(file.Bucket, file.FileKey); err != nil {
+				return false, err
+			}
+			deleted = true
+		}
+	}
+	return deleted, errors.WithStack(artifact.MarkExpired(taskId, file.Link))
+}
+
+// backoff returns how long to wait before retrying a file that failed to be
+// deleted the given number of times before.
+func backoff(attempts int) time.Duration {
+	delay := retryDelay
+	for i := 0; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
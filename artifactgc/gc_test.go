@@ -0,0 +1,113 @@
+package artifactgc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/testutil"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func init() {
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testutil.TestConfig()))
+}
+
+func TestCollect(t *testing.T) {
+	Convey("With artifacts that share an s3 object", t, func() {
+		testutil.HandleTestingErr(db.ClearCollections(artifact.Collection, artifact.UploadCollection), t,
+			"error clearing collections")
+
+		now := time.Now()
+		expired := artifact.File{Name: "old", Link: "link/a", Bucket: "bucket", FileKey: "a",
+			ExpirationClass: artifact.ExpireShort, ExpireAt: now.Add(-time.Hour)}
+		linked := artifact.File{Name: "new", Link: "link/a", Bucket: "bucket", FileKey: "a",
+			ExpirationClass: artifact.ExpireLong, ExpireAt: now.Add(time.Hour)}
+		kept := artifact.File{Name: "kept", Link: "link/b", Bucket: "bucket", FileKey: "b"}
+		So(artifact.Entry{TaskId: "t1", Files: []artifact.File{expired, kept}}.Upsert(), ShouldBeNil)
+		So(artifact.Entry{TaskId: "t2", Files: []artifact.File{linked}}.Upsert(), ShouldBeNil)
+		_, err := artifact.RecordUpload("bucket", "a", "t1")
+		So(err, ShouldBeNil)
+		_, err = artifact.RecordUpload("bucket", "b", "t1")
+		So(err, ShouldBeNil)
+
+		deleted := []string{}
+		collector := &Collector{
+			Delete: func(bucket, key string) error {
+				deleted = append(deleted, bucket+"/"+key)
+				return nil
+			},
+			Buckets:   []string{"bucket"},
+			BatchSize: 10,
+		}
+
+		Convey("an object that a kept file links to should not be deleted", func() {
+			stats, err := collector.Collect(now)
+			So(err, ShouldBeNil)
+			So(stats, ShouldResemble, Stats{Files: 1})
+			So(len(deleted), ShouldEqual, 0)
+
+			entry, err := artifact.FindOne(artifact.ByTaskId("t1"))
+			So(err, ShouldBeNil)
+			So(entry.Files[0].Expired, ShouldBeTrue)
+			So(entry.Files[1].Expired, ShouldBeFalse)
+
+			Convey("until every file linking to it expires", func() {
+				stats, err = collector.Collect(now.Add(2 * time.Hour))
+				So(err, ShouldBeNil)
+				So(stats, ShouldResemble, Stats{Files: 1, Objects: 1})
+				So(deleted, ShouldResemble, []string{"bucket/a"})
+			})
+		})
+
+		Convey("an object outside the allowed buckets should never be deleted", func() {
+			collector.Buckets = []string{"other"}
+			stats, err := collector.Collect(now.Add(2 * time.Hour))
+			So(err, ShouldBeNil)
+			So(stats, ShouldResemble, Stats{Files: 2})
+			So(len(deleted), ShouldEqual, 0)
+
+			entry, err := artifact.FindOne(artifact.ByTaskId("t2"))
+			So(err, ShouldBeNil)
+			So(entry.Files[0].Expired, ShouldBeTrue)
+		})
+
+		Convey("an object whose upload was not recorded should never be deleted", func() {
+			So(db.Clear(artifact.UploadCollection), ShouldBeNil)
+			stats, err := collector.Collect(now.Add(2 * time.Hour))
+			So(err, ShouldBeNil)
+			So(stats, ShouldResemble, Stats{Files: 2})
+			So(len(deleted), ShouldEqual, 0)
+
+			entry, err := artifact.FindOne(artifact.ByTaskId("t2"))
+			So(err, ShouldBeNil)
+			So(entry.Files[0].Expired, ShouldBeTrue)
+		})
+
+		Convey("a file that fails to be deleted should be backed off", func() {
+			collector.Delete = func(bucket, key string) error {
+				return errors.New("access denied")
+			}
+			later := now.Add(2 * time.Hour)
+			_, err := collector.Collect(later)
+			So(err, ShouldNotBeNil)
+
+			entry, err := artifact.FindOne(artifact.ByTaskId("t2"))
+			So(err, ShouldBeNil)
+			So(entry.Files[0].Expired, ShouldBeFalse)
+			So(entry.Files[0].ExpireAttempts, ShouldEqual, 1)
+
+			count, err := artifact.Count(artifact.ByExpiringFiles(later))
+			So(err, ShouldBeNil)
+			So(count, ShouldEqual, 0)
+
+			Convey("and retried once the backoff passes", func() {
+				count, err = artifact.Count(artifact.ByExpiringFiles(later.Add(retryDelay)))
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 2)
+			})
+		})
+	})
+}
@@ -0,0 +1,63 @@
+package artifactgc
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/goamz/goamz/aws"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// Runner deletes expired task artifacts from s3.
+type Runner struct{}
+
+const (
+	RunnerName  = "artifactgc"
+	Description = "delete expired task artifacts from s3"
+)
+
+func (r *Runner) Name() string {
+	return RunnerName
+}
+
+func (r *Runner) Description() string {
+	return Description
+}
+
+func (r *Runner) Run(config *evergreen.Settings) error {
+	startTime := time.Now()
+	grip.Infoln("Starting artifact collector at time", startTime)
+
+	auth := &aws.Auth{
+		AccessKey: config.Providers.AWS.Id,
+		SecretKey: config.Providers.AWS.Secret,
+	}
+	collector := &Collector{
+		Delete: func(bucket, key string) error {
+			return thirdparty.DeleteS3File(auth, bucket, key)
+		},
+		Buckets:   config.Artifacts.Buckets,
+		BatchSize: config.Artifacts.BatchSize,
+	}
+	if collector.BatchSize == 0 {
+		collector.BatchSize = DefaultBatchSize
+	}
+
+	stats, err := collector.Collect(startTime)
+	grip.Infof("expired %d artifact files and deleted %d s3 objects", stats.Files, stats.Objects)
+	if err != nil {
+		err = errors.Wrap(err, "error collecting artifacts")
+		grip.Error(err)
+		return err
+	}
+
+	runtime := time.Since(startTime)
+	if err = model.SetProcessRuntimeCompleted(RunnerName, runtime); err != nil {
+		grip.Errorf("error updating process status: %+v", err)
+	}
+	grip.Infof("Artifact collector took %s to run", runtime)
+	return nil
+}
@@ -123,7 +123,7 @@ func TestCLIFetchSource(t *testing.T) {
 			"3c7bfeb82d492dc453e7431be664539c35b5db4b",
 			"all",
 			[]string{"all"},
-			false}
+			false, "", nil}
 
 		// Set up a test patch that contains module changes
 		ac, rc, _, err := getAPIClients(&Options{testSetup.settingsFilePath})
@@ -330,7 +330,7 @@ func TestCLIFunctions(t *testing.T) {
 					"3c7bfeb82d492dc453e7431be664539c35b5db4b",
 					"all",
 					[]string{"all"},
-					false}
+					false, "", nil}
 
 				newPatch, err := ac.PutPatch(patchSub)
 				So(err, ShouldBeNil)
@@ -386,6 +386,8 @@ func TestCLIFunctions(t *testing.T) {
 					"all",
 					[]string{},
 					false,
+					"",
+					nil,
 				}
 				_, err := ac.PutPatch(patchSub)
 				So(err, ShouldBeNil)
@@ -398,7 +400,7 @@ func TestCLIFunctions(t *testing.T) {
 					"3c7bfeb82d492dc453e7431be664539c35b5db4b",
 					"osx-108",
 					[]string{"failing_test"},
-					false}
+					false, "", nil}
 
 				_, err := ac.PutPatch(patchSub)
 				So(err, ShouldBeNil)
@@ -503,7 +505,7 @@ func TestCLIFunctions(t *testing.T) {
 					"3c7bfeb82d492dc453e7431be664539c35b5db4b",
 					"all",
 					[]string{"failing_test"},
-					false}
+					false, "", nil}
 
 				_, err := ac.PutPatch(patchSub)
 				So(err, ShouldBeNil)
@@ -530,7 +532,7 @@ func TestCLIFunctions(t *testing.T) {
 					"3c7bfeb82d492dc453e7431be664539c35b5db4b",
 					"osx-108",
 					[]string{"all"},
-					false}
+					false, "", nil}
 
 				_, err := ac.PutPatch(patchSub)
 				So(err, ShouldBeNil)
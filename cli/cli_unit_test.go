@@ -1,9 +1,13 @@
 package cli
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/testutil"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -45,3 +49,136 @@ func TestGetHistoryCreateUrlQuery(t *testing.T) {
 		})
 	})
 }
+
+// newTestCheckout creates a git checkout in dir whose master branch tracks
+// the master branch of a new repository at remote, and commits the files
+// to both.
+func newTestCheckout(t *testing.T, dir, remote string, files map[string]string) {
+	runTestGit(t, "", "init", "--quiet", "--bare", remote)
+	runTestGit(t, "", "init", "--quiet", dir)
+	runTestGit(t, dir, "checkout", "--quiet", "-b", "master")
+	writeTestFiles(t, dir, files)
+	runTestGit(t, dir, "add", ".")
+	runTestGit(t, dir, "commit", "--quiet", "-m", "initial commit")
+	runTestGit(t, dir, "remote", "add", "origin", remote)
+	runTestGit(t, dir, "push", "--quiet", "-u", "origin", "master")
+}
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, contents := range files {
+		testutil.HandleTestingErr(ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644),
+			t, "error writing %s", name)
+	}
+}
+
+func runTestGit(t *testing.T, dir string, args ...string) string {
+	out, err := gitCmdIn(dir, "-c", append([]string{"user.name=Test Author", "-c",
+		"user.email=author@example.com"}, args...)...)
+	testutil.HandleTestingErr(err, t, "error running git")
+	return out
+}
+
+func TestLoadGitData(t *testing.T) {
+	Convey("With a checkout of a project that has local changes", t, func() {
+		tmp, err := ioutil.TempDir("", "cli-git")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(tmp)
+
+		work := filepath.Join(tmp, "work")
+		newTestCheckout(t, work, filepath.Join(tmp, "project.git"), map[string]string{
+			"evergreen.yml": "modules:\n- name: mod\n  prefix: src\n  branch: master\n",
+			"a.txt":         "a\n",
+			"b.txt":         "b\n",
+			"c.txt":         "c\n",
+		})
+		pushed := runTestGit(t, work, "rev-parse", "HEAD")[:40]
+
+		// a commit, a staged change, and an unstaged change
+		writeTestFiles(t, work, map[string]string{"a.txt": "a2\n"})
+		runTestGit(t, work, "commit", "--quiet", "-am", "change a")
+		writeTestFiles(t, work, map[string]string{"b.txt": "b2\n"})
+		runTestGit(t, work, "add", "b.txt")
+		writeTestFiles(t, work, map[string]string{"c.txt": "c2\n"})
+
+		Convey("by default the patch should include every change since the merge base", func() {
+			diff, err := loadGitData(work, "master", gitDiffOptions{})
+			So(err, ShouldBeNil)
+			So(diff.base, ShouldEqual, pushed)
+			So(diff.fullPatch, ShouldContainSubstring, "+a2")
+			So(diff.fullPatch, ShouldContainSubstring, "+b2")
+			So(diff.fullPatch, ShouldContainSubstring, "+c2")
+			So(diff.log, ShouldContainSubstring, "change a")
+		})
+
+		Convey("a staged patch should leave out unstaged changes", func() {
+			diff, err := loadGitData(work, "master", gitDiffOptions{staged: true})
+			So(err, ShouldBeNil)
+			So(diff.fullPatch, ShouldContainSubstring, "+a2")
+			So(diff.fullPatch, ShouldContainSubstring, "+b2")
+			So(diff.fullPatch, ShouldNotContainSubstring, "+c2")
+		})
+
+		Convey("a patch of a range of commits should include only those commits", func() {
+			diff, err := loadGitData(work, "master", gitDiffOptions{commits: "HEAD"})
+			So(err, ShouldBeNil)
+			So(diff.base, ShouldEqual, pushed)
+			So(diff.fullPatch, ShouldContainSubstring, "+a2")
+			So(diff.fullPatch, ShouldNotContainSubstring, "+b2")
+
+			diff, err = loadGitData(work, "master", gitDiffOptions{commits: pushed + ".."})
+			So(err, ShouldBeNil)
+			So(diff.fullPatch, ShouldContainSubstring, "+a2")
+
+			_, err = loadGitData(work, "master", gitDiffOptions{commits: "missing..HEAD"})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("an uncommitted patch should be on top of a pushed HEAD", func() {
+			_, err := loadGitData(work, "master", gitDiffOptions{uncommitted: true})
+			So(err, ShouldNotBeNil)
+
+			runTestGit(t, work, "push", "--quiet")
+			diff, err := loadGitData(work, "master", gitDiffOptions{uncommitted: true})
+			So(err, ShouldBeNil)
+			So(diff.fullPatch, ShouldNotContainSubstring, "+a2")
+			So(diff.fullPatch, ShouldContainSubstring, "+b2")
+			So(diff.fullPatch, ShouldContainSubstring, "+c2")
+
+			diff, err = loadGitData(work, "master", gitDiffOptions{uncommitted: true, staged: true})
+			So(err, ShouldBeNil)
+			So(diff.fullPatch, ShouldContainSubstring, "+b2")
+			So(diff.fullPatch, ShouldNotContainSubstring, "+c2")
+		})
+
+		Convey("changes to a module checked out at its prefix should be included", func() {
+			ref := &model.ProjectRef{RemotePath: "evergreen.yml"}
+			modules, err := loadModuleGitData(work, ref, gitDiffOptions{})
+			So(err, ShouldBeNil)
+			So(len(modules), ShouldEqual, 0)
+
+			moduleDir := filepath.Join(work, "src", "mod")
+			So(os.Mkdir(filepath.Join(work, "src"), 0755), ShouldBeNil)
+			newTestCheckout(t, moduleDir, filepath.Join(tmp, "mod.git"), map[string]string{"m.txt": "m\n"})
+			moduleBase := runTestGit(t, moduleDir, "rev-parse", "HEAD")[:40]
+
+			modules, err = loadModuleGitData(work, ref, gitDiffOptions{})
+			So(err, ShouldBeNil)
+			So(len(modules), ShouldEqual, 0)
+
+			writeTestFiles(t, moduleDir, map[string]string{"m.txt": "m2\n"})
+			modules, err = loadModuleGitData(work, ref, gitDiffOptions{})
+			So(err, ShouldBeNil)
+			So(len(modules), ShouldEqual, 1)
+			So(modules[0].name, ShouldEqual, "mod")
+			So(modules[0].diff.base, ShouldEqual, moduleBase)
+			So(modules[0].diff.fullPatch, ShouldContainSubstring, "+m2")
+
+			Convey("unless the module's branch has no upstream", func() {
+				runTestGit(t, moduleDir, "branch", "--quiet", "--unset-upstream")
+				modules, err = loadModuleGitData(work, ref, gitDiffOptions{})
+				So(err, ShouldBeNil)
+				So(len(modules), ShouldEqual, 0)
+			})
+		})
+	})
+}
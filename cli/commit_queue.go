@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// CommitQueueCommand is used to show the patches in a project's commit queue.
+type CommitQueueCommand struct {
+	GlobalOpts *Options `no-flag:"true"`
+	Project    string   `short:"p" long:"project" description:"project whose commit queue should be shown"`
+}
+
+// EnqueuePatchCommand is used to add a patch to its project's commit queue.
+type EnqueuePatchCommand struct {
+	GlobalOpts *Options `no-flag:"true"`
+	PatchId    string   `short:"i" description:"id of the patch to merge" required:"true"`
+}
+
+// DequeuePatchCommand is used to remove a patch from its project's commit queue.
+type DequeuePatchCommand struct {
+	GlobalOpts *Options `no-flag:"true"`
+	PatchId    string   `short:"i" description:"id of the patch to remove" required:"true"`
+}
+
+func (cqc *CommitQueueCommand) Execute(_ []string) error {
+	ac, _, settings, err := getAPIClients(cqc.GlobalOpts)
+	if err != nil {
+		return err
+	}
+	notifyUserUpdate(ac)
+
+	if cqc.Project == "" {
+		cqc.Project = settings.FindDefaultProject()
+	}
+	if cqc.Project == "" {
+		return errors.New("Need to specify a project.")
+	}
+
+	items, err := ac.GetCommitQueue(cqc.Project)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		fmt.Printf("The commit queue of project '%v' is empty.\n", cqc.Project)
+		return nil
+	}
+	for i, item := range items {
+		status := "waiting"
+		if item.TestPatchId != "" {
+			status = fmt.Sprintf("testing in patch %v (%v)", item.TestPatchId, item.TestStatus)
+		}
+		fmt.Printf("%v. %v '%v' by %v, queued by %v at %v: %v\n      %v/patch/%v\n", i+1,
+			item.PatchId, item.Description, item.Author, item.EnqueuedBy,
+			item.EnqueueTime.Format("2006-01-02 15:04:05"), status, settings.UIServerHost, item.PatchId)
+	}
+	return nil
+}
+
+func (epc *EnqueuePatchCommand) Execute(_ []string) error {
+	ac, _, _, err := getAPIClients(epc.GlobalOpts)
+	if err != nil {
+		return err
+	}
+	notifyUserUpdate(ac)
+
+	p, err := ac.GetPatch(epc.PatchId)
+	if err != nil {
+		return err
+	}
+	position, err := ac.EnqueuePatch(p.Project, epc.PatchId)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Patch added to the commit queue of project '%v' at position %v.\n", p.Project, position+1)
+	return nil
+}
+
+func (dpc *DequeuePatchCommand) Execute(_ []string) error {
+	ac, _, _, err := getAPIClients(dpc.GlobalOpts)
+	if err != nil {
+		return err
+	}
+	notifyUserUpdate(ac)
+
+	p, err := ac.GetPatch(dpc.PatchId)
+	if err != nil {
+		return err
+	}
+	if err = ac.DequeuePatch(p.Project, dpc.PatchId); err != nil {
+		return err
+	}
+	fmt.Println("Patch removed from the commit queue.")
+	return nil
+}
@@ -135,9 +135,14 @@ func (ac *APIClient) modifyExisting(patchId, action string) error {
 	return nil
 }
 
-// ValidateLocalConfig validates the local project config with the server
-func (ac *APIClient) ValidateLocalConfig(data []byte) ([]validator.ValidationError, error) {
-	resp, err := ac.post("validate", bytes.NewBuffer(data))
+// ValidateLocalConfig validates the local project config with the server. If
+// a project is given, its settings are checked against the config too.
+func (ac *APIClient) ValidateLocalConfig(data []byte, project string) ([]validator.ValidationError, error) {
+	path := "validate"
+	if project != "" {
+		path += "?project=" + url.QueryEscape(project)
+	}
+	resp, err := ac.post(path, bytes.NewBuffer(data))
 	if err != nil {
 		return nil, err
 	}
@@ -350,13 +355,15 @@ func (ac *APIClient) ListVariants(project string) ([]model.BuildVariant, error)
 // the patch object itself.
 func (ac *APIClient) PutPatch(incomingPatch patchSubmission) (*patch.Patch, error) {
 	data := struct {
-		Description string   `json:"desc"`
-		Project     string   `json:"project"`
-		Patch       string   `json:"patch"`
-		Githash     string   `json:"githash"`
-		Variants    string   `json:"buildvariants"` //TODO make this an array
-		Tasks       []string `json:"tasks"`
-		Finalize    bool     `json:"finalize"`
+		Description string                   `json:"desc"`
+		Project     string                   `json:"project"`
+		Patch       string                   `json:"patch"`
+		Githash     string                   `json:"githash"`
+		Variants    string                   `json:"buildvariants"` //TODO make this an array
+		Tasks       []string                 `json:"tasks"`
+		Finalize    bool                     `json:"finalize"`
+		Alias       string                   `json:"alias,omitempty"`
+		Modules     []service.PatchAPIModule `json:"modules,omitempty"`
 	}{
 		incomingPatch.description,
 		incomingPatch.projectId,
@@ -365,6 +372,15 @@ func (ac *APIClient) PutPatch(incomingPatch patchSubmission) (*patch.Patch, erro
 		incomingPatch.variants,
 		incomingPatch.tasks,
 		incomingPatch.finalize,
+		incomingPatch.alias,
+		nil,
+	}
+	for _, m := range incomingPatch.modules {
+		data.Modules = append(data.Modules, service.PatchAPIModule{
+			Module:  m.name,
+			Patch:   m.diff.fullPatch,
+			Githash: m.diff.base,
+		})
 	}
 
 	rPipe, wPipe := io.Pipe()
@@ -395,6 +411,64 @@ func (ac *APIClient) PutPatch(incomingPatch patchSubmission) (*patch.Patch, erro
 	return reply.Patch, nil
 }
 
+// GetCommitQueue requests the items in a project's commit queue, in the order they will be merged.
+func (ac *APIClient) GetCommitQueue(projectId string) ([]service.RestCommitQueueItem, error) {
+	resp, err := ac.get(fmt.Sprintf("commit_queue/%s", projectId), nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, NewAPIError(resp)
+	}
+	items := []service.RestCommitQueueItem{}
+	if err := util.ReadJSONInto(resp.Body, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// EnqueuePatch adds a patch to the back of its project's commit queue, and returns its position.
+func (ac *APIClient) EnqueuePatch(projectId, patchId string) (int, error) {
+	data := struct {
+		PatchId string `json:"patch_id"`
+	}{patchId}
+
+	rPipe, wPipe := io.Pipe()
+	encoder := json.NewEncoder(wPipe)
+	go func() {
+		grip.Warning(encoder.Encode(data))
+		grip.Warning(wPipe.Close())
+	}()
+	defer rPipe.Close()
+
+	resp, err := ac.put(fmt.Sprintf("commit_queue/%s", projectId), rPipe)
+	if err != nil {
+		return -1, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return -1, NewAPIError(resp)
+	}
+	reply := struct {
+		Position int `json:"position"`
+	}{}
+	if err := util.ReadJSONInto(resp.Body, &reply); err != nil {
+		return -1, err
+	}
+	return reply.Position, nil
+}
+
+// DequeuePatch removes a patch from a project's commit queue.
+func (ac *APIClient) DequeuePatch(projectId, patchId string) error {
+	resp, err := ac.delete(fmt.Sprintf("commit_queue/%s/%s", projectId, patchId), nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return NewAPIError(resp)
+	}
+	return nil
+}
+
 // CheckUpdates fetches information about available updates to client binaries from the server.
 func (ac *APIClient) CheckUpdates() (*evergreen.ClientConfig, error) {
 	resp, err := ac.get("update", nil)
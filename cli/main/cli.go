@@ -21,10 +21,14 @@ func main() {
 	parser.AddCommand("rm-module", "remove a module from an existing patch", "", &cli.RemoveModuleCommand{GlobalOpts: &opts})
 	parser.AddCommand("cancel-patch", "cancel an existing patch", "", &cli.CancelPatchCommand{GlobalOpts: &opts})
 	parser.AddCommand("finalize-patch", "finalize an existing patch", "", &cli.FinalizePatchCommand{GlobalOpts: &opts})
+	parser.AddCommand("commit-queue", "show the patches in a project's commit queue", "", &cli.CommitQueueCommand{GlobalOpts: &opts})
+	parser.AddCommand("enqueue-patch", "add a patch to its project's commit queue, to be merged once it passes", "", &cli.EnqueuePatchCommand{GlobalOpts: &opts})
+	parser.AddCommand("dequeue-patch", "remove a patch from its project's commit queue", "", &cli.DequeuePatchCommand{GlobalOpts: &opts})
 	parser.AddCommand("list", "list available projects, tasks, or variants", "", &cli.ListCommand{GlobalOpts: &opts})
 	parser.AddCommand("last-green", "return a project's most recent successful version for given variants", "", &cli.LastGreenCommand{GlobalOpts: &opts})
 	parser.AddCommand("validate", "validate a config file", "", &cli.ValidateCommand{GlobalOpts: &opts})
 	parser.AddCommand("evaluate", "display a project file's evaluated and expanded form", "", &cli.EvaluateCommand{})
+	parser.AddCommand("run-local", "run a task from a project config file on this machine", "", &cli.RunLocalCommand{})
 	parser.AddCommand("fetch", "fetch data associated with a task", "", &cli.FetchCommand{GlobalOpts: &opts})
 	parser.AddCommand("export", "export statistics as csv or json for given options", "", &cli.ExportCommand{GlobalOpts: &opts})
 	parser.AddCommand("test-history", "retrieve test history for a given project", "", &cli.TestHistoryCommand{GlobalOpts: &opts})
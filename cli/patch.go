@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"sort"
 	"strings"
 	"text/tabwriter"
@@ -60,6 +61,28 @@ type localDiff struct {
 	base         string
 }
 
+// moduleDiff is the changes to one of the project's modules, which the user
+// has checked out inside of their checkout of the project.
+type moduleDiff struct {
+	name string
+	dir  string
+	diff *localDiff
+}
+
+// gitDiffOptions selects which of the changes in a checkout a patch includes.
+// By default, it includes the commits since the merge base with the upstream
+// branch along with any uncommitted changes.
+type gitDiffOptions struct {
+	// uncommitted includes only the changes that are not yet committed, on
+	// top of HEAD, which must already be on the upstream branch.
+	uncommitted bool
+	// staged leaves out the changes that are not yet staged.
+	staged bool
+	// commits includes only the changes in a range of commits, such as
+	// "A..B", or in a single commit.
+	commits string
+}
+
 type patchSubmission struct {
 	projectId   string
 	patchData   string
@@ -68,6 +91,8 @@ type patchSubmission struct {
 	variants    string
 	tasks       []string
 	finalize    bool
+	alias       string
+	modules     []moduleDiff
 }
 
 // ListPatchesCommand is used to list a user's existing patches.
@@ -91,6 +116,7 @@ type ListCommand struct {
 // ValidateCommand is used to verify that a config file is valid.
 type ValidateCommand struct {
 	GlobalOpts *Options `no-flag:"true"`
+	Project    string   `short:"p" long:"project" description:"project whose settings, such as its aliases, should be checked against the file"`
 	Positional struct {
 		FileName string `positional-arg-name:"filename" description:"path to an evergreen project file"`
 	} `positional-args:"1" required:"yes"`
@@ -111,6 +137,10 @@ type FinalizePatchCommand struct {
 // PatchCommand is used to submit a new patch to the API server.
 type PatchCommand struct {
 	PatchCommandParams
+	Uncommitted bool   `short:"u" long:"uncommitted" description:"include only uncommitted changes, on top of HEAD"`
+	Staged      bool   `long:"staged" description:"include only staged changes, leaving out unstaged ones"`
+	Commits     string `long:"commits" description:"include only the changes in a range of commits, such as 'A..B', or in a single commit"`
+	SkipModules bool   `long:"skip-modules" description:"do not include changes to modules checked out inside of the project"`
 }
 
 // PatchFileCommand is used to submit a new patch to the API server using a diff file.
@@ -126,6 +156,7 @@ type PatchCommandParams struct {
 	Project     string   `short:"p" long:"project" description:"project to submit patch for"`
 	Variants    []string `short:"v" long:"variants"`
 	Tasks       []string `short:"t" long:"tasks"`
+	Alias       string   `short:"a" long:"alias" description:"project alias that selects the variants and tasks to run, instead of -v/-t"`
 	SkipConfirm bool     `short:"y" long:"yes" description:"skip confirmation text"`
 	Description string   `short:"d" long:"description" description:"description of patch (optional)"`
 	Finalize    bool     `short:"f" long:"finalize" description:"schedule tasks immediately"`
@@ -226,7 +257,7 @@ func (vc *ValidateCommand) Execute(_ []string) error {
 	if err != nil {
 		return err
 	}
-	projErrors, err := ac.ValidateLocalConfig(confFile)
+	projErrors, err := ac.ValidateLocalConfig(confFile, vc.Project)
 	if err != nil {
 		return nil
 	}
@@ -286,7 +317,7 @@ func (smc *SetModuleCommand) Execute(args []string) error {
 	}
 
 	// diff against the module branch.
-	diffData, err := loadGitData(moduleBranch, args...)
+	diffData, err := loadGitData("", moduleBranch, gitDiffOptions{}, args...)
 	if err != nil {
 		return err
 	}
@@ -329,17 +360,30 @@ func (smc *SetModuleCommand) Execute(args []string) error {
 }
 
 func (pc *PatchCommand) Execute(args []string) error {
+	if pc.Commits != "" && (pc.Uncommitted || pc.Staged) {
+		return errors.New("cannot specify a range of commits along with --uncommitted or --staged")
+	}
 	ac, settings, ref, err := validatePatchCommand(&pc.PatchCommandParams)
 	if err != nil {
 		return err
 	}
 
-	diffData, err := loadGitData(ref.Branch, args...)
+	opts := gitDiffOptions{uncommitted: pc.Uncommitted, staged: pc.Staged, commits: pc.Commits}
+	diffData, err := loadGitData("", ref.Branch, opts, args...)
 	if err != nil {
 		return err
 	}
 
-	return createPatch(pc.PatchCommandParams, ac, settings, diffData)
+	// a range of commits only exists in the project's own repository
+	var modules []moduleDiff
+	if !pc.SkipModules && pc.Commits == "" {
+		modules, err = loadModuleGitData("", ref, opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return createPatch(pc.PatchCommandParams, ac, settings, diffData, modules...)
 }
 
 func (pfc *PatchFileCommand) Execute(_ []string) error {
@@ -580,13 +624,39 @@ func validatePatchCommand(params *PatchCommandParams) (ac *APIClient, settings *
 		return
 	}
 
+	if params.Alias != "" {
+		if len(params.Variants) > 0 || len(params.Tasks) > 0 {
+			err = errors.New("cannot specify variants or tasks along with an alias")
+			return
+		}
+		if len(ref.FindAliases(params.Alias)) == 0 {
+			err = errors.Errorf("project '%v' has no alias '%v'", params.Project, params.Alias)
+			if names := ref.AliasNames(); len(names) > 0 {
+				err = errors.Errorf("%v, known aliases are:\n\t%v", err, strings.Join(names, "\n\t"))
+			}
+			return
+		}
+	} else if err = updatePatchVariantsTasks(params, settings); err != nil {
+		return
+	}
+
+	if params.Description == "" && !params.SkipConfirm {
+		params.Description = prompt("Enter a description for this patch (optional):")
+	}
+
+	return
+}
+
+// updatePatchVariantsTasks fills in the variants and tasks that the patch
+// runs from the user's defaults for the project if none were given, or
+// offers to save the given ones as the defaults.
+func updatePatchVariantsTasks(params *PatchCommandParams, settings *model.CLISettings) error {
 	// update variants
 	if len(params.Variants) == 0 {
 		params.Variants = settings.FindDefaultVariants(params.Project)
 		if len(params.Variants) == 0 && params.Finalize {
-			err = errors.Errorf("Need to specify at least one buildvariant with -v when finalizing." +
+			return errors.Errorf("Need to specify at least one buildvariant with -v when finalizing." +
 				" Run with `-v all` to finalize against all variants.")
-			return
 		}
 	} else {
 		defaultVariants := settings.FindDefaultVariants(params.Project)
@@ -604,9 +674,8 @@ func validatePatchCommand(params *PatchCommandParams) (ac *APIClient, settings *
 	if len(params.Tasks) == 0 {
 		params.Tasks = settings.FindDefaultTasks(params.Project)
 		if len(params.Tasks) == 0 && params.Finalize {
-			err = errors.Errorf("Need to specify at least one task with -t when finalizing." +
+			return errors.Errorf("Need to specify at least one task with -t when finalizing." +
 				" Run with `-t all` to finalize against all tasks.")
-			return
 		}
 	} else {
 		defaultTasks := settings.FindDefaultTasks(params.Project)
@@ -619,28 +688,36 @@ func validatePatchCommand(params *PatchCommandParams) (ac *APIClient, settings *
 			}
 		}
 	}
-
-	if params.Description == "" && !params.SkipConfirm {
-		params.Description = prompt("Enter a description for this patch (optional):")
-	}
-
-	return
+	return nil
 }
 
-// Creates a patch using diffData
-func createPatch(params PatchCommandParams, ac *APIClient, settings *model.CLISettings, diffData *localDiff) error {
+// Creates a patch using diffData, along with the changes to any modules
+func createPatch(params PatchCommandParams, ac *APIClient, settings *model.CLISettings, diffData *localDiff,
+	modules ...moduleDiff) error {
 	if err := validatePatchSize(diffData, params.Large); err != nil {
 		return err
 	}
-	if !params.SkipConfirm && len(diffData.fullPatch) == 0 {
+	for _, m := range modules {
+		if err := validatePatchSize(m.diff, params.Large); err != nil {
+			return errors.Wrapf(err, "module %v", m.name)
+		}
+	}
+	if !params.SkipConfirm && len(diffData.fullPatch) == 0 && len(modules) == 0 {
 		if !confirm("Patch submission is empty. Continue?(y/n)", true) {
 			return nil
 		}
-	} else if !params.SkipConfirm && diffData.patchSummary != "" {
+	} else if !params.SkipConfirm && (diffData.patchSummary != "" || len(modules) > 0) {
 		fmt.Println(diffData.patchSummary)
 		if diffData.log != "" {
 			fmt.Println(diffData.log)
 		}
+		for _, m := range modules {
+			fmt.Printf("Module %v (%v):\n", m.name, m.dir)
+			fmt.Println(m.diff.patchSummary)
+			if m.diff.log != "" {
+				fmt.Println(m.diff.log)
+			}
+		}
 
 		if !confirm("This is a summary of the patch to be submitted. Continue? (y/n):", true) {
 			return nil
@@ -650,7 +727,7 @@ func createPatch(params PatchCommandParams, ac *APIClient, settings *model.CLISe
 	variantsStr := strings.Join(params.Variants, ",")
 	patchSub := patchSubmission{
 		params.Project, diffData.fullPatch, params.Description,
-		diffData.base, variantsStr, params.Tasks, params.Finalize,
+		diffData.base, variantsStr, params.Tasks, params.Finalize, params.Alias, modules,
 	}
 
 	newPatch, err := ac.PutPatch(patchSub)
@@ -682,42 +759,151 @@ func validatePatchSize(diff *localDiff, allowLarge bool) error {
 	return nil
 }
 
-// loadGitData inspects the current git working directory and returns a patch and its summary.
-// The branch argument is used to determine where to generate the merge base from, and any extra
-// arguments supplied are passed directly in as additional args to git diff.
-func loadGitData(branch string, extraArgs ...string) (*localDiff, error) {
+// loadGitData inspects the git checkout in dir, or in the current working directory if dir
+// is empty, and returns a patch and its summary. The branch argument is used to determine
+// where to generate the merge base from, opts selects which of the changes to include, and
+// any extra arguments supplied are passed directly in as additional args to git diff.
+func loadGitData(dir, branch string, opts gitDiffOptions, extraArgs ...string) (*localDiff, error) {
+	if opts.commits != "" {
+		return loadGitCommitsData(dir, opts.commits, extraArgs...)
+	}
+
 	// branch@{upstream} refers to the branch that the branch specified by branchname is set to
 	// build on top of. This allows automatically detecting a branch based on the correct remote,
 	// if the user's repo is a fork, for example.
 	// For details see: https://git-scm.com/docs/gitrevisions
-	mergeBase, err := gitMergeBase(branch+"@{upstream}", "HEAD")
+	base, err := gitMergeBase(dir, branch+"@{upstream}", "HEAD")
 	if err != nil {
 		return nil, errors.Errorf("Error getting merge base: %v", err)
 	}
-	statArgs := []string{"--stat"}
-	if len(extraArgs) > 0 {
-		statArgs = append(statArgs, extraArgs...)
+	if opts.uncommitted {
+		// the patch's base must be a commit that evergreen knows about
+		var head string
+		head, err = gitRevParse(dir, "HEAD")
+		if err != nil {
+			return nil, errors.Errorf("Error getting HEAD: %v", err)
+		}
+		if head != base {
+			return nil, errors.Errorf("HEAD has commits that are not on %v@{upstream}, "+
+				"so it cannot be the base of a patch of only uncommitted changes", branch)
+		}
+	}
+
+	diffArgs := []string{}
+	if opts.staged {
+		diffArgs = append(diffArgs, "--cached")
 	}
-	stat, err := gitDiff(mergeBase, statArgs...)
+	diffArgs = append(diffArgs, extraArgs...)
+	stat, err := gitDiff(dir, base, append([]string{"--stat"}, diffArgs...)...)
 	if err != nil {
 		return nil, errors.Errorf("Error getting diff summary: %v", err)
 	}
-	log, err := gitLog(mergeBase)
+	log, err := gitLog(dir, base)
 	if err != nil {
 		return nil, errors.Errorf("git log: %v", err)
 	}
 
-	patch, err := gitDiff(mergeBase, extraArgs...)
+	patch, err := gitDiff(dir, base, diffArgs...)
 	if err != nil {
 		return nil, errors.Errorf("Error getting patch: %v", err)
 	}
-	return &localDiff{patch, stat, log, mergeBase}, nil
+	return &localDiff{patch, stat, log, base}, nil
 }
 
-// gitMergeBase runs "git merge-base <branch1> <branch2>" and returns the
+// loadGitCommitsData returns the patch of a range of commits, such as "A..B", or of a
+// single commit, whose base is the first commit of the range.
+func loadGitCommitsData(dir, commits string, extraArgs ...string) (*localDiff, error) {
+	from, to := commits+"^", commits
+	if i := strings.Index(commits, ".."); i >= 0 {
+		if strings.Contains(commits, "...") {
+			return nil, errors.Errorf("invalid range of commits '%v', use 'A..B'", commits)
+		}
+		from, to = commits[:i], commits[i+2:]
+		if to == "" {
+			to = "HEAD"
+		}
+	}
+	base, err := gitRevParse(dir, from)
+	if err != nil {
+		return nil, errors.Errorf("Error getting base of commits '%v': %v", commits, err)
+	}
+	head, err := gitRevParse(dir, to)
+	if err != nil {
+		return nil, errors.Errorf("Error getting end of commits '%v': %v", commits, err)
+	}
+
+	diffArgs := append([]string{head}, extraArgs...)
+	stat, err := gitDiff(dir, base, append([]string{"--stat"}, diffArgs...)...)
+	if err != nil {
+		return nil, errors.Errorf("Error getting diff summary: %v", err)
+	}
+	log, err := gitCmdIn(dir, "log", base+".."+head, "--oneline")
+	if err != nil {
+		return nil, errors.Errorf("git log: %v", err)
+	}
+
+	patch, err := gitDiff(dir, base, diffArgs...)
+	if err != nil {
+		return nil, errors.Errorf("Error getting patch: %v", err)
+	}
+	return &localDiff{patch, stat, log, base}, nil
+}
+
+// loadModuleGitData returns the changes to each of the project's modules that the user has
+// checked out where a task would clone it, at the module's prefix inside of the project's
+// checkout in dir, or in the current working directory if dir is empty. The modules are read
+// from the project's config file in the checkout. Modules whose branch has no upstream to
+// compare against are left out with a warning.
+func loadModuleGitData(dir string, ref *model.ProjectRef, opts gitDiffOptions) ([]moduleDiff, error) {
+	root, err := gitCmdIn(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, errors.Errorf("Error finding the root of the checkout: %v", err)
+	}
+	root = strings.TrimSpace(root)
+
+	project, err := loadLocalConfig(filepath.Join(root, ref.RemotePath))
+	if err != nil {
+		grip.Warningf("not including changes to modules, could not read the project's config: %v", err)
+		return nil, nil
+	}
+
+	modules := []moduleDiff{}
+	for _, module := range project.Modules {
+		moduleDir := filepath.Join(root, module.Prefix, module.Name)
+		if !isGitCheckout(moduleDir) {
+			continue
+		}
+		if opts.commits == "" {
+			if _, err = gitRevParse(moduleDir, module.Branch+"@{upstream}"); err != nil {
+				grip.Warningf("not including changes to module %v: branch %v in %v has no upstream branch",
+					module.Name, module.Branch, moduleDir)
+				continue
+			}
+		}
+		diff, err := loadGitData(moduleDir, module.Branch, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error getting the changes to module %v in %v", module.Name, moduleDir)
+		}
+		if diff.fullPatch == "" {
+			continue
+		}
+		modules = append(modules, moduleDiff{name: module.Name, dir: moduleDir, diff: diff})
+	}
+	return modules, nil
+}
+
+// isGitCheckout returns whether dir is the root of a git checkout of its own.
+func isGitCheckout(dir string) bool {
+	// .git is a file rather than a directory in a submodule or worktree
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// gitMergeBase runs "git merge-base <branch1> <branch2>" in dir and returns the
 // resulting githash as string
-func gitMergeBase(branch1, branch2 string) (string, error) {
+func gitMergeBase(dir, branch1, branch2 string) (string, error) {
 	cmd := exec.Command("git", "merge-base", branch1, branch2)
+	cmd.Dir = dir
 	out, err := cmd.Output()
 	if err != nil {
 		return "", errors.Errorf("'git merge-base %v %v' failed: %v", branch1, branch2, err)
@@ -725,31 +911,50 @@ func gitMergeBase(branch1, branch2 string) (string, error) {
 	return strings.TrimSpace(string(out)), err
 }
 
-// gitDiff runs "git diff <base> <diffargs ...>" and returns the output of the command as a string
-func gitDiff(base string, diffArgs ...string) (string, error) {
-	args := make([]string, 0, 1+len(diffArgs))
-	args = append(args, "--no-ext-diff")
+// gitRevParse returns the githash of the commit that rev names in dir
+func gitRevParse(dir, rev string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Errorf("unknown commit '%v'", rev)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitDiff runs "git diff <base> <diffargs ...>" in dir and returns the output of the command as a string
+func gitDiff(dir, base string, diffArgs ...string) (string, error) {
+	args := make([]string, 0, 2+len(diffArgs))
+	args = append(args, base, "--no-ext-diff")
 	args = append(args, diffArgs...)
-	return gitCmd("diff", base, args...)
+	return gitCmdIn(dir, "diff", args...)
 }
 
-// getLog runs "git log <base>
-func gitLog(base string, logArgs ...string) (string, error) {
-	args := append(logArgs, "--oneline")
-	return gitCmd("log", fmt.Sprintf("...%v", base), args...)
+// getLog runs "git log <base> in dir
+func gitLog(dir, base string, logArgs ...string) (string, error) {
+	args := append([]string{fmt.Sprintf("...%v", base)}, logArgs...)
+	return gitCmdIn(dir, "log", append(args, "--oneline")...)
 }
 
 func gitCmd(cmdName, base string, gitArgs ...string) (string, error) {
 	args := make([]string, 0, 1+len(gitArgs))
-	args = append(args, cmdName)
 	if base != "" {
 		args = append(args, base)
 	}
 	args = append(args, gitArgs...)
+	return gitCmdIn("", cmdName, args...)
+}
+
+// gitCmdIn runs the git command in dir, or in the current working directory if dir is empty
+func gitCmdIn(dir, cmdName string, gitArgs ...string) (string, error) {
+	args := make([]string, 0, 1+len(gitArgs))
+	args = append(args, cmdName)
+	args = append(args, gitArgs...)
 	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", errors.Errorf("'git %v %v' failed with err %v", base, strings.Join(args, " "), err)
+		return "", errors.Errorf("'git %v' failed with err %v", strings.Join(args, " "), err)
 	}
 	return string(out), err
 }
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen/agent"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	// register the builtin plugins so that their commands can be run
+	_ "github.com/evergreen-ci/evergreen/plugin/config"
+)
+
+// RunLocalCommand runs a task from a project config file on the local
+// machine, without submitting anything to the Evergreen server.
+type RunLocalCommand struct {
+	ConfigFile     string   `short:"p" long:"project" description:"path to the project config file" required:"true"`
+	Variant        string   `short:"v" long:"variant" description:"build variant to run the task on" required:"true"`
+	Task           string   `short:"t" long:"task" description:"task to run" required:"true"`
+	Expansions     []string `short:"e" long:"expansion" description:"expansion in the form key=value. may be specified multiple times"`
+	ExpansionsFile string   `long:"expansions-file" description:"yaml file of expansions, overridden by --expansion"`
+	WorkDir        string   `long:"dir" description:"directory to run the task in. defaults to current working directory"`
+	OutputDir      string   `short:"o" long:"output" description:"directory for logs, results and attached files. defaults to evergreen-local/<variant>_<task> in the task directory"`
+}
+
+func (rlc *RunLocalCommand) Execute(_ []string) error {
+	configBytes, err := ioutil.ReadFile(rlc.ConfigFile)
+	if err != nil {
+		return errors.Wrap(err, "error reading project config")
+	}
+
+	project := &model.Project{}
+	if err = model.LoadProjectInto(configBytes, "", project); err != nil {
+		return errors.Wrap(err, "error loading project")
+	}
+
+	expansions, err := rlc.loadExpansions()
+	if err != nil {
+		return err
+	}
+
+	runner := &agent.LocalRunner{
+		Project:    project,
+		Variant:    rlc.Variant,
+		Task:       rlc.Task,
+		Expansions: expansions,
+		WorkDir:    rlc.WorkDir,
+		OutputDir:  rlc.OutputDir,
+	}
+
+	// interrupting run-local stops the running command, then runs post;
+	// interrupting it again exits without waiting for post
+	stop := make(chan bool)
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	go func() {
+		if _, ok := <-interrupt; ok {
+			signal.Stop(interrupt)
+			close(stop)
+		}
+	}()
+
+	return errors.Wrapf(runner.Run(stop), "task '%s' failed", rlc.Task)
+}
+
+// loadExpansions merges the expansions file with the expansions passed on
+// the command line.
+func (rlc *RunLocalCommand) loadExpansions() (map[string]string, error) {
+	expansions := map[string]string{}
+	if rlc.ExpansionsFile != "" {
+		data, err := ioutil.ReadFile(rlc.ExpansionsFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading expansions file")
+		}
+		if err = yaml.Unmarshal(data, expansions); err != nil {
+			return nil, errors.Wrap(err, "error parsing expansions file")
+		}
+	}
+
+	for _, expansion := range rlc.Expansions {
+		parts := strings.SplitN(expansion, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("expansion '%s' is not in the form key=value", expansion)
+		}
+		expansions[parts[0]] = parts[1]
+	}
+	return expansions, nil
+}
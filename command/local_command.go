@@ -17,6 +17,7 @@ type LocalCommand struct {
 	Shell            string
 	Environment      []string
 	ScriptMode       bool
+	Prefix           []string
 	Stdout           io.Writer
 	Stderr           io.Writer
 	Cmd              *exec.Cmd
@@ -54,12 +55,18 @@ func (lc *LocalCommand) Start() error {
 		lc.Shell = "sh"
 	}
 
-	var cmd *exec.Cmd
+	// a prefix, if set, is run with the shell's command line as its
+	// arguments and is expected to exec the shell
+	args := append([]string{}, lc.Prefix...)
 	if lc.ScriptMode {
-		cmd = exec.Command(lc.Shell)
-		cmd.Stdin = strings.NewReader(lc.CmdString)
+		args = append(args, lc.Shell)
 	} else {
-		cmd = exec.Command(lc.Shell, "-c", lc.CmdString)
+		args = append(args, lc.Shell, "-c", lc.CmdString)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	if lc.ScriptMode {
+		cmd.Stdin = strings.NewReader(lc.CmdString)
 	}
 
 	// create the command, set the options
@@ -0,0 +1,264 @@
+package commitqueue
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/pkg/errors"
+)
+
+const (
+	// git modes of the files the commit queue can merge changes to
+	regularFileMode    = "100644"
+	executableFileMode = "100755"
+)
+
+// mergePatch merges the queued patch into the project's branch. A pull
+// request is merged through GitHub, provided it has been approved and has
+// not been pushed to since the patch was made. Any other patch is committed
+// on top of the revision that the test patch tested.
+func mergePatch(settings *evergreen.Settings, projectRef *model.ProjectRef, p, testPatch *patch.Patch) error {
+	oauthToken := settings.Credentials["github"]
+	if data := p.GithubPatchData; data != nil {
+		reviews, err := thirdparty.GetGithubPullRequestReviews(oauthToken, data.BaseOwner, data.BaseRepo,
+			data.PRNumber)
+		if err != nil {
+			return errors.Wrapf(err, "error getting reviews of pull request #%d", data.PRNumber)
+		}
+		if !thirdparty.IsGithubPullRequestApproved(reviews) {
+			return errors.Errorf("pull request #%d has not been approved", data.PRNumber)
+		}
+		return thirdparty.MergeGithubPullRequest(oauthToken, data.BaseOwner, data.BaseRepo,
+			data.PRNumber, data.HeadHash)
+	}
+
+	if err := testPatch.FetchPatchFiles(); err != nil {
+		return errors.Wrap(err, "error fetching patch files")
+	}
+	diff, err := projectDiff(testPatch)
+	if err != nil {
+		return err
+	}
+
+	files, err := fetchPatchedFiles(oauthToken, projectRef, testPatch.Githash, diff)
+	if err != nil {
+		return err
+	}
+	existed := map[string]bool{}
+	for path, file := range files {
+		existed[path] = file != nil
+	}
+	if err = applyDiff(files, diff); err != nil {
+		return err
+	}
+
+	entries := []thirdparty.GithubTreeEntry{}
+	for path, file := range files {
+		entry := thirdparty.GithubTreeEntry{Path: path, Mode: regularFileMode, Type: "blob"}
+		if file == nil {
+			// a nil SHA deletes the file
+			if existed[path] {
+				entries = append(entries, entry)
+			}
+			continue
+		}
+		sha, err := thirdparty.CreateGithubBlob(oauthToken, projectRef.Owner, projectRef.Repo, file.Content)
+		if err != nil {
+			return err
+		}
+		entry.Mode, entry.SHA = file.Mode, &sha
+		entries = append(entries, entry)
+	}
+
+	message := p.Description
+	if message == "" {
+		message = fmt.Sprintf("Merge patch %s", p.Id.Hex())
+	}
+	var author *thirdparty.GithubGitAuthor
+	dbUser, err := user.FindOne(user.ById(p.Author))
+	if err != nil {
+		return errors.Wrapf(err, "error finding user %s", p.Author)
+	}
+	if dbUser != nil && dbUser.Email() != "" {
+		author = &thirdparty.GithubGitAuthor{Name: dbUser.DisplayName(), Email: dbUser.Email()}
+	}
+
+	commit, err := thirdparty.CreateGithubCommit(oauthToken, projectRef.Owner, projectRef.Repo,
+		testPatch.Githash, entries, message, author)
+	if err != nil {
+		return err
+	}
+	return thirdparty.UpdateGithubBranch(oauthToken, projectRef.Owner, projectRef.Repo,
+		projectRef.Branch, commit)
+}
+
+// projectDiff returns the diff of the patch to the project's own
+// repository. Patches to modules cannot be merged.
+func projectDiff(p *patch.Patch) (string, error) {
+	diff := ""
+	for _, modulePatch := range p.Patches {
+		if modulePatch.ModuleName != "" {
+			if modulePatch.PatchSet.Patch != "" {
+				return "", errors.Errorf("changes to module '%s' cannot be merged", modulePatch.ModuleName)
+			}
+			continue
+		}
+		diff = modulePatch.PatchSet.Patch
+	}
+	if diff == "" {
+		return "", errors.New("patch has no changes to merge")
+	}
+	return diff, nil
+}
+
+// patchedFile is the contents and git mode of a file that a diff changes.
+type patchedFile struct {
+	Content []byte
+	Mode    string
+}
+
+// diffPaths returns the paths of the files that a git diff reads or writes.
+func diffPaths(diff string) []string {
+	paths := []string{}
+	seen := map[string]bool{}
+	add := func(path string) {
+		if path != "" && path != "/dev/null" && !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "--- a/"):
+			add(strings.TrimPrefix(line, "--- a/"))
+		case strings.HasPrefix(line, "+++ b/"):
+			add(strings.TrimPrefix(line, "+++ b/"))
+		case strings.HasPrefix(line, "rename from "):
+			add(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "rename to "):
+			add(strings.TrimPrefix(line, "rename to "))
+		case strings.HasPrefix(line, "copy to "):
+			add(strings.TrimPrefix(line, "copy to "))
+		case strings.HasPrefix(line, "diff --git a/"):
+			// files whose mode alone changes, or that are empty, have no
+			// ---/+++ lines
+			names := strings.SplitN(strings.TrimPrefix(line, "diff --git a/"), " b/", 2)
+			if len(names) == 2 && names[0] == names[1] {
+				add(names[0])
+			}
+		}
+	}
+	return paths
+}
+
+// fetchPatchedFiles gets the files that the diff changes, as at the given
+// revision. Files that do not exist at the revision are nil.
+func fetchPatchedFiles(oauthToken string, projectRef *model.ProjectRef, revision,
+	diff string) (map[string]*patchedFile, error) {
+	modes, err := thirdparty.GetGithubTreeModes(oauthToken, projectRef.Owner, projectRef.Repo, revision)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing files")
+	}
+
+	files := map[string]*patchedFile{}
+	for _, path := range diffPaths(diff) {
+		mode, ok := modes[path]
+		if !ok {
+			files[path] = nil
+			continue
+		}
+		if mode != regularFileMode && mode != executableFileMode {
+			return nil, errors.Errorf("changes to '%s', which is not a regular file, cannot be merged", path)
+		}
+		githubFile, err := thirdparty.GetGithubFile(oauthToken,
+			thirdparty.GetGithubFileURL(projectRef.Owner, projectRef.Repo, path, revision))
+		if err != nil {
+			return nil, errors.Wrapf(err, "error getting file '%s'", path)
+		}
+		content, err := base64.StdEncoding.DecodeString(githubFile.Content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error decoding file '%s'", path)
+		}
+		files[path] = &patchedFile{Content: content, Mode: mode}
+	}
+	return files, nil
+}
+
+// applyDiff applies the diff to the files, which are updated in place. Files
+// that the diff deletes become nil.
+func applyDiff(files map[string]*patchedFile, diff string) error {
+	dir, err := ioutil.TempDir("", "commit-queue")
+	if err != nil {
+		return errors.Wrap(err, "error making working directory")
+	}
+	defer os.RemoveAll(dir)
+
+	for path, file := range files {
+		if file == nil {
+			continue
+		}
+		perm := os.FileMode(0644)
+		if file.Mode == executableFileMode {
+			perm = 0755
+		}
+		fullPath := filepath.Join(dir, path)
+		if err = os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return errors.Wrapf(err, "error making directory for '%s'", path)
+		}
+		if err = ioutil.WriteFile(fullPath, file.Content, perm); err != nil {
+			return errors.Wrapf(err, "error writing '%s'", path)
+		}
+		// WriteFile's permissions are subject to the umask
+		if err = os.Chmod(fullPath, perm); err != nil {
+			return errors.Wrapf(err, "error setting mode of '%s'", path)
+		}
+	}
+
+	// git apply applies a diff relative to the top of the repository it runs
+	// in, so the working directory is made a repository of its own
+	var stderr bytes.Buffer
+	for _, args := range [][]string{{"init", "--quiet"}, {"apply", "--whitespace=nowarn", "-"}} {
+		stderr.Reset()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Stdin = strings.NewReader(diff)
+		cmd.Stderr = &stderr
+		if err = cmd.Run(); err != nil {
+			return errors.Wrapf(err, "'git %s' failed: %s", strings.Join(args, " "),
+				strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	for path := range files {
+		fullPath := filepath.Join(dir, path)
+		info, err := os.Stat(fullPath)
+		if os.IsNotExist(err) {
+			files[path] = nil
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "error reading '%s'", path)
+		}
+		content, err := ioutil.ReadFile(fullPath)
+		if err != nil {
+			return errors.Wrapf(err, "error reading '%s'", path)
+		}
+		mode := regularFileMode
+		if info.Mode()&0111 != 0 {
+			mode = executableFileMode
+		}
+		files[path] = &patchedFile{Content: content, Mode: mode}
+	}
+	return nil
+}
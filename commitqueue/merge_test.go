@@ -0,0 +1,67 @@
+package commitqueue
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testDiff = `diff --git a/README.md b/README.md
+index 3b18e51..a042389 100644
+--- a/README.md
++++ b/README.md
+@@ -1,2 +1,2 @@
+ # project
+-hello world
++hello commit queue
+diff --git a/run.sh b/run.sh
+new file mode 100755
+index 0000000..1a2b3c4
+--- /dev/null
++++ b/run.sh
+@@ -0,0 +1 @@
++echo run
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+index 9daeafb..0000000
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-test
+diff --git a/build.sh b/build.sh
+old mode 100644
+new mode 100755
+`
+
+func TestDiffPaths(t *testing.T) {
+	Convey("The paths of every file a diff changes should be found", t, func() {
+		So(diffPaths(testDiff), ShouldResemble, []string{"README.md", "run.sh", "old.txt", "build.sh"})
+	})
+}
+
+func TestApplyDiff(t *testing.T) {
+	Convey("With the files a diff changes", t, func() {
+		files := map[string]*patchedFile{
+			"README.md": {Content: []byte("# project\nhello world\n"), Mode: regularFileMode},
+			"run.sh":    nil,
+			"old.txt":   {Content: []byte("test\n"), Mode: regularFileMode},
+			"build.sh":  {Content: []byte("make\n"), Mode: regularFileMode},
+		}
+
+		Convey("applying the diff should change, create, delete, and chmod them", func() {
+			So(applyDiff(files, testDiff), ShouldBeNil)
+			So(string(files["README.md"].Content), ShouldEqual, "# project\nhello commit queue\n")
+			So(files["README.md"].Mode, ShouldEqual, regularFileMode)
+			So(string(files["run.sh"].Content), ShouldEqual, "echo run\n")
+			So(files["run.sh"].Mode, ShouldEqual, executableFileMode)
+			So(files["old.txt"], ShouldBeNil)
+			So(string(files["build.sh"].Content), ShouldEqual, "make\n")
+			So(files["build.sh"].Mode, ShouldEqual, executableFileMode)
+		})
+
+		Convey("a diff that does not apply should be an error", func() {
+			files["README.md"].Content = []byte("# project\nsomething else\n")
+			So(applyDiff(files, testDiff), ShouldNotBeNil)
+		})
+	})
+}
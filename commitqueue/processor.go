@@ -0,0 +1,172 @@
+// Package commitqueue merges the patches in projects' commit queues. The
+// patch at the head of each queue is tested on top of the project's branch,
+// then merged through the GitHub API if its tests pass, or ejected from the
+// queue if they fail.
+package commitqueue
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	queue "github.com/evergreen-ci/evergreen/model/commitqueue"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// TestTimeout is how long the patch at the head of a queue may be tested
+// before it is ejected, so that a test that never finishes does not hold up
+// the patches behind it.
+var TestTimeout = 12 * time.Hour
+
+// Processor advances commit queues. Its functions do the work that reaches
+// outside of the database, so that tests can replace them.
+type Processor struct {
+	// StartTest creates and finalizes a patch that tests the queued patch
+	// on top of the project's branch.
+	StartTest func(projectRef *model.ProjectRef, p *patch.Patch) (*patch.Patch, error)
+	// BranchHead returns the revision that the project's branch is at.
+	BranchHead func(projectRef *model.ProjectRef) (string, error)
+	// Merge merges the queued patch into the project's branch, as tested by
+	// the test patch.
+	Merge func(projectRef *model.ProjectRef, p, testPatch *patch.Patch) error
+	// CancelTest stops a test patch whose results are no longer needed.
+	CancelTest func(testPatch *patch.Patch) error
+	// Notify tells the user who queued the patch that it left the queue.
+	Notify func(item *queue.Item, p *patch.Patch, message string) error
+}
+
+// Process advances every commit queue that has patches in it.
+func (proc *Processor) Process() error {
+	queues, err := queue.Find(queue.NonEmpty())
+	if err != nil {
+		return errors.Wrap(err, "error finding commit queues")
+	}
+
+	catcher := grip.NewCatcher()
+	for i := range queues {
+		catcher.Add(errors.Wrapf(proc.processQueue(&queues[i]),
+			"error processing commit queue of project '%s'", queues[i].ProjectId))
+	}
+	return catcher.Resolve()
+}
+
+// processQueue advances the queue until the patch at its head is waiting on
+// its tests, or the queue is empty.
+func (proc *Processor) processQueue(cq *queue.CommitQueue) error {
+	projectRef, err := model.FindOneProjectRef(cq.ProjectId)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if projectRef == nil {
+		return errors.New("project not found")
+	}
+
+	for item := cq.Head(); item != nil; item = cq.Head() {
+		left, err := proc.processHead(projectRef, item)
+		if err != nil || !left {
+			return err
+		}
+		cq.Queue = cq.Queue[1:]
+	}
+	return nil
+}
+
+// processHead advances the item at the head of the queue, returning whether
+// it left the queue.
+func (proc *Processor) processHead(projectRef *model.ProjectRef, item *queue.Item) (bool, error) {
+	if !patch.IsValidId(item.PatchId) {
+		return proc.eject(projectRef, item, nil, "is not a valid patch")
+	}
+	p, err := patch.FindOne(patch.ById(patch.NewId(item.PatchId)))
+	if err != nil {
+		return false, errors.Wrapf(err, "error finding patch %s", item.PatchId)
+	}
+	if p == nil {
+		return proc.eject(projectRef, item, nil, "no longer exists")
+	}
+	if !projectRef.CommitQueueEnabled {
+		return proc.eject(projectRef, item, p, "was queued for a project whose commit queue is disabled")
+	}
+
+	if item.TestPatchId == "" {
+		testPatch, err := proc.StartTest(projectRef, p)
+		if err != nil {
+			return proc.eject(projectRef, item, p, fmt.Sprintf("could not be tested: %v", err))
+		}
+		grip.Infof("Testing patch %s from the commit queue of project '%s' with patch %s",
+			item.PatchId, projectRef.Identifier, testPatch.Id.Hex())
+		return false, errors.WithStack(queue.SetTestPatch(projectRef.Identifier, item.PatchId,
+			testPatch.Id.Hex()))
+	}
+
+	var testPatch *patch.Patch
+	if patch.IsValidId(item.TestPatchId) {
+		testPatch, err = patch.FindOne(patch.ById(patch.NewId(item.TestPatchId)))
+		if err != nil {
+			return false, errors.Wrapf(err, "error finding test patch %s", item.TestPatchId)
+		}
+	}
+	if testPatch == nil {
+		return proc.eject(projectRef, item, p, "could not be tested: its test patch no longer exists")
+	}
+
+	if testPatch.Status == evergreen.PatchFailed {
+		return proc.eject(projectRef, item, p, fmt.Sprintf("failed its tests in patch %s",
+			testPatch.Id.Hex()))
+	}
+
+	head, err := proc.BranchHead(projectRef)
+	if err != nil {
+		return false, errors.Wrap(err, "error finding branch head")
+	}
+	if head != testPatch.Githash {
+		// the branch moved while the patch was tested, so the merge would
+		// not be what is tested; stop the test and test the patch again on
+		// top of the branch
+		grip.Infof("Branch %s of project '%s' moved from %s to %s, testing patch %s again",
+			projectRef.Branch, projectRef.Identifier, testPatch.Githash, head, item.PatchId)
+		if err = proc.CancelTest(testPatch); err != nil {
+			return false, errors.Wrapf(err, "error cancelling test patch %s", testPatch.Id.Hex())
+		}
+		return false, errors.WithStack(queue.SetTestPatch(projectRef.Identifier, item.PatchId, ""))
+	}
+	if testPatch.Status != evergreen.PatchSucceeded {
+		if time.Since(testPatch.CreateTime) < TestTimeout {
+			return false, nil
+		}
+		if err = proc.CancelTest(testPatch); err != nil {
+			return false, errors.Wrapf(err, "error cancelling test patch %s", testPatch.Id.Hex())
+		}
+		return proc.eject(projectRef, item, p, fmt.Sprintf("did not finish its tests in patch %s within %s",
+			testPatch.Id.Hex(), TestTimeout))
+	}
+
+	if err = proc.Merge(projectRef, p, testPatch); err != nil {
+		return proc.eject(projectRef, item, p, fmt.Sprintf("passed its tests but could not be merged: %v", err))
+	}
+	return proc.leave(projectRef, item, p, "was merged")
+}
+
+// eject removes the item from the queue without merging it.
+func (proc *Processor) eject(projectRef *model.ProjectRef, item *queue.Item, p *patch.Patch,
+	reason string) (bool, error) {
+	grip.Infof("Ejecting patch %s from the commit queue of project '%s': %s", item.PatchId,
+		projectRef.Identifier, reason)
+	return proc.leave(projectRef, item, p, "was removed from the commit queue because it "+reason)
+}
+
+// leave removes the item from the queue and tells the user who queued it.
+func (proc *Processor) leave(projectRef *model.ProjectRef, item *queue.Item, p *patch.Patch,
+	message string) (bool, error) {
+	if _, err := queue.Remove(projectRef.Identifier, item.PatchId); err != nil {
+		return false, errors.WithStack(err)
+	}
+	if err := proc.Notify(item, p, message); err != nil {
+		grip.Errorf("Error notifying %s that patch %s %s: %+v", item.EnqueuedBy, item.PatchId,
+			message, err)
+	}
+	return true, nil
+}
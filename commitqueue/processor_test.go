@@ -0,0 +1,206 @@
+package commitqueue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	queue "github.com/evergreen-ci/evergreen/model/commitqueue"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/testutil"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func init() {
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testutil.TestConfig()))
+}
+
+func TestProcess(t *testing.T) {
+	Convey("With a commit queue of two patches", t, func() {
+		testutil.HandleTestingErr(db.ClearCollections(queue.Collection, patch.Collection,
+			model.ProjectRefCollection), t, "error clearing collections")
+
+		projectRef := &model.ProjectRef{
+			Identifier:         "proj",
+			Owner:              "evergreen-ci",
+			Repo:               "evergreen",
+			Branch:             "master",
+			RepoKind:           "github",
+			CommitQueueEnabled: true,
+		}
+		So(projectRef.Insert(), ShouldBeNil)
+
+		first := &patch.Patch{Id: bson.NewObjectId(), Project: "proj", Description: "first"}
+		second := &patch.Patch{Id: bson.NewObjectId(), Project: "proj", Description: "second"}
+		So(first.Insert(), ShouldBeNil)
+		So(second.Insert(), ShouldBeNil)
+		for _, p := range []*patch.Patch{first, second} {
+			_, err := queue.Enqueue("proj", queue.Item{
+				PatchId:     p.Id.Hex(),
+				EnqueuedBy:  "me",
+				EnqueueTime: time.Now(),
+			})
+			So(err, ShouldBeNil)
+		}
+
+		head := "aaaaaa"
+		started := []string{}
+		merged := []string{}
+		cancelled := []string{}
+		notified := map[string]string{}
+		var mergeErr error
+		processor := &Processor{
+			StartTest: func(_ *model.ProjectRef, p *patch.Patch) (*patch.Patch, error) {
+				started = append(started, p.Id.Hex())
+				testPatch := &patch.Patch{
+					Id:         bson.NewObjectId(),
+					Project:    p.Project,
+					Githash:    head,
+					CreateTime: time.Now(),
+					Status:     evergreen.PatchStarted,
+				}
+				return testPatch, testPatch.Insert()
+			},
+			BranchHead: func(_ *model.ProjectRef) (string, error) {
+				return head, nil
+			},
+			Merge: func(_ *model.ProjectRef, p, _ *patch.Patch) error {
+				if mergeErr != nil {
+					return mergeErr
+				}
+				merged = append(merged, p.Id.Hex())
+				head = "merged-" + p.Id.Hex()
+				return nil
+			},
+			Notify: func(item *queue.Item, _ *patch.Patch, message string) error {
+				notified[item.PatchId] = message
+				return nil
+			},
+			CancelTest: func(testPatch *patch.Patch) error {
+				cancelled = append(cancelled, testPatch.Id.Hex())
+				return nil
+			},
+		}
+
+		finishTest := func(status string) {
+			cq, err := queue.FindOne(queue.ById("proj"))
+			So(err, ShouldBeNil)
+			So(cq.Head().TestPatchId, ShouldNotEqual, "")
+			So(patch.UpdateOne(bson.M{patch.IdKey: patch.NewId(cq.Head().TestPatchId)},
+				bson.M{"$set": bson.M{patch.StatusKey: status}}), ShouldBeNil)
+		}
+		queued := func() []string {
+			cq, err := queue.FindOne(queue.ById("proj"))
+			So(err, ShouldBeNil)
+			ids := []string{}
+			for _, item := range cq.Queue {
+				ids = append(ids, item.PatchId)
+			}
+			return ids
+		}
+
+		Convey("only the head of the queue should be tested", func() {
+			So(processor.Process(), ShouldBeNil)
+			So(started, ShouldResemble, []string{first.Id.Hex()})
+
+			Convey("and nothing should happen while its tests run", func() {
+				So(processor.Process(), ShouldBeNil)
+				So(len(started), ShouldEqual, 1)
+				So(len(merged), ShouldEqual, 0)
+				So(queued(), ShouldResemble, []string{first.Id.Hex(), second.Id.Hex()})
+			})
+
+			Convey("a patch that passes should be merged, and the next patch tested on top of it", func() {
+				finishTest(evergreen.PatchSucceeded)
+				So(processor.Process(), ShouldBeNil)
+				So(merged, ShouldResemble, []string{first.Id.Hex()})
+				So(notified[first.Id.Hex()], ShouldEqual, "was merged")
+				So(started, ShouldResemble, []string{first.Id.Hex(), second.Id.Hex()})
+				So(queued(), ShouldResemble, []string{second.Id.Hex()})
+
+				testPatch, err := patch.FindOne(patch.ByProject("proj").
+					WithFields(patch.GithashKey).Sort([]string{"-" + patch.IdKey}).Limit(1))
+				So(err, ShouldBeNil)
+				So(testPatch.Githash, ShouldEqual, "merged-"+first.Id.Hex())
+			})
+
+			Convey("a patch that fails should be ejected", func() {
+				finishTest(evergreen.PatchFailed)
+				So(processor.Process(), ShouldBeNil)
+				So(len(merged), ShouldEqual, 0)
+				So(notified[first.Id.Hex()], ShouldStartWith, "was removed from the commit queue")
+				So(queued(), ShouldResemble, []string{second.Id.Hex()})
+			})
+
+			Convey("a patch whose tests do not finish in time should be ejected", func() {
+				cq, err := queue.FindOne(queue.ById("proj"))
+				So(err, ShouldBeNil)
+				testPatchId := cq.Head().TestPatchId
+				So(patch.UpdateOne(bson.M{patch.IdKey: patch.NewId(testPatchId)},
+					bson.M{"$set": bson.M{patch.CreateTimeKey: time.Now().Add(-TestTimeout)}}), ShouldBeNil)
+
+				So(processor.Process(), ShouldBeNil)
+				So(cancelled, ShouldResemble, []string{testPatchId})
+				So(len(merged), ShouldEqual, 0)
+				So(notified[first.Id.Hex()], ShouldContainSubstring, "did not finish its tests")
+				So(started, ShouldResemble, []string{first.Id.Hex(), second.Id.Hex()})
+				So(queued(), ShouldResemble, []string{second.Id.Hex()})
+			})
+
+			Convey("a patch that passes should be tested again if the branch moved", func() {
+				finishTest(evergreen.PatchSucceeded)
+				head = "bbbbbb"
+				So(processor.Process(), ShouldBeNil)
+				So(len(merged), ShouldEqual, 0)
+				So(queued(), ShouldResemble, []string{first.Id.Hex(), second.Id.Hex()})
+
+				So(processor.Process(), ShouldBeNil)
+				So(started, ShouldResemble, []string{first.Id.Hex(), first.Id.Hex()})
+			})
+
+			Convey("a running test should be cancelled once the branch moves", func() {
+				cq, err := queue.FindOne(queue.ById("proj"))
+				So(err, ShouldBeNil)
+				testPatchId := cq.Head().TestPatchId
+
+				head = "bbbbbb"
+				So(processor.Process(), ShouldBeNil)
+				So(cancelled, ShouldResemble, []string{testPatchId})
+				So(len(merged), ShouldEqual, 0)
+
+				So(processor.Process(), ShouldBeNil)
+				So(started, ShouldResemble, []string{first.Id.Hex(), first.Id.Hex()})
+			})
+
+			Convey("a patch that passes but cannot be merged should be ejected", func() {
+				finishTest(evergreen.PatchSucceeded)
+				mergeErr = errors.New("merge conflict")
+				So(processor.Process(), ShouldBeNil)
+				So(notified[first.Id.Hex()], ShouldContainSubstring, "merge conflict")
+				So(started, ShouldResemble, []string{first.Id.Hex(), second.Id.Hex()})
+			})
+		})
+
+		Convey("a patch that cannot be tested should be ejected", func() {
+			processor.StartTest = func(_ *model.ProjectRef, _ *patch.Patch) (*patch.Patch, error) {
+				return nil, errors.New("invalid config")
+			}
+			So(processor.Process(), ShouldBeNil)
+			So(notified[first.Id.Hex()], ShouldContainSubstring, "invalid config")
+			So(notified[second.Id.Hex()], ShouldContainSubstring, "invalid config")
+			So(len(queued()), ShouldEqual, 0)
+		})
+
+		Convey("patches should be ejected once the commit queue is disabled", func() {
+			projectRef.CommitQueueEnabled = false
+			So(projectRef.Upsert(), ShouldBeNil)
+			So(processor.Process(), ShouldBeNil)
+			So(len(started), ShouldEqual, 0)
+			So(len(queued()), ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,95 @@
+package commitqueue
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	queue "github.com/evergreen-ci/evergreen/model/commitqueue"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/notify"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// Runner tests and merges the patches in commit queues.
+type Runner struct{}
+
+const (
+	RunnerName  = "commitqueue"
+	Description = "test and merge the patches in commit queues"
+)
+
+func (r *Runner) Name() string {
+	return RunnerName
+}
+
+func (r *Runner) Description() string {
+	return Description
+}
+
+func (r *Runner) Run(config *evergreen.Settings) error {
+	startTime := time.Now()
+	grip.Infoln("Starting commit queue processor at time", startTime)
+
+	processor := &Processor{
+		StartTest: func(projectRef *model.ProjectRef, p *patch.Patch) (*patch.Patch, error) {
+			return startTestPatch(config, projectRef, p)
+		},
+		BranchHead: func(projectRef *model.ProjectRef) (string, error) {
+			return branchHead(config, projectRef)
+		},
+		Merge: func(projectRef *model.ProjectRef, p, testPatch *patch.Patch) error {
+			return mergePatch(config, projectRef, p, testPatch)
+		},
+		CancelTest: func(testPatch *patch.Patch) error {
+			return model.CancelPatch(testPatch, RunnerName)
+		},
+		Notify: func(item *queue.Item, p *patch.Patch, message string) error {
+			return notifyLeft(config, item, p, message)
+		},
+	}
+
+	if err := processor.Process(); err != nil {
+		err = errors.Wrap(err, "error processing commit queues")
+		grip.Error(err)
+		return err
+	}
+
+	runtime := time.Since(startTime)
+	if err := model.SetProcessRuntimeCompleted(RunnerName, runtime); err != nil {
+		grip.Errorf("error updating process status: %+v", err)
+	}
+	grip.Infof("Commit queue processor took %s to run", runtime)
+	return nil
+}
+
+// notifyLeft emails the user who queued the patch, and the patch's author if
+// someone else queued it, that it left the queue. If the patch tests a pull
+// request, the message is also commented on it.
+func notifyLeft(settings *evergreen.Settings, item *queue.Item, p *patch.Patch, message string) error {
+	subject := fmt.Sprintf("Patch %s %s", item.PatchId, message)
+	body := fmt.Sprintf("Patch %s/patch/%s %s.", settings.Ui.Url, item.PatchId, message)
+	if p != nil && p.Description != "" {
+		subject = fmt.Sprintf("Patch '%s' %s", p.Description, message)
+	}
+
+	recipients := []string{item.EnqueuedBy}
+	if p != nil && p.Author != "" && p.Author != item.EnqueuedBy {
+		recipients = append(recipients, p.Author)
+	}
+
+	catcher := grip.NewCatcher()
+	for _, recipient := range recipients {
+		catcher.Add(notify.TrySendNotificationToUser(recipient, subject, body,
+			notify.ConstructMailer(settings.Notify)))
+	}
+	if p != nil && p.GithubPatchData != nil {
+		data := p.GithubPatchData
+		catcher.Add(thirdparty.CommentOnGithubIssue(settings.Credentials["github"], data.BaseOwner,
+			data.BaseRepo, data.PRNumber, fmt.Sprintf("This pull request %s.", message)))
+	}
+	return catcher.Resolve()
+}
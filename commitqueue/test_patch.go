@@ -0,0 +1,89 @@
+package commitqueue
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/evergreen-ci/evergreen/validator"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/yaml.v2"
+)
+
+// branchHead returns the revision that the project's branch is at.
+func branchHead(settings *evergreen.Settings, projectRef *model.ProjectRef) (string, error) {
+	branch, err := thirdparty.GetBranchEvent(settings.Credentials["github"], projectRef.Owner,
+		projectRef.Repo, projectRef.Branch)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return branch.Commit.SHA, nil
+}
+
+// startTestPatch creates and finalizes a copy of the patch based on the
+// head of the project's branch, which runs the same variants and tasks.
+func startTestPatch(settings *evergreen.Settings, projectRef *model.ProjectRef,
+	p *patch.Patch) (*patch.Patch, error) {
+	head, err := branchHead(settings, projectRef)
+	if err != nil {
+		return nil, errors.Wrap(err, "error finding branch head")
+	}
+	if err = p.FetchPatchFiles(); err != nil {
+		return nil, errors.Wrap(err, "error fetching patch files")
+	}
+
+	testPatch := &patch.Patch{
+		Id:            bson.NewObjectId(),
+		Description:   fmt.Sprintf("Commit queue test of patch %s: %s", p.Id.Hex(), p.Description),
+		Author:        p.Author,
+		Project:       p.Project,
+		Githash:       head,
+		CreateTime:    time.Now(),
+		Status:        evergreen.PatchCreated,
+		BuildVariants: p.BuildVariants,
+		Tasks:         p.Tasks,
+		VariantsTasks: p.VariantsTasks,
+		Patches:       append([]patch.ModulePatch{}, p.Patches...),
+	}
+	for i := range testPatch.Patches {
+		// modules stay at the revisions the patch was made against
+		if testPatch.Patches[i].ModuleName == "" {
+			testPatch.Patches[i].Githash = head
+		}
+	}
+
+	project, err := validator.GetPatchedProject(testPatch, settings)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid patched config")
+	}
+	projectYamlBytes, err := yaml.Marshal(project)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshaling patched config")
+	}
+	testPatch.PatchedConfig = string(projectYamlBytes)
+
+	// test patches are numbered like the patch they test; pull requests have
+	// no user to count their patches
+	if p.Author == evergreen.GithubPatchUser {
+		testPatch.PatchNumber, err = patch.GetNewGithubPatchNumber()
+	} else {
+		testPatch.PatchNumber, err = (&user.DBUser{Id: p.Author}).IncPatchNumber()
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error computing patch num")
+	}
+	testPatch.ClearPatchData()
+
+	if err = testPatch.Insert(); err != nil {
+		return nil, errors.Wrap(err, "error inserting test patch")
+	}
+	if _, err = model.FinalizePatch(testPatch, settings); err != nil {
+		return nil, errors.Wrap(err, "error finalizing test patch")
+	}
+	return testPatch, nil
+}
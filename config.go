@@ -67,6 +67,16 @@ type RepoTrackerConfig struct {
 	NumNewRepoRevisionsToFetch int
 	MaxRepoRevisionsToSearch   int
 	LogFile                    string
+
+	// GithubWebhookSecret is the secret GitHub signs push webhooks with. If
+	// it is set, pushes are tracked as they are received and the repotracker
+	// only polls every PollIntervalSeconds to catch up on missed pushes.
+	GithubWebhookSecret string `yaml:"github_webhook_secret"`
+	PollIntervalSeconds int    `yaml:"poll_interval_seconds"`
+
+	// MirrorDirectory is where the repotracker keeps its mirror clones of
+	// projects whose repositories are not on GitHub.
+	MirrorDirectory string `yaml:"mirror_directory"`
 }
 
 type ClientBinary struct {
@@ -156,6 +166,62 @@ type TaskRunnerConfig struct {
 	LogFile string
 }
 
+// LogStorageConfig holds settings for moving the logs of finished tasks out
+// of the database. Logs stay in the database when no backend is set.
+type LogStorageConfig struct {
+	// Backend is either "s3" or "filesystem".
+	Backend string `yaml:"backend"`
+	// ArchiveAfterHours is how long after a task finishes its logs are moved.
+	ArchiveAfterHours int `yaml:"archive_after_hours"`
+	// Path is the root directory of the filesystem backend.
+	Path string `yaml:"path"`
+	// Bucket and Prefix locate the logs in the s3 backend. Endpoint may be
+	// set to use an S3-compatible server instead of the AWS Region.
+	Bucket   string `yaml:"bucket"`
+	Prefix   string `yaml:"prefix"`
+	Region   string `yaml:"region"`
+	Endpoint string `yaml:"endpoint"`
+}
+
+// LogSearchConfig holds settings for indexing the logs of finished tasks
+// for search. Logs are not indexed when RetentionDays is 0.
+type LogSearchConfig struct {
+	// RetentionDays is how long after a task finishes its logs can be searched.
+	RetentionDays int `yaml:"retention_days"`
+}
+
+// RetentionPolicy sets how many days after a task finishes its task logs,
+// test logs, events and artifact metadata are kept. Zero keeps them forever.
+type RetentionPolicy struct {
+	MainlineDays int `yaml:"mainline_days" bson:"mainline_days" json:"mainline_days"`
+	PatchDays    int `yaml:"patch_days" bson:"patch_days" json:"patch_days"`
+}
+
+// RetentionConfig holds the retention policy of projects that do not set
+// their own, and settings for the process that enforces the policies.
+type RetentionConfig struct {
+	Default RetentionPolicy `yaml:"default"`
+	// EventLogDays is how long host, distro and scheduler events are kept.
+	EventLogDays int `yaml:"event_log_days"`
+	// BatchSize is the most tasks of each project and requester whose data is
+	// deleted in one run.
+	BatchSize int `yaml:"batch_size"`
+	// DryRun reports what would be deleted without deleting it.
+	DryRun bool `yaml:"dry_run"`
+}
+
+// ArtifactConfig holds settings for deleting expired artifacts from s3.
+type ArtifactConfig struct {
+	// ExpirationDays overrides how many days after its task started a file
+	// of each expiration class is kept. Zero keeps the files forever.
+	ExpirationDays map[string]int `yaml:"expiration_days"`
+	// BatchSize is the most artifact entries whose files are deleted in one run.
+	BatchSize int `yaml:"batch_size"`
+	// Buckets are the s3 buckets expired files may be deleted from. Files
+	// in any other bucket are never deleted.
+	Buckets []string `yaml:"buckets"`
+}
+
 // CloudProviders stores configuration settings for the supported cloud host providers.
 type CloudProviders struct {
 	AWS          AWSConfig          `yaml:"aws"`
@@ -229,6 +295,10 @@ type Settings struct {
 	Scheduler           SchedulerConfig   `yaml:"scheduler"`
 	TaskRunner          TaskRunnerConfig  `yaml:"taskrunner"`
 	Expansions          map[string]string `yaml:"expansions"`
+	LogStorage          LogStorageConfig  `yaml:"log_storage"`
+	LogSearch           LogSearchConfig   `yaml:"log_search"`
+	Retention           RetentionConfig   `yaml:"retention"`
+	Artifacts           ArtifactConfig    `yaml:"artifacts"`
 	Plugins             PluginConfig      `yaml:"plugins"`
 	IsProd              bool              `yaml:"isprod"`
 }
@@ -376,4 +446,49 @@ var ConfigValidationRules = []ConfigValidator{
 		}
 		return nil
 	},
+
+	func(settings *Settings) error {
+		logStorage := settings.LogStorage
+		switch logStorage.Backend {
+		case "":
+			return nil
+		case LogStorageFilesystem:
+			if logStorage.Path == "" {
+				return errors.New("You must specify a path for filesystem log storage")
+			}
+		case LogStorageS3:
+			if logStorage.Bucket == "" {
+				return errors.New("You must specify a bucket for s3 log storage")
+			}
+		default:
+			return errors.Errorf("Unknown log storage backend '%s'", logStorage.Backend)
+		}
+		if logStorage.ArchiveAfterHours < 0 {
+			return errors.New("archive_after_hours must not be negative")
+		}
+		return nil
+	},
+
+	func(settings *Settings) error {
+		retention := settings.Retention
+		if retention.Default.MainlineDays < 0 || retention.Default.PatchDays < 0 || retention.EventLogDays < 0 {
+			return errors.New("Retention days must not be negative")
+		}
+		if retention.BatchSize < 0 {
+			return errors.New("Retention batch size must not be negative")
+		}
+		return nil
+	},
+
+	func(settings *Settings) error {
+		for class, days := range settings.Artifacts.ExpirationDays {
+			if days < 0 {
+				return errors.Errorf("Expiration days of artifact class '%v' must not be negative", class)
+			}
+		}
+		if settings.Artifacts.BatchSize < 0 {
+			return errors.New("Artifact batch size must not be negative")
+		}
+		return nil
+	},
 }
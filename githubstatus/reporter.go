@@ -0,0 +1,117 @@
+// Package githubstatus posts the results of GitHub pull request patches
+// back to their pull requests as commit statuses.
+package githubstatus
+
+import (
+	"fmt"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// Context is the context of the status of a whole patch. The status of each
+// of its variants has the context Context/<variant>.
+const Context = "evergreen"
+
+// Reporter posts the statuses of pull request patches that have changed
+// since they were last posted.
+type Reporter struct {
+	// Post posts a commit status to a revision of a GitHub repository.
+	Post func(owner, repo, revision string, status *thirdparty.GithubStatus) error
+	// UIURL is the root of the links from statuses to their results.
+	UIURL string
+}
+
+// Report posts the changed statuses of every patch whose final statuses
+// have not been posted yet.
+func (r *Reporter) Report() error {
+	patches, err := patch.Find(patch.WithUnfinishedGithubStatuses())
+	if err != nil {
+		return errors.Wrap(err, "error finding pull request patches")
+	}
+
+	catcher := grip.NewCatcher()
+	for i := range patches {
+		catcher.Add(errors.Wrapf(r.reportPatch(&patches[i]),
+			"error reporting statuses of patch %s", patches[i].Id.Hex()))
+	}
+	return catcher.Resolve()
+}
+
+// reportPatch posts the patch's statuses that differ from those last
+// posted, and records the statuses that were posted.
+func (r *Reporter) reportPatch(p *patch.Patch) error {
+	builds, err := build.Find(build.ByVersion(p.Version))
+	if err != nil {
+		return errors.Wrap(err, "error finding builds")
+	}
+
+	data := p.GithubPatchData
+	posted := map[string]string{}
+	for context, state := range data.Statuses {
+		posted[context] = state
+	}
+	changed := false
+	catcher := grip.NewCatcher()
+	for _, status := range patchStatuses(p, builds, r.UIURL) {
+		if posted[status.Context] == status.State {
+			continue
+		}
+		if err = r.Post(data.BaseOwner, data.BaseRepo, data.HeadHash, &status); err != nil {
+			catcher.Add(errors.Wrapf(err, "error posting status '%s'", status.Context))
+			continue
+		}
+		posted[status.Context] = status.State
+		changed = true
+	}
+
+	// statuses that failed to post are tried again on the next run
+	final := isFinished(p) && !catcher.HasErrors()
+	if changed || final {
+		catcher.Add(p.SetGithubStatuses(posted, final))
+	}
+	return catcher.Resolve()
+}
+
+func isFinished(p *patch.Patch) bool {
+	return p.Status == evergreen.PatchSucceeded || p.Status == evergreen.PatchFailed
+}
+
+// patchStatuses returns the current status of the patch as a whole and of
+// each of its builds.
+func patchStatuses(p *patch.Patch, builds []build.Build, uiURL string) []thirdparty.GithubStatus {
+	state, description := thirdparty.GithubStatusPending, "patch is running"
+	switch p.Status {
+	case evergreen.PatchSucceeded:
+		state, description = thirdparty.GithubStatusSuccess, "patch succeeded"
+	case evergreen.PatchFailed:
+		state, description = thirdparty.GithubStatusFailure, "patch failed"
+	}
+	statuses := []thirdparty.GithubStatus{{
+		State:       state,
+		TargetUrl:   fmt.Sprintf("%s/version/%s", uiURL, p.Version),
+		Description: description,
+		Context:     Context,
+	}}
+
+	for _, b := range builds {
+		state, description = thirdparty.GithubStatusPending, "build is running"
+		switch b.Status {
+		case evergreen.BuildSucceeded:
+			state, description = thirdparty.GithubStatusSuccess, "build succeeded"
+		case evergreen.BuildFailed:
+			state, description = thirdparty.GithubStatusFailure, "build failed"
+		}
+		statuses = append(statuses, thirdparty.GithubStatus{
+			State:       state,
+			TargetUrl:   fmt.Sprintf("%s/build/%s", uiURL, b.Id),
+			Description: description,
+			Context:     Context + "/" + b.BuildVariant,
+		})
+	}
+	return statuses
+}
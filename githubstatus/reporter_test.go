@@ -0,0 +1,105 @@
+package githubstatus
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/testutil"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func init() {
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testutil.TestConfig()))
+}
+
+func TestReport(t *testing.T) {
+	Convey("With a running pull request patch", t, func() {
+		testutil.HandleTestingErr(db.ClearCollections(patch.Collection, build.Collection), t,
+			"error clearing collections")
+
+		p := &patch.Patch{
+			Id:        bson.NewObjectId(),
+			Version:   "v1",
+			Status:    evergreen.PatchStarted,
+			Activated: true,
+			GithubPatchData: &patch.GithubPatch{
+				PRNumber:  12,
+				BaseOwner: "evergreen-ci",
+				BaseRepo:  "evergreen",
+				HeadHash:  "abcdef",
+			},
+		}
+		So(p.Insert(), ShouldBeNil)
+		linux := &build.Build{Id: "b1", Version: "v1", BuildVariant: "linux", Status: evergreen.BuildStarted}
+		So(linux.Insert(), ShouldBeNil)
+
+		posted := []thirdparty.GithubStatus{}
+		failPosts := false
+		reporter := &Reporter{
+			Post: func(owner, repo, revision string, status *thirdparty.GithubStatus) error {
+				So(owner, ShouldEqual, "evergreen-ci")
+				So(repo, ShouldEqual, "evergreen")
+				So(revision, ShouldEqual, "abcdef")
+				if failPosts {
+					return errors.New("github is unavailable")
+				}
+				posted = append(posted, *status)
+				return nil
+			},
+			UIURL: "https://evergreen.example.com",
+		}
+
+		Convey("pending statuses should be posted once", func() {
+			So(reporter.Report(), ShouldBeNil)
+			So(len(posted), ShouldEqual, 2)
+			So(posted[0].Context, ShouldEqual, "evergreen")
+			So(posted[0].State, ShouldEqual, thirdparty.GithubStatusPending)
+			So(posted[0].TargetUrl, ShouldEqual, "https://evergreen.example.com/version/v1")
+			So(posted[1].Context, ShouldEqual, "evergreen/linux")
+			So(posted[1].TargetUrl, ShouldEqual, "https://evergreen.example.com/build/b1")
+
+			So(reporter.Report(), ShouldBeNil)
+			So(len(posted), ShouldEqual, 2)
+
+			Convey("and final statuses posted when the patch finishes", func() {
+				So(build.UpdateOne(bson.M{build.IdKey: "b1"},
+					bson.M{"$set": bson.M{build.StatusKey: evergreen.BuildFailed}}), ShouldBeNil)
+				So(patch.UpdateOne(bson.M{patch.IdKey: p.Id},
+					bson.M{"$set": bson.M{patch.StatusKey: evergreen.PatchFailed}}), ShouldBeNil)
+
+				So(reporter.Report(), ShouldBeNil)
+				So(len(posted), ShouldEqual, 4)
+				So(posted[2].State, ShouldEqual, thirdparty.GithubStatusFailure)
+				So(posted[3].State, ShouldEqual, thirdparty.GithubStatusFailure)
+
+				dbPatch, err := patch.FindOne(patch.ById(p.Id))
+				So(err, ShouldBeNil)
+				So(dbPatch.GithubPatchData.StatusesFinal, ShouldBeTrue)
+
+				So(reporter.Report(), ShouldBeNil)
+				So(len(posted), ShouldEqual, 4)
+			})
+		})
+
+		Convey("statuses that fail to post should be tried again", func() {
+			So(patch.UpdateOne(bson.M{patch.IdKey: p.Id},
+				bson.M{"$set": bson.M{patch.StatusKey: evergreen.PatchSucceeded}}), ShouldBeNil)
+			failPosts = true
+			So(reporter.Report(), ShouldNotBeNil)
+
+			dbPatch, err := patch.FindOne(patch.ById(p.Id))
+			So(err, ShouldBeNil)
+			So(dbPatch.GithubPatchData.StatusesFinal, ShouldBeFalse)
+
+			failPosts = false
+			So(reporter.Report(), ShouldBeNil)
+			So(len(posted), ShouldEqual, 2)
+		})
+	})
+}
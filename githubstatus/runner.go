@@ -0,0 +1,53 @@
+package githubstatus
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// Runner posts the results of pull request patches to GitHub.
+type Runner struct{}
+
+const (
+	RunnerName  = "githubstatus"
+	Description = "post pull request patch results as github commit statuses"
+)
+
+func (r *Runner) Name() string {
+	return RunnerName
+}
+
+func (r *Runner) Description() string {
+	return Description
+}
+
+func (r *Runner) Run(config *evergreen.Settings) error {
+	startTime := time.Now()
+	grip.Infoln("Starting github status reporter at time", startTime)
+
+	oauthToken := config.Credentials["github"]
+	reporter := &Reporter{
+		Post: func(owner, repo, revision string, status *thirdparty.GithubStatus) error {
+			return thirdparty.SetGithubCommitStatus(oauthToken, owner, repo, revision, status)
+		},
+		UIURL: config.Ui.Url,
+	}
+
+	if err := reporter.Report(); err != nil {
+		err = errors.Wrap(err, "error reporting github statuses")
+		grip.Error(err)
+		return err
+	}
+
+	runtime := time.Since(startTime)
+	if err := model.SetProcessRuntimeCompleted(RunnerName, runtime); err != nil {
+		grip.Errorf("error updating process status: %+v", err)
+	}
+	grip.Infof("Github status reporter took %s to run", runtime)
+	return nil
+}
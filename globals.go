@@ -12,6 +12,9 @@ import (
 const (
 	User = "mci"
 
+	// GithubPatchUser is the author of patches made from GitHub pull requests
+	GithubPatchUser = "github_pull_request"
+
 	HostRunning         = "running"
 	HostTerminated      = "terminated"
 	HostUninitialized   = "starting"
@@ -93,6 +96,12 @@ const (
 	HostSecretHeader = "Host-Secret"
 )
 
+// log storage backends
+const (
+	LogStorageFilesystem = "filesystem"
+	LogStorageS3         = "s3"
+)
+
 // HTTP constants. Added after Go1.4. Here for compatibility with GCCGO
 // compatibility. Copied from: https://golang.org/pkg/net/http/#pkg-constants
 const (
@@ -127,6 +136,9 @@ var (
 	// version requester types
 	PatchVersionRequester       = "patch_request"
 	RepotrackerVersionRequester = "gitter_request"
+	TriggerRequester            = "trigger_request"
+	PeriodicBuildRequester      = "periodic_build_request"
+	GitTagRequester             = "git_tag_request"
 
 	// constant arrays for db update logic
 	AbortableStatuses = []string{TaskStarted, TaskDispatched}
@@ -0,0 +1,174 @@
+// Package logsearch indexes the task and test logs of recently finished tasks
+// so that they can be searched by project for a line of text.
+package logsearch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/db/bsonutil"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	Collection = "log_search"
+
+	// MaxLineLength is the most characters of a line that are indexed.
+	MaxLineLength = 1000
+
+	insertBatchSize = 1000
+)
+
+// LogLine is an indexed line of a task's log or of one of its test logs.
+type LogLine struct {
+	Id           bson.ObjectId `bson:"_id,omitempty" json:"-"`
+	Project      string        `bson:"project" json:"project"`
+	BuildVariant string        `bson:"build_variant" json:"build_variant"`
+	TaskName     string        `bson:"task_name" json:"task_name"`
+	TaskId       string        `bson:"task_id" json:"task_id"`
+	Execution    int           `bson:"execution" json:"execution"`
+	TestLogId    string        `bson:"test_log_id,omitempty" json:"test_log_id,omitempty"`
+	TestName     string        `bson:"test_name,omitempty" json:"test_name,omitempty"`
+	LineNum      int           `bson:"line" json:"line"`
+	Severity     string        `bson:"severity,omitempty" json:"severity,omitempty"`
+	Text         string        `bson:"text" json:"text"`
+	Timestamp    time.Time     `bson:"ts" json:"ts"`
+
+	// Finished is when the task finished, which starts the retention window
+	Finished time.Time `bson:"finished" json:"-"`
+}
+
+var (
+	ProjectKey      = bsonutil.MustHaveTag(LogLine{}, "Project")
+	BuildVariantKey = bsonutil.MustHaveTag(LogLine{}, "BuildVariant")
+	TaskNameKey     = bsonutil.MustHaveTag(LogLine{}, "TaskName")
+	TaskIdKey       = bsonutil.MustHaveTag(LogLine{}, "TaskId")
+	ExecutionKey    = bsonutil.MustHaveTag(LogLine{}, "Execution")
+	SeverityKey     = bsonutil.MustHaveTag(LogLine{}, "Severity")
+	TextKey         = bsonutil.MustHaveTag(LogLine{}, "Text")
+	TimestampKey    = bsonutil.MustHaveTag(LogLine{}, "Timestamp")
+	FinishedKey     = bsonutil.MustHaveTag(LogLine{}, "Finished")
+)
+
+// URL returns the path to the line in the UI's view of its log.
+func (l *LogLine) URL() string {
+	if l.TestLogId != "" {
+		return fmt.Sprintf("/test_log/%s#L%d", l.TestLogId, l.LineNum)
+	}
+	// only task output is indexed, so lines are numbered in that log
+	return fmt.Sprintf("/task_log_raw/%s/%d?type=%s#L%d", l.TaskId, l.Execution, model.TaskLogPrefix, l.LineNum)
+}
+
+// EnsureIndexes creates the text index used for searches, and the index that
+// expires lines once their task finished longer than retention ago.
+func EnsureIndexes(retention time.Duration) error {
+	err := db.EnsureIndex(Collection, mgo.Index{
+		Key: []string{ProjectKey, "$text:" + TextKey},
+		// log lines are not prose, so words are neither stemmed nor dropped
+		DefaultLanguage: "none",
+	})
+	if err != nil {
+		return errors.Wrap(err, "problem creating text index")
+	}
+
+	ttl := mgo.Index{Key: []string{FinishedKey}, ExpireAfter: retention}
+	if err = db.EnsureIndex(Collection, ttl); err != nil {
+		// the retention changed, so the old index has to be replaced
+		if err = db.DropIndex(Collection, FinishedKey); err != nil {
+			return errors.Wrap(err, "problem dropping retention index")
+		}
+		if err = db.EnsureIndex(Collection, ttl); err != nil {
+			return errors.Wrap(err, "problem creating retention index")
+		}
+	}
+	return nil
+}
+
+// InsertLines stores the lines.
+func InsertLines(lines []LogLine) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	session, database, err := db.GetGlobalSessionFactory().GetSession()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer session.Close()
+
+	for start := 0; start < len(lines); start += insertBatchSize {
+		end := start + insertBatchSize
+		if end > len(lines) {
+			end = len(lines)
+		}
+		docs := make([]interface{}, 0, end-start)
+		for _, line := range lines[start:end] {
+			docs = append(docs, line)
+		}
+		if err = database.C(Collection).Insert(docs...); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// RemoveTaskLines deletes the indexed lines of a task execution.
+func RemoveTaskLines(taskId string, execution int) error {
+	return errors.WithStack(db.RemoveAll(Collection, bson.M{
+		TaskIdKey:    taskId,
+		ExecutionKey: execution,
+	}))
+}
+
+// SearchOptions restricts a search to the lines of one project's logs that
+// contain Text. Empty options do not restrict the search.
+type SearchOptions struct {
+	Project      string
+	Text         string
+	BuildVariant string
+	TaskName     string
+	Severities   []string
+	Since        time.Time
+	Until        time.Time
+	Limit        int
+}
+
+// Search returns the lines that match the options, newest first.
+func Search(opts SearchOptions) ([]LogLine, error) {
+	if opts.Project == "" || opts.Text == "" {
+		return nil, errors.New("a search needs a project and text")
+	}
+
+	// quoting the text makes it a phrase, which matches it case-insensitively
+	query := bson.M{
+		ProjectKey: opts.Project,
+		"$text":    bson.M{"$search": "\"" + strings.Replace(opts.Text, "\"", " ", -1) + "\""},
+	}
+	if opts.BuildVariant != "" {
+		query[BuildVariantKey] = opts.BuildVariant
+	}
+	if opts.TaskName != "" {
+		query[TaskNameKey] = opts.TaskName
+	}
+	if len(opts.Severities) > 0 {
+		query[SeverityKey] = bson.M{"$in": opts.Severities}
+	}
+	timeRange := bson.M{}
+	if !opts.Since.IsZero() {
+		timeRange["$gte"] = opts.Since
+	}
+	if !opts.Until.IsZero() {
+		timeRange["$lte"] = opts.Until
+	}
+	if len(timeRange) > 0 {
+		query[TimestampKey] = timeRange
+	}
+
+	lines := []LogLine{}
+	err := db.FindAll(Collection, query, db.NoProjection, []string{"-" + TimestampKey}, db.NoSkip, opts.Limit, &lines)
+	return lines, errors.WithStack(err)
+}
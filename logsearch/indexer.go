@@ -0,0 +1,189 @@
+package logsearch
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/logstore"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// IndexBatchSize is the most tasks whose logs are indexed in one run.
+var IndexBatchSize = 200
+
+const (
+	// indexRetryDelay is how long a task whose logs failed to be indexed
+	// waits before it is retried, doubling with each failure up to
+	// maxIndexRetryDelay.
+	indexRetryDelay    = time.Hour
+	maxIndexRetryDelay = 24 * time.Hour
+)
+
+// Runner indexes the logs of recently finished tasks for search.
+type Runner struct{}
+
+const (
+	RunnerName  = "logindexer"
+	Description = "index the logs of recently finished tasks for search"
+)
+
+func (r *Runner) Name() string {
+	return RunnerName
+}
+
+func (r *Runner) Description() string {
+	return Description
+}
+
+func (r *Runner) Run(config *evergreen.Settings) error {
+	if config.LogSearch.RetentionDays <= 0 {
+		return nil
+	}
+
+	startTime := time.Now()
+	grip.Infoln("Starting log indexer at time", startTime)
+
+	if err := indexFinishedTasks(config); err != nil {
+		err = errors.Wrap(err, "error indexing logs")
+		grip.Error(err)
+		return err
+	}
+
+	runtime := time.Since(startTime)
+	if err := model.SetProcessRuntimeCompleted(RunnerName, runtime); err != nil {
+		grip.Errorf("error updating process status: %+v", err)
+	}
+	grip.Infof("Log indexer took %s to run", runtime)
+	return nil
+}
+
+// indexFinishedTasks indexes the logs of tasks that finished within the
+// retention period, oldest first. A task whose logs cannot be indexed is
+// retried after a delay that doubles with each failure, so that it does not
+// hold up the tasks after it.
+func indexFinishedTasks(config *evergreen.Settings) error {
+	retention := time.Duration(config.LogSearch.RetentionDays) * 24 * time.Hour
+	if err := EnsureIndexes(retention); err != nil {
+		return err
+	}
+
+	logs, err := logstore.New(config)
+	if err != nil {
+		return errors.Wrap(err, "error configuring log storage")
+	}
+
+	now := time.Now()
+	tasks, err := task.Find(db.Query(bson.M{
+		task.StatusKey:      bson.M{"$in": evergreen.CompletedStatuses},
+		task.FinishTimeKey:  bson.M{"$gt": now.Add(-retention)},
+		task.LogsIndexedKey: bson.M{"$ne": true},
+		"$or": []bson.M{
+			{task.LogsIndexRetryAtKey: bson.M{"$exists": false}},
+			{task.LogsIndexRetryAtKey: bson.M{"$lte": now}},
+		},
+	}).WithFields(task.IdKey, task.ExecutionKey, task.ProjectKey, task.BuildVariantKey,
+		task.DisplayNameKey, task.FinishTimeKey, task.LogsIndexAttemptsKey).
+		Sort([]string{task.FinishTimeKey}).Limit(IndexBatchSize))
+	if err != nil {
+		return errors.Wrap(err, "error finding finished tasks")
+	}
+
+	indexed := 0
+	for i := range tasks {
+		if err = IndexTask(logs, &tasks[i]); err != nil {
+			grip.Errorf("error indexing logs of task %s: %+v", tasks[i].Id, err)
+			retryAt := now.Add(util.DoublingBackoff(indexRetryDelay, maxIndexRetryDelay, tasks[i].LogsIndexAttempts))
+			if err = tasks[i].MarkLogsIndexFailed(retryAt); err != nil {
+				grip.Errorf("error recording failed indexing of task %s: %+v", tasks[i].Id, err)
+			}
+			continue
+		}
+		indexed++
+	}
+	grip.Infof("indexed logs of %d of %d finished tasks", indexed, len(tasks))
+	return nil
+}
+
+// IndexTask indexes the task output and test logs of a task's latest
+// execution, replacing lines indexed before.
+func IndexTask(logs *logstore.Logs, t *task.Task) error {
+	if err := RemoveTaskLines(t.Id, t.Execution); err != nil {
+		return err
+	}
+
+	newLine := func(lineNum int, text string) LogLine {
+		return LogLine{
+			Project:      t.Project,
+			BuildVariant: t.BuildVariant,
+			TaskName:     t.DisplayName,
+			TaskId:       t.Id,
+			Execution:    t.Execution,
+			LineNum:      lineNum,
+			Text:         truncateLine(text, MaxLineLength),
+			Timestamp:    t.FinishTime,
+			Finished:     t.FinishTime,
+		}
+	}
+
+	// line numbers match the UI's view of the task output, so every message
+	// is counted even though blank ones are not indexed
+	channel, err := logs.GetRawTaskLogChannel(t.Id, t.Execution, nil, []string{model.TaskLogPrefix})
+	if err != nil {
+		return errors.Wrap(err, "problem reading task log")
+	}
+	lines := []LogLine{}
+	lineNum := 0
+	for msg := range channel {
+		if strings.TrimSpace(msg.Message) != "" {
+			line := newLine(lineNum, msg.Message)
+			line.Severity = msg.Severity
+			if !msg.Timestamp.IsZero() {
+				line.Timestamp = msg.Timestamp
+			}
+			lines = append(lines, line)
+		}
+		lineNum++
+	}
+
+	testLogs, err := logs.FindTestLogsByTask(t.Id, t.Execution)
+	if err != nil {
+		return errors.Wrap(err, "problem reading test logs")
+	}
+	for _, testLog := range testLogs {
+		for i, text := range testLog.Lines {
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			line := newLine(i, text)
+			line.TestLogId = testLog.Id
+			line.TestName = testLog.Name
+			lines = append(lines, line)
+		}
+	}
+
+	if err = InsertLines(lines); err != nil {
+		return errors.Wrap(err, "problem storing indexed lines")
+	}
+	return errors.WithStack(t.SetLogsIndexed())
+}
+
+// truncateLine shortens text to at most max bytes without splitting a
+// multi-byte UTF-8 character.
+func truncateLine(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	end := max
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return text[:end]
+}
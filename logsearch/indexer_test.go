@@ -0,0 +1,164 @@
+package logsearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/logstore"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var testConfig = testutil.TestConfig()
+
+func init() {
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testConfig))
+}
+
+func cleanUpLogDB() error {
+	session, _, err := db.GetGlobalSessionFactory().GetSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	_, err = session.DB(model.TaskLogDB).C(model.TaskLogCollection).RemoveAll(bson.M{})
+	return err
+}
+
+func TestIndexAndSearch(t *testing.T) {
+	Convey("With a finished task that logged a failure", t, func() {
+		testutil.HandleTestingErr(db.ClearCollections(task.Collection, model.TestLogCollection, Collection),
+			t, "error clearing collections")
+		testutil.HandleTestingErr(cleanUpLogDB(), t, "error clearing task logs")
+		So(EnsureIndexes(24*time.Hour), ShouldBeNil)
+
+		t1 := &task.Task{
+			Id:           "t1",
+			Project:      "proj",
+			BuildVariant: "linux",
+			DisplayName:  "compile",
+			Status:       evergreen.TaskFailed,
+			FinishTime:   time.Now(),
+		}
+		So(t1.Insert(), ShouldBeNil)
+		for i, text := range []string{"starting", "", "Segmentation fault in worker", "done"} {
+			msg := &model.LogMessage{
+				Type:      model.TaskLogPrefix,
+				Severity:  model.LogErrorPrefix,
+				Message:   text,
+				Timestamp: time.Now().Add(time.Duration(i) * time.Millisecond),
+			}
+			So(msg.Insert("t1", 0), ShouldBeNil)
+		}
+		testLog := &model.TestLog{Name: "TestWorker", Task: "t1", Lines: []string{"ok", "panic: segmentation fault in worker"}}
+		So(testLog.Insert(), ShouldBeNil)
+
+		logs, err := logstore.New(&evergreen.Settings{})
+		So(err, ShouldBeNil)
+		So(IndexTask(logs, t1), ShouldBeNil)
+
+		Convey("a search should find the task and test log lines that contain the text", func() {
+			lines, err := Search(SearchOptions{Project: "proj", Text: "segmentation fault"})
+			So(err, ShouldBeNil)
+			So(len(lines), ShouldEqual, 2)
+
+			for _, line := range lines {
+				if line.TestLogId == "" {
+					So(line.LineNum, ShouldEqual, 2)
+					So(line.URL(), ShouldEqual, "/task_log_raw/t1/0?type=T#L2")
+				} else {
+					So(line.LineNum, ShouldEqual, 1)
+					So(line.URL(), ShouldEqual, "/test_log/"+testLog.Id+"#L1")
+				}
+			}
+		})
+
+		Convey("a search should be restricted by the filters", func() {
+			lines, err := Search(SearchOptions{Project: "proj", Text: "segmentation fault", Severities: []string{model.LogErrorPrefix}})
+			So(err, ShouldBeNil)
+			So(len(lines), ShouldEqual, 1)
+
+			lines, err = Search(SearchOptions{Project: "proj", Text: "segmentation fault", BuildVariant: "windows"})
+			So(err, ShouldBeNil)
+			So(len(lines), ShouldEqual, 0)
+
+			lines, err = Search(SearchOptions{Project: "other", Text: "segmentation fault"})
+			So(err, ShouldBeNil)
+			So(len(lines), ShouldEqual, 0)
+		})
+
+		Convey("indexing the task again should not duplicate lines", func() {
+			So(IndexTask(logs, t1), ShouldBeNil)
+			lines, err := Search(SearchOptions{Project: "proj", Text: "segmentation fault"})
+			So(err, ShouldBeNil)
+			So(len(lines), ShouldEqual, 2)
+
+			fromDB, err := task.FindOne(task.ById("t1"))
+			So(err, ShouldBeNil)
+			So(fromDB.LogsIndexed, ShouldBeTrue)
+		})
+	})
+}
+
+func TestIndexFinishedTasks(t *testing.T) {
+	Convey("With a finished task whose logs cannot be read", t, func() {
+		testutil.HandleTestingErr(db.ClearCollections(task.Collection, model.TestLogCollection, Collection,
+			logstore.ArchiveCollection), t, "error clearing collections")
+		testutil.HandleTestingErr(cleanUpLogDB(), t, "error clearing task logs")
+		config := &evergreen.Settings{LogSearch: evergreen.LogSearchConfig{RetentionDays: 1}}
+
+		// the log was archived to a backend that is not configured
+		broken := &task.Task{Id: "broken", Status: evergreen.TaskFailed, FinishTime: time.Now().Add(-2 * time.Hour)}
+		So(broken.Insert(), ShouldBeNil)
+		So((&logstore.Archive{Key: "broken", TaskId: "broken", Backend: "elsewhere"}).Upsert(), ShouldBeNil)
+		ok := &task.Task{Id: "ok", Status: evergreen.TaskSucceeded, FinishTime: time.Now().Add(-time.Hour)}
+		So(ok.Insert(), ShouldBeNil)
+
+		Convey("indexing should record the failure and retry the task later", func() {
+			So(indexFinishedTasks(config), ShouldBeNil)
+			fromDB, err := task.FindOne(task.ById("broken"))
+			So(err, ShouldBeNil)
+			So(fromDB.LogsIndexed, ShouldBeFalse)
+			So(fromDB.LogsIndexAttempts, ShouldEqual, 1)
+			So(fromDB.LogsIndexRetryAt, ShouldHappenAfter, time.Now())
+
+			So(indexFinishedTasks(config), ShouldBeNil)
+			fromDB, err = task.FindOne(task.ById("broken"))
+			So(err, ShouldBeNil)
+			So(fromDB.LogsIndexAttempts, ShouldEqual, 1)
+		})
+
+		Convey("the failing task should not hold up the tasks after it", func() {
+			batchSize := IndexBatchSize
+			IndexBatchSize = 1
+			defer func() { IndexBatchSize = batchSize }()
+
+			So(indexFinishedTasks(config), ShouldBeNil)
+			So(indexFinishedTasks(config), ShouldBeNil)
+			fromDB, err := task.FindOne(task.ById("ok"))
+			So(err, ShouldBeNil)
+			So(fromDB.LogsIndexed, ShouldBeTrue)
+		})
+	})
+}
+
+func TestTruncateLine(t *testing.T) {
+	Convey("When truncating an indexed line", t, func() {
+		Convey("a short line should be left alone", func() {
+			So(truncateLine("héllo", 10), ShouldEqual, "héllo")
+		})
+		Convey("a long line should be cut to the limit", func() {
+			So(truncateLine("hello world", 5), ShouldEqual, "hello")
+		})
+		Convey("a multi-byte character at the limit should not be split", func() {
+			// "é" is two bytes, so the limit falls inside it
+			So(truncateLine("héllo", 2), ShouldEqual, "h")
+			So(truncateLine("h日本", 5), ShouldEqual, "h日")
+		})
+	})
+}
@@ -0,0 +1,120 @@
+package logstore
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/s3"
+	"github.com/pkg/errors"
+)
+
+// FileBucket is a Bucket that keeps objects as files under a directory.
+type FileBucket struct {
+	Root string
+}
+
+func (fb *FileBucket) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean != "/"+key {
+		return "", errors.Errorf("invalid key '%s'", key)
+	}
+	return filepath.Join(fb.Root, filepath.FromSlash(clean)), nil
+}
+
+func (fb *FileBucket) Put(key string, data []byte) error {
+	filename, err := fb.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// write to a temporary file first, so that readers never see part of an object
+	tmp := filename + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(tmp, filename))
+}
+
+func (fb *FileBucket) Get(key string) ([]byte, error) {
+	filename, err := fb.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return data, errors.WithStack(err)
+}
+
+func (fb *FileBucket) Delete(key string) error {
+	filename, err := fb.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// S3Bucket is a Bucket that keeps objects in an S3 bucket, under a prefix.
+type S3Bucket struct {
+	Bucket *s3.Bucket
+	Prefix string
+}
+
+// NewS3Bucket returns an S3Bucket for the named bucket. If endpoint is set it
+// is used in place of the region's endpoint, for S3-compatible servers.
+func NewS3Bucket(auth *aws.Auth, region, endpoint, name, prefix string) (*S3Bucket, error) {
+	if region == "" {
+		region = aws.USEast.Name
+	}
+	awsRegion, ok := aws.Regions[region]
+	if !ok && endpoint == "" {
+		return nil, errors.Errorf("unknown region '%s'", region)
+	}
+	if endpoint != "" {
+		awsRegion.Name = region
+		awsRegion.S3Endpoint = endpoint
+		awsRegion.S3BucketEndpoint = ""
+	}
+
+	session := thirdparty.NewS3Session(auth, awsRegion)
+	return &S3Bucket{
+		Bucket: session.Bucket(name),
+		Prefix: strings.Trim(prefix, "/"),
+	}, nil
+}
+
+func (sb *S3Bucket) key(key string) string {
+	if sb.Prefix == "" {
+		return key
+	}
+	return sb.Prefix + "/" + key
+}
+
+func (sb *S3Bucket) Put(key string, data []byte) error {
+	return errors.WithStack(sb.Bucket.Put(sb.key(key), data, "application/gzip", s3.Private, s3.Options{}))
+}
+
+func (sb *S3Bucket) Get(key string) ([]byte, error) {
+	data, err := sb.Bucket.Get(sb.key(key))
+	if s3Err, ok := err.(*s3.Error); ok && s3Err.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	return data, errors.WithStack(err)
+}
+
+func (sb *S3Bucket) Delete(key string) error {
+	return errors.WithStack(sb.Bucket.Del(sb.key(key)))
+}
@@ -0,0 +1,98 @@
+package logstore
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/db/bsonutil"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const ArchiveCollection = "log_archives"
+
+// Archive records a log that was moved out of the database, so that it is
+// read from the log storage instead. Test logs have a TestLogId.
+type Archive struct {
+	Key       string    `bson:"_id"`
+	TaskId    string    `bson:"task_id"`
+	Execution int       `bson:"execution"`
+	TestLogId string    `bson:"test_log_id,omitempty"`
+	TestName  string    `bson:"test_name,omitempty"`
+	Backend   string    `bson:"backend"`
+	Created   time.Time `bson:"created"`
+}
+
+var (
+	ArchiveKeyKey       = bsonutil.MustHaveTag(Archive{}, "Key")
+	ArchiveTaskIdKey    = bsonutil.MustHaveTag(Archive{}, "TaskId")
+	ArchiveExecutionKey = bsonutil.MustHaveTag(Archive{}, "Execution")
+	ArchiveTestLogIdKey = bsonutil.MustHaveTag(Archive{}, "TestLogId")
+	ArchiveTestNameKey  = bsonutil.MustHaveTag(Archive{}, "TestName")
+)
+
+// Upsert records the archive, replacing any earlier record of the same log.
+func (a *Archive) Upsert() error {
+	_, err := db.Upsert(ArchiveCollection, bson.M{ArchiveKeyKey: a.Key}, a)
+	return errors.WithStack(err)
+}
+
+// RemoveArchive deletes the record of an archived log.
+func RemoveArchive(key string) error {
+	return errors.WithStack(db.Remove(ArchiveCollection, bson.M{ArchiveKeyKey: key}))
+}
+
+func findOneArchive(query bson.M) (*Archive, error) {
+	archive := &Archive{}
+	err := db.FindOne(ArchiveCollection, query, db.NoProjection, db.NoSort, archive)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	return archive, errors.WithStack(err)
+}
+
+// FindTaskLogArchive returns the archive of a task execution's log, or nil if
+// the log is still in the database.
+func FindTaskLogArchive(taskId string, execution int) (*Archive, error) {
+	return findOneArchive(bson.M{
+		ArchiveTaskIdKey:    taskId,
+		ArchiveExecutionKey: execution,
+		ArchiveTestLogIdKey: bson.M{"$exists": false},
+	})
+}
+
+// FindTestLogArchiveById returns the archive of a test log, or nil if the log
+// is still in the database.
+func FindTestLogArchiveById(id string) (*Archive, error) {
+	return findOneArchive(bson.M{ArchiveTestLogIdKey: id})
+}
+
+// FindTestLogArchive returns the archive of a test log given the test's
+// name, task id and execution, or nil if the log is still in the database.
+func FindTestLogArchive(name, taskId string, execution int) (*Archive, error) {
+	return findOneArchive(bson.M{
+		ArchiveTaskIdKey:    taskId,
+		ArchiveExecutionKey: execution,
+		ArchiveTestNameKey:  name,
+	})
+}
+
+// FindTestLogArchivesByTask returns the archives of a task execution's test logs.
+func FindTestLogArchivesByTask(taskId string, execution int) ([]Archive, error) {
+	archives := []Archive{}
+	err := db.FindAll(
+		ArchiveCollection,
+		bson.M{
+			ArchiveTaskIdKey:    taskId,
+			ArchiveExecutionKey: execution,
+			ArchiveTestLogIdKey: bson.M{"$exists": true},
+		},
+		db.NoProjection,
+		db.NoSort,
+		db.NoSkip,
+		db.NoLimit,
+		&archives,
+	)
+	return archives, errors.WithStack(err)
+}
@@ -0,0 +1,345 @@
+package logstore
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/goamz/goamz/aws"
+	"github.com/pkg/errors"
+)
+
+// Logs reads task and test logs from the database, or from the archive once
+// they have been moved there, and moves the logs of finished tasks.
+type Logs struct {
+	// Database is where logs are written while their task runs.
+	Database Storage
+	// Archive is where the logs of finished tasks are moved, or nil if logs
+	// stay in the database.
+	Archive Storage
+	// Backend names the archive's backend.
+	Backend string
+}
+
+// New returns Logs that archive to the log storage configured in the settings.
+func New(settings *evergreen.Settings) (*Logs, error) {
+	config := settings.LogStorage
+	logs := &Logs{Database: MongoStorage{}, Backend: config.Backend}
+
+	switch config.Backend {
+	case "":
+	case evergreen.LogStorageFilesystem:
+		logs.Archive = &BlobStorage{Bucket: &FileBucket{Root: config.Path}}
+	case evergreen.LogStorageS3:
+		auth := &aws.Auth{
+			AccessKey: settings.Providers.AWS.Id,
+			SecretKey: settings.Providers.AWS.Secret,
+		}
+		bucket, err := NewS3Bucket(auth, config.Region, config.Endpoint, config.Bucket, config.Prefix)
+		if err != nil {
+			return nil, errors.Wrap(err, "problem configuring s3 log storage")
+		}
+		logs.Archive = &BlobStorage{Bucket: bucket}
+	default:
+		return nil, errors.Errorf("unknown log storage backend '%s'", config.Backend)
+	}
+	return logs, nil
+}
+
+// storageFor returns the storage an archived log was moved to.
+func (l *Logs) storageFor(archive *Archive) (Storage, error) {
+	if l.Archive == nil || archive.Backend != l.Backend {
+		return nil, errors.Errorf("log %s was archived to %s log storage, which is not configured",
+			archive.Key, archive.Backend)
+	}
+	return l.Archive, nil
+}
+
+// archivedTaskLog returns the log of a task execution if it was archived, or nil.
+func (l *Logs) archivedTaskLog(taskId string, execution int) ([]model.LogMessage, error) {
+	archive, err := FindTaskLogArchive(taskId, execution)
+	if err != nil || archive == nil {
+		return nil, err
+	}
+	storage, err := l.storageFor(archive)
+	if err != nil {
+		return nil, err
+	}
+	return storage.TaskLog(taskId, execution)
+}
+
+// FindMostRecentLogMessages returns up to numMsgs of the most recent messages
+// of a task execution's log, newest first, like model.FindMostRecentLogMessages.
+func (l *Logs) FindMostRecentLogMessages(taskId string, execution int, numMsgs int,
+	severities []string, msgTypes []string) ([]model.LogMessage, error) {
+	messages, err := l.archivedTaskLog(taskId, execution)
+	if err != nil {
+		return nil, err
+	}
+	if messages == nil {
+		return model.FindMostRecentLogMessages(taskId, execution, numMsgs, severities, msgTypes)
+	}
+
+	recent := []model.LogMessage{}
+	for i := len(messages) - 1; i >= 0 && len(recent) != numMsgs; i-- {
+		if messages[i].MatchesFilter(severities, msgTypes) {
+			recent = append(recent, messages[i])
+		}
+	}
+	return recent, nil
+}
+
+// GetRawTaskLogChannel returns a channel of the messages of a task
+// execution's log, oldest first, like model.GetRawTaskLogChannel.
+func (l *Logs) GetRawTaskLogChannel(taskId string, execution int, severities []string,
+	msgTypes []string) (chan model.LogMessage, error) {
+	messages, err := l.archivedTaskLog(taskId, execution)
+	if err != nil {
+		return nil, err
+	}
+	if messages == nil {
+		return model.GetRawTaskLogChannel(taskId, execution, severities, msgTypes)
+	}
+
+	channel := make(chan model.LogMessage, 100)
+	go func() {
+		defer close(channel)
+		for _, msg := range messages {
+			if msg.MatchesFilter(severities, msgTypes) {
+				channel <- msg
+			}
+		}
+	}()
+	return channel, nil
+}
+
+// FindOneTestLogById returns the test log with the given id, or nil.
+func (l *Logs) FindOneTestLogById(id string) (*model.TestLog, error) {
+	archive, err := FindTestLogArchiveById(id)
+	if err != nil {
+		return nil, err
+	}
+	return l.testLog(archive, func() (*model.TestLog, error) {
+		return model.FindOneTestLogById(id)
+	})
+}
+
+// FindOneTestLog returns a test log given the test's name, task id and
+// execution, or nil.
+func (l *Logs) FindOneTestLog(name, taskId string, execution int) (*model.TestLog, error) {
+	archive, err := FindTestLogArchive(name, taskId, execution)
+	if err != nil {
+		return nil, err
+	}
+	return l.testLog(archive, func() (*model.TestLog, error) {
+		return model.FindOneTestLog(name, taskId, execution)
+	})
+}
+
+// FindTestLogsByTask returns every test log of a task execution, wherever
+// they are stored.
+func (l *Logs) FindTestLogsByTask(taskId string, execution int) ([]model.TestLog, error) {
+	testLogs, err := model.FindTestLogsByTask(taskId, execution)
+	if err != nil {
+		return nil, err
+	}
+	archives, err := FindTestLogArchivesByTask(taskId, execution)
+	if err != nil {
+		return nil, err
+	}
+	// a log being archived may be in both places
+	inDatabase := map[string]bool{}
+	for _, log := range testLogs {
+		inDatabase[log.Id] = true
+	}
+	for i := range archives {
+		if inDatabase[archives[i].TestLogId] {
+			continue
+		}
+		log, err := l.testLog(&archives[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		if log != nil {
+			testLogs = append(testLogs, *log)
+		}
+	}
+	return testLogs, nil
+}
+
+// testLog reads an archived test log, or calls find if it was not archived.
+func (l *Logs) testLog(archive *Archive, find func() (*model.TestLog, error)) (*model.TestLog, error) {
+	if archive == nil {
+		return find()
+	}
+	storage, err := l.storageFor(archive)
+	if err != nil {
+		return nil, err
+	}
+	return storage.TestLog(archive.TestLogId)
+}
+
+// ArchiveTask moves the task and test logs of every execution of a task from
+// the database to the archive. Each log is stored and recorded before it is
+// removed from the database, so it can always be read.
+func (l *Logs) ArchiveTask(t *task.Task) error {
+	if l.Archive == nil {
+		return errors.New("no log storage is configured")
+	}
+
+	for execution := 0; execution <= t.Execution; execution++ {
+		if err := l.archiveTaskLog(t.Id, execution); err != nil {
+			return errors.Wrapf(err, "problem archiving log of task %s execution %d", t.Id, execution)
+		}
+
+		testLogs, err := model.FindTestLogsByTask(t.Id, execution)
+		if err != nil {
+			return errors.Wrapf(err, "problem finding test logs of task %s", t.Id)
+		}
+		for i := range testLogs {
+			if err = l.archiveTestLog(&testLogs[i]); err != nil {
+				return errors.Wrapf(err, "problem archiving test log %s", testLogs[i].Id)
+			}
+		}
+	}
+	return errors.WithStack(t.SetLogsArchived())
+}
+
+func (l *Logs) archiveTaskLog(taskId string, execution int) error {
+	messages, err := l.Database.TaskLog(taskId, execution)
+	if err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	if err = l.Archive.PutTaskLog(taskId, execution, messages); err != nil {
+		return err
+	}
+	archive := &Archive{
+		Key:       taskLogKey(taskId, execution),
+		TaskId:    taskId,
+		Execution: execution,
+		Backend:   l.Backend,
+		Created:   time.Now(),
+	}
+	if err = archive.Upsert(); err != nil {
+		return err
+	}
+	return l.Database.RemoveTaskLog(taskId, execution)
+}
+
+func (l *Logs) archiveTestLog(log *model.TestLog) error {
+	if err := l.Archive.PutTestLog(log); err != nil {
+		return err
+	}
+	archive := &Archive{
+		Key:       testLogKey(log.Id),
+		TaskId:    log.Task,
+		Execution: log.TaskExecution,
+		TestLogId: log.Id,
+		TestName:  log.Name,
+		Backend:   l.Backend,
+		Created:   time.Now(),
+	}
+	if err := archive.Upsert(); err != nil {
+		return err
+	}
+	return l.Database.RemoveTestLog(log.Id)
+}
+
+// RemoveTask deletes the task and test logs of every execution of a task,
+// wherever they are stored, and returns how many of each there were. With
+// dryRun set the logs are only counted.
+func (l *Logs) RemoveTask(t *task.Task, dryRun bool) (taskLogs, testLogs int, err error) {
+	for execution := 0; execution <= t.Execution; execution++ {
+		removed, err := l.removeTaskLog(t.Id, execution, dryRun)
+		if err != nil {
+			return taskLogs, testLogs, errors.Wrapf(err, "problem removing log of task %s execution %d", t.Id, execution)
+		}
+		if removed {
+			taskLogs++
+		}
+
+		count, err := l.removeTestLogs(t.Id, execution, dryRun)
+		testLogs += count
+		if err != nil {
+			return taskLogs, testLogs, errors.Wrapf(err, "problem removing test logs of task %s execution %d", t.Id, execution)
+		}
+	}
+	return taskLogs, testLogs, nil
+}
+
+// removeTaskLog deletes the log of a task execution, and returns whether the
+// execution had one.
+func (l *Logs) removeTaskLog(taskId string, execution int, dryRun bool) (bool, error) {
+	archive, err := FindTaskLogArchive(taskId, execution)
+	if err != nil {
+		return false, err
+	}
+	chunks, err := model.CountTaskLogs(taskId, execution)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	if dryRun || (archive == nil && chunks == 0) {
+		return archive != nil || chunks > 0, nil
+	}
+
+	if archive != nil {
+		storage, err := l.storageFor(archive)
+		if err != nil {
+			return false, err
+		}
+		if err = storage.RemoveTaskLog(taskId, execution); err != nil {
+			return false, err
+		}
+		if err = RemoveArchive(archive.Key); err != nil {
+			return false, err
+		}
+	}
+	if chunks > 0 {
+		if err = l.Database.RemoveTaskLog(taskId, execution); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// removeTestLogs deletes the test logs of a task execution, and returns how
+// many there were.
+func (l *Logs) removeTestLogs(taskId string, execution int, dryRun bool) (int, error) {
+	archives, err := FindTestLogArchivesByTask(taskId, execution)
+	if err != nil {
+		return 0, err
+	}
+	inDB, err := model.CountTestLogsByTask(taskId, execution)
+	if err != nil {
+		return 0, err
+	}
+	if dryRun {
+		return len(archives) + inDB, nil
+	}
+
+	removed := 0
+	for _, archive := range archives {
+		storage, err := l.storageFor(&archive)
+		if err != nil {
+			return removed, err
+		}
+		if err = storage.RemoveTestLog(archive.TestLogId); err != nil {
+			return removed, err
+		}
+		if err = RemoveArchive(archive.Key); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	if inDB > 0 {
+		if err = model.RemoveTestLogsByTask(taskId, execution); err != nil {
+			return removed, err
+		}
+	}
+	return removed + inDB, nil
+}
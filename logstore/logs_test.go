@@ -0,0 +1,189 @@
+package logstore
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var testConfig = testutil.TestConfig()
+
+func init() {
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testConfig))
+}
+
+func cleanUpLogDB() error {
+	session, _, err := db.GetGlobalSessionFactory().GetSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	_, err = session.DB(model.TaskLogDB).C(model.TaskLogCollection).RemoveAll(bson.M{})
+	return err
+}
+
+func TestBlobStorage(t *testing.T) {
+	Convey("With blob storage in a scratch directory", t, func() {
+		dir, err := ioutil.TempDir("", "logstore")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		storage := &BlobStorage{Bucket: &FileBucket{Root: dir}}
+
+		Convey("a stored task log should be read back in order", func() {
+			messages := []model.LogMessage{
+				{Type: model.TaskLogPrefix, Severity: model.LogInfoPrefix, Message: "one"},
+				{Type: model.TaskLogPrefix, Severity: model.LogInfoPrefix, Message: "two"},
+			}
+			So(storage.PutTaskLog("t1", 0, messages), ShouldBeNil)
+			fromStorage, err := storage.TaskLog("t1", 0)
+			So(err, ShouldBeNil)
+			So(len(fromStorage), ShouldEqual, 2)
+			So(fromStorage[1].Message, ShouldEqual, "two")
+
+			Convey("and be gone once removed", func() {
+				So(storage.RemoveTaskLog("t1", 0), ShouldBeNil)
+				fromStorage, err = storage.TaskLog("t1", 0)
+				So(err, ShouldBeNil)
+				So(len(fromStorage), ShouldEqual, 0)
+			})
+		})
+
+		Convey("a stored test log should be read back by its id", func() {
+			So(storage.PutTestLog(&model.TestLog{Id: "abc", Name: "test", Lines: []string{"line"}}), ShouldBeNil)
+			log, err := storage.TestLog("abc")
+			So(err, ShouldBeNil)
+			So(log.Name, ShouldEqual, "test")
+
+			log, err = storage.TestLog("def")
+			So(err, ShouldBeNil)
+			So(log, ShouldBeNil)
+		})
+
+		Convey("keys outside the directory should be rejected", func() {
+			So(storage.Bucket.Put("../escape", []byte("data")), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestArchiveTask(t *testing.T) {
+	Convey("With a finished task whose logs are in the database", t, func() {
+		testutil.HandleTestingErr(db.ClearCollections(task.Collection, model.TestLogCollection, ArchiveCollection),
+			t, "error clearing collections")
+		testutil.HandleTestingErr(cleanUpLogDB(), t, "error clearing task logs")
+
+		dir, err := ioutil.TempDir("", "logstore")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		settings := &evergreen.Settings{LogStorage: evergreen.LogStorageConfig{
+			Backend: evergreen.LogStorageFilesystem,
+			Path:    dir,
+		}}
+		logs, err := New(settings)
+		So(err, ShouldBeNil)
+
+		t1 := &task.Task{Id: "t1", Status: evergreen.TaskSucceeded, FinishTime: time.Now().Add(-2 * time.Hour)}
+		So(t1.Insert(), ShouldBeNil)
+		for i := 0; i < 25; i++ {
+			msg := &model.LogMessage{
+				Type:      model.TaskLogPrefix,
+				Severity:  model.LogInfoPrefix,
+				Message:   fmt.Sprintf("message %d", i),
+				Timestamp: time.Now().Add(time.Duration(i) * time.Millisecond),
+			}
+			So(msg.Insert("t1", 0), ShouldBeNil)
+		}
+		testLog := &model.TestLog{Name: "test", Task: "t1", Lines: []string{"passed"}}
+		So(testLog.Insert(), ShouldBeNil)
+
+		Convey("archiving its logs should move them out of the database", func() {
+			So(logs.ArchiveFinishedTasks(time.Hour), ShouldBeNil)
+
+			inDB, err := model.FindAllTaskLogs("t1", 0)
+			So(err, ShouldBeNil)
+			So(len(inDB), ShouldEqual, 0)
+			testLogInDB, err := model.FindOneTestLogById(testLog.Id)
+			So(err, ShouldBeNil)
+			So(testLogInDB, ShouldBeNil)
+
+			fromDB, err := task.FindOne(task.ById("t1"))
+			So(err, ShouldBeNil)
+			So(fromDB.LogsArchived, ShouldBeTrue)
+
+			Convey("and they should still be readable", func() {
+				recent, err := logs.FindMostRecentLogMessages("t1", 0, 10, nil, nil)
+				So(err, ShouldBeNil)
+				So(len(recent), ShouldEqual, 10)
+				So(recent[0].Message, ShouldEqual, "message 24")
+
+				channel, err := logs.GetRawTaskLogChannel("t1", 0, nil, []string{model.TaskLogPrefix})
+				So(err, ShouldBeNil)
+				count := 0
+				for range channel {
+					count++
+				}
+				So(count, ShouldEqual, 25)
+
+				log, err := logs.FindOneTestLog("test", "t1", 0)
+				So(err, ShouldBeNil)
+				So(log.Lines, ShouldResemble, []string{"passed"})
+				log, err = logs.FindOneTestLogById(testLog.Id)
+				So(err, ShouldBeNil)
+				So(log.Name, ShouldEqual, "test")
+			})
+		})
+
+		Convey("a task whose logs fail to archive should be retried later", func() {
+			archive := logs.Archive
+			logs.Archive = nil
+			So(logs.ArchiveFinishedTasks(time.Hour), ShouldBeNil)
+
+			fromDB, err := task.FindOne(task.ById("t1"))
+			So(err, ShouldBeNil)
+			So(fromDB.LogsArchived, ShouldBeFalse)
+			So(fromDB.LogsArchiveAttempts, ShouldEqual, 1)
+			So(fromDB.LogsArchiveRetryAt, ShouldHappenAfter, time.Now())
+
+			logs.Archive = archive
+			So(logs.ArchiveFinishedTasks(time.Hour), ShouldBeNil)
+			fromDB, err = task.FindOne(task.ById("t1"))
+			So(err, ShouldBeNil)
+			So(fromDB.LogsArchived, ShouldBeFalse)
+		})
+
+		Convey("a task waiting to be retried should not hold up the tasks after it", func() {
+			batchSize := ArchiveBatchSize
+			ArchiveBatchSize = 1
+			defer func() { ArchiveBatchSize = batchSize }()
+
+			t0 := &task.Task{
+				Id:                 "t0",
+				Status:             evergreen.TaskFailed,
+				FinishTime:         time.Now().Add(-5 * time.Hour),
+				LogsArchiveRetryAt: time.Now().Add(time.Hour),
+			}
+			So(t0.Insert(), ShouldBeNil)
+			So(logs.ArchiveFinishedTasks(time.Hour), ShouldBeNil)
+
+			fromDB, err := task.FindOne(task.ById("t1"))
+			So(err, ShouldBeNil)
+			So(fromDB.LogsArchived, ShouldBeTrue)
+		})
+
+		Convey("tasks that finished recently should keep their logs in the database", func() {
+			So(logs.ArchiveFinishedTasks(3*time.Hour), ShouldBeNil)
+			inDB, err := model.FindAllTaskLogs("t1", 0)
+			So(err, ShouldBeNil)
+			So(len(inDB), ShouldBeGreaterThan, 0)
+		})
+	})
+}
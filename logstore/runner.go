@@ -0,0 +1,107 @@
+package logstore
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ArchiveBatchSize is the most tasks whose logs are archived in one run.
+var ArchiveBatchSize = 500
+
+const (
+	// archiveRetryDelay is how long a task whose logs failed to be archived
+	// waits before it is retried, doubling with each failure up to
+	// maxArchiveRetryDelay.
+	archiveRetryDelay    = time.Hour
+	maxArchiveRetryDelay = 24 * time.Hour
+)
+
+// Runner moves the logs of finished tasks to the configured log storage.
+type Runner struct{}
+
+const (
+	RunnerName  = "logarchiver"
+	Description = "move the logs of finished tasks to the configured log storage"
+)
+
+func (r *Runner) Name() string {
+	return RunnerName
+}
+
+func (r *Runner) Description() string {
+	return Description
+}
+
+func (r *Runner) Run(config *evergreen.Settings) error {
+	if config.LogStorage.Backend == "" {
+		return nil
+	}
+
+	startTime := time.Now()
+	grip.Infoln("Starting log archiver at time", startTime)
+
+	logs, err := New(config)
+	if err != nil {
+		err = errors.Wrap(err, "error configuring log storage")
+		grip.Error(err)
+		return err
+	}
+
+	age := time.Duration(config.LogStorage.ArchiveAfterHours) * time.Hour
+	if err = logs.ArchiveFinishedTasks(age); err != nil {
+		err = errors.Wrap(err, "error archiving logs")
+		grip.Error(err)
+		return err
+	}
+
+	runtime := time.Since(startTime)
+	if err = model.SetProcessRuntimeCompleted(RunnerName, runtime); err != nil {
+		grip.Errorf("error updating process status: %+v", err)
+	}
+	grip.Infof("Log archiver took %s to run", runtime)
+	return nil
+}
+
+// ArchiveFinishedTasks archives the logs of tasks that finished more than age
+// ago, oldest first. A task whose logs cannot be archived is retried after a
+// delay that doubles with each failure, so that it does not hold up the tasks
+// after it.
+func (l *Logs) ArchiveFinishedTasks(age time.Duration) error {
+	now := time.Now()
+	tasks, err := task.Find(db.Query(bson.M{
+		task.StatusKey:       bson.M{"$in": evergreen.CompletedStatuses},
+		task.FinishTimeKey:   bson.M{"$lt": now.Add(-age)},
+		task.LogsArchivedKey: bson.M{"$ne": true},
+		"$or": []bson.M{
+			{task.LogsArchiveRetryAtKey: bson.M{"$exists": false}},
+			{task.LogsArchiveRetryAtKey: bson.M{"$lte": now}},
+		},
+	}).WithFields(task.IdKey, task.ExecutionKey, task.LogsArchiveAttemptsKey).
+		Sort([]string{task.FinishTimeKey}).Limit(ArchiveBatchSize))
+	if err != nil {
+		return errors.Wrap(err, "error finding finished tasks")
+	}
+
+	archived := 0
+	for i := range tasks {
+		if err = l.ArchiveTask(&tasks[i]); err != nil {
+			grip.Errorf("error archiving logs of task %s: %+v", tasks[i].Id, err)
+			retryAt := now.Add(util.DoublingBackoff(archiveRetryDelay, maxArchiveRetryDelay, tasks[i].LogsArchiveAttempts))
+			if err = tasks[i].MarkLogsArchiveFailed(retryAt); err != nil {
+				grip.Errorf("error recording failed archive of task %s: %+v", tasks[i].Id, err)
+			}
+			continue
+		}
+		archived++
+	}
+	grip.Infof("archived logs of %d of %d finished tasks", archived, len(tasks))
+	return nil
+}
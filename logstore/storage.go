@@ -0,0 +1,185 @@
+// Package logstore stores the task and test logs of finished tasks. Logs are
+// written to the database while a task runs, and may later be archived as
+// compressed objects in a blob store, such as S3 or a local directory.
+// Logs are read through the package the same way wherever they are kept.
+package logstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/pkg/errors"
+)
+
+// Storage is a place where task and test logs can be kept.
+type Storage interface {
+	// TaskLog returns the messages of a task execution's log, oldest first.
+	TaskLog(taskId string, execution int) ([]model.LogMessage, error)
+	// PutTaskLog stores the messages of a task execution's log.
+	PutTaskLog(taskId string, execution int, messages []model.LogMessage) error
+	// RemoveTaskLog deletes a task execution's log.
+	RemoveTaskLog(taskId string, execution int) error
+
+	// TestLog returns the test log with the given id, or nil if there is none.
+	TestLog(id string) (*model.TestLog, error)
+	// PutTestLog stores a test log under its id.
+	PutTestLog(log *model.TestLog) error
+	// RemoveTestLog deletes the test log with the given id.
+	RemoveTestLog(id string) error
+}
+
+// MongoStorage keeps logs in the database, where the agent writes them.
+type MongoStorage struct{}
+
+func (MongoStorage) TaskLog(taskId string, execution int) ([]model.LogMessage, error) {
+	channel, err := model.GetRawTaskLogChannel(taskId, execution, nil, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	messages := []model.LogMessage{}
+	for msg := range channel {
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+func (MongoStorage) PutTaskLog(taskId string, execution int, messages []model.LogMessage) error {
+	for start := 0; start < len(messages); start += model.MessagesPerLog {
+		end := start + model.MessagesPerLog
+		if end > len(messages) {
+			end = len(messages)
+		}
+		taskLog := &model.TaskLog{
+			TaskId:       taskId,
+			Execution:    execution,
+			Timestamp:    messages[start].Timestamp,
+			MessageCount: end - start,
+			Messages:     messages[start:end],
+		}
+		if err := taskLog.Insert(); err != nil {
+			return errors.Wrapf(err, "problem storing log for task %s", taskId)
+		}
+	}
+	return nil
+}
+
+func (MongoStorage) RemoveTaskLog(taskId string, execution int) error {
+	return errors.WithStack(model.RemoveTaskLogs(taskId, execution))
+}
+
+func (MongoStorage) TestLog(id string) (*model.TestLog, error) {
+	return model.FindOneTestLogById(id)
+}
+
+func (MongoStorage) PutTestLog(log *model.TestLog) error {
+	if log.Id == "" {
+		return log.Insert()
+	}
+	return errors.WithStack(db.Insert(model.TestLogCollection, log))
+}
+
+func (MongoStorage) RemoveTestLog(id string) error {
+	return model.RemoveTestLog(id)
+}
+
+// ErrNotFound is returned by a Bucket when there is no object with a key.
+var ErrNotFound = errors.New("object not found")
+
+// Bucket is a flat store of objects, such as an S3 bucket.
+type Bucket interface {
+	// Put stores data under the key, replacing any existing object.
+	Put(key string, data []byte) error
+	// Get returns the object stored under the key, or ErrNotFound.
+	Get(key string) ([]byte, error)
+	// Delete removes the object stored under the key, if there is one.
+	Delete(key string) error
+}
+
+// BlobStorage keeps each log as a gzipped JSON object in a Bucket.
+type BlobStorage struct {
+	Bucket Bucket
+}
+
+func taskLogKey(taskId string, execution int) string {
+	return fmt.Sprintf("task_logs/%s/%d.json.gz", taskId, execution)
+}
+
+func testLogKey(id string) string {
+	return fmt.Sprintf("test_logs/%s.json.gz", id)
+}
+
+func (bs *BlobStorage) TaskLog(taskId string, execution int) ([]model.LogMessage, error) {
+	messages := []model.LogMessage{}
+	if err := bs.get(taskLogKey(taskId, execution), &messages); err != nil {
+		if errors.Cause(err) == ErrNotFound {
+			return []model.LogMessage{}, nil
+		}
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (bs *BlobStorage) PutTaskLog(taskId string, execution int, messages []model.LogMessage) error {
+	return bs.put(taskLogKey(taskId, execution), messages)
+}
+
+func (bs *BlobStorage) RemoveTaskLog(taskId string, execution int) error {
+	return errors.WithStack(bs.Bucket.Delete(taskLogKey(taskId, execution)))
+}
+
+func (bs *BlobStorage) TestLog(id string) (*model.TestLog, error) {
+	log := &model.TestLog{}
+	if err := bs.get(testLogKey(id), log); err != nil {
+		if errors.Cause(err) == ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return log, nil
+}
+
+func (bs *BlobStorage) PutTestLog(log *model.TestLog) error {
+	if log.Id == "" {
+		return errors.New("cannot store a test log without an id")
+	}
+	return bs.put(testLogKey(log.Id), log)
+}
+
+func (bs *BlobStorage) RemoveTestLog(id string) error {
+	return errors.WithStack(bs.Bucket.Delete(testLogKey(id)))
+}
+
+// put compresses the JSON encoding of data and stores it under the key.
+func (bs *BlobStorage) put(key string, data interface{}) error {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	if err := json.NewEncoder(gz).Encode(data); err != nil {
+		return errors.Wrapf(err, "problem encoding %s", key)
+	}
+	if err := gz.Close(); err != nil {
+		return errors.Wrapf(err, "problem compressing %s", key)
+	}
+	return errors.Wrapf(bs.Bucket.Put(key, buf.Bytes()), "problem storing %s", key)
+}
+
+// get reads the object stored under the key into out.
+func (bs *BlobStorage) get(key string, out interface{}) error {
+	data, err := bs.Bucket.Get(key)
+	if err != nil {
+		return errors.Wrapf(err, "problem reading %s", key)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrapf(err, "problem decompressing %s", key)
+	}
+	defer gz.Close()
+	if err = json.NewDecoder(gz).Decode(out); err != nil && err != io.EOF {
+		return errors.Wrapf(err, "problem decoding %s", key)
+	}
+	return nil
+}
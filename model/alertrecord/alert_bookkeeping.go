@@ -18,7 +18,11 @@ var (
 	FirstVariantFailureId  = "first_variant_failure"
 	FirstTaskTypeFailureId = "first_tasktype_failure"
 	TaskFailTransitionId   = "task_transition_failure"
+	TaskSystemFailureId    = "task_system_failure"
+	TaskSetupFailureId     = "task_setup_failure"
+	TaskTimedOutId         = "task_timed_out"
 	LastRevisionNotFound   = "last_revision_not_found"
+	PerfRegressionId       = "perf_regression"
 )
 
 // Host triggers
@@ -106,6 +110,15 @@ func ByHostAlertRecordType(hostId, triggerId string) db.Q {
 	}).Limit(1)
 }
 
+// ByTaskAlertRecordType finds the alert record of the given type that was
+// stored for a task.
+func ByTaskAlertRecordType(taskId, triggerId string) db.Q {
+	return db.Query(bson.M{
+		TypeKey:   triggerId,
+		TaskIdKey: taskId,
+	}).Limit(1)
+}
+
 func ByLastRevNotFound(projectId, versionId string) db.Q {
 	return db.Query(bson.M{
 		TypeKey:      LastRevisionNotFound,
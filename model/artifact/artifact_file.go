@@ -1,6 +1,11 @@
 package artifact
 
-const Collection = "artifact_files"
+import "time"
+
+const (
+	Collection       = "artifact_files"
+	UploadCollection = "artifact_uploads"
+)
 
 const (
 	// strings for setting visibility
@@ -11,6 +16,24 @@ const (
 
 var ValidVisibilities = []string{Public, Private, None, ""}
 
+const (
+	// expiration classes, which set how long a file is kept in s3
+	ExpireNever  = ""
+	ExpireShort  = "short"
+	ExpireMedium = "medium"
+	ExpireLong   = "long"
+)
+
+var ValidExpirationClasses = []string{ExpireNever, ExpireShort, ExpireMedium, ExpireLong}
+
+// DefaultExpirationDays is how many days after its task started a file of
+// each expiration class is kept, unless the settings override it.
+var DefaultExpirationDays = map[string]int{
+	ExpireShort:  7,
+	ExpireMedium: 30,
+	ExpireLong:   365,
+}
+
 // Entry stores groups of names and links (not content!) for
 // files uploaded to the api server by a running agent. These links could
 // be for build or task-relevant files (things like extra results,
@@ -23,8 +46,9 @@ type Entry struct {
 }
 
 // Params stores file entries as key-value pairs, for easy parameter parsing.
-//  Key = Human-readable name for file
-//  Value = link for the file
+//
+//	Key = Human-readable name for file
+//	Value = link for the file
 type Params map[string]string
 
 // File is a pairing of name and link for easy storage/display
@@ -35,6 +59,59 @@ type File struct {
 	Link string `json:"link" bson:"link"`
 	// Visibility determines who can see the file in the UI
 	Visibility string `json:"visibility" bson:"visibility"`
+
+	// Bucket and FileKey locate a file that was put to s3, so that it can
+	// be deleted once it expires
+	Bucket  string `json:"bucket,omitempty" bson:"bucket,omitempty"`
+	FileKey string `json:"file_key,omitempty" bson:"file_key,omitempty"`
+	// Permissions is the ACL the file was put to s3 with
+	Permissions string `json:"permissions,omitempty" bson:"permissions,omitempty"`
+	// ContentHash is the hex encoded sha256 of the file's contents, which
+	// identifies identical uploads
+	ContentHash string `json:"content_hash,omitempty" bson:"content_hash,omitempty"`
+
+	// ExpirationClass sets how long the file is kept, see ValidExpirationClasses
+	ExpirationClass string `json:"expiration_class,omitempty" bson:"expiration_class,omitempty"`
+	// ExpireAt is when the file is deleted, which the API server sets from
+	// its expiration class
+	ExpireAt time.Time `json:"expire_at,omitempty" bson:"expire_at,omitempty"`
+	// Expired is set once the file has been deleted
+	Expired bool `json:"expired,omitempty" bson:"expired,omitempty"`
+	// ExpireAttempts counts the failed deletions of the file, and
+	// ExpireRetryAt is when the next one may be tried
+	ExpireAttempts int       `json:"-" bson:"expire_attempts,omitempty"`
+	ExpireRetryAt  time.Time `json:"-" bson:"expire_retry_at,omitempty"`
+}
+
+// Upload records that a task asked the API server before putting an s3
+// object. Only recorded objects are ever deleted when their files expire, so
+// that a task cannot schedule the deletion of objects it did not put.
+type Upload struct {
+	// Id is the bucket and key of the object, joined by a slash
+	Id      string `bson:"_id"`
+	Bucket  string `bson:"bucket"`
+	FileKey string `bson:"file_key"`
+	// TaskId is the task that first put the object
+	TaskId  string    `bson:"task_id"`
+	Created time.Time `bson:"created"`
+}
+
+// ExpirationTime returns when a file of the expiration class should be
+// deleted, given when its task started and the days each class is kept for.
+// Classes missing from days are kept for their default number of days. The
+// zero time means the file is kept forever.
+func ExpirationTime(class string, taskStart time.Time, days map[string]int) time.Time {
+	if class == ExpireNever {
+		return time.Time{}
+	}
+	keep, ok := days[class]
+	if !ok {
+		keep = DefaultExpirationDays[class]
+	}
+	if keep <= 0 {
+		return time.Time{}
+	}
+	return taskStart.Add(time.Duration(keep) * 24 * time.Hour)
 }
 
 // Array turns the parameter map into an array of File structs.
@@ -42,7 +119,7 @@ type File struct {
 func (params Params) Array() []File {
 	var files []File
 	for name, link := range params {
-		files = append(files, File{name, link, ""})
+		files = append(files, File{Name: name, Link: link})
 	}
 	return files
 }
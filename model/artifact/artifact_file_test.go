@@ -28,8 +28,8 @@ func TestEntryUpsert(t *testing.T) {
 			TaskDisplayName: "Task One",
 			BuildId:         "build1",
 			Files: []File{
-				{"cat_pix", "http://placekitten.com/800/600", ""},
-				{"fast_download", "https://fastdl.mongodb.org", ""},
+				{Name: "cat_pix", Link: "http://placekitten.com/800/600"},
+				{Name: "fast_download", Link: "https://fastdl.mongodb.org"},
 			},
 		}
 
@@ -53,8 +53,8 @@ func TestEntryUpsert(t *testing.T) {
 				// reusing test entry but overwriting files field --
 				// consider this as an additional update from the agent
 				testEntry.Files = []File{
-					{"cat_pix", "http://placekitten.com/300/400", ""},
-					{"the_value_of_four", "4", ""},
+					{Name: "cat_pix", Link: "http://placekitten.com/300/400"},
+					{Name: "the_value_of_four", Link: "4"},
 				}
 				So(testEntry.Upsert(), ShouldBeNil)
 				count, err := db.Count(Collection, bson.M{})
@@ -84,3 +84,37 @@ func TestEntryUpsert(t *testing.T) {
 		})
 	})
 }
+
+func TestRecordUpload(t *testing.T) {
+	Convey("With no recorded uploads", t, func() {
+		testutil.HandleTestingErr(db.Clear(UploadCollection), t, "Error clearing collection")
+
+		Convey("an unrecorded object should not be found", func() {
+			upload, err := FindUpload("bucket", "key")
+			So(err, ShouldBeNil)
+			So(upload, ShouldBeNil)
+		})
+
+		Convey("the first task to record an object should own it", func() {
+			upload, err := RecordUpload("bucket", "key", "task1")
+			So(err, ShouldBeNil)
+			So(upload.TaskId, ShouldEqual, "task1")
+
+			upload, err = RecordUpload("bucket", "key", "task2")
+			So(err, ShouldBeNil)
+			So(upload.TaskId, ShouldEqual, "task1")
+
+			upload, err = FindUpload("bucket", "key")
+			So(err, ShouldBeNil)
+			So(upload.Bucket, ShouldEqual, "bucket")
+			So(upload.FileKey, ShouldEqual, "key")
+			So(upload.TaskId, ShouldEqual, "task1")
+
+			Convey("but not other objects in the bucket", func() {
+				upload, err = RecordUpload("bucket", "other", "task2")
+				So(err, ShouldBeNil)
+				So(upload.TaskId, ShouldEqual, "task2")
+			})
+		})
+	})
+}
@@ -1,6 +1,8 @@
 package artifact
 
 import (
+	"time"
+
 	"github.com/evergreen-ci/evergreen/db"
 	"github.com/evergreen-ci/evergreen/db/bsonutil"
 	"gopkg.in/mgo.v2"
@@ -15,6 +17,22 @@ var (
 	FilesKey    = bsonutil.MustHaveTag(Entry{}, "Files")
 	NameKey     = bsonutil.MustHaveTag(File{}, "Name")
 	LinkKey     = bsonutil.MustHaveTag(File{}, "Link")
+
+	BucketKey         = bsonutil.MustHaveTag(File{}, "Bucket")
+	FileKeyKey        = bsonutil.MustHaveTag(File{}, "FileKey")
+	PermissionsKey    = bsonutil.MustHaveTag(File{}, "Permissions")
+	ContentHashKey    = bsonutil.MustHaveTag(File{}, "ContentHash")
+	ExpireAtKey       = bsonutil.MustHaveTag(File{}, "ExpireAt")
+	ExpiredKey        = bsonutil.MustHaveTag(File{}, "Expired")
+	ExpireAttemptsKey = bsonutil.MustHaveTag(File{}, "ExpireAttempts")
+	ExpireRetryAtKey  = bsonutil.MustHaveTag(File{}, "ExpireRetryAt")
+
+	// BSON fields for upload structs
+	UploadIdKey      = bsonutil.MustHaveTag(Upload{}, "Id")
+	UploadBucketKey  = bsonutil.MustHaveTag(Upload{}, "Bucket")
+	UploadFileKeyKey = bsonutil.MustHaveTag(Upload{}, "FileKey")
+	UploadTaskIdKey  = bsonutil.MustHaveTag(Upload{}, "TaskId")
+	UploadCreatedKey = bsonutil.MustHaveTag(Upload{}, "Created")
 )
 
 // === Queries ===
@@ -29,6 +47,68 @@ func ByBuildId(id string) db.Q {
 	return db.Query(bson.D{{BuildIdKey, id}}).Sort([]string{TaskNameKey})
 }
 
+// ByTaskIdWithoutPendingExpiration returns the entries of a task that have no
+// files waiting to be deleted from s3, so that removing the entries does not
+// leave files behind.
+func ByTaskIdWithoutPendingExpiration(id string) db.Q {
+	return db.Query(bson.M{
+		TaskIdKey: id,
+		FilesKey: bson.M{"$not": bson.M{"$elemMatch": bson.M{
+			ExpireAtKey: bson.M{"$exists": true},
+			ExpiredKey:  bson.M{"$ne": true},
+		}}},
+	})
+}
+
+// ByExpiringFiles returns entries with files that should have been deleted by
+// the given time, leaving out files whose failed deletion is not yet due to be
+// retried.
+func ByExpiringFiles(now time.Time) db.Q {
+	return db.Query(bson.M{
+		FilesKey: bson.M{"$elemMatch": bson.M{
+			ExpireAtKey: bson.M{"$lte": now},
+			ExpiredKey:  bson.M{"$ne": true},
+			"$or": []bson.M{
+				{ExpireRetryAtKey: bson.M{"$exists": false}},
+				{ExpireRetryAtKey: bson.M{"$lte": now}},
+			},
+		}},
+	})
+}
+
+// liveFile matches files that are not due to be deleted before the given time.
+func liveFile(query bson.M, until time.Time) bson.M {
+	query[ExpiredKey] = bson.M{"$ne": true}
+	query["$or"] = []bson.M{
+		{ExpireAtKey: bson.M{"$exists": false}},
+		{ExpireAtKey: bson.M{"$gt": until}},
+	}
+	return query
+}
+
+// ByLiveS3File returns entries with files that still use an s3 object at the
+// given time.
+func ByLiveS3File(bucket, key string, now time.Time) db.Q {
+	return db.Query(bson.M{
+		FilesKey: bson.M{"$elemMatch": liveFile(bson.M{
+			BucketKey:  bucket,
+			FileKeyKey: key,
+		}, now)},
+	})
+}
+
+// ByLiveContent returns entries with files in the bucket that have the given
+// content and permissions, and are kept until at least the given time.
+func ByLiveContent(bucket, hash, permissions string, until time.Time) db.Q {
+	return db.Query(bson.M{
+		FilesKey: bson.M{"$elemMatch": liveFile(bson.M{
+			BucketKey:      bucket,
+			ContentHashKey: hash,
+			PermissionsKey: permissions,
+		}, until)},
+	})
+}
+
 // === DB Logic ===
 
 // Upsert updates the files entry in the db if an entry already exists,
@@ -71,3 +151,135 @@ func FindAll(query db.Q) ([]Entry, error) {
 	err := db.FindAllQ(Collection, query, &entries)
 	return entries, err
 }
+
+// Count returns how many entries match the query
+func Count(query db.Q) (int, error) {
+	return db.CountQ(Collection, query)
+}
+
+// Remove deletes every Entry that matches the query
+func Remove(query db.Q) error {
+	return db.RemoveAllQ(Collection, query)
+}
+
+// FindDuplicate returns a file in the bucket with the given content and
+// permissions that is kept until at least the given time, or nil if there is
+// none. Its s3 object can be linked to instead of uploading the same content.
+func FindDuplicate(bucket, hash, permissions string, until time.Time) (*File, error) {
+	entry, err := FindOne(ByLiveContent(bucket, hash, permissions, until))
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	for i, file := range entry.Files {
+		if file.Bucket == bucket && file.ContentHash == hash && file.Permissions == permissions &&
+			!file.Expired && (file.ExpireAt.IsZero() || file.ExpireAt.After(until)) {
+			return &entry.Files[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// MarkExpired records that a task's file with the given link was deleted.
+func MarkExpired(taskId, link string) error {
+	err := db.Update(
+		Collection,
+		bson.M{
+			TaskIdKey: taskId,
+			FilesKey: bson.M{"$elemMatch": bson.M{
+				LinkKey:    link,
+				ExpiredKey: bson.M{"$ne": true},
+			}},
+		},
+		bson.M{
+			"$set": bson.M{
+				FilesKey + ".$." + ExpiredKey: true,
+			},
+		},
+	)
+	if err == mgo.ErrNotFound {
+		// the file was already marked
+		return nil
+	}
+	return err
+}
+
+// MarkExpireFailed records a failed deletion of a task's file with the given
+// link, so that it is not retried before retryAt.
+func MarkExpireFailed(taskId, link string, retryAt time.Time) error {
+	err := db.Update(
+		Collection,
+		bson.M{
+			TaskIdKey: taskId,
+			FilesKey: bson.M{"$elemMatch": bson.M{
+				LinkKey:    link,
+				ExpiredKey: bson.M{"$ne": true},
+			}},
+		},
+		bson.M{
+			"$inc": bson.M{FilesKey + ".$." + ExpireAttemptsKey: 1},
+			"$set": bson.M{FilesKey + ".$." + ExpireRetryAtKey: retryAt},
+		},
+	)
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// uploadId returns the id of the upload of an object. Bucket names cannot
+// contain a slash, so the id is unique.
+func uploadId(bucket, key string) string {
+	return bucket + "/" + key
+}
+
+// RecordUpload records that the task is putting the object, unless another
+// task put it first, and returns the upload as recorded.
+func RecordUpload(bucket, key, taskId string) (*Upload, error) {
+	upload := &Upload{}
+	_, err := db.FindAndModify(
+		UploadCollection,
+		bson.M{
+			UploadIdKey: uploadId(bucket, key),
+		},
+		nil,
+		mgo.Change{
+			Update: bson.M{
+				"$setOnInsert": bson.M{
+					UploadBucketKey:  bucket,
+					UploadFileKeyKey: key,
+					UploadTaskIdKey:  taskId,
+					UploadCreatedKey: time.Now(),
+				},
+			},
+			Upsert:    true,
+			ReturnNew: true,
+		},
+		upload,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// FindUpload returns the recorded upload of the object, or nil if it was not
+// recorded.
+func FindUpload(bucket, key string) (*Upload, error) {
+	upload := &Upload{}
+	err := db.FindOne(
+		UploadCollection,
+		bson.M{
+			UploadIdKey: uploadId(bucket, key),
+		},
+		db.NoProjection,
+		db.NoSort,
+		upload,
+	)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
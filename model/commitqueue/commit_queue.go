@@ -0,0 +1,106 @@
+// Package commitqueue stores the queues of patches waiting to be merged into
+// their projects' branches.
+package commitqueue
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// CommitQueue is a project's queue of patches to merge. The patch at the
+// head of the queue is tested on top of the project's branch, and merged if
+// its tests pass. Items behind it wait, so that each is tested with the
+// changes of the items ahead of it already merged.
+type CommitQueue struct {
+	ProjectId string `bson:"_id" json:"project_id"`
+	Queue     []Item `bson:"queue" json:"queue"`
+}
+
+// Item is a patch waiting in a commit queue.
+type Item struct {
+	PatchId     string    `bson:"patch_id" json:"patch_id"`
+	EnqueuedBy  string    `bson:"enqueued_by" json:"enqueued_by"`
+	EnqueueTime time.Time `bson:"enqueue_time" json:"enqueue_time"`
+	// TestPatchId is the patch that tests the item on top of the branch.
+	// It is set once the item reaches the head of the queue.
+	TestPatchId string `bson:"test_patch_id,omitempty" json:"test_patch_id,omitempty"`
+}
+
+// Head returns the item at the head of the queue, or nil if the queue is
+// empty.
+func (cq *CommitQueue) Head() *Item {
+	if len(cq.Queue) == 0 {
+		return nil
+	}
+	return &cq.Queue[0]
+}
+
+// Position returns the zero-based position of the patch in the queue, or -1
+// if it is not in the queue.
+func (cq *CommitQueue) Position(patchId string) int {
+	for i, item := range cq.Queue {
+		if item.PatchId == patchId {
+			return i
+		}
+	}
+	return -1
+}
+
+// Enqueue adds the item to the back of the project's queue, making the queue
+// if the project does not have one yet. It returns the item's position.
+func Enqueue(projectId string, item Item) (int, error) {
+	cq, err := FindOne(ById(projectId))
+	if err != nil {
+		return -1, errors.Wrap(err, "error finding commit queue")
+	}
+	if cq != nil && cq.Position(item.PatchId) >= 0 {
+		return -1, errors.Errorf("patch '%s' is already in the commit queue", item.PatchId)
+	}
+
+	if err = Upsert(
+		bson.M{IdKey: projectId},
+		bson.M{"$push": bson.M{QueueKey: item}},
+	); err != nil {
+		return -1, errors.Wrap(err, "error adding to commit queue")
+	}
+
+	cq, err = FindOne(ById(projectId))
+	if err != nil {
+		return -1, errors.Wrap(err, "error finding commit queue")
+	}
+	return cq.Position(item.PatchId), nil
+}
+
+// Remove removes the patch from the project's queue. It returns whether the
+// patch was in the queue.
+func Remove(projectId, patchId string) (bool, error) {
+	err := UpdateOne(
+		bson.M{
+			IdKey:                         projectId,
+			QueueKey + "." + ItemPatchKey: patchId,
+		},
+		bson.M{"$pull": bson.M{QueueKey: bson.M{ItemPatchKey: patchId}}},
+	)
+	if err == mgo.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "error removing from commit queue")
+	}
+	return true, nil
+}
+
+// SetTestPatch records the patch that tests the queued patch, which is
+// cleared if testPatchId is empty.
+func SetTestPatch(projectId, patchId, testPatchId string) error {
+	return UpdateOne(
+		bson.M{
+			IdKey:                         projectId,
+			QueueKey + "." + ItemPatchKey: patchId,
+		},
+		bson.M{"$set": bson.M{QueueKey + ".$." + ItemTestPatchKey: testPatchId}},
+	)
+}
@@ -0,0 +1,69 @@
+package commitqueue
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func init() {
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testutil.TestConfig()))
+}
+
+func TestCommitQueue(t *testing.T) {
+	Convey("With an empty commit queue collection", t, func() {
+		testutil.HandleTestingErr(db.Clear(Collection), t, "error clearing collection")
+
+		Convey("enqueued patches should be queued in order", func() {
+			position, err := Enqueue("proj", Item{PatchId: "p1", EnqueuedBy: "me"})
+			So(err, ShouldBeNil)
+			So(position, ShouldEqual, 0)
+			position, err = Enqueue("proj", Item{PatchId: "p2", EnqueuedBy: "me"})
+			So(err, ShouldBeNil)
+			So(position, ShouldEqual, 1)
+
+			cq, err := FindOne(ById("proj"))
+			So(err, ShouldBeNil)
+			So(cq.Head().PatchId, ShouldEqual, "p1")
+			So(cq.Position("p2"), ShouldEqual, 1)
+			So(cq.Position("p3"), ShouldEqual, -1)
+
+			Convey("a patch should not be queued twice", func() {
+				_, err = Enqueue("proj", Item{PatchId: "p2", EnqueuedBy: "me"})
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("a queued patch's test patch should be recorded", func() {
+				So(SetTestPatch("proj", "p2", "t2"), ShouldBeNil)
+				cq, err = FindOne(ById("proj"))
+				So(err, ShouldBeNil)
+				So(cq.Queue[0].TestPatchId, ShouldEqual, "")
+				So(cq.Queue[1].TestPatchId, ShouldEqual, "t2")
+			})
+
+			Convey("removing a patch should move the patches behind it forward", func() {
+				removed, err := Remove("proj", "p1")
+				So(err, ShouldBeNil)
+				So(removed, ShouldBeTrue)
+				removed, err = Remove("proj", "p1")
+				So(err, ShouldBeNil)
+				So(removed, ShouldBeFalse)
+
+				cq, err = FindOne(ById("proj"))
+				So(err, ShouldBeNil)
+				So(cq.Head().PatchId, ShouldEqual, "p2")
+
+				queues, err := Find(NonEmpty())
+				So(err, ShouldBeNil)
+				So(len(queues), ShouldEqual, 1)
+				_, err = Remove("proj", "p2")
+				So(err, ShouldBeNil)
+				queues, err = Find(NonEmpty())
+				So(err, ShouldBeNil)
+				So(len(queues), ShouldEqual, 0)
+			})
+		})
+	})
+}
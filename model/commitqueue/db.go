@@ -0,0 +1,67 @@
+package commitqueue
+
+import (
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/db/bsonutil"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const Collection = "commit_queues"
+
+var (
+	// BSON fields for commit queues
+	IdKey    = bsonutil.MustHaveTag(CommitQueue{}, "ProjectId")
+	QueueKey = bsonutil.MustHaveTag(CommitQueue{}, "Queue")
+
+	// BSON fields for commit queue items
+	ItemPatchKey     = bsonutil.MustHaveTag(Item{}, "PatchId")
+	ItemTestPatchKey = bsonutil.MustHaveTag(Item{}, "TestPatchId")
+)
+
+// === Queries ===
+
+// ById returns a query for the commit queue of the given project.
+func ById(projectId string) db.Q {
+	return db.Query(bson.M{IdKey: projectId})
+}
+
+// NonEmpty returns a query for the commit queues with items in them.
+func NonEmpty() db.Q {
+	return db.Query(bson.M{QueueKey + ".0": bson.M{"$exists": true}})
+}
+
+// ByPatch returns a query for the commit queue that holds the given patch.
+func ByPatch(patchId string) db.Q {
+	return db.Query(bson.M{QueueKey + "." + ItemPatchKey: patchId})
+}
+
+// === DB Logic ===
+
+// FindOne gets one commit queue for the given query.
+func FindOne(query db.Q) (*CommitQueue, error) {
+	cq := &CommitQueue{}
+	err := db.FindOneQ(Collection, query, cq)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	return cq, err
+}
+
+// Find gets every commit queue matching the given query.
+func Find(query db.Q) ([]CommitQueue, error) {
+	queues := []CommitQueue{}
+	err := db.FindAllQ(Collection, query, &queues)
+	return queues, err
+}
+
+// UpdateOne updates one commit queue.
+func UpdateOne(query interface{}, update interface{}) error {
+	return db.Update(Collection, query, update)
+}
+
+// Upsert updates one commit queue, inserting it if it does not exist.
+func Upsert(query interface{}, update interface{}) error {
+	_, err := db.Upsert(Collection, query, update)
+	return err
+}
@@ -0,0 +1,246 @@
+package coverage
+
+import (
+	"path"
+	"sort"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/pkg/errors"
+)
+
+const Collection = "coverage"
+
+// FileCoverage is how many of the statements or lines in one source file
+// were run by a task.
+type FileCoverage struct {
+	Name    string `bson:"name" json:"name"`
+	Package string `bson:"package" json:"package"`
+	Covered int    `bson:"covered" json:"covered"`
+	Total   int    `bson:"total" json:"total"`
+}
+
+// PackageCoverage sums the coverage of the files in a package or directory.
+type PackageCoverage struct {
+	Name    string `bson:"name" json:"name"`
+	Covered int    `bson:"covered" json:"covered"`
+	Total   int    `bson:"total" json:"total"`
+}
+
+// TaskCoverage is the coverage attached to a task, along with enough about
+// the task to find the coverage of the same task on other commits.
+type TaskCoverage struct {
+	TaskId              string            `bson:"_id" json:"task_id"`
+	TaskName            string            `bson:"task_name" json:"task_name"`
+	BuildVariant        string            `bson:"build_variant" json:"build_variant"`
+	Project             string            `bson:"project" json:"project"`
+	Version             string            `bson:"version" json:"version"`
+	Revision            string            `bson:"revision" json:"revision"`
+	RevisionOrderNumber int               `bson:"order" json:"order"`
+	IsPatch             bool              `bson:"is_patch" json:"is_patch"`
+	CreateTime          time.Time         `bson:"create_time" json:"create_time"`
+	Covered             int               `bson:"covered" json:"covered"`
+	Total               int               `bson:"total" json:"total"`
+	Packages            []PackageCoverage `bson:"packages" json:"packages"`
+	Files               []FileCoverage    `bson:"files" json:"files"`
+}
+
+// Percent returns the percentage of covered out of total, or 0 when there
+// is nothing to cover.
+func Percent(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(covered) / float64(total)
+}
+
+// Percent returns the task's overall coverage percentage.
+func (c *TaskCoverage) Percent() float64 {
+	return Percent(c.Covered, c.Total)
+}
+
+// New returns the coverage of the given files for a task.
+func New(t *task.Task, files []FileCoverage) *TaskCoverage {
+	// only commits tracked by the repotracker make up the history, so
+	// triggered, periodic and tag versions are left out like patches
+	c := &TaskCoverage{
+		TaskId:              t.Id,
+		TaskName:            t.DisplayName,
+		BuildVariant:        t.BuildVariant,
+		Project:             t.Project,
+		Version:             t.Version,
+		Revision:            t.Revision,
+		RevisionOrderNumber: t.RevisionOrderNumber,
+		IsPatch:             t.Requester != evergreen.RepotrackerVersionRequester,
+		CreateTime:          t.CreateTime,
+	}
+	c.Merge(files)
+	return c
+}
+
+// Merge adds the files to the task's coverage, replacing any files with the
+// same names, and recomputes the package and overall totals.
+func (c *TaskCoverage) Merge(files []FileCoverage) {
+	byName := map[string]FileCoverage{}
+	for _, f := range c.Files {
+		byName[f.Name] = f
+	}
+	for _, f := range files {
+		if f.Package == "" {
+			f.Package = path.Dir(f.Name)
+		}
+		byName[f.Name] = f
+	}
+
+	c.Files = make([]FileCoverage, 0, len(byName))
+	for _, f := range byName {
+		c.Files = append(c.Files, f)
+	}
+	sort.Sort(filesByName(c.Files))
+
+	c.Covered, c.Total = 0, 0
+	packages := map[string]*PackageCoverage{}
+	c.Packages = []PackageCoverage{}
+	for _, f := range c.Files {
+		c.Covered += f.Covered
+		c.Total += f.Total
+		pkg, ok := packages[f.Package]
+		if !ok {
+			pkg = &PackageCoverage{Name: f.Package}
+			packages[f.Package] = pkg
+		}
+		pkg.Covered += f.Covered
+		pkg.Total += f.Total
+	}
+	for _, pkg := range packages {
+		c.Packages = append(c.Packages, *pkg)
+	}
+	sort.Sort(packagesByName(c.Packages))
+}
+
+// FileDelta is the change in coverage of one file between two tasks. A file
+// that only exists in one of them has zeroes for the other.
+type FileDelta struct {
+	Name        string  `json:"name"`
+	BaseCovered int     `json:"base_covered"`
+	BaseTotal   int     `json:"base_total"`
+	Covered     int     `json:"covered"`
+	Total       int     `json:"total"`
+	Change      float64 `json:"change"`
+}
+
+// Delta is the change in coverage between a task and the same task on its
+// base commit.
+type Delta struct {
+	BasePercent float64     `json:"base_percent"`
+	Percent     float64     `json:"percent"`
+	Change      float64     `json:"change"`
+	Files       []FileDelta `json:"files"`
+}
+
+// Diff returns how the coverage changed from base to current. Only the files
+// whose coverage changed are included.
+func Diff(base, current *TaskCoverage) Delta {
+	d := Delta{
+		BasePercent: base.Percent(),
+		Percent:     current.Percent(),
+		Files:       []FileDelta{},
+	}
+	d.Change = d.Percent - d.BasePercent
+
+	files := map[string]*FileDelta{}
+	for _, f := range base.Files {
+		files[f.Name] = &FileDelta{Name: f.Name, BaseCovered: f.Covered, BaseTotal: f.Total}
+	}
+	for _, f := range current.Files {
+		fd, ok := files[f.Name]
+		if !ok {
+			fd = &FileDelta{Name: f.Name}
+			files[f.Name] = fd
+		}
+		fd.Covered, fd.Total = f.Covered, f.Total
+	}
+	for _, fd := range files {
+		if fd.Covered == fd.BaseCovered && fd.Total == fd.BaseTotal {
+			continue
+		}
+		fd.Change = Percent(fd.Covered, fd.Total) - Percent(fd.BaseCovered, fd.BaseTotal)
+		d.Files = append(d.Files, *fd)
+	}
+	sort.Sort(deltasByName(d.Files))
+	return d
+}
+
+type filesByName []FileCoverage
+
+func (f filesByName) Len() int           { return len(f) }
+func (f filesByName) Less(i, j int) bool { return f[i].Name < f[j].Name }
+func (f filesByName) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+
+type packagesByName []PackageCoverage
+
+func (p packagesByName) Len() int           { return len(p) }
+func (p packagesByName) Less(i, j int) bool { return p[i].Name < p[j].Name }
+func (p packagesByName) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+type deltasByName []FileDelta
+
+func (d deltasByName) Len() int           { return len(d) }
+func (d deltasByName) Less(i, j int) bool { return d[i].Name < d[j].Name }
+func (d deltasByName) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+
+// FindBase returns the coverage of the task on the base commit of the given
+// patch task, or nil if there is none.
+func FindBase(t *task.Task) (*TaskCoverage, error) {
+	base, err := t.FindTaskOnBaseCommit()
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding task on base commit")
+	}
+	if base == nil {
+		return nil, nil
+	}
+	return FindOne(ByTaskId(base.Id))
+}
+
+// DeltaForTask returns how a patch task changed the coverage of its base
+// commit, or nil if the task is not in a patch or either task has no
+// coverage.
+func DeltaForTask(t *task.Task) (*Delta, error) {
+	if t.Requester != evergreen.PatchVersionRequester {
+		return nil, nil
+	}
+	current, err := FindOne(ByTaskId(t.Id))
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding coverage for task %s", t.Id)
+	}
+	if current == nil {
+		return nil, nil
+	}
+	base, err := FindBase(t)
+	if err != nil || base == nil {
+		return nil, errors.Wrapf(err, "problem finding base coverage for task %s", t.Id)
+	}
+	delta := Diff(base, current)
+	return &delta, nil
+}
+
+// HistoryForTask returns the coverage of the task on up to limit mainline
+// commits, most recent first, going back from the task's commit or, for a
+// patch task, from its base commit.
+func HistoryForTask(t *task.Task, limit int) ([]TaskCoverage, error) {
+	order := t.RevisionOrderNumber
+	if t.Requester == evergreen.PatchVersionRequester {
+		// a patch's order number counts patches, not commits
+		base, err := t.FindTaskOnBaseCommit()
+		if err != nil {
+			return nil, errors.Wrap(err, "problem finding task on base commit")
+		}
+		if base == nil {
+			return []TaskCoverage{}, nil
+		}
+		order = base.RevisionOrderNumber
+	}
+	history, err := Find(History(t.Project, t.BuildVariant, t.DisplayName, order, limit))
+	return history, errors.Wrapf(err, "problem finding coverage history for task %s", t.Id)
+}
@@ -0,0 +1,192 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func init() {
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testutil.TestConfig()))
+}
+
+func reset(t *testing.T) {
+	testutil.HandleTestingErr(
+		db.ClearCollections(Collection, task.Collection),
+		t, "Error clearing collections")
+}
+
+func TestMerge(t *testing.T) {
+	Convey("With the coverage of a task", t, func() {
+		c := New(&task.Task{Id: "t1"}, []FileCoverage{
+			{Name: "util/math.go", Covered: 3, Total: 4},
+			{Name: "util/strings.go", Covered: 0, Total: 2},
+			{Name: "db/query.go", Package: "database", Covered: 1, Total: 1},
+		})
+
+		Convey("files should be summed into packages and totals", func() {
+			So(c.Covered, ShouldEqual, 4)
+			So(c.Total, ShouldEqual, 7)
+			So(c.Packages, ShouldResemble, []PackageCoverage{
+				{Name: "database", Covered: 1, Total: 1},
+				{Name: "util", Covered: 3, Total: 6},
+			})
+		})
+
+		Convey("merging should replace files with the same name", func() {
+			c.Merge([]FileCoverage{
+				{Name: "util/strings.go", Covered: 2, Total: 2},
+				{Name: "main.go", Covered: 0, Total: 5},
+			})
+			So(len(c.Files), ShouldEqual, 4)
+			So(c.Covered, ShouldEqual, 6)
+			So(c.Total, ShouldEqual, 12)
+			So(c.Packages[0], ShouldResemble, PackageCoverage{Name: ".", Covered: 0, Total: 5})
+			So(c.Packages[2], ShouldResemble, PackageCoverage{Name: "util", Covered: 5, Total: 6})
+		})
+	})
+}
+
+func TestDiff(t *testing.T) {
+	Convey("With coverage on a base commit and in a patch", t, func() {
+		base := New(&task.Task{Id: "base"}, []FileCoverage{
+			{Name: "a.go", Covered: 5, Total: 10},
+			{Name: "b.go", Covered: 2, Total: 2},
+			{Name: "removed.go", Covered: 1, Total: 4},
+		})
+		patch := New(&task.Task{Id: "patch"}, []FileCoverage{
+			{Name: "a.go", Covered: 8, Total: 10},
+			{Name: "b.go", Covered: 2, Total: 2},
+			{Name: "added.go", Covered: 0, Total: 4},
+		})
+		delta := Diff(base, patch)
+
+		Convey("the overall change should be the difference of the percentages", func() {
+			So(delta.BasePercent, ShouldAlmostEqual, 50)
+			So(delta.Percent, ShouldAlmostEqual, 62.5)
+			So(delta.Change, ShouldAlmostEqual, 12.5)
+		})
+
+		Convey("only files whose coverage changed should be included", func() {
+			So(len(delta.Files), ShouldEqual, 3)
+			So(delta.Files[0], ShouldResemble, FileDelta{Name: "a.go",
+				BaseCovered: 5, BaseTotal: 10, Covered: 8, Total: 10, Change: 30})
+			So(delta.Files[1], ShouldResemble, FileDelta{Name: "added.go",
+				Covered: 0, Total: 4, Change: 0})
+			So(delta.Files[2], ShouldResemble, FileDelta{Name: "removed.go",
+				BaseCovered: 1, BaseTotal: 4, Change: -25})
+		})
+	})
+}
+
+func TestAttachAndHistory(t *testing.T) {
+	Convey("With tasks on several commits and a patch", t, func() {
+		reset(t)
+
+		tasks := []task.Task{}
+		for i, id := range []string{"c1", "c2", "c3"} {
+			tasks = append(tasks, task.Task{
+				Id:                  id,
+				DisplayName:         "unit",
+				BuildVariant:        "linux",
+				Project:             "evergreen",
+				Revision:            id,
+				RevisionOrderNumber: i + 1,
+				Requester:           evergreen.RepotrackerVersionRequester,
+			})
+		}
+		patchTask := task.Task{
+			Id:                  "p1",
+			DisplayName:         "unit",
+			BuildVariant:        "linux",
+			Project:             "evergreen",
+			Revision:            "c2",
+			RevisionOrderNumber: 100,
+			Requester:           evergreen.PatchVersionRequester,
+		}
+		for _, tk := range append(tasks, patchTask) {
+			So(tk.Insert(), ShouldBeNil)
+		}
+
+		for i := range tasks {
+			_, err := Attach(&tasks[i], []FileCoverage{{Name: "a.go", Covered: i + 1, Total: 4}})
+			So(err, ShouldBeNil)
+		}
+
+		Convey("attaching twice in a task should merge the files", func() {
+			c, err := Attach(&patchTask, []FileCoverage{{Name: "a.go", Covered: 4, Total: 4}})
+			So(err, ShouldBeNil)
+			So(c.IsPatch, ShouldBeTrue)
+			_, err = Attach(&patchTask, []FileCoverage{{Name: "b.go", Covered: 0, Total: 4}})
+			So(err, ShouldBeNil)
+
+			c, err = FindOne(ByTaskId("p1"))
+			So(err, ShouldBeNil)
+			So(len(c.Files), ShouldEqual, 2)
+			So(c.Covered, ShouldEqual, 4)
+			So(c.Total, ShouldEqual, 8)
+
+			Convey("and the patch should be compared to its base commit", func() {
+				delta, err := DeltaForTask(&patchTask)
+				So(err, ShouldBeNil)
+				So(delta, ShouldNotBeNil)
+				So(delta.BasePercent, ShouldAlmostEqual, 50)
+				So(delta.Change, ShouldAlmostEqual, 0)
+				So(len(delta.Files), ShouldEqual, 2)
+			})
+		})
+
+		Convey("mainline tasks should have no delta", func() {
+			delta, err := DeltaForTask(&tasks[2])
+			So(err, ShouldBeNil)
+			So(delta, ShouldBeNil)
+		})
+
+		Convey("history should go back from the task's commit, leaving out patches", func() {
+			_, err := Attach(&patchTask, []FileCoverage{{Name: "a.go", Covered: 4, Total: 4}})
+			So(err, ShouldBeNil)
+
+			history, err := HistoryForTask(&tasks[2], 10)
+			So(err, ShouldBeNil)
+			So(len(history), ShouldEqual, 3)
+			So(history[0].TaskId, ShouldEqual, "c3")
+			So(history[2].TaskId, ShouldEqual, "c1")
+			So(history[0].Files, ShouldBeEmpty)
+
+			history, err = HistoryForTask(&patchTask, 10)
+			So(err, ShouldBeNil)
+			So(len(history), ShouldEqual, 2)
+			So(history[0].TaskId, ShouldEqual, "c2")
+
+			history, err = Find(History("evergreen", "linux", "unit", 0, 1))
+			So(err, ShouldBeNil)
+			So(len(history), ShouldEqual, 1)
+			So(history[0].TaskId, ShouldEqual, "c3")
+		})
+
+		Convey("versions outside the commit history should be left out of it", func() {
+			for _, requester := range []string{evergreen.TriggerRequester,
+				evergreen.PeriodicBuildRequester, evergreen.GitTagRequester} {
+				other := task.Task{
+					Id:           "other_" + requester,
+					DisplayName:  "unit",
+					BuildVariant: "linux",
+					Project:      "evergreen",
+					Requester:    requester,
+				}
+				So(other.Insert(), ShouldBeNil)
+				c, err := Attach(&other, []FileCoverage{{Name: "a.go", Covered: 4, Total: 4}})
+				So(err, ShouldBeNil)
+				So(c.IsPatch, ShouldBeTrue)
+			}
+
+			history, err := Find(History("evergreen", "linux", "unit", 0, 10))
+			So(err, ShouldBeNil)
+			So(len(history), ShouldEqual, 3)
+		})
+	})
+}
@@ -0,0 +1,105 @@
+package coverage
+
+import (
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/db/bsonutil"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	// BSON fields for the task coverage struct
+	TaskIdKey              = bsonutil.MustHaveTag(TaskCoverage{}, "TaskId")
+	TaskNameKey            = bsonutil.MustHaveTag(TaskCoverage{}, "TaskName")
+	BuildVariantKey        = bsonutil.MustHaveTag(TaskCoverage{}, "BuildVariant")
+	ProjectKey             = bsonutil.MustHaveTag(TaskCoverage{}, "Project")
+	VersionKey             = bsonutil.MustHaveTag(TaskCoverage{}, "Version")
+	RevisionKey            = bsonutil.MustHaveTag(TaskCoverage{}, "Revision")
+	RevisionOrderNumberKey = bsonutil.MustHaveTag(TaskCoverage{}, "RevisionOrderNumber")
+	IsPatchKey             = bsonutil.MustHaveTag(TaskCoverage{}, "IsPatch")
+	CreateTimeKey          = bsonutil.MustHaveTag(TaskCoverage{}, "CreateTime")
+	CoveredKey             = bsonutil.MustHaveTag(TaskCoverage{}, "Covered")
+	TotalKey               = bsonutil.MustHaveTag(TaskCoverage{}, "Total")
+	PackagesKey            = bsonutil.MustHaveTag(TaskCoverage{}, "Packages")
+	FilesKey               = bsonutil.MustHaveTag(TaskCoverage{}, "Files")
+)
+
+// === Queries ===
+
+// ByTaskId returns a query for the coverage of the given task.
+func ByTaskId(id string) db.Q {
+	return db.Query(bson.M{TaskIdKey: id})
+}
+
+// ByVersion returns a query for the coverage of every task in a version,
+// without the per-file breakdown.
+func ByVersion(version string) db.Q {
+	return db.Query(bson.M{VersionKey: version}).
+		WithoutFields(FilesKey).
+		Sort([]string{BuildVariantKey, TaskNameKey})
+}
+
+// History returns a query for the coverage of a task on the project's
+// mainline commits, most recent first, going back from the given revision
+// order number. The per-file breakdown is left out; a beforeOrder of 0
+// starts from the latest commit.
+func History(project, variant, taskName string, beforeOrder, limit int) db.Q {
+	q := bson.M{
+		ProjectKey:      project,
+		BuildVariantKey: variant,
+		TaskNameKey:     taskName,
+		IsPatchKey:      false,
+	}
+	if beforeOrder > 0 {
+		q[RevisionOrderNumberKey] = bson.M{"$lte": beforeOrder}
+	}
+	return db.Query(q).
+		WithoutFields(FilesKey).
+		Sort([]string{"-" + RevisionOrderNumberKey}).
+		Limit(limit)
+}
+
+// === DB Logic ===
+
+// Attach adds the files to the task's coverage, merging them with any
+// coverage attached earlier in the task, and saves the result.
+func Attach(t *task.Task, files []FileCoverage) (*TaskCoverage, error) {
+	c, err := FindOne(ByTaskId(t.Id))
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding coverage for task %s", t.Id)
+	}
+	if c == nil {
+		c = New(t, files)
+	} else {
+		c.Merge(files)
+	}
+	if err = c.Upsert(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Upsert replaces the task's coverage document, creating it if needed.
+func (c *TaskCoverage) Upsert() error {
+	_, err := db.Upsert(Collection, bson.M{TaskIdKey: c.TaskId}, c)
+	return errors.Wrapf(err, "problem saving coverage for task %s", c.TaskId)
+}
+
+// FindOne gets one TaskCoverage for the given query.
+func FindOne(query db.Q) (*TaskCoverage, error) {
+	c := &TaskCoverage{}
+	err := db.FindOneQ(Collection, query, c)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	return c, err
+}
+
+// Find gets every TaskCoverage for the given query.
+func Find(query db.Q) ([]TaskCoverage, error) {
+	coverage := []TaskCoverage{}
+	err := db.FindAllQ(Collection, query, &coverage)
+	return coverage, err
+}
@@ -25,6 +25,8 @@ var (
 	SpawnAllowedKey = bsonutil.MustHaveTag(Distro{}, "SpawnAllowed")
 	ExpansionsKey   = bsonutil.MustHaveTag(Distro{}, "Expansions")
 
+	ResourceLimitsKey = bsonutil.MustHaveTag(Distro{}, "ResourceLimits")
+
 	// bson fields for the UserData struct
 	UserDataFileKey     = bsonutil.MustHaveTag(UserData{}, "File")
 	UserDataValidateKey = bsonutil.MustHaveTag(UserData{}, "Validate")
@@ -25,6 +25,8 @@ type Distro struct {
 
 	SpawnAllowed bool        `bson:"spawn_allowed" json:"spawn_allowed,omitempty" mapstructure:"spawn_allowed,omitempty"`
 	Expansions   []Expansion `bson:"expansions,omitempty" json:"expansions,omitempty" mapstructure:"expansions,omitempty"`
+
+	ResourceLimits ResourceLimits `bson:"resource_limits,omitempty" json:"resource_limits,omitempty" mapstructure:"resource_limits,omitempty"`
 }
 
 type ValidateFormat string
@@ -38,3 +40,43 @@ type Expansion struct {
 	Key   string `bson:"key,omitempty" json:"key,omitempty"`
 	Value string `bson:"value,omitempty" json:"value,omitempty"`
 }
+
+// ResourceLimits describes the maximum resources that the processes of a
+// single task may consume on a host. A zero value for any field means that
+// the resource is not limited. Limits are only enforced on Linux, where the
+// agent places each task's processes in their own cgroup.
+type ResourceLimits struct {
+	// MemoryLimitMB is the maximum resident memory of all task processes, in megabytes.
+	MemoryLimitMB int `bson:"memory_limit_mb,omitempty" json:"memory_limit_mb,omitempty" mapstructure:"memory_limit_mb,omitempty" yaml:"memory_limit_mb,omitempty"`
+
+	// CPUPercent is the maximum CPU time available to the task, as a
+	// percentage of a single core (e.g. 200 allows two full cores).
+	CPUPercent int `bson:"cpu_percent,omitempty" json:"cpu_percent,omitempty" mapstructure:"cpu_percent,omitempty" yaml:"cpu_percent,omitempty"`
+
+	// PidsLimit is the maximum number of processes and threads the task may run at once.
+	PidsLimit int `bson:"pids_limit,omitempty" json:"pids_limit,omitempty" mapstructure:"pids_limit,omitempty" yaml:"pids_limit,omitempty"`
+}
+
+// IsZero returns true if no resource is limited.
+func (r ResourceLimits) IsZero() bool {
+	return r.MemoryLimitMB == 0 && r.CPUPercent == 0 && r.PidsLimit == 0
+}
+
+// Merge returns a copy of the limits with every non-zero field in
+// override taking precedence. It is used to apply task-level limits on
+// top of the limits configured for the distro.
+func (r ResourceLimits) Merge(override *ResourceLimits) ResourceLimits {
+	if override == nil {
+		return r
+	}
+	if override.MemoryLimitMB != 0 {
+		r.MemoryLimitMB = override.MemoryLimitMB
+	}
+	if override.CPUPercent != 0 {
+		r.CPUPercent = override.CPUPercent
+	}
+	if override.PidsLimit != 0 {
+		r.PidsLimit = override.PidsLimit
+	}
+	return r
+}
@@ -1,6 +1,8 @@
 package event
 
 import (
+	"time"
+
 	"github.com/evergreen-ci/evergreen/db"
 	"gopkg.in/mgo.v2/bson"
 )
@@ -15,8 +17,32 @@ func Find(coll string, query db.Q) ([]Event, error) {
 	return events, err
 }
 
+// Count returns how many events in a collection match a query.
+func Count(coll string, query db.Q) (int, error) {
+	return db.CountQ(coll, query)
+}
+
+// Remove deletes the events in a collection that match a query.
+func Remove(coll string, query db.Q) error {
+	return db.RemoveAllQ(coll, query)
+}
+
 // === Queries ===
 
+// ByResourceId returns every event of a resource, whatever its type.
+func ByResourceId(id string) db.Q {
+	return db.Query(bson.M{ResourceIdKey: id})
+}
+
+// NonTaskEventsOlderThan returns the events logged before a time, except for
+// task events, which are kept as long as the rest of their task's data.
+func NonTaskEventsOlderThan(ts time.Time) db.Q {
+	return db.Query(bson.M{
+		TimestampKey:                    bson.M{"$lt": ts},
+		DataKey + "." + ResourceTypeKey: bson.M{"$ne": ResourceTypeTask},
+	})
+}
+
 // Host Events
 func HostEventsForId(id string) db.Q {
 	return db.Query(bson.D{
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"github.com/pkg/errors"
+)
+
+// GitTag is a tag in a project's repository, along with the commit that it
+// points to.
+type GitTag struct {
+	Name     string
+	Revision string
+}
+
+// GitTagDefinition creates a version of the project at the commit of each
+// new tag in its repository whose name matches the definition's pattern,
+// such as the tag of a release.
+type GitTagDefinition struct {
+	// Pattern is a regular expression matched against whole tag names.
+	Pattern string `bson:"pattern" json:"pattern"`
+	// ConfigFile is the path of the config file that the versions use. It
+	// defaults to the project's own config file.
+	ConfigFile string `bson:"config_file,omitempty" json:"config_file,omitempty"`
+	// Alias names the project alias that selects the variants and tasks that
+	// the versions run, such as the release variants. Without one, every
+	// variant runs.
+	Alias string `bson:"alias,omitempty" json:"alias,omitempty"`
+}
+
+// Validate checks the definition's pattern and alias.
+func (d *GitTagDefinition) Validate(ref *ProjectRef) error {
+	if d.Pattern == "" {
+		return errors.New("git tag definition must have a pattern")
+	}
+	if _, err := compileWholeName(d.Pattern); err != nil {
+		return errors.Wrapf(err, "invalid git tag pattern '%s'", d.Pattern)
+	}
+	if d.Alias != "" && len(ref.FindAliases(d.Alias)) == 0 {
+		return errors.Errorf("project '%s' has no alias '%s'", ref.Identifier, d.Alias)
+	}
+	return nil
+}
+
+// Matches returns whether the tag with the given name creates a version.
+func (d *GitTagDefinition) Matches(tagName string) bool {
+	regex, err := compileWholeName(d.Pattern)
+	if err != nil {
+		return false
+	}
+	return regex.MatchString(tagName)
+}
+
+// ValidateGitTagVersions checks each of the project's git tag definitions.
+func (projectRef *ProjectRef) ValidateGitTagVersions() error {
+	for _, d := range projectRef.GitTagVersions {
+		if err := d.Validate(projectRef); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindGitTagVersion returns the first of the project's git tag definitions
+// that the tag matches, or nil if none does.
+func (projectRef *ProjectRef) FindGitTagVersion(tagName string) *GitTagDefinition {
+	for i := range projectRef.GitTagVersions {
+		if projectRef.GitTagVersions[i].Matches(tagName) {
+			return &projectRef.GitTagVersions[i]
+		}
+	}
+	return nil
+}
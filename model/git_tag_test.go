@@ -0,0 +1,37 @@
+package model
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGitTagDefinitions(t *testing.T) {
+	Convey("With a project that creates versions for release tags", t, func() {
+		ref := &ProjectRef{
+			Identifier: "proj",
+			Aliases:    []ProjectAlias{{Alias: "release", Variant: ".*", Tasks: []string{"package"}}},
+			GitTagVersions: []GitTagDefinition{
+				{Pattern: `r[0-9]+\.[0-9]+\.[0-9]+`, Alias: "release"},
+				{Pattern: `rc-.*`},
+			},
+		}
+		So(ref.ValidateGitTagVersions(), ShouldBeNil)
+
+		Convey("tags should match the first definition whose pattern matches their whole name", func() {
+			So(ref.FindGitTagVersion("r3.4.1").Alias, ShouldEqual, "release")
+			So(ref.FindGitTagVersion("rc-1"), ShouldNotBeNil)
+			So(ref.FindGitTagVersion("r3.4.1-rc0"), ShouldBeNil)
+			So(ref.FindGitTagVersion("v1"), ShouldBeNil)
+		})
+
+		Convey("definitions without a valid pattern or with an unknown alias should be invalid", func() {
+			ref.GitTagVersions = []GitTagDefinition{{}}
+			So(ref.ValidateGitTagVersions(), ShouldNotBeNil)
+			ref.GitTagVersions = []GitTagDefinition{{Pattern: "r[0-9"}}
+			So(ref.ValidateGitTagVersions(), ShouldNotBeNil)
+			ref.GitTagVersions = []GitTagDefinition{{Pattern: "r.*", Alias: "missing"}}
+			So(ref.ValidateGitTagVersions(), ShouldNotBeNil)
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package patch
 
 import (
+	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/db"
 	"github.com/evergreen-ci/evergreen/db/bsonutil"
 	"gopkg.in/mgo.v2"
@@ -10,8 +11,18 @@ import (
 const (
 	Collection   = "patches"
 	GridFSPrefix = "patchfiles"
+
+	// NumbersCollection holds the counters that number patches whose author
+	// is not a user, like the patches of GitHub pull requests.
+	NumbersCollection = "patch_numbers"
 )
 
+// patchNumber is the counter of the patches of one author.
+type patchNumber struct {
+	Author string `bson:"_id"`
+	Number int    `bson:"number"`
+}
+
 // BSON fields for the patches
 var (
 	IdKey            = bsonutil.MustHaveTag(Patch{}, "Id")
@@ -31,6 +42,16 @@ var (
 	PatchesKey       = bsonutil.MustHaveTag(Patch{}, "Patches")
 	ActivatedKey     = bsonutil.MustHaveTag(Patch{}, "Activated")
 	PatchedConfigKey = bsonutil.MustHaveTag(Patch{}, "PatchedConfig")
+	AliasKey         = bsonutil.MustHaveTag(Patch{}, "Alias")
+
+	GithubPatchDataKey = bsonutil.MustHaveTag(Patch{}, "GithubPatchData")
+
+	// BSON fields for the github patch struct
+	GithubPatchPRNumberKey      = bsonutil.MustHaveTag(GithubPatch{}, "PRNumber")
+	GithubPatchBaseOwnerKey     = bsonutil.MustHaveTag(GithubPatch{}, "BaseOwner")
+	GithubPatchBaseRepoKey      = bsonutil.MustHaveTag(GithubPatch{}, "BaseRepo")
+	GithubPatchStatusesKey      = bsonutil.MustHaveTag(GithubPatch{}, "Statuses")
+	GithubPatchStatusesFinalKey = bsonutil.MustHaveTag(GithubPatch{}, "StatusesFinal")
 
 	// BSON fields for the module patch struct
 	ModulePatchNameKey    = bsonutil.MustHaveTag(ModulePatch{}, "ModuleName")
@@ -96,6 +117,26 @@ func ByVersions(versions []string) db.Q {
 	return db.Query(bson.M{VersionKey: bson.M{"$in": versions}})
 }
 
+// ByGithubPR produces a query that returns the project's patches of the
+// given GitHub pull request.
+func ByGithubPR(project, owner, repo string, number int) db.Q {
+	return db.Query(bson.M{
+		ProjectKey: project,
+		GithubPatchDataKey + "." + GithubPatchBaseOwnerKey: owner,
+		GithubPatchDataKey + "." + GithubPatchBaseRepoKey:  repo,
+		GithubPatchDataKey + "." + GithubPatchPRNumberKey:  number,
+	})
+}
+
+// WithUnfinishedGithubStatuses produces a query that returns the activated
+// pull request patches whose final commit statuses have not been posted yet.
+func WithUnfinishedGithubStatuses() db.Q {
+	return db.Query(bson.M{
+		ActivatedKey: true,
+		GithubPatchDataKey + "." + GithubPatchStatusesFinalKey: false,
+	})
+}
+
 // ExcludePatchDiff is a projection that excludes diff data, helping load times.
 var ExcludePatchDiff = bson.D{
 	{PatchesKey + "." + ModulePatchSetKey + "." + PatchSetPatchKey, 0},
@@ -103,6 +144,34 @@ var ExcludePatchDiff = bson.D{
 
 // Query Functions
 
+// GetNewGithubPatchNumber returns the number of a new patch of a GitHub pull
+// request. The patches are counted apart from the patches of users, since
+// there is no user document for their author.
+func GetNewGithubPatchNumber() (int, error) {
+	counter := &patchNumber{}
+	_, err := db.FindAndModify(
+		NumbersCollection,
+		bson.M{
+			"_id": evergreen.GithubPatchUser,
+		},
+		nil,
+		mgo.Change{
+			Update: bson.M{
+				"$inc": bson.M{
+					"number": 1,
+				},
+			},
+			Upsert:    true,
+			ReturnNew: true,
+		},
+		counter,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return counter.Number, nil
+}
+
 // FindOne runs a patch query, returning one patch.
 func FindOne(query db.Q) (*Patch, error) {
 	patch := &Patch{}
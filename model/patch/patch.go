@@ -38,6 +38,30 @@ type Patch struct {
 	Patches       []ModulePatch  `bson:"patches"`
 	Activated     bool           `bson:"activated"`
 	PatchedConfig string         `bson:"patched_config"`
+
+	// Alias is the project alias that selected the patch's variants and tasks
+	Alias string `bson:"alias,omitempty"`
+
+	// GithubPatchData is set on patches that test a GitHub pull request
+	GithubPatchData *GithubPatch `bson:"github_patch_data,omitempty"`
+}
+
+// GithubPatch describes the GitHub pull request that a patch tests.
+type GithubPatch struct {
+	PRNumber   int    `bson:"pr_number"`
+	BaseOwner  string `bson:"base_owner"`
+	BaseRepo   string `bson:"base_repo"`
+	BaseBranch string `bson:"base_branch"`
+	HeadOwner  string `bson:"head_owner"`
+	HeadRepo   string `bson:"head_repo"`
+	HeadHash   string `bson:"head_hash"`
+	Author     string `bson:"author"`
+
+	// Statuses are the states of the commit statuses last posted to the
+	// pull request's head commit, keyed by status context.
+	Statuses map[string]string `bson:"statuses,omitempty"`
+	// StatusesFinal is set once the posted statuses are all final.
+	StatusesFinal bool `bson:"statuses_final"`
 }
 
 // this stores request details for a patch
@@ -235,6 +259,37 @@ func (p *Patch) ConfigChanged(remotePath string) bool {
 	return false
 }
 
+// FilesChanged returns the names of the files that the patch changes in the
+// project's own repository.
+func (p *Patch) FilesChanged() []string {
+	files := []string{}
+	for _, patchPart := range p.Patches {
+		if patchPart.ModuleName != "" {
+			continue
+		}
+		for _, summary := range patchPart.PatchSet.Summary {
+			files = append(files, summary.Name)
+		}
+	}
+	return files
+}
+
+// SetGithubStatuses records the commit statuses posted to the head commit of
+// the patch's pull request, and whether they are all final.
+func (p *Patch) SetGithubStatuses(statuses map[string]string, final bool) error {
+	p.GithubPatchData.Statuses = statuses
+	p.GithubPatchData.StatusesFinal = final
+	return UpdateOne(
+		bson.M{IdKey: p.Id},
+		bson.M{
+			"$set": bson.M{
+				GithubPatchDataKey + "." + GithubPatchStatusesKey:      statuses,
+				GithubPatchDataKey + "." + GithubPatchStatusesFinalKey: final,
+			},
+		},
+	)
+}
+
 // SetActivated sets the patch to activated in the db
 func (p *Patch) SetActivated(versionId string) error {
 	p.Version = versionId
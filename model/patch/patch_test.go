@@ -3,9 +3,18 @@ package patch
 import (
 	"testing"
 
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/testutil"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+var testConfig = testutil.TestConfig()
+
+func init() {
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testConfig))
+}
+
 func TestConfigChanged(t *testing.T) {
 
 	Convey("With calling ConfigChanged with a remote configuration "+
@@ -44,3 +53,26 @@ func TestConfigChanged(t *testing.T) {
 		})
 	})
 }
+
+func TestGetNewGithubPatchNumber(t *testing.T) {
+	Convey("With no pull request patches numbered yet", t, func() {
+		testutil.HandleTestingErr(db.ClearCollections(NumbersCollection, user.Collection), t,
+			"error clearing collections")
+
+		Convey("each new patch should get the next number", func() {
+			for i := 1; i <= 3; i++ {
+				number, err := GetNewGithubPatchNumber()
+				So(err, ShouldBeNil)
+				So(number, ShouldEqual, i)
+			}
+		})
+
+		Convey("no user should be made to count them", func() {
+			_, err := GetNewGithubPatchNumber()
+			So(err, ShouldBeNil)
+			count, err := db.Count(user.Collection, nil)
+			So(err, ShouldBeNil)
+			So(count, ShouldEqual, 0)
+		})
+	})
+}
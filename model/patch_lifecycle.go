@@ -314,13 +314,22 @@ func FinalizePatch(p *patch.Patch, settings *evergreen.Settings) (*version.Versi
 		p.VariantsTasks = TVPairsToVariantTasks(pairs)
 	}
 
-	tt := NewPatchTaskIdTable(project, patchVersion, pairs)
+	// skip the tasks whose paths none of the patch's changes match
+	var runPairs TVPairSet
+	runPairs, patchVersion.SkippedTasks = project.FilterByChangedFiles(pairs, p.FilesChanged())
+
+	tt := NewPatchTaskIdTable(project, patchVersion, runPairs)
 	variantsProcessed := map[string]bool{}
 	for _, vt := range p.VariantsTasks {
 		if _, ok := variantsProcessed[vt.Variant]; ok {
 			continue
 		}
-		buildId, err := CreateBuildFromVersion(project, patchVersion, tt, vt.Variant, true, vt.Tasks)
+		variantsProcessed[vt.Variant] = true
+		taskNames := runPairs.TaskNames(vt.Variant)
+		if len(taskNames) == 0 {
+			continue
+		}
+		buildId, err := CreateBuildFromVersion(project, patchVersion, tt, vt.Variant, true, taskNames)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -334,12 +343,24 @@ func FinalizePatch(p *patch.Patch, settings *evergreen.Settings) (*version.Versi
 		)
 	}
 
+	// a patch whose tasks were all skipped has nothing left to run, so it
+	// is finished as soon as it starts
+	finished := len(patchVersion.BuildIds) == 0
+	if finished {
+		patchVersion.Status = evergreen.PatchSucceeded
+	}
 	if err = patchVersion.Insert(); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	if err = p.SetActivated(patchVersion.Id); err != nil {
 		return nil, errors.WithStack(err)
 	}
+	if finished {
+		p.Status, p.FinishTime = evergreen.PatchSucceeded, time.Now()
+		if err = patch.TryMarkFinished(patchVersion.Id, p.FinishTime, p.Status); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
 	return patchVersion, nil
 }
 
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/db/bsonutil"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// PeriodicBuildDefinition creates a version of the project from the head of
+// its branch on a cron schedule, whether or not there are new commits.
+type PeriodicBuildDefinition struct {
+	// ID identifies the definition, so that its schedule can be tracked.
+	ID string `bson:"id" json:"id"`
+	// Cron is a five field cron expression, which is evaluated in UTC.
+	Cron string `bson:"cron" json:"cron"`
+	// ConfigFile is the path of the config file that the versions use. It
+	// defaults to the project's own config file.
+	ConfigFile string `bson:"config_file,omitempty" json:"config_file,omitempty"`
+	// Alias names the project alias that selects the variants and tasks that
+	// the versions run. Without one, every variant runs.
+	Alias string `bson:"alias,omitempty" json:"alias,omitempty"`
+	// Message describes the versions, such as "nightly build".
+	Message string `bson:"message,omitempty" json:"message,omitempty"`
+	// NextRunTime is when the next version is created.
+	NextRunTime time.Time `bson:"next_run_time,omitempty" json:"next_run_time,omitempty"`
+}
+
+var (
+	PeriodicBuildIDKey          = bsonutil.MustHaveTag(PeriodicBuildDefinition{}, "ID")
+	PeriodicBuildNextRunTimeKey = bsonutil.MustHaveTag(PeriodicBuildDefinition{}, "NextRunTime")
+)
+
+// Validate checks the definition's cron expression and alias.
+func (d *PeriodicBuildDefinition) Validate(ref *ProjectRef) error {
+	if _, err := util.ParseCron(d.Cron); err != nil {
+		return errors.Wrap(err, "invalid periodic build schedule")
+	}
+	if d.Alias != "" && len(ref.FindAliases(d.Alias)) == 0 {
+		return errors.Errorf("project '%s' has no alias '%s'", ref.Identifier, d.Alias)
+	}
+	return nil
+}
+
+// NextRunAfter returns the first time after t that the definition's schedule
+// matches.
+func (d *PeriodicBuildDefinition) NextRunAfter(t time.Time) (time.Time, error) {
+	schedule, err := util.ParseCron(d.Cron)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "invalid periodic build schedule")
+	}
+	next := schedule.Next(t.UTC())
+	if next.IsZero() {
+		return next, errors.Errorf("cron expression '%s' never matches", d.Cron)
+	}
+	return next, nil
+}
+
+// SetNextPeriodicBuild records when the project's periodic build with the
+// given id next runs.
+func (projectRef *ProjectRef) SetNextPeriodicBuild(id string, next time.Time) error {
+	return db.Update(
+		ProjectRefCollection,
+		bson.M{
+			ProjectRefIdentifierKey:                                projectRef.Identifier,
+			ProjectRefPeriodicBuildsKey + "." + PeriodicBuildIDKey: id,
+		},
+		bson.M{
+			"$set": bson.M{
+				ProjectRefPeriodicBuildsKey + ".$." + PeriodicBuildNextRunTimeKey: next,
+			},
+		},
+	)
+}
+
+// SetPeriodicBuilds replaces the project's periodic builds with the given
+// definitions after validating them. Definitions that keep the ID and cron
+// expression of an existing one keep its next run time; the rest are
+// scheduled from now, and those without an ID are given one.
+func (projectRef *ProjectRef) SetPeriodicBuilds(definitions []PeriodicBuildDefinition, now time.Time) error {
+	existing := map[string]PeriodicBuildDefinition{}
+	for _, d := range projectRef.PeriodicBuilds {
+		existing[d.ID] = d
+	}
+
+	periodicBuilds := make([]PeriodicBuildDefinition, 0, len(definitions))
+	for _, d := range definitions {
+		if err := d.Validate(projectRef); err != nil {
+			return err
+		}
+		if d.ID == "" {
+			d.ID = bson.NewObjectId().Hex()
+		}
+		if old, ok := existing[d.ID]; ok && old.Cron == d.Cron {
+			d.NextRunTime = old.NextRunTime
+		} else {
+			next, err := d.NextRunAfter(now)
+			if err != nil {
+				return err
+			}
+			d.NextRunTime = next
+		}
+		periodicBuilds = append(periodicBuilds, d)
+	}
+	projectRef.PeriodicBuilds = periodicBuilds
+	return nil
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSetPeriodicBuilds(t *testing.T) {
+	Convey("With a project that has a nightly build", t, func() {
+		now := time.Date(2017, time.March, 15, 14, 30, 0, 0, time.UTC)
+		scheduled := time.Date(2017, time.March, 16, 0, 0, 0, 0, time.UTC)
+		ref := &ProjectRef{
+			Identifier: "proj",
+			Aliases:    []ProjectAlias{{Alias: "soak", Variant: ".*", Tasks: []string{"soak"}}},
+			PeriodicBuilds: []PeriodicBuildDefinition{
+				{ID: "nightly", Cron: "@daily", NextRunTime: scheduled},
+			},
+		}
+
+		Convey("an unchanged definition should keep its next run time", func() {
+			So(ref.SetPeriodicBuilds([]PeriodicBuildDefinition{{ID: "nightly", Cron: "@daily", Message: "nightly"}}, now), ShouldBeNil)
+			So(ref.PeriodicBuilds[0].NextRunTime, ShouldResemble, scheduled)
+			So(ref.PeriodicBuilds[0].Message, ShouldEqual, "nightly")
+		})
+
+		Convey("changed and new definitions should be scheduled from now", func() {
+			So(ref.SetPeriodicBuilds([]PeriodicBuildDefinition{
+				{ID: "nightly", Cron: "0 12 * * *"},
+				{Cron: "0 6 * * 0", Alias: "soak"},
+			}, now), ShouldBeNil)
+			So(len(ref.PeriodicBuilds), ShouldEqual, 2)
+			So(ref.PeriodicBuilds[0].NextRunTime, ShouldResemble, time.Date(2017, time.March, 16, 12, 0, 0, 0, time.UTC))
+			So(ref.PeriodicBuilds[1].ID, ShouldNotEqual, "")
+			So(ref.PeriodicBuilds[1].NextRunTime, ShouldResemble, time.Date(2017, time.March, 19, 6, 0, 0, 0, time.UTC))
+		})
+
+		Convey("invalid cron expressions and unknown aliases should be errors", func() {
+			So(ref.SetPeriodicBuilds([]PeriodicBuildDefinition{{Cron: "every night"}}, now), ShouldNotBeNil)
+			So(ref.SetPeriodicBuilds([]PeriodicBuildDefinition{{Cron: "@weekly", Alias: "missing"}}, now), ShouldNotBeNil)
+			So(ref.PeriodicBuilds[0].ID, ShouldEqual, "nightly")
+		})
+	})
+}
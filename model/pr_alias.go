@@ -0,0 +1,92 @@
+package model
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// PRAlias selects variants and tasks to run when testing a GitHub pull
+// request. Variant and each of Tasks are regular expressions matched against
+// whole build variant and task names.
+type PRAlias struct {
+	Variant string   `yaml:"variant" bson:"variant"`
+	Tasks   []string `yaml:"tasks" bson:"tasks"`
+}
+
+// compileWholeName compiles a regular expression that must match an entire
+// name.
+func compileWholeName(expr string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + expr + ")$")
+}
+
+// Compile checks that the alias's regular expressions are valid.
+func (a *PRAlias) Compile() (*regexp.Regexp, []*regexp.Regexp, error) {
+	return compileSelection(a.Variant, a.Tasks)
+}
+
+// compileSelection compiles a variant regular expression and a list of task
+// regular expressions.
+func compileSelection(variant string, tasks []string) (*regexp.Regexp, []*regexp.Regexp, error) {
+	variantRegex, err := compileWholeName(variant)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid variant regex '%s'", variant)
+	}
+	taskRegexes := make([]*regexp.Regexp, 0, len(tasks))
+	for _, expr := range tasks {
+		taskRegex, err := compileWholeName(expr)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid task regex '%s'", expr)
+		}
+		taskRegexes = append(taskRegexes, taskRegex)
+	}
+	return variantRegex, taskRegexes, nil
+}
+
+// PRAliasPairs returns the variant/task pairs that the project's pr_aliases
+// select. Disabled variants and unpatchable tasks are never selected.
+func (p *Project) PRAliasPairs() ([]TVPair, error) {
+	pairs := []TVPair{}
+	selected := map[TVPair]bool{}
+	for _, alias := range p.PRAliases {
+		variantRegex, taskRegexes, err := alias.Compile()
+		if err != nil {
+			return nil, err
+		}
+		for _, bv := range p.BuildVariants {
+			if bv.Disabled || !variantRegex.MatchString(bv.Name) {
+				continue
+			}
+			for _, bvTask := range bv.Tasks {
+				pair := TVPair{Variant: bv.Name, TaskName: bvTask.Name}
+				if selected[pair] || !matchesAny(taskRegexes, bvTask.Name) {
+					continue
+				}
+				projectTask := p.FindProjectTask(bvTask.Name)
+				if projectTask == nil {
+					continue
+				}
+				// a variant can override whether its task is patchable
+				patchable := projectTask.Patchable
+				if bvTask.Patchable != nil {
+					patchable = bvTask.Patchable
+				}
+				if patchable != nil && !*patchable {
+					continue
+				}
+				selected[pair] = true
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+	return pairs, nil
+}
+
+func matchesAny(regexes []*regexp.Regexp, name string) bool {
+	for _, regex := range regexes {
+		if regex.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
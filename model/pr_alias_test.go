@@ -0,0 +1,80 @@
+package model
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPRAliasPairs(t *testing.T) {
+	Convey("With a project that declares pr aliases", t, func() {
+		unpatchable := false
+		project := &Project{
+			Tasks: []ProjectTask{
+				{Name: "compile"},
+				{Name: "test-unit"},
+				{Name: "test-integration"},
+				{Name: "push", Patchable: &unpatchable},
+			},
+			BuildVariants: []BuildVariant{
+				{
+					Name: "linux",
+					Tasks: []BuildVariantTask{{Name: "compile"}, {Name: "test-unit"},
+						{Name: "test-integration", Patchable: &unpatchable}, {Name: "push"}},
+				},
+				{
+					Name:  "linux-race",
+					Tasks: []BuildVariantTask{{Name: "compile"}, {Name: "test-unit"}},
+				},
+				{
+					Name:     "windows",
+					Disabled: true,
+					Tasks:    []BuildVariantTask{{Name: "compile"}},
+				},
+			},
+		}
+
+		Convey("regexes should match whole variant and task names", func() {
+			project.PRAliases = []PRAlias{{Variant: "linux", Tasks: []string{"test"}}}
+			pairs, err := project.PRAliasPairs()
+			So(err, ShouldBeNil)
+			So(len(pairs), ShouldEqual, 0)
+
+			project.PRAliases = []PRAlias{{Variant: "linux", Tasks: []string{"test-.*"}}}
+			pairs, err = project.PRAliasPairs()
+			So(err, ShouldBeNil)
+			So(pairs, ShouldResemble, []TVPair{{Variant: "linux", TaskName: "test-unit"}})
+		})
+
+		Convey("unpatchable tasks and disabled variants should not be selected", func() {
+			project.PRAliases = []PRAlias{{Variant: ".*", Tasks: []string{".*"}}}
+			pairs, err := project.PRAliasPairs()
+			So(err, ShouldBeNil)
+			So(pairs, ShouldResemble, []TVPair{
+				{Variant: "linux", TaskName: "compile"},
+				{Variant: "linux", TaskName: "test-unit"},
+				{Variant: "linux-race", TaskName: "compile"},
+				{Variant: "linux-race", TaskName: "test-unit"},
+			})
+		})
+
+		Convey("pairs selected by several aliases should be listed once", func() {
+			project.PRAliases = []PRAlias{
+				{Variant: "linux", Tasks: []string{"compile"}},
+				{Variant: "linux.*", Tasks: []string{"compile"}},
+			}
+			pairs, err := project.PRAliasPairs()
+			So(err, ShouldBeNil)
+			So(pairs, ShouldResemble, []TVPair{
+				{Variant: "linux", TaskName: "compile"},
+				{Variant: "linux-race", TaskName: "compile"},
+			})
+		})
+
+		Convey("an invalid regex should be an error", func() {
+			project.PRAliases = []PRAlias{{Variant: "linux", Tasks: []string{"test-("}}}
+			_, err := project.PRAliasPairs()
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -19,6 +19,7 @@ import (
 const (
 	TestCommandType   = "test"
 	SystemCommandType = "system"
+	SetupCommandType  = "setup"
 )
 
 const (
@@ -50,8 +51,16 @@ type Project struct {
 	Tasks           []ProjectTask              `yaml:"tasks,omitempty" bson:"tasks"`
 	ExecTimeoutSecs int                        `yaml:"exec_timeout_secs,omitempty" bson:"exec_timeout_secs"`
 
+	// DumpOnTimeout asks the processes of a timed out task to dump their
+	// state before they are killed
+	DumpOnTimeout bool `yaml:"dump_on_timeout,omitempty" bson:"dump_on_timeout"`
+
 	// Flag that indicates a project as requiring user authentication
 	Private bool `yaml:"private,omitempty" bson:"private"`
+
+	// PRAliases select the variants and tasks that test GitHub pull requests
+	// opened against the project's branch
+	PRAliases []PRAlias `yaml:"pr_aliases,omitempty" bson:"pr_aliases"`
 }
 
 // Unmarshalled from the "tasks" list in an individual build variant
@@ -145,6 +154,12 @@ type BuildVariant struct {
 	// provided for the task
 	RunOn []string `yaml:"run_on,omitempty" bson:"run_on"`
 
+	// Paths and IgnorePaths are gitignore-style patterns that restrict the
+	// variant to changes to the files matching Paths, if any are given, and
+	// not matching IgnorePaths.
+	Paths       []string `yaml:"paths,omitempty" bson:"paths,omitempty"`
+	IgnorePaths []string `yaml:"ignore_paths,omitempty" bson:"ignore_paths,omitempty"`
+
 	// all of the tasks to be run on the build variant, compile through tests.
 	Tasks []BuildVariantTask `yaml:"tasks,omitempty" bson:"tasks"`
 }
@@ -275,12 +290,21 @@ type ProjectTask struct {
 	Commands        []PluginCommandConf `yaml:"commands,omitempty" bson:"commands"`
 	Tags            []string            `yaml:"tags,omitempty" bson:"tags"`
 
+	// Paths and IgnorePaths restrict the task to changes to the files they
+	// match, as they do for build variants.
+	Paths       []string `yaml:"paths,omitempty" bson:"paths,omitempty"`
+	IgnorePaths []string `yaml:"ignore_paths,omitempty" bson:"ignore_paths,omitempty"`
+
 	// Use a *bool so that there are 3 possible states:
 	//   1. nil   = not overriding the project setting (default)
 	//   2. true  = overriding the project setting with true
 	//   3. false = overriding the project setting with false
 	Patchable *bool `yaml:"patchable,omitempty" bson:"patchable,omitempty"`
 	Stepback  *bool `yaml:"stepback,omitempty" bson:"stepback,omitempty"`
+
+	// ResourceLimits overrides the distro's limits on the memory, CPU
+	// and processes available to the task.
+	ResourceLimits *distro.ResourceLimits `yaml:"resource_limits,omitempty" bson:"resource_limits,omitempty"`
 }
 
 type TaskConfig struct {
@@ -482,6 +506,7 @@ func populateExpansions(d *distro.Distro, v *version.Version, bv *BuildVariant,
 	for _, e := range d.Expansions {
 		expansions.Put(e.Key, e.Value)
 	}
+	expansions.Update(v.Expansions)
 	expansions.Update(bv.Expansions)
 	return expansions
 }
@@ -724,3 +749,127 @@ func (p *Project) IgnoresAllFiles(files []string) bool {
 	}
 	return true
 }
+
+// HasPathFilters returns whether any of the project's variants or tasks is
+// restricted to changes to certain files.
+func (p *Project) HasPathFilters() bool {
+	for _, bv := range p.BuildVariants {
+		if len(bv.Paths) > 0 || len(bv.IgnorePaths) > 0 {
+			return true
+		}
+	}
+	for _, t := range p.Tasks {
+		if len(t.Paths) > 0 || len(t.IgnorePaths) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesChangedFiles returns whether any of the files matches the paths
+// patterns, if there are any, without matching the ignorePaths patterns.
+func matchesChangedFiles(paths, ignorePaths, files []string) bool {
+	if len(paths) == 0 && len(ignorePaths) == 0 {
+		return true
+	}
+	// CompileIgnoreLines always returns a nil error
+	var matcher, ignorer *ignore.GitIgnore
+	if len(paths) > 0 {
+		matcher, _ = ignore.CompileIgnoreLines(paths...)
+	}
+	if len(ignorePaths) > 0 {
+		ignorer, _ = ignore.CompileIgnoreLines(ignorePaths...)
+	}
+	for _, f := range files {
+		if (matcher == nil || matcher.MatchesPath(f)) && (ignorer == nil || !ignorer.MatchesPath(f)) {
+			return true
+		}
+	}
+	return false
+}
+
+// changedFilesSkipReason returns why the task does not run on the variant for
+// a change to the files, or an empty string if it runs.
+func (p *Project) changedFilesSkipReason(pair TVPair, files []string) string {
+	if bv := p.FindBuildVariant(pair.Variant); bv != nil &&
+		!matchesChangedFiles(bv.Paths, bv.IgnorePaths, files) {
+		return fmt.Sprintf("no changed files match the paths of variant '%v'", pair.Variant)
+	}
+	if t := p.FindProjectTask(pair.TaskName); t != nil &&
+		!matchesChangedFiles(t.Paths, t.IgnorePaths, files) {
+		return fmt.Sprintf("no changed files match the paths of task '%v'", pair.TaskName)
+	}
+	return ""
+}
+
+// includeDependencies returns the set of the given pairs along with every
+// pair that they depend on or require, directly or transitively. Only the
+// dependencies for which canInclude returns true are added.
+func (p *Project) includeDependencies(pairs TVPairSet, canInclude func(TVPair) bool) map[TVPair]bool {
+	included := map[TVPair]bool{}
+	toVisit := []TVPair{}
+	for _, pair := range pairs {
+		if !included[pair] {
+			included[pair] = true
+			toVisit = append(toVisit, pair)
+		}
+	}
+
+	di := &dependencyIncluder{Project: p}
+	for len(toVisit) > 0 {
+		pair := toVisit[0]
+		toVisit = toVisit[1:]
+		bvt := p.FindTaskForVariant(pair.TaskName, pair.Variant)
+		if bvt == nil {
+			continue
+		}
+		deps := append(di.expandRequirements(pair, bvt.Requires),
+			di.expandDependencies(pair, bvt.DependsOn)...)
+		for _, dep := range deps {
+			if !included[dep] && canInclude(dep) {
+				included[dep] = true
+				toVisit = append(toVisit, dep)
+			}
+		}
+	}
+	return included
+}
+
+// FilterByChangedFiles splits the task/variant pairs into those that run for
+// a change to the files, and those that are skipped because their paths do
+// not match any of the files. Skipped tasks that a running task depends on
+// or requires still run. If the changed files are unknown, every task runs.
+func (p *Project) FilterByChangedFiles(pairs TVPairSet, files []string) (TVPairSet, []version.SkippedTask) {
+	if len(files) == 0 || !p.HasPathFilters() {
+		return pairs, nil
+	}
+
+	requested := map[TVPair]bool{}
+	matched := TVPairSet{}
+	for _, pair := range pairs {
+		requested[pair] = true
+		if p.changedFilesSkipReason(pair, files) == "" {
+			matched = append(matched, pair)
+		}
+	}
+
+	// the dependencies of running tasks run too
+	running := p.includeDependencies(matched, func(pair TVPair) bool {
+		return requested[pair]
+	})
+
+	run := TVPairSet{}
+	skipped := []version.SkippedTask{}
+	for _, pair := range pairs {
+		if running[pair] {
+			run = append(run, pair)
+			continue
+		}
+		skipped = append(skipped, version.SkippedTask{
+			BuildVariant: pair.Variant,
+			TaskName:     pair.TaskName,
+			Reason:       p.changedFilesSkipReason(pair, files),
+		})
+	}
+	return run, skipped
+}
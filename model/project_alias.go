@@ -0,0 +1,161 @@
+package model
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// ProjectAlias is a named selection of a project's variants and tasks, such
+// as the tasks that a trigger runs or that a patch tests. Variant and each of
+// Tasks are regular expressions matched against whole build variant and task
+// names. A variant is also selected if it has any of VariantTags, and a task
+// if it has any of TaskTags. A project may define several aliases with the
+// same name, which together select the union of their variants and tasks.
+type ProjectAlias struct {
+	Alias       string   `bson:"alias" json:"alias"`
+	Variant     string   `bson:"variant" json:"variant"`
+	VariantTags []string `bson:"variant_tags,omitempty" json:"variant_tags,omitempty"`
+	Tasks       []string `bson:"tasks" json:"tasks"`
+	TaskTags    []string `bson:"task_tags,omitempty" json:"task_tags,omitempty"`
+}
+
+// Compile checks that the alias's regular expressions are valid.
+func (a *ProjectAlias) Compile() (*regexp.Regexp, []*regexp.Regexp, error) {
+	return compileSelection(a.Variant, a.Tasks)
+}
+
+// Validate checks that the alias is named, selects variants and tasks by
+// name or tag, and has valid regular expressions.
+func (a *ProjectAlias) Validate() error {
+	if a.Alias == "" {
+		return errors.New("alias must have a name")
+	}
+	if a.Variant == "" && len(a.VariantTags) == 0 {
+		return errors.Errorf("alias '%s' must select variants by name or tag", a.Alias)
+	}
+	if len(a.Tasks) == 0 && len(a.TaskTags) == 0 {
+		return errors.Errorf("alias '%s' must select tasks by name or tag", a.Alias)
+	}
+	if _, _, err := a.Compile(); err != nil {
+		return errors.Wrapf(err, "alias '%s'", a.Alias)
+	}
+	return nil
+}
+
+// hasAnyTag returns whether any of the tags is one of the wanted tags.
+func hasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		for _, w := range wanted {
+			if tag == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FindAliases returns the project's aliases with the given name.
+func (projectRef *ProjectRef) FindAliases(name string) []ProjectAlias {
+	aliases := []ProjectAlias{}
+	for _, alias := range projectRef.Aliases {
+		if alias.Alias == name {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+// AliasPairs returns the variant/task pairs that the aliases select, along
+// with the pairs that they depend on or require. Disabled variants are never
+// selected.
+func (p *Project) AliasPairs(aliases []ProjectAlias) (TVPairSet, error) {
+	selected := TVPairSet{}
+	for _, alias := range aliases {
+		variantRegex, taskRegexes, err := alias.Compile()
+		if err != nil {
+			return nil, errors.Wrapf(err, "alias '%s'", alias.Alias)
+		}
+		for _, bv := range p.BuildVariants {
+			if bv.Disabled || !(variantRegex.MatchString(bv.Name) || hasAnyTag(bv.Tags, alias.VariantTags)) {
+				continue
+			}
+			for _, bvTask := range bv.Tasks {
+				if matchesAny(taskRegexes, bvTask.Name) || alias.selectsTaskTags(p, bvTask.Name) {
+					selected = append(selected, TVPair{Variant: bv.Name, TaskName: bvTask.Name})
+				}
+			}
+		}
+	}
+
+	included := p.includeDependencies(selected, func(pair TVPair) bool {
+		bv := p.FindBuildVariant(pair.Variant)
+		return bv != nil && !bv.Disabled
+	})
+
+	// return the pairs in the order the project defines them
+	pairs := TVPairSet{}
+	for _, bv := range p.BuildVariants {
+		for _, bvTask := range bv.Tasks {
+			pair := TVPair{Variant: bv.Name, TaskName: bvTask.Name}
+			if included[pair] {
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+	return pairs, nil
+}
+
+// selectsTaskTags returns whether the project's task has any of the alias's
+// task tags.
+func (a *ProjectAlias) selectsTaskTags(p *Project, taskName string) bool {
+	if len(a.TaskTags) == 0 {
+		return false
+	}
+	task := p.FindProjectTask(taskName)
+	return task != nil && hasAnyTag(task.Tags, a.TaskTags)
+}
+
+// AliasNames returns the names of the project's aliases, in the order that
+// they are first defined.
+func (projectRef *ProjectRef) AliasNames() []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, alias := range projectRef.Aliases {
+		if !seen[alias.Alias] {
+			seen[alias.Alias] = true
+			names = append(names, alias.Alias)
+		}
+	}
+	return names
+}
+
+// PatchAliasPairs returns the variant/task pairs that the project's aliases
+// with the given name select for a patch, which never runs unpatchable tasks.
+func (p *Project) PatchAliasPairs(projectRef *ProjectRef, name string) ([]TVPair, error) {
+	aliases := projectRef.FindAliases(name)
+	if len(aliases) == 0 {
+		return nil, errors.Errorf("project '%s' has no alias '%s'", projectRef.Identifier, name)
+	}
+	pairs, err := p.AliasPairs(aliases)
+	if err != nil {
+		return nil, err
+	}
+	patchPairs := []TVPair{}
+	for _, pair := range pairs {
+		if p.isPatchable(pair) {
+			patchPairs = append(patchPairs, pair)
+		}
+	}
+	return patchPairs, nil
+}
+
+// isPatchable returns whether the pair's task may run in a patch, which a
+// variant can override for its own task.
+func (p *Project) isPatchable(pair TVPair) bool {
+	if p.FindProjectTask(pair.TaskName) == nil {
+		return false
+	}
+	bvTask := p.FindTaskForVariant(pair.TaskName, pair.Variant)
+	return bvTask == nil || bvTask.Patchable == nil || *bvTask.Patchable
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAliasPairs(t *testing.T) {
+	Convey("With a project and its aliases", t, func() {
+		project := &Project{
+			Tasks: []ProjectTask{
+				{Name: "compile"},
+				{Name: "smoke", DependsOn: []TaskDependency{{Name: "compile"}}},
+				{Name: "soak"},
+			},
+			BuildVariants: []BuildVariant{
+				{
+					Name:  "linux",
+					Tasks: []BuildVariantTask{{Name: "compile"}, {Name: "smoke"}, {Name: "soak"}},
+				},
+				{
+					Name:     "windows",
+					Disabled: true,
+					Tasks:    []BuildVariantTask{{Name: "compile"}, {Name: "smoke"}},
+				},
+			},
+		}
+		ref := &ProjectRef{
+			Identifier: "proj",
+			Aliases: []ProjectAlias{
+				{Alias: "smoke", Variant: ".*", Tasks: []string{"smoke"}},
+				{Alias: "nightly", Variant: "linux", Tasks: []string{"soak"}},
+				{Alias: "nightly", Variant: "linux", Tasks: []string{"smoke"}},
+			},
+		}
+
+		Convey("an alias should select its tasks and their dependencies", func() {
+			pairs, err := project.AliasPairs(ref.FindAliases("smoke"))
+			So(err, ShouldBeNil)
+			So(pairs, ShouldResemble, TVPairSet{
+				{Variant: "linux", TaskName: "compile"},
+				{Variant: "linux", TaskName: "smoke"},
+			})
+		})
+
+		Convey("aliases with the same name should select the union of their tasks", func() {
+			pairs, err := project.AliasPairs(ref.FindAliases("nightly"))
+			So(err, ShouldBeNil)
+			So(len(pairs), ShouldEqual, 3)
+		})
+
+		Convey("an alias with an invalid regex should be an error", func() {
+			_, err := project.AliasPairs([]ProjectAlias{{Alias: "bad", Variant: "("}})
+			So(err, ShouldNotBeNil)
+			So(len(ref.FindAliases("missing")), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestPatchAliasPairs(t *testing.T) {
+	Convey("With a project whose variants and tasks are tagged", t, func() {
+		unpatchable := false
+		project := &Project{
+			Tasks: []ProjectTask{
+				{Name: "compile", Tags: []string{"build"}},
+				{Name: "lint", Tags: []string{"quick"}},
+				{Name: "test", Tags: []string{"quick"}},
+				{Name: "release", Tags: []string{"quick"}, Patchable: &unpatchable},
+			},
+			BuildVariants: []BuildVariant{
+				{
+					Name: "linux",
+					Tags: []string{"primary"},
+					Tasks: []BuildVariantTask{{Name: "compile"}, {Name: "lint"},
+						{Name: "test"}, {Name: "release"}},
+				},
+				{
+					Name:  "osx",
+					Tasks: []BuildVariantTask{{Name: "compile"}, {Name: "test", Patchable: &unpatchable}},
+				},
+			},
+		}
+		ref := &ProjectRef{
+			Identifier: "proj",
+			Aliases: []ProjectAlias{
+				{Alias: "quick", VariantTags: []string{"primary"}, TaskTags: []string{"quick"}},
+				{Alias: "all", Variant: ".*", Tasks: []string{".*"}},
+			},
+		}
+
+		Convey("an alias should select variants and tasks by tag", func() {
+			pairs, err := project.AliasPairs(ref.FindAliases("quick"))
+			So(err, ShouldBeNil)
+			So(pairs, ShouldResemble, TVPairSet{
+				{Variant: "linux", TaskName: "lint"},
+				{Variant: "linux", TaskName: "test"},
+				{Variant: "linux", TaskName: "release"},
+			})
+		})
+
+		Convey("a patch alias should not select unpatchable tasks", func() {
+			pairs, err := project.PatchAliasPairs(ref, "all")
+			So(err, ShouldBeNil)
+			So(pairs, ShouldResemble, []TVPair{
+				{Variant: "linux", TaskName: "compile"},
+				{Variant: "linux", TaskName: "lint"},
+				{Variant: "linux", TaskName: "test"},
+				{Variant: "osx", TaskName: "compile"},
+			})
+
+			_, err = project.PatchAliasPairs(ref, "missing")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("an alias should be validated", func() {
+			So(ref.Aliases[0].Validate(), ShouldBeNil)
+			So((&ProjectAlias{Alias: "none", Tasks: []string{".*"}}).Validate(), ShouldNotBeNil)
+			So((&ProjectAlias{Alias: "none", Variant: ".*"}).Validate(), ShouldNotBeNil)
+			So((&ProjectAlias{Variant: ".*", Tasks: []string{".*"}}).Validate(), ShouldNotBeNil)
+			So((&ProjectAlias{Alias: "bad", Variant: "(", Tasks: []string{".*"}}).Validate(), ShouldNotBeNil)
+			So(ref.AliasNames(), ShouldResemble, []string{"quick", "all"})
+		})
+	})
+}
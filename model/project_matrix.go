@@ -40,6 +40,8 @@ type matrix struct {
 	BatchTime   *int              `yaml:"batchtime"`
 	Stepback    *bool             `yaml:"stepback"`
 	RunOn       parserStringSlice `yaml:"run_on"`
+	Paths       parserStringSlice `yaml:"paths"`
+	IgnorePaths parserStringSlice `yaml:"ignore_paths"`
 	Tasks       parserBVTasks     `yaml:"tasks"`
 	Rules       []matrixRule      `yaml:"rules"`
 }
@@ -296,13 +298,15 @@ func buildMatrixVariants(axes []matrixAxis, ase *axisSelectorEvaluator, matrices
 // execution.
 func buildMatrixVariant(axes []matrixAxis, mv matrixValue, m *matrix, ase *axisSelectorEvaluator) (*parserBV, error) {
 	v := parserBV{
-		matrixVal:  mv,
-		matrixId:   m.Id,
-		Stepback:   m.Stepback,
-		BatchTime:  m.BatchTime,
-		Modules:    m.Modules,
-		RunOn:      m.RunOn,
-		Expansions: *command.NewExpansions(mv),
+		matrixVal:   mv,
+		matrixId:    m.Id,
+		Stepback:    m.Stepback,
+		BatchTime:   m.BatchTime,
+		Modules:     m.Modules,
+		RunOn:       m.RunOn,
+		Paths:       m.Paths,
+		IgnorePaths: m.IgnorePaths,
+		Expansions:  *command.NewExpansions(mv),
 	}
 	// we declare a separate expansion map for evaluating the display name
 	displayNameExp := command.Expansions{}
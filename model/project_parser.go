@@ -5,6 +5,7 @@ import (
 	"reflect"
 
 	"github.com/evergreen-ci/evergreen/command"
+	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/util"
 	"github.com/mongodb/grip"
 	"github.com/pkg/errors"
@@ -58,6 +59,8 @@ type parserProject struct {
 	Functions       map[string]*YAMLCommandSet `yaml:"functions"`
 	Tasks           []parserTask               `yaml:"tasks"`
 	ExecTimeoutSecs int                        `yaml:"exec_timeout_secs"`
+	DumpOnTimeout   bool                       `yaml:"dump_on_timeout"`
+	PRAliases       []PRAlias                  `yaml:"pr_aliases"`
 
 	// Matrix code
 	Axes []matrixAxis `yaml:"axes"`
@@ -65,16 +68,19 @@ type parserProject struct {
 
 // parserTask represents an intermediary state of task definitions.
 type parserTask struct {
-	Name            string              `yaml:"name"`
-	Priority        int64               `yaml:"priority"`
-	ExecTimeoutSecs int                 `yaml:"exec_timeout_secs"`
-	DisableCleanup  bool                `yaml:"disable_cleanup"`
-	DependsOn       parserDependencies  `yaml:"depends_on"`
-	Requires        taskSelectors       `yaml:"requires"`
-	Commands        []PluginCommandConf `yaml:"commands"`
-	Tags            parserStringSlice   `yaml:"tags"`
-	Patchable       *bool               `yaml:"patchable"`
-	Stepback        *bool               `yaml:"stepback"`
+	Name            string                 `yaml:"name"`
+	Priority        int64                  `yaml:"priority"`
+	ExecTimeoutSecs int                    `yaml:"exec_timeout_secs"`
+	DisableCleanup  bool                   `yaml:"disable_cleanup"`
+	DependsOn       parserDependencies     `yaml:"depends_on"`
+	Requires        taskSelectors          `yaml:"requires"`
+	Commands        []PluginCommandConf    `yaml:"commands"`
+	Tags            parserStringSlice      `yaml:"tags"`
+	Paths           parserStringSlice      `yaml:"paths"`
+	IgnorePaths     parserStringSlice      `yaml:"ignore_paths"`
+	Patchable       *bool                  `yaml:"patchable"`
+	Stepback        *bool                  `yaml:"stepback"`
+	ResourceLimits  *distro.ResourceLimits `yaml:"resource_limits"`
 }
 
 // helper methods for task tag evaluations
@@ -222,6 +228,8 @@ type parserBV struct {
 	BatchTime   *int               `yaml:"batchtime"`
 	Stepback    *bool              `yaml:"stepback"`
 	RunOn       parserStringSlice  `yaml:"run_on"`
+	Paths       parserStringSlice  `yaml:"paths"`
+	IgnorePaths parserStringSlice  `yaml:"ignore_paths"`
 	Tasks       parserBVTasks      `yaml:"tasks"`
 
 	// internal matrix stuff
@@ -421,6 +429,8 @@ func translateProject(pp *parserProject) (*Project, []error) {
 		Modules:         pp.Modules,
 		Functions:       pp.Functions,
 		ExecTimeoutSecs: pp.ExecTimeoutSecs,
+		DumpOnTimeout:   pp.DumpOnTimeout,
+		PRAliases:       pp.PRAliases,
 	}
 	tse := NewParserTaskSelectorEvaluator(pp.Tasks)
 	ase := NewAxisSelectorEvaluator(pp.Axes)
@@ -463,8 +473,11 @@ func evaluateTasks(tse *taskSelectorEvaluator, vse *variantSelectorEvaluator,
 			ExecTimeoutSecs: pt.ExecTimeoutSecs,
 			Commands:        pt.Commands,
 			Tags:            pt.Tags,
+			Paths:           pt.Paths,
+			IgnorePaths:     pt.IgnorePaths,
 			Patchable:       pt.Patchable,
 			Stepback:        pt.Stepback,
+			ResourceLimits:  pt.ResourceLimits,
 		}
 		t.DependsOn, errs = evaluateDependsOn(tse, vse, pt.DependsOn)
 		evalErrs = append(evalErrs, errs...)
@@ -493,6 +506,8 @@ func evaluateBuildVariants(tse *taskSelectorEvaluator, vse *variantSelectorEvalu
 			Stepback:    pbv.Stepback,
 			RunOn:       pbv.RunOn,
 			Tags:        pbv.Tags,
+			Paths:       pbv.Paths,
+			IgnorePaths: pbv.IgnorePaths,
 		}
 		bv.Tasks, errs = evaluateBVTasks(tse, vse, pbv.Tasks)
 		// evaluate any rules passed in during matrix construction
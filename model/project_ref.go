@@ -2,9 +2,13 @@ package model
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/db"
 	"github.com/evergreen-ci/evergreen/db/bsonutil"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/util"
 	"github.com/pkg/errors"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
@@ -28,6 +32,10 @@ type ProjectRef struct {
 	//Tracked determines whether or not the project is discoverable in the UI
 	Tracked bool `bson:"tracked" json:"tracked"`
 
+	// RepoURL is the URL that repositories of the "git" kind are cloned
+	// from. GitHub repositories are found by Owner and Repo instead.
+	RepoURL string `bson:"repo_url,omitempty" json:"repo_url,omitempty" yaml:"repo_url"`
+
 	// Admins contain a list of users who are able to access the projects page.
 	Admins []string `bson:"admins" json:"admins"`
 
@@ -38,6 +46,43 @@ type ProjectRef struct {
 	// RepoDetails contain the details of the status of the consistency
 	// between what is in GitHub and what is in Evergreen
 	RepotrackerError *RepositoryErrorDetails `bson:"repotracker_error" json:"repotracker_error"`
+
+	// Retention overrides the default retention policy for the project's data
+	Retention *evergreen.RetentionPolicy `bson:"retention,omitempty" json:"retention,omitempty"`
+
+	// QuarantinedTests are tests whose failures are recorded but do not fail
+	// their tasks, such as flaky tests that are being fixed.
+	QuarantinedTests []QuarantinedTest `bson:"quarantined_tests,omitempty" json:"quarantined_tests,omitempty"`
+
+	// CommitQueueEnabled allows patches to be merged into the project's
+	// branch through its commit queue.
+	CommitQueueEnabled bool `bson:"commit_queue_enabled" json:"commit_queue_enabled" yaml:"commit_queue_enabled"`
+
+	// Aliases are named selections of the project's variants and tasks.
+	Aliases []ProjectAlias `bson:"aliases,omitempty" json:"aliases,omitempty"`
+
+	// Triggers create versions of the project when tasks or builds of
+	// other projects finish.
+	Triggers []TriggerDefinition `bson:"triggers,omitempty" json:"triggers,omitempty"`
+
+	// PeriodicBuilds create versions of the project on schedules, whether
+	// or not there are new commits.
+	PeriodicBuilds []PeriodicBuildDefinition `bson:"periodic_builds,omitempty" json:"periodic_builds,omitempty"`
+
+	// GitTagVersions create versions of the project for new tags in its
+	// repository, such as the tags of releases.
+	GitTagVersions []GitTagDefinition `bson:"git_tag_versions,omitempty" json:"git_tag_versions,omitempty"`
+}
+
+// QuarantinedTest is a test that a project has quarantined.
+type QuarantinedTest struct {
+	TestFile string `bson:"test_file" json:"test_file"`
+	// TaskName limits the quarantine to the test when it runs in the named
+	// task. If it is empty, the test is quarantined in every task.
+	TaskName   string    `bson:"task_name" json:"task_name,omitempty"`
+	Reason     string    `bson:"reason,omitempty" json:"reason,omitempty"`
+	Author     string    `bson:"author,omitempty" json:"author,omitempty"`
+	CreateTime time.Time `bson:"create_time" json:"create_time"`
 }
 
 // RepositoryErrorDetails indicates whether or not there is an invalid revision and if there is one,
@@ -66,6 +111,7 @@ var (
 	ProjectRefRepoKey               = bsonutil.MustHaveTag(ProjectRef{}, "Repo")
 	ProjectRefBranchKey             = bsonutil.MustHaveTag(ProjectRef{}, "Branch")
 	ProjectRefRepoKindKey           = bsonutil.MustHaveTag(ProjectRef{}, "RepoKind")
+	ProjectRefRepoURLKey            = bsonutil.MustHaveTag(ProjectRef{}, "RepoURL")
 	ProjectRefEnabledKey            = bsonutil.MustHaveTag(ProjectRef{}, "Enabled")
 	ProjectRefPrivateKey            = bsonutil.MustHaveTag(ProjectRef{}, "Private")
 	ProjectRefBatchTimeKey          = bsonutil.MustHaveTag(ProjectRef{}, "BatchTime")
@@ -78,6 +124,17 @@ var (
 	ProjectRefAlertsKey             = bsonutil.MustHaveTag(ProjectRef{}, "Alerts")
 	ProjectRefRepotrackerError      = bsonutil.MustHaveTag(ProjectRef{}, "RepotrackerError")
 	ProjectRefAdminsKey             = bsonutil.MustHaveTag(ProjectRef{}, "Admins")
+	ProjectRefRetentionKey          = bsonutil.MustHaveTag(ProjectRef{}, "Retention")
+	ProjectRefQuarantinedTestsKey   = bsonutil.MustHaveTag(ProjectRef{}, "QuarantinedTests")
+	ProjectRefCommitQueueEnabledKey = bsonutil.MustHaveTag(ProjectRef{}, "CommitQueueEnabled")
+	ProjectRefAliasesKey            = bsonutil.MustHaveTag(ProjectRef{}, "Aliases")
+	ProjectRefTriggersKey           = bsonutil.MustHaveTag(ProjectRef{}, "Triggers")
+	ProjectRefPeriodicBuildsKey     = bsonutil.MustHaveTag(ProjectRef{}, "PeriodicBuilds")
+	ProjectRefGitTagVersionsKey     = bsonutil.MustHaveTag(ProjectRef{}, "GitTagVersions")
+
+	// bson fields for the QuarantinedTest struct
+	QuarantinedTestTestFileKey = bsonutil.MustHaveTag(QuarantinedTest{}, "TestFile")
+	QuarantinedTestTaskNameKey = bsonutil.MustHaveTag(QuarantinedTest{}, "TaskName")
 )
 
 const (
@@ -139,6 +196,27 @@ func FindAllProjectRefs() ([]ProjectRef, error) {
 	return projectRefs, err
 }
 
+// FindTrackedProjectRefsByRepoAndBranch returns the tracked project refs that
+// build the given branch of the given repository.
+func FindTrackedProjectRefsByRepoAndBranch(owner, repo, branch string) ([]ProjectRef, error) {
+	projectRefs := []ProjectRef{}
+	err := db.FindAll(
+		ProjectRefCollection,
+		bson.M{
+			ProjectRefOwnerKey:   owner,
+			ProjectRefRepoKey:    repo,
+			ProjectRefBranchKey:  branch,
+			ProjectRefTrackedKey: true,
+		},
+		db.NoProjection,
+		db.NoSort,
+		db.NoSkip,
+		db.NoLimit,
+		&projectRefs,
+	)
+	return projectRefs, err
+}
+
 // UntrackStaleProjectRefs sets all project_refs in the db not in the array
 // of project identifiers to "untracked."
 func UntrackStaleProjectRefs(activeProjects []string) error {
@@ -165,6 +243,7 @@ func (projectRef *ProjectRef) Upsert() error {
 		bson.M{
 			"$set": bson.M{
 				ProjectRefRepoKindKey:           projectRef.RepoKind,
+				ProjectRefRepoURLKey:            projectRef.RepoURL,
 				ProjectRefEnabledKey:            projectRef.Enabled,
 				ProjectRefPrivateKey:            projectRef.Private,
 				ProjectRefBatchTimeKey:          projectRef.BatchTime,
@@ -180,12 +259,28 @@ func (projectRef *ProjectRef) Upsert() error {
 				ProjectRefAlertsKey:             projectRef.Alerts,
 				ProjectRefRepotrackerError:      projectRef.RepotrackerError,
 				ProjectRefAdminsKey:             projectRef.Admins,
+				ProjectRefRetentionKey:          projectRef.Retention,
+				ProjectRefQuarantinedTestsKey:   projectRef.QuarantinedTests,
+				ProjectRefCommitQueueEnabledKey: projectRef.CommitQueueEnabled,
+				ProjectRefAliasesKey:            projectRef.Aliases,
+				ProjectRefTriggersKey:           projectRef.Triggers,
+				ProjectRefPeriodicBuildsKey:     projectRef.PeriodicBuilds,
+				ProjectRefGitTagVersionsKey:     projectRef.GitTagVersions,
 			},
 		},
 	)
 	return err
 }
 
+// GetRetentionPolicy returns the project's retention policy, or the default
+// policy if the project does not set one.
+func (projectRef *ProjectRef) GetRetentionPolicy(defaultPolicy evergreen.RetentionPolicy) evergreen.RetentionPolicy {
+	if projectRef.Retention != nil {
+		return *projectRef.Retention
+	}
+	return defaultPolicy
+}
+
 // ProjectRef returns a string representation of a ProjectRef
 func (projectRef *ProjectRef) String() string {
 	return projectRef.Identifier
@@ -209,3 +304,53 @@ func (projectRef *ProjectRef) Location() (string, error) {
 	}
 	return fmt.Sprintf("git@github.com:%v/%v.git", projectRef.Owner, projectRef.Repo), nil
 }
+
+// IsQuarantined returns whether the project has quarantined the test when
+// it runs in the named task.
+func (projectRef *ProjectRef) IsQuarantined(taskName, testFile string) bool {
+	for _, q := range projectRef.QuarantinedTests {
+		if q.TestFile == testFile && (q.TaskName == "" || q.TaskName == taskName) {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkQuarantinedResults flags the results of the tests that the project has
+// quarantined in the named task.
+func (projectRef *ProjectRef) MarkQuarantinedResults(taskName string, results []task.TestResult) {
+	for i := range results {
+		results[i].Quarantined = projectRef.IsQuarantined(taskName, results[i].TestFile)
+	}
+}
+
+// AddQuarantinedTest quarantines a test in the project, replacing any
+// existing quarantine of the same test and task.
+func AddQuarantinedTest(identifier string, test QuarantinedTest) error {
+	if test.TestFile == "" {
+		return errors.New("a quarantined test must have a test file")
+	}
+	if err := RemoveQuarantinedTest(identifier, test.TaskName, test.TestFile); err != nil {
+		return err
+	}
+	if util.IsZeroTime(test.CreateTime) {
+		test.CreateTime = time.Now()
+	}
+	return errors.Wrapf(db.Update(
+		ProjectRefCollection,
+		bson.M{ProjectRefIdentifierKey: identifier},
+		bson.M{"$push": bson.M{ProjectRefQuarantinedTestsKey: test}},
+	), "problem quarantining test '%s' in project '%s'", test.TestFile, identifier)
+}
+
+// RemoveQuarantinedTest lifts the quarantine of a test in the project.
+func RemoveQuarantinedTest(identifier, taskName, testFile string) error {
+	return errors.Wrapf(db.Update(
+		ProjectRefCollection,
+		bson.M{ProjectRefIdentifierKey: identifier},
+		bson.M{"$pull": bson.M{ProjectRefQuarantinedTestsKey: bson.M{
+			QuarantinedTestTestFileKey: testFile,
+			QuarantinedTestTaskNameKey: taskName,
+		}}},
+	), "problem removing quarantine of test '%s' in project '%s'", testFile, identifier)
+}
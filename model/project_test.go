@@ -268,6 +268,73 @@ func TestIgnoresAllFiles(t *testing.T) {
 	})
 }
 
+func TestFilterByChangedFiles(t *testing.T) {
+	Convey("With a project whose variants and tasks have paths", t, func() {
+		yml := `
+tasks:
+- name: compile
+- name: docs
+  paths: ["docs/*", "*.md"]
+- name: test
+  depends_on:
+  - name: compile
+- name: lint
+  ignore_paths: ["docs/*"]
+buildvariants:
+- name: linux
+  tasks: ["*"]
+- name: ui
+  paths: ["ui/*"]
+  tasks: ["compile", "test"]
+`
+		p := &Project{}
+		So(LoadProjectInto([]byte(yml), "proj", p), ShouldBeNil)
+		So(p.HasPathFilters(), ShouldBeTrue)
+		So(p.FindProjectTask("docs").Paths, ShouldResemble, []string{"docs/*", "*.md"})
+		So(p.FindBuildVariant("ui").Paths, ShouldResemble, []string{"ui/*"})
+
+		pairs := TVPairSet{
+			{Variant: "linux", TaskName: "compile"},
+			{Variant: "linux", TaskName: "docs"},
+			{Variant: "linux", TaskName: "test"},
+			{Variant: "linux", TaskName: "lint"},
+			{Variant: "ui", TaskName: "compile"},
+			{Variant: "ui", TaskName: "test"},
+		}
+
+		Convey("a change to docs should only run the tasks whose paths match", func() {
+			run, skipped := p.FilterByChangedFiles(pairs, []string{"docs/guide.txt"})
+			So(run, ShouldResemble, TVPairSet{
+				{Variant: "linux", TaskName: "compile"},
+				{Variant: "linux", TaskName: "docs"},
+				{Variant: "linux", TaskName: "test"},
+			})
+			So(len(skipped), ShouldEqual, 3)
+			So(skipped[0].TaskName, ShouldEqual, "lint")
+			So(skipped[0].Reason, ShouldContainSubstring, "task 'lint'")
+			So(skipped[1].BuildVariant, ShouldEqual, "ui")
+			So(skipped[1].Reason, ShouldContainSubstring, "variant 'ui'")
+		})
+
+		Convey("the dependencies of a running task should run even if skipped", func() {
+			p.Tasks[0].Paths = []string{"src/*"}
+			run, skipped := p.FilterByChangedFiles(pairs, []string{"ui/app.js"})
+			So(len(run), ShouldEqual, 5)
+			So(skipped, ShouldResemble, []version.SkippedTask{{
+				BuildVariant: "linux",
+				TaskName:     "docs",
+				Reason:       "no changed files match the paths of task 'docs'",
+			}})
+		})
+
+		Convey("every task should run if the changed files are unknown", func() {
+			run, skipped := p.FilterByChangedFiles(pairs, nil)
+			So(run, ShouldResemble, pairs)
+			So(len(skipped), ShouldEqual, 0)
+		})
+	})
+}
+
 func boolPtr(b bool) *bool {
 	return &b
 }
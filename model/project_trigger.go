@@ -0,0 +1,172 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/pkg/errors"
+)
+
+const (
+	// TriggerLevelTask triggers fire when a task of the upstream project finishes
+	TriggerLevelTask = "task"
+	// TriggerLevelBuild triggers fire when a build of the upstream project finishes
+	TriggerLevelBuild = "build"
+
+	// TriggerStatusAny matches tasks and builds whether they succeed or fail
+	TriggerStatusAny = "*"
+)
+
+// TriggerDefinition creates a version of the project that defines it when a
+// task or build of an upstream project finishes.
+type TriggerDefinition struct {
+	// Project is the identifier of the upstream project.
+	Project string `bson:"project" json:"project"`
+	// Level is whether the trigger watches the upstream project's tasks or
+	// its builds.
+	Level string `bson:"level" json:"level"`
+	// Variant and TaskName are regular expressions matched against whole
+	// build variant and task names. An empty expression matches every name.
+	// TaskName is only used by task triggers.
+	Variant  string `bson:"variant,omitempty" json:"variant,omitempty"`
+	TaskName string `bson:"task_name,omitempty" json:"task_name,omitempty"`
+	// Status is the status that the task or build must finish with: "success",
+	// "failed", or "*" for either. It defaults to "success".
+	Status string `bson:"status,omitempty" json:"status,omitempty"`
+	// ConfigFile is the path of the config file that the downstream version
+	// uses. It defaults to the project's own config file.
+	ConfigFile string `bson:"config_file,omitempty" json:"config_file,omitempty"`
+	// Alias names the project alias that selects the variants and tasks that
+	// the downstream version runs. Without one, every variant runs.
+	Alias string `bson:"alias,omitempty" json:"alias,omitempty"`
+}
+
+// Validate checks that the trigger of the given downstream project is
+// complete and that its regular expressions and alias are valid.
+func (t *TriggerDefinition) Validate(downstream *ProjectRef) error {
+	if t.Project == "" {
+		return errors.New("trigger must name an upstream project")
+	}
+	if t.Project == downstream.Identifier {
+		return errors.Errorf("project '%s' cannot trigger itself", t.Project)
+	}
+	if t.Level != TriggerLevelTask && t.Level != TriggerLevelBuild {
+		return errors.Errorf("trigger level must be '%s' or '%s', not '%s'",
+			TriggerLevelTask, TriggerLevelBuild, t.Level)
+	}
+	switch t.Status {
+	case "", evergreen.TaskSucceeded, evergreen.TaskFailed, TriggerStatusAny:
+	default:
+		return errors.Errorf("invalid trigger status '%s'", t.Status)
+	}
+	if _, err := compileWholeName(t.variantExpr()); err != nil {
+		return errors.Wrapf(err, "invalid variant regex '%s'", t.Variant)
+	}
+	if _, err := compileWholeName(t.taskExpr()); err != nil {
+		return errors.Wrapf(err, "invalid task regex '%s'", t.TaskName)
+	}
+	if t.Alias != "" && len(downstream.FindAliases(t.Alias)) == 0 {
+		return errors.Errorf("project '%s' has no alias '%s'", downstream.Identifier, t.Alias)
+	}
+	return nil
+}
+
+// Matches returns whether a task or build of the upstream project that
+// finished with the given status fires the trigger. The task name is ignored
+// for build triggers.
+func (t *TriggerDefinition) Matches(variant, taskName, status string) bool {
+	switch t.Status {
+	case TriggerStatusAny:
+		if status != evergreen.TaskSucceeded && status != evergreen.TaskFailed {
+			return false
+		}
+	case "":
+		if status != evergreen.TaskSucceeded {
+			return false
+		}
+	default:
+		if status != t.Status {
+			return false
+		}
+	}
+
+	variantRegex, err := compileWholeName(t.variantExpr())
+	if err != nil || !variantRegex.MatchString(variant) {
+		return false
+	}
+	if t.Level == TriggerLevelBuild {
+		return true
+	}
+	taskRegex, err := compileWholeName(t.taskExpr())
+	return err == nil && taskRegex.MatchString(taskName)
+}
+
+func (t *TriggerDefinition) variantExpr() string {
+	if t.Variant == "" {
+		return ".*"
+	}
+	return t.Variant
+}
+
+func (t *TriggerDefinition) taskExpr() string {
+	if t.TaskName == "" {
+		return ".*"
+	}
+	return t.TaskName
+}
+
+// ValidateTriggers checks the triggers of a project against the rest of the
+// projects. Every upstream project must exist, and the project must not
+// trigger itself through any chain of triggers.
+func ValidateTriggers(downstream *ProjectRef, refs []ProjectRef) error {
+	// the downstream project's triggers replace its saved ones
+	downstreams := map[string][]string{}
+	exists := map[string]bool{downstream.Identifier: true}
+	for _, ref := range refs {
+		exists[ref.Identifier] = true
+		if ref.Identifier == downstream.Identifier {
+			continue
+		}
+		for _, t := range ref.Triggers {
+			downstreams[t.Project] = append(downstreams[t.Project], ref.Identifier)
+		}
+	}
+	for _, t := range downstream.Triggers {
+		if err := t.Validate(downstream); err != nil {
+			return err
+		}
+		if !exists[t.Project] {
+			return errors.Errorf("upstream project '%s' does not exist", t.Project)
+		}
+		downstreams[t.Project] = append(downstreams[t.Project], downstream.Identifier)
+	}
+
+	if cycle := findTriggerCycle(downstream.Identifier, downstreams); cycle != nil {
+		return errors.Errorf("triggers form a cycle: %s", strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+// findTriggerCycle returns a chain of projects from the project back to
+// itself along the edges from upstream to downstream projects, or nil if
+// there is none.
+func findTriggerCycle(project string, downstreams map[string][]string) []string {
+	visited := map[string]bool{}
+	var visit func(path []string) []string
+	visit = func(path []string) []string {
+		for _, next := range downstreams[path[len(path)-1]] {
+			if next == project {
+				return append(path, next)
+			}
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+			if cycle := visit(append(path, next)); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	return visit([]string{project})
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTriggerMatches(t *testing.T) {
+	Convey("With a task trigger", t, func() {
+		trigger := TriggerDefinition{Project: "core", Level: TriggerLevelTask, Variant: "linux.*", TaskName: "package"}
+
+		Convey("it should fire for matching tasks that succeed by default", func() {
+			So(trigger.Matches("linux-64", "package", evergreen.TaskSucceeded), ShouldBeTrue)
+			So(trigger.Matches("linux-64", "package", evergreen.TaskFailed), ShouldBeFalse)
+			So(trigger.Matches("windows", "package", evergreen.TaskSucceeded), ShouldBeFalse)
+			So(trigger.Matches("linux-64", "package-debug", evergreen.TaskSucceeded), ShouldBeFalse)
+		})
+
+		Convey("it should fire for either status if any status is allowed", func() {
+			trigger.Status = TriggerStatusAny
+			So(trigger.Matches("linux-64", "package", evergreen.TaskFailed), ShouldBeTrue)
+			So(trigger.Matches("linux-64", "package", evergreen.TaskUndispatched), ShouldBeFalse)
+		})
+
+		Convey("a build trigger should ignore the task name", func() {
+			trigger.Level = TriggerLevelBuild
+			So(trigger.Matches("linux-64", "", evergreen.BuildSucceeded), ShouldBeTrue)
+		})
+	})
+}
+
+func TestValidateTriggers(t *testing.T) {
+	Convey("With a chain of projects that trigger each other", t, func() {
+		refs := []ProjectRef{
+			{Identifier: "core"},
+			{Identifier: "driver", Triggers: []TriggerDefinition{{Project: "core", Level: TriggerLevelBuild}}},
+			{Identifier: "app", Triggers: []TriggerDefinition{{Project: "driver", Level: TriggerLevelTask}}},
+		}
+
+		Convey("a project downstream of the chain should be valid", func() {
+			ref := &ProjectRef{Identifier: "docs", Triggers: []TriggerDefinition{{Project: "app", Level: TriggerLevelTask}}}
+			So(ValidateTriggers(ref, refs), ShouldBeNil)
+		})
+
+		Convey("a trigger that closes the chain into a cycle should be an error", func() {
+			ref := refs[0]
+			ref.Triggers = []TriggerDefinition{{Project: "app", Level: TriggerLevelTask}}
+			err := ValidateTriggers(&ref, refs)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "core -> driver -> app -> core")
+		})
+
+		Convey("a project should not trigger itself", func() {
+			ref := &ProjectRef{Identifier: "core", Triggers: []TriggerDefinition{{Project: "core", Level: TriggerLevelTask}}}
+			So(ValidateTriggers(ref, refs), ShouldNotBeNil)
+		})
+
+		Convey("triggers must name existing projects, valid levels, and defined aliases", func() {
+			ref := &ProjectRef{Identifier: "docs", Triggers: []TriggerDefinition{{Project: "missing", Level: TriggerLevelTask}}}
+			So(ValidateTriggers(ref, refs), ShouldNotBeNil)
+			ref.Triggers = []TriggerDefinition{{Project: "app", Level: "version"}}
+			So(ValidateTriggers(ref, refs), ShouldNotBeNil)
+			ref.Triggers = []TriggerDefinition{{Project: "app", Level: TriggerLevelTask, Alias: "smoke"}}
+			So(ValidateTriggers(ref, refs), ShouldNotBeNil)
+			ref.Aliases = []ProjectAlias{{Alias: "smoke", Variant: ".*", Tasks: []string{".*"}}}
+			So(ValidateTriggers(ref, refs), ShouldBeNil)
+		})
+	})
+}
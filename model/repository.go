@@ -14,6 +14,10 @@ type Repository struct {
 	Project             string `bson:"_id"`
 	LastRevision        string `bson:"last_revision"`
 	RevisionOrderNumber int    `bson:"last_commit_number"`
+	// GitTags are the names of the tags in the repository that the
+	// repotracker has seen, once GitTagsTracked is set.
+	GitTags        []string `bson:"git_tags,omitempty"`
+	GitTagsTracked bool     `bson:"git_tags_tracked,omitempty"`
 }
 
 var (
@@ -24,6 +28,10 @@ var (
 		"LastRevision")
 	RepositoryOrderNumberKey = bsonutil.MustHaveTag(Repository{},
 		"RevisionOrderNumber")
+	RepoGitTagsKey = bsonutil.MustHaveTag(Repository{},
+		"GitTags")
+	RepoGitTagsTrackedKey = bsonutil.MustHaveTag(Repository{},
+		"GitTagsTracked")
 )
 
 const (
@@ -32,11 +40,12 @@ const (
 
 const (
 	GithubRepoType = "github"
+	GitRepoType    = "git"
 )
 
-// valid repositories - currently only github supported
+// valid repositories - github, or any other git server
 var (
-	ValidRepoTypes = []string{GithubRepoType}
+	ValidRepoTypes = []string{GithubRepoType, GitRepoType}
 )
 
 type Revision struct {
@@ -80,6 +89,26 @@ func UpdateLastRevision(projectId, revision string) error {
 	)
 }
 
+// AddGitTags records that the repotracker has seen the given tags in the
+// project's repository.
+func AddGitTags(projectId string, tags []string) error {
+	_, err := db.Upsert(
+		RepositoriesCollection,
+		bson.M{
+			RepoProjectKey: projectId,
+		},
+		bson.M{
+			"$addToSet": bson.M{
+				RepoGitTagsKey: bson.M{"$each": tags},
+			},
+			"$set": bson.M{
+				RepoGitTagsTrackedKey: true,
+			},
+		},
+	)
+	return err
+}
+
 // GetNewRevisionOrderNumber gets a new revision order number for a project.
 func GetNewRevisionOrderNumber(projectId string) (int, error) {
 	repo := &Repository{}
@@ -56,17 +56,27 @@ var (
 	PriorityKey            = bsonutil.MustHaveTag(Task{}, "Priority")
 	ActivatedByKey         = bsonutil.MustHaveTag(Task{}, "ActivatedBy")
 	CostKey                = bsonutil.MustHaveTag(Task{}, "Cost")
+	LogsArchivedKey        = bsonutil.MustHaveTag(Task{}, "LogsArchived")
+	LogsArchiveAttemptsKey = bsonutil.MustHaveTag(Task{}, "LogsArchiveAttempts")
+	LogsArchiveRetryAtKey  = bsonutil.MustHaveTag(Task{}, "LogsArchiveRetryAt")
+	LogsIndexedKey         = bsonutil.MustHaveTag(Task{}, "LogsIndexed")
+	LogsIndexAttemptsKey   = bsonutil.MustHaveTag(Task{}, "LogsIndexAttempts")
+	LogsIndexRetryAtKey    = bsonutil.MustHaveTag(Task{}, "LogsIndexRetryAt")
+	DataExpiredKey         = bsonutil.MustHaveTag(Task{}, "DataExpired")
+	CacheHitsKey           = bsonutil.MustHaveTag(Task{}, "CacheHits")
+	CacheMissesKey         = bsonutil.MustHaveTag(Task{}, "CacheMisses")
 
 	// BSON fields for the test result struct
-	TestResultStatusKey    = bsonutil.MustHaveTag(TestResult{}, "Status")
-	TestResultLineNumKey   = bsonutil.MustHaveTag(TestResult{}, "LineNum")
-	TestResultTestFileKey  = bsonutil.MustHaveTag(TestResult{}, "TestFile")
-	TestResultURLKey       = bsonutil.MustHaveTag(TestResult{}, "URL")
-	TestResultLogIdKey     = bsonutil.MustHaveTag(TestResult{}, "LogId")
-	TestResultURLRawKey    = bsonutil.MustHaveTag(TestResult{}, "URLRaw")
-	TestResultExitCodeKey  = bsonutil.MustHaveTag(TestResult{}, "ExitCode")
-	TestResultStartTimeKey = bsonutil.MustHaveTag(TestResult{}, "StartTime")
-	TestResultEndTimeKey   = bsonutil.MustHaveTag(TestResult{}, "EndTime")
+	TestResultStatusKey      = bsonutil.MustHaveTag(TestResult{}, "Status")
+	TestResultLineNumKey     = bsonutil.MustHaveTag(TestResult{}, "LineNum")
+	TestResultTestFileKey    = bsonutil.MustHaveTag(TestResult{}, "TestFile")
+	TestResultURLKey         = bsonutil.MustHaveTag(TestResult{}, "URL")
+	TestResultLogIdKey       = bsonutil.MustHaveTag(TestResult{}, "LogId")
+	TestResultURLRawKey      = bsonutil.MustHaveTag(TestResult{}, "URLRaw")
+	TestResultExitCodeKey    = bsonutil.MustHaveTag(TestResult{}, "ExitCode")
+	TestResultStartTimeKey   = bsonutil.MustHaveTag(TestResult{}, "StartTime")
+	TestResultEndTimeKey     = bsonutil.MustHaveTag(TestResult{}, "EndTime")
+	TestResultQuarantinedKey = bsonutil.MustHaveTag(TestResult{}, "Quarantined")
 )
 
 var (
@@ -75,6 +85,8 @@ var (
 	TaskEndDetailTimedOut    = bsonutil.MustHaveTag(apimodels.TaskEndDetail{}, "TimedOut")
 	TaskEndDetailType        = bsonutil.MustHaveTag(apimodels.TaskEndDetail{}, "Type")
 	TaskEndDetailDescription = bsonutil.MustHaveTag(apimodels.TaskEndDetail{}, "Description")
+	TaskEndDetailOOMKilled   = bsonutil.MustHaveTag(apimodels.TaskEndDetail{}, "OOMKilled")
+	TaskEndDetailFailureType = bsonutil.MustHaveTag(apimodels.TaskEndDetail{}, "FailureType")
 )
 
 // Queries
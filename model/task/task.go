@@ -92,6 +92,31 @@ type Task struct {
 
 	// test results captured and sent back by agent
 	TestResults []TestResult `bson:"test_results" json:"test_results"`
+
+	// LogsArchived is set once the task's logs have been moved out of the database
+	LogsArchived bool `bson:"logs_archived,omitempty" json:"-"`
+
+	// LogsArchiveAttempts counts the failed attempts to archive the task's
+	// logs, and LogsArchiveRetryAt is when they may next be retried
+	LogsArchiveAttempts int       `bson:"logs_archive_attempts,omitempty" json:"-"`
+	LogsArchiveRetryAt  time.Time `bson:"logs_archive_retry_at,omitempty" json:"-"`
+
+	// LogsIndexed is set once the task's logs have been indexed for search
+	LogsIndexed bool `bson:"logs_indexed,omitempty" json:"-"`
+
+	// LogsIndexAttempts counts the failed attempts to index the task's logs,
+	// and LogsIndexRetryAt is when they may next be retried
+	LogsIndexAttempts int       `bson:"logs_index_attempts,omitempty" json:"-"`
+	LogsIndexRetryAt  time.Time `bson:"logs_index_retry_at,omitempty" json:"-"`
+
+	// DataExpired is set once the task's logs and artifacts have been deleted
+	// under its project's retention policy
+	DataExpired bool `bson:"data_expired,omitempty" json:"-"`
+
+	// CacheHits and CacheMisses count the cache restores of the task that
+	// did and did not find files saved under their key
+	CacheHits   int `bson:"cache_hits,omitempty" json:"cache_hits,omitempty"`
+	CacheMisses int `bson:"cache_misses,omitempty" json:"cache_misses,omitempty"`
 }
 
 // Dependency represents a task that must be completed before the owning
@@ -147,6 +172,10 @@ type TestResult struct {
 	StartTime float64 `json:"start" bson:"start"`
 	EndTime   float64 `json:"end" bson:"end"`
 
+	// Quarantined is set when the project has quarantined the test. A
+	// quarantined test's failures are recorded but do not fail the task.
+	Quarantined bool `json:"quarantined,omitempty" bson:"quarantined,omitempty"`
+
 	// LogRaw is not saved in the task
 	LogRaw string `json:"log_raw" bson:"log_raw,omitempty"`
 }
@@ -496,6 +525,15 @@ func (t *Task) Reset() error {
 	t.ScheduledTime = util.ZeroTime
 	t.FinishTime = util.ZeroTime
 	t.TestResults = []TestResult{}
+	t.LogsArchived = false
+	t.LogsArchiveAttempts = 0
+	t.LogsArchiveRetryAt = time.Time{}
+	t.LogsIndexed = false
+	t.LogsIndexAttempts = 0
+	t.LogsIndexRetryAt = time.Time{}
+	t.DataExpired = false
+	t.CacheHits = 0
+	t.CacheMisses = 0
 	reset := bson.M{
 		"$set": bson.M{
 			ActivatedKey:     true,
@@ -508,7 +546,16 @@ func (t *Task) Reset() error {
 			TestResultsKey:   []TestResult{},
 		},
 		"$unset": bson.M{
-			DetailsKey: "",
+			DetailsKey:             "",
+			LogsArchivedKey:        "",
+			LogsArchiveAttemptsKey: "",
+			LogsArchiveRetryAtKey:  "",
+			LogsIndexedKey:         "",
+			LogsIndexAttemptsKey:   "",
+			LogsIndexRetryAtKey:    "",
+			DataExpiredKey:         "",
+			CacheHitsKey:           "",
+			CacheMissesKey:         "",
 		},
 	}
 
@@ -535,7 +582,16 @@ func ResetTasks(taskIds []string) error {
 			TestResultsKey:   []TestResult{},
 		},
 		"$unset": bson.M{
-			DetailsKey: "",
+			DetailsKey:             "",
+			LogsArchivedKey:        "",
+			LogsArchiveAttemptsKey: "",
+			LogsArchiveRetryAtKey:  "",
+			LogsIndexedKey:         "",
+			LogsIndexAttemptsKey:   "",
+			LogsIndexRetryAtKey:    "",
+			DataExpiredKey:         "",
+			CacheHitsKey:           "",
+			CacheMissesKey:         "",
 		},
 	}
 
@@ -549,6 +605,112 @@ func ResetTasks(taskIds []string) error {
 
 }
 
+// SetLogsArchived marks the task's logs as moved out of the database.
+func (t *Task) SetLogsArchived() error {
+	t.LogsArchived = true
+	return UpdateOne(
+		bson.M{
+			IdKey: t.Id,
+		},
+		bson.M{
+			"$set": bson.M{
+				LogsArchivedKey: true,
+			},
+		},
+	)
+}
+
+// MarkLogsArchiveFailed records a failed attempt to archive the task's logs,
+// so that it is not retried before retryAt.
+func (t *Task) MarkLogsArchiveFailed(retryAt time.Time) error {
+	t.LogsArchiveAttempts++
+	t.LogsArchiveRetryAt = retryAt
+	return UpdateOne(
+		bson.M{
+			IdKey: t.Id,
+		},
+		bson.M{
+			"$inc": bson.M{
+				LogsArchiveAttemptsKey: 1,
+			},
+			"$set": bson.M{
+				LogsArchiveRetryAtKey: retryAt,
+			},
+		},
+	)
+}
+
+// SetLogsIndexed marks the task's logs as indexed for search.
+func (t *Task) SetLogsIndexed() error {
+	t.LogsIndexed = true
+	return UpdateOne(
+		bson.M{
+			IdKey: t.Id,
+		},
+		bson.M{
+			"$set": bson.M{
+				LogsIndexedKey: true,
+			},
+		},
+	)
+}
+
+// MarkLogsIndexFailed records a failed attempt to index the task's logs, so
+// that it is not retried before retryAt.
+func (t *Task) MarkLogsIndexFailed(retryAt time.Time) error {
+	t.LogsIndexAttempts++
+	t.LogsIndexRetryAt = retryAt
+	return UpdateOne(
+		bson.M{
+			IdKey: t.Id,
+		},
+		bson.M{
+			"$inc": bson.M{
+				LogsIndexAttemptsKey: 1,
+			},
+			"$set": bson.M{
+				LogsIndexRetryAtKey: retryAt,
+			},
+		},
+	)
+}
+
+// SetDataExpired marks the task's logs and artifacts as deleted.
+func (t *Task) SetDataExpired() error {
+	t.DataExpired = true
+	return UpdateOne(
+		bson.M{
+			IdKey: t.Id,
+		},
+		bson.M{
+			"$set": bson.M{
+				DataExpiredKey: true,
+			},
+		},
+	)
+}
+
+// IncCacheStats counts a cache restore of the task as a hit or a miss.
+func (t *Task) IncCacheStats(hit bool) error {
+	key := CacheMissesKey
+	if hit {
+		key = CacheHitsKey
+		t.CacheHits++
+	} else {
+		t.CacheMisses++
+	}
+	return UpdateOne(
+		bson.M{
+			IdKey: t.Id,
+		},
+		bson.M{
+			"$inc": bson.M{
+				key: 1,
+			},
+		},
+	)
+}
+
 // UpdateHeartbeat updates the heartbeat to be the current time
 func (t *Task) UpdateHeartbeat() error {
 	t.LastHeartbeat = time.Now()
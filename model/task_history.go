@@ -66,6 +66,7 @@ type TestHistoryResult struct {
 	TaskStatus      string  `bson:"task_status"`
 	TestStatus      string  `bson:"test_status"`
 	Revision        string  `bson:"r"`
+	RevisionOrder   int     `bson:"ord"`
 	Project         string  `bson:"p"`
 	TaskId          string  `bson:"tid"`
 	BuildVariant    string  `bson:"bv"`
@@ -87,6 +88,7 @@ var (
 	TaskStatusKey      = bsonutil.MustHaveTag(TestHistoryResult{}, "TaskStatus")
 	TestStatusKey      = bsonutil.MustHaveTag(TestHistoryResult{}, "TestStatus")
 	RevisionKey        = bsonutil.MustHaveTag(TestHistoryResult{}, "Revision")
+	RevisionOrderKey   = bsonutil.MustHaveTag(TestHistoryResult{}, "RevisionOrder")
 	ProjectKey         = bsonutil.MustHaveTag(TestHistoryResult{}, "Project")
 	TaskIdKey          = bsonutil.MustHaveTag(TestHistoryResult{}, "TaskId")
 	BuildVariantKey    = bsonutil.MustHaveTag(TestHistoryResult{}, "BuildVariant")
@@ -623,6 +625,7 @@ func buildTestHistoryQuery(testHistoryParameters *TestHistoryParameters) ([]bson
 			TestStatusKey:      "$" + task.TestResultsKey + "." + task.TestResultStatusKey,
 			TaskStatusKey:      "$" + task.StatusKey,
 			RevisionKey:        "$" + task.RevisionKey,
+			RevisionOrderKey:   "$" + task.RevisionOrderNumberKey,
 			ProjectKey:         "$" + task.ProjectKey,
 			TaskNameKey:        "$" + task.DisplayNameKey,
 			BuildVariantKey:    "$" + task.BuildVariantKey,
@@ -257,6 +257,15 @@ func doStepback(t *task.Task) error {
 	return errors.WithStack(ActivatePreviousTask(t.Id, evergreen.StepbackTaskActivator))
 }
 
+// testResultsCommands are the commands that attach test results, and fail when
+// any of the tests failed.
+var testResultsCommands = map[string]bool{
+	"attach.results":       true,
+	"attach.xunit_results": true,
+	"attach.test_results":  true,
+	"gotest.parse_files":   true,
+}
+
 // MarkEnd updates the task as being finished, performs a stepback if necessary, and updates the build status
 func MarkEnd(taskId, caller string, finishTime time.Time, detail *apimodels.TaskEndDetail,
 	p *Project, deactivatePrevious bool) error {
@@ -269,10 +278,28 @@ func MarkEnd(taskId, caller string, finishTime time.Time, detail *apimodels.Task
 		return errors.Errorf("Task not found for taskId: %s", taskId)
 	}
 
+	failedTests, quarantinedFailures := 0, 0
 	for _, result := range t.TestResults {
-		if result.Status == evergreen.TestFailedStatus {
-			detail.Status = evergreen.TaskFailed
-			break
+		if result.Status != evergreen.TestFailedStatus {
+			continue
+		}
+		if result.Quarantined {
+			quarantinedFailures++
+		} else {
+			failedTests++
+		}
+	}
+	if failedTests > 0 {
+		detail.Status = evergreen.TaskFailed
+	} else if quarantinedFailures > 0 && detail.Status == evergreen.TaskFailed &&
+		detail.FailureType == apimodels.FailureTypeTest && testResultsCommands[detail.FailedCommand] {
+		// the task failed only because of its test results, and the only
+		// tests that failed are quarantined, so their failures are kept in
+		// the results but the task passes
+		*detail = apimodels.TaskEndDetail{
+			Status:      evergreen.TaskSucceeded,
+			Type:        detail.Type,
+			Description: QuarantinedFailuresDescription,
 		}
 	}
 
@@ -527,6 +527,40 @@ func TestMarkEnd(t *testing.T) {
 			So(MarkEnd(testTask.Id, userName, time.Now(), &details, p, false), ShouldBeNil)
 
 		})
+
+		Convey("with only quarantined tests failing", func() {
+			So(testTask.SetResults([]task.TestResult{
+				{TestFile: "passing", Status: evergreen.TestSucceededStatus},
+				{TestFile: "flaky", Status: evergreen.TestFailedStatus, Quarantined: true},
+			}), ShouldBeNil)
+
+			Convey("a task failed by its test results should pass", func() {
+				details := apimodels.TaskEndDetail{
+					Status:        evergreen.TaskFailed,
+					FailureType:   apimodels.FailureTypeTest,
+					FailedCommand: "attach.xunit_results",
+				}
+				So(MarkEnd(testTask.Id, userName, time.Now(), &details, p, false), ShouldBeNil)
+				t, err := task.FindOne(task.ById(testTask.Id))
+				So(err, ShouldBeNil)
+				So(t.Status, ShouldEqual, evergreen.TaskSucceeded)
+				So(t.Details.Description, ShouldEqual, QuarantinedFailuresDescription)
+			})
+
+			Convey("a task failed by another command should still fail", func() {
+				details := apimodels.TaskEndDetail{
+					Status:        evergreen.TaskFailed,
+					FailureType:   apimodels.FailureTypeTest,
+					FailedCommand: "shell.exec",
+					ExitCode:      1,
+				}
+				So(MarkEnd(testTask.Id, userName, time.Now(), &details, p, false), ShouldBeNil)
+				t, err := task.FindOne(task.ById(testTask.Id))
+				So(err, ShouldBeNil)
+				So(t.Status, ShouldEqual, evergreen.TaskFailed)
+				So(t.Details.FailedCommand, ShouldEqual, "shell.exec")
+			})
+		})
 	})
 }
 
@@ -44,6 +44,10 @@ type TaskLog struct {
 	Timestamp    time.Time     `bson:"ts" json:"ts"`
 	MessageCount int           `bson:"c" json:"c"`
 	Messages     []LogMessage  `bson:"m" json:"m"`
+
+	// Sequence orders the chunks of a log sent over a log stream, so that
+	// chunks resent after a reconnect are only stored once
+	Sequence int64 `bson:"seq,omitempty" json:"seq,omitempty"`
 }
 
 var (
@@ -54,6 +58,7 @@ var (
 	TaskLogTimestampKey    = bsonutil.MustHaveTag(TaskLog{}, "Timestamp")
 	TaskLogMessageCountKey = bsonutil.MustHaveTag(TaskLog{}, "MessageCount")
 	TaskLogMessagesKey     = bsonutil.MustHaveTag(TaskLog{}, "Messages")
+	TaskLogSequenceKey     = bsonutil.MustHaveTag(TaskLog{}, "Sequence")
 
 	// bson fields for the log message struct
 	LogMessageTypeKey      = bsonutil.MustHaveTag(LogMessage{}, "Type")
@@ -84,6 +89,56 @@ func (self *TaskLog) Insert() error {
 	return db.C(TaskLogCollection).Insert(self)
 }
 
+// EnsureTaskLogIndexes creates the unique index that keeps a chunk of a log
+// stream from being stored twice when the agent resends it.
+func EnsureTaskLogIndexes() error {
+	session, db, err := getSessionAndDB()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	// mgo.Index cannot describe a partial index, and only streamed chunks have
+	// a sequence number
+	return db.Run(bson.D{
+		{Name: "createIndexes", Value: TaskLogCollection},
+		{Name: "indexes", Value: []bson.M{{
+			"name": "t_id_1_e_1_seq_1",
+			"key": bson.D{
+				{Name: TaskLogTaskIdKey, Value: 1},
+				{Name: TaskLogExecutionKey, Value: 1},
+				{Name: TaskLogSequenceKey, Value: 1},
+			},
+			"unique": true,
+			"partialFilterExpression": bson.M{
+				TaskLogSequenceKey: bson.M{"$exists": true},
+			},
+		}}},
+	}, nil)
+}
+
+// InsertChunk stores a chunk of a log stream, unless a chunk with the same or a
+// later sequence number is already stored for the task execution, which happens
+// when the agent resends a chunk whose acknowledgement it did not receive. It
+// returns the sequence number of the last stored chunk.
+func (self *TaskLog) InsertChunk() (int64, error) {
+	last, err := FindLastTaskLogSequence(self.TaskId, self.Execution)
+	if err != nil {
+		return 0, err
+	}
+	if self.Sequence <= last {
+		return last, nil
+	}
+	if err = self.Insert(); err != nil {
+		// a resent chunk stored since the check is as good as stored by us
+		if mgo.IsDup(err) {
+			return self.Sequence, nil
+		}
+		return last, err
+	}
+	return self.Sequence, nil
+}
+
 func (self *TaskLog) AddLogMessage(msg LogMessage) error {
 	session, db, err := getSessionAndDB()
 	if err != nil {
@@ -130,6 +185,41 @@ func FindAllTaskLogs(taskId string, execution int) ([]TaskLog, error) {
 	return result, err
 }
 
+// RemoveTaskLogs deletes every chunk of the log of a task execution.
+func RemoveTaskLogs(taskId string, execution int) error {
+	session, db, err := getSessionAndDB()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	_, err = db.C(TaskLogCollection).RemoveAll(taskLogExecutionQuery(taskId, execution))
+	return err
+}
+
+// CountTaskLogs returns how many chunks the log of a task execution has.
+func CountTaskLogs(taskId string, execution int) (int, error) {
+	session, db, err := getSessionAndDB()
+	if err != nil {
+		return 0, err
+	}
+	defer session.Close()
+
+	return db.C(TaskLogCollection).Find(taskLogExecutionQuery(taskId, execution)).Count()
+}
+
+func taskLogExecutionQuery(taskId string, execution int) bson.M {
+	query := bson.M{
+		TaskLogTaskIdKey:    taskId,
+		TaskLogExecutionKey: execution,
+	}
+	// logs of the first execution may have no execution field, as in GetRawTaskLogChannel
+	if execution == 0 {
+		query[TaskLogExecutionKey] = bson.M{"$in": []interface{}{0, nil}}
+	}
+	return query
+}
+
 func FindMostRecentTaskLogs(taskId string, execution int, limit int) ([]TaskLog, error) {
 	session, db, err := getSessionAndDB()
 	if err != nil {
@@ -150,6 +240,55 @@ func FindMostRecentTaskLogs(taskId string, execution int, limit int) ([]TaskLog,
 	return result, err
 }
 
+// FindLastTaskLogSequence returns the highest sequence number of the chunks of
+// the task's log that were received over a log stream, or 0 if there are none.
+func FindLastTaskLogSequence(taskId string, execution int) (int64, error) {
+	session, db, err := getSessionAndDB()
+	if err != nil {
+		return 0, err
+	}
+	defer session.Close()
+
+	result := TaskLog{}
+	err = db.C(TaskLogCollection).Find(
+		bson.M{
+			TaskLogTaskIdKey:    taskId,
+			TaskLogExecutionKey: execution,
+			TaskLogSequenceKey:  bson.M{"$gt": 0},
+		},
+	).Sort("-" + TaskLogSequenceKey).Select(bson.M{TaskLogSequenceKey: 1}).One(&result)
+	if err == mgo.ErrNotFound {
+		return 0, nil
+	}
+	return result.Sequence, err
+}
+
+// FindTaskLogsAfterId returns the chunks of the task's log that were stored
+// after the chunk with the given id, oldest first. If the id is empty, all
+// chunks are returned.
+func FindTaskLogsAfterId(taskId string, execution int, after bson.ObjectId) ([]TaskLog, error) {
+	session, db, err := getSessionAndDB()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	query := bson.M{
+		TaskLogTaskIdKey:    taskId,
+		TaskLogExecutionKey: execution,
+	}
+	if after != "" {
+		query[TaskLogIdKey] = bson.M{"$gt": after}
+	}
+
+	result := []TaskLog{}
+	err = db.C(TaskLogCollection).Find(query).Sort(TaskLogIdKey).All(&result)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	return result, err
+}
+
 func FindTaskLogsBeforeTime(taskId string, execution int, ts time.Time, limit int) ([]TaskLog, error) {
 	session, db, err := getSessionAndDB()
 	if err != nil {
@@ -267,6 +406,27 @@ func (self *LogMessage) Insert(taskId string, execution int) error {
 	return mostRecent[0].AddLogMessage(*self)
 }
 
+// MatchesFilter returns true if the message has one of the given severities
+// and one of the given types. Empty filters match every message, and the
+// types of old messages are matched by their long names.
+func (self *LogMessage) MatchesFilter(severities []string, msgTypes []string) bool {
+	if len(severities) > 0 && !util.SliceContains(severities, self.Severity) {
+		return false
+	}
+	if len(msgTypes) == 0 || util.SliceContains(msgTypes, self.Type) {
+		return true
+	}
+	for _, msgType := range msgTypes {
+		switch {
+		case msgType == SystemLogPrefix && self.Type == "system",
+			msgType == AgentLogPrefix && self.Type == "agent",
+			msgType == TaskLogPrefix && self.Type == "task":
+			return true
+		}
+	}
+	return false
+}
+
 // note: to ignore severity or type filtering, pass in empty slices
 func FindMostRecentLogMessages(taskId string, execution int, numMsgs int,
 	severities []string, msgTypes []string) ([]LogMessage, error) {
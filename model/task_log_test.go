@@ -160,6 +160,63 @@ func TestAddLogMessage(t *testing.T) {
 
 }
 
+func TestInsertLogChunk(t *testing.T) {
+
+	Convey("When inserting chunks of a log stream", t, func() {
+
+		testutil.HandleTestingErr(cleanUpLogDB(), t, "Error cleaning up task log"+
+			" database")
+		So(EnsureTaskLogIndexes(), ShouldBeNil)
+
+		for i := 1; i <= 3; i++ {
+			chunk := &TaskLog{TaskId: "task_id", Sequence: int64(i), Timestamp: time.Now()}
+			last, err := chunk.InsertChunk()
+			So(err, ShouldBeNil)
+			So(last, ShouldEqual, i)
+		}
+
+		Convey("a chunk that is already stored should not be stored again", func() {
+			chunk := &TaskLog{TaskId: "task_id", Sequence: 2, Timestamp: time.Now()}
+			last, err := chunk.InsertChunk()
+			So(err, ShouldBeNil)
+			So(last, ShouldEqual, 3)
+
+			taskLogs, err := FindAllTaskLogs("task_id", 0)
+			So(err, ShouldBeNil)
+			So(len(taskLogs), ShouldEqual, 3)
+		})
+
+		Convey("a chunk sent on two connections at once should be stored once", func() {
+			errs := make(chan error, 10)
+			for i := 0; i < 10; i++ {
+				go func() {
+					chunk := &TaskLog{TaskId: "task_id", Sequence: 4, Timestamp: time.Now()}
+					_, err := chunk.InsertChunk()
+					errs <- err
+				}()
+			}
+			for i := 0; i < 10; i++ {
+				So(<-errs, ShouldBeNil)
+			}
+
+			taskLogs, err := FindAllTaskLogs("task_id", 0)
+			So(err, ShouldBeNil)
+			So(len(taskLogs), ShouldEqual, 4)
+		})
+
+		Convey("chunks that are not part of a stream should not be limited", func() {
+			for i := 0; i < 2; i++ {
+				So((&TaskLog{TaskId: "task_id", Timestamp: time.Now()}).Insert(), ShouldBeNil)
+			}
+			taskLogs, err := FindAllTaskLogs("task_id", 0)
+			So(err, ShouldBeNil)
+			So(len(taskLogs), ShouldEqual, 5)
+		})
+
+	})
+
+}
+
 func TestInsertLogMessage(t *testing.T) {
 
 	Convey("When inserting a log message", t, func() {
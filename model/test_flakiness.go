@@ -0,0 +1,230 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultFlakinessRevisions is how many of a project's most recent
+	// commits test flakiness is computed over by default.
+	DefaultFlakinessRevisions = 50
+
+	// QuarantinedFailuresDescription is the description of a task that
+	// passed because the only tests that failed in it were quarantined.
+	QuarantinedFailuresDescription = "only quarantined tests failed"
+)
+
+// TestFlakiness describes how often a test's result flipped between passing
+// and failing, both when a task was restarted on the same commit and between
+// nearby commits.
+type TestFlakiness struct {
+	TestFile     string `json:"test_file"`
+	TaskName     string `json:"task_name"`
+	BuildVariant string `json:"build_variant"`
+
+	Runs      int `json:"runs"`
+	Failures  int `json:"failures"`
+	Revisions int `json:"revisions"`
+
+	// SameRevisionFlips is the number of commits on which the test both
+	// passed and failed.
+	SameRevisionFlips int `json:"same_revision_flips"`
+	// Transitions is the number of times the test's result changed from one
+	// commit to the next, leaving out commits where it both passed and
+	// failed.
+	Transitions int `json:"transitions"`
+
+	// Score is the fraction of the chances the test had to flip that it did
+	// flip, from 0 for a test that was stable on every commit to 1 for a
+	// test that flipped every time it could.
+	Score float64 `json:"score"`
+
+	// LastFailedRevision is the most recent commit the test failed on.
+	LastFailedRevision string `json:"last_failed_revision,omitempty"`
+
+	Quarantined bool `json:"quarantined"`
+}
+
+// TestFlakinessParameters are the parameters of a test flakiness query.
+type TestFlakinessParameters struct {
+	Project       string   `json:"project"`
+	TaskNames     []string `json:"task_names"`
+	BuildVariants []string `json:"variants"`
+	TestNames     []string `json:"test_names"`
+
+	// Revisions is how many of the project's most recent mainline commits
+	// are considered.
+	Revisions int `json:"revisions"`
+
+	// MinScore leaves out tests that are less flaky than it.
+	MinScore float64 `json:"min_score"`
+}
+
+// GetTestFlakiness computes the flakiness of the tests that ran on the
+// project's most recent commits from their test history, most flaky first.
+// Tests that never failed are left out.
+func GetTestFlakiness(params *TestFlakinessParameters) ([]TestFlakiness, error) {
+	if params.Revisions <= 0 {
+		params.Revisions = DefaultFlakinessRevisions
+	}
+
+	// find the commits that bound the history; the after revision is
+	// exclusive, so one more than the window is needed
+	versions, err := version.Find(version.ByMostRecentForRequester(params.Project,
+		evergreen.RepotrackerVersionRequester).
+		WithFields(version.RevisionKey).
+		Limit(params.Revisions + 1))
+	if err != nil {
+		return nil, errors.Wrap(err, "error finding recent commits")
+	}
+	if len(versions) == 0 {
+		return []TestFlakiness{}, nil
+	}
+
+	historyParams := &TestHistoryParameters{
+		Project:        params.Project,
+		TaskNames:      params.TaskNames,
+		BuildVariants:  params.BuildVariants,
+		TestNames:      params.TestNames,
+		TaskStatuses:   []string{evergreen.TaskFailed, evergreen.TaskSucceeded},
+		TestStatuses:   []string{evergreen.TestFailedStatus, evergreen.TestSucceededStatus},
+		BeforeRevision: versions[0].Revision,
+		Sort:           1,
+	}
+	if len(versions) > params.Revisions {
+		historyParams.AfterRevision = versions[len(versions)-1].Revision
+	}
+	if err = historyParams.SetDefaultsAndValidate(); err != nil {
+		return nil, err
+	}
+	history, err := GetTestHistory(historyParams)
+	if err != nil {
+		return nil, errors.Wrap(err, "error finding test history")
+	}
+
+	projectRef, err := FindOneProjectRef(params.Project)
+	if err != nil {
+		return nil, errors.Wrap(err, "error finding project")
+	}
+
+	flakiness := []TestFlakiness{}
+	for _, f := range ComputeTestFlakiness(history) {
+		if f.Score < params.MinScore {
+			continue
+		}
+		if projectRef != nil {
+			f.Quarantined = projectRef.IsQuarantined(f.TaskName, f.TestFile)
+		}
+		flakiness = append(flakiness, f)
+	}
+	return flakiness, nil
+}
+
+type flakinessKey struct {
+	testFile     string
+	taskName     string
+	buildVariant string
+}
+
+type revisionResults struct {
+	order    int
+	revision string
+	passed   bool
+	failed   bool
+}
+
+type revisionResultsByOrder []*revisionResults
+
+func (r revisionResultsByOrder) Len() int           { return len(r) }
+func (r revisionResultsByOrder) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r revisionResultsByOrder) Less(i, j int) bool { return r[i].order < r[j].order }
+
+type testFlakinessByScore []TestFlakiness
+
+func (t testFlakinessByScore) Len() int      { return len(t) }
+func (t testFlakinessByScore) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
+func (t testFlakinessByScore) Less(i, j int) bool {
+	if t[i].Score != t[j].Score {
+		return t[i].Score > t[j].Score
+	}
+	if t[i].TestFile != t[j].TestFile {
+		return t[i].TestFile < t[j].TestFile
+	}
+	if t[i].TaskName != t[j].TaskName {
+		return t[i].TaskName < t[j].TaskName
+	}
+	return t[i].BuildVariant < t[j].BuildVariant
+}
+
+// ComputeTestFlakiness scores the flakiness of each test in each task and
+// variant of the history, which may be in any order. Tests that never
+// failed are left out. The result is sorted most flaky first.
+func ComputeTestFlakiness(history []TestHistoryResult) []TestFlakiness {
+	runs := map[flakinessKey]map[int]*revisionResults{}
+	counts := map[flakinessKey]*TestFlakiness{}
+	for _, result := range history {
+		key := flakinessKey{result.TestFile, result.TaskName, result.BuildVariant}
+		if _, ok := runs[key]; !ok {
+			runs[key] = map[int]*revisionResults{}
+			counts[key] = &TestFlakiness{
+				TestFile:     result.TestFile,
+				TaskName:     result.TaskName,
+				BuildVariant: result.BuildVariant,
+			}
+		}
+		rev, ok := runs[key][result.RevisionOrder]
+		if !ok {
+			rev = &revisionResults{order: result.RevisionOrder, revision: result.Revision}
+			runs[key][result.RevisionOrder] = rev
+		}
+
+		counts[key].Runs++
+		switch result.TestStatus {
+		case evergreen.TestFailedStatus:
+			rev.failed = true
+			counts[key].Failures++
+		case evergreen.TestSucceededStatus:
+			rev.passed = true
+		}
+	}
+
+	flakiness := []TestFlakiness{}
+	for key, byOrder := range runs {
+		f := counts[key]
+		if f.Failures == 0 {
+			continue
+		}
+
+		revisions := make(revisionResultsByOrder, 0, len(byOrder))
+		for _, rev := range byOrder {
+			revisions = append(revisions, rev)
+		}
+		sort.Sort(revisions)
+
+		f.Revisions = len(revisions)
+		var previous *revisionResults
+		for _, rev := range revisions {
+			if rev.failed {
+				f.LastFailedRevision = rev.revision
+			}
+			if rev.passed && rev.failed {
+				f.SameRevisionFlips++
+				continue
+			}
+			if previous != nil && previous.failed != rev.failed {
+				f.Transitions++
+			}
+			previous = rev
+		}
+
+		// a test can flip on each commit, and between each pair of commits
+		f.Score = float64(f.SameRevisionFlips+f.Transitions) / float64(2*f.Revisions-1)
+		flakiness = append(flakiness, *f)
+	}
+	sort.Sort(testFlakinessByScore(flakiness))
+	return flakiness
+}
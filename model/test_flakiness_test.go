@@ -0,0 +1,105 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/task"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func flakinessResult(test string, order int, status string) TestHistoryResult {
+	return TestHistoryResult{
+		TestFile:      test,
+		TaskName:      "compile",
+		BuildVariant:  "linux",
+		Revision:      fmt.Sprintf("rev%d", order),
+		RevisionOrder: order,
+		TestStatus:    status,
+	}
+}
+
+func TestComputeTestFlakiness(t *testing.T) {
+	Convey("With a test history", t, func() {
+		history := []TestHistoryResult{
+			// stable test, never failed
+			flakinessResult("stable", 1, evergreen.TestSucceededStatus),
+			flakinessResult("stable", 2, evergreen.TestSucceededStatus),
+			flakinessResult("stable", 3, evergreen.TestSucceededStatus),
+
+			// broken on the last commit
+			flakinessResult("broken", 1, evergreen.TestSucceededStatus),
+			flakinessResult("broken", 2, evergreen.TestSucceededStatus),
+			flakinessResult("broken", 3, evergreen.TestFailedStatus),
+
+			// flips on a restart and between commits, out of order
+			flakinessResult("flaky", 3, evergreen.TestSucceededStatus),
+			flakinessResult("flaky", 1, evergreen.TestFailedStatus),
+			flakinessResult("flaky", 2, evergreen.TestSucceededStatus),
+			flakinessResult("flaky", 2, evergreen.TestFailedStatus),
+		}
+		flakiness := ComputeTestFlakiness(history)
+
+		Convey("tests that never failed should be left out", func() {
+			So(len(flakiness), ShouldEqual, 2)
+		})
+
+		Convey("the most flaky test should come first", func() {
+			So(flakiness[0].TestFile, ShouldEqual, "flaky")
+			So(flakiness[1].TestFile, ShouldEqual, "broken")
+		})
+
+		Convey("flips on the same commit and between commits should be counted", func() {
+			flaky := flakiness[0]
+			So(flaky.Runs, ShouldEqual, 4)
+			So(flaky.Failures, ShouldEqual, 2)
+			So(flaky.Revisions, ShouldEqual, 3)
+			So(flaky.SameRevisionFlips, ShouldEqual, 1)
+			So(flaky.Transitions, ShouldEqual, 1)
+			So(flaky.Score, ShouldAlmostEqual, 2.0/5.0)
+			So(flaky.LastFailedRevision, ShouldEqual, "rev2")
+
+			broken := flakiness[1]
+			So(broken.SameRevisionFlips, ShouldEqual, 0)
+			So(broken.Transitions, ShouldEqual, 1)
+			So(broken.Score, ShouldAlmostEqual, 1.0/5.0)
+			So(broken.LastFailedRevision, ShouldEqual, "rev3")
+		})
+	})
+}
+
+func TestQuarantinedTests(t *testing.T) {
+	Convey("With a project that quarantines tests", t, func() {
+		projectRef := &ProjectRef{
+			Identifier: "project",
+			QuarantinedTests: []QuarantinedTest{
+				{TestFile: "everywhere"},
+				{TestFile: "compile_only", TaskName: "compile"},
+			},
+		}
+
+		Convey("a test quarantined without a task should match every task", func() {
+			So(projectRef.IsQuarantined("compile", "everywhere"), ShouldBeTrue)
+			So(projectRef.IsQuarantined("test", "everywhere"), ShouldBeTrue)
+		})
+
+		Convey("a test quarantined in a task should only match that task", func() {
+			So(projectRef.IsQuarantined("compile", "compile_only"), ShouldBeTrue)
+			So(projectRef.IsQuarantined("test", "compile_only"), ShouldBeFalse)
+			So(projectRef.IsQuarantined("compile", "other"), ShouldBeFalse)
+		})
+
+		Convey("attached results should be marked as quarantined", func() {
+			results := []task.TestResult{
+				{TestFile: "everywhere", Status: evergreen.TestFailedStatus},
+				{TestFile: "compile_only", Status: evergreen.TestFailedStatus},
+				{TestFile: "other", Status: evergreen.TestFailedStatus},
+			}
+			projectRef.MarkQuarantinedResults("test", results)
+			So(results[0].Quarantined, ShouldBeTrue)
+			So(results[1].Quarantined, ShouldBeFalse)
+			So(results[2].Quarantined, ShouldBeFalse)
+		})
+	})
+}
@@ -66,6 +66,46 @@ func FindOneTestLog(name, task string, execution int) (*TestLog, error) {
 	return tl, errors.WithStack(err)
 }
 
+// FindTestLogsByTask returns every TestLog of a task execution.
+func FindTestLogsByTask(task string, execution int) ([]TestLog, error) {
+	logs := []TestLog{}
+	err := db.FindAll(
+		TestLogCollection,
+		bson.M{
+			TestLogTaskKey:          task,
+			TestLogTaskExecutionKey: execution,
+		},
+		db.NoProjection,
+		db.NoSort,
+		db.NoSkip,
+		db.NoLimit,
+		&logs,
+	)
+	return logs, errors.WithStack(err)
+}
+
+// RemoveTestLog deletes the TestLog with the given id.
+func RemoveTestLog(id string) error {
+	return errors.WithStack(db.Remove(TestLogCollection, bson.M{TestLogIdKey: id}))
+}
+
+// CountTestLogsByTask returns how many test logs a task execution has.
+func CountTestLogsByTask(task string, execution int) (int, error) {
+	count, err := db.Count(TestLogCollection, bson.M{
+		TestLogTaskKey:          task,
+		TestLogTaskExecutionKey: execution,
+	})
+	return count, errors.WithStack(err)
+}
+
+// RemoveTestLogsByTask deletes the test logs of a task execution.
+func RemoveTestLogsByTask(task string, execution int) error {
+	return errors.WithStack(db.RemoveAll(TestLogCollection, bson.M{
+		TestLogTaskKey:          task,
+		TestLogTaskExecutionKey: execution,
+	}))
+}
+
 // Insert inserts the TestLog into the database
 func (self *TestLog) Insert() error {
 	self.Id = bson.NewObjectId().Hex()
@@ -40,6 +40,14 @@ var (
 	IdentifierKey          = bsonutil.MustHaveTag(Version{}, "Identifier")
 	RemoteKey              = bsonutil.MustHaveTag(Version{}, "Remote")
 	RemoteURLKey           = bsonutil.MustHaveTag(Version{}, "RemotePath")
+	PinnedKey              = bsonutil.MustHaveTag(Version{}, "Pinned")
+	SkippedTasksKey        = bsonutil.MustHaveTag(Version{}, "SkippedTasks")
+	ExpansionsKey          = bsonutil.MustHaveTag(Version{}, "Expansions")
+	TriggerIDKey           = bsonutil.MustHaveTag(Version{}, "TriggerID")
+	TriggerTypeKey         = bsonutil.MustHaveTag(Version{}, "TriggerType")
+	TriggerProjectKey      = bsonutil.MustHaveTag(Version{}, "TriggerProject")
+	TriggerVersionKey      = bsonutil.MustHaveTag(Version{}, "TriggerVersion")
+	GitTagKey              = bsonutil.MustHaveTag(Version{}, "GitTag")
 )
 
 // ById returns a db.Q object which will filter on {_id : <the id param>}
@@ -118,6 +126,15 @@ func ByLastVariantActivation(projectId, variant string) db.Q {
 	).Sort([]string{"-" + RevisionOrderNumberKey})
 }
 
+// ByPinnedInProject finds the pinned versions of a project.
+func ByPinnedInProject(projectId string) db.Q {
+	return db.Query(
+		bson.M{
+			IdentifierKey: projectId,
+			PinnedKey:     true,
+		})
+}
+
 // ByProjectId finds all non-patch versions within a project.
 func ByProjectId(projectId string) db.Q {
 	return db.Query(
@@ -138,6 +155,19 @@ func ByMostRecentForRequester(projectId, requester string) db.Q {
 	).Sort([]string{"-" + RevisionOrderNumberKey})
 }
 
+// ByMostRecentlyCreatedForRequester finds all versions within a project that
+// have the given requester, ordered by most recently created to oldest. Unlike
+// ordering by revision order number, this orders versions that the
+// repotracker did not create for new commits.
+func ByMostRecentlyCreatedForRequester(projectId, requester string) db.Q {
+	return db.Query(
+		bson.M{
+			RequesterKey:  requester,
+			IdentifierKey: projectId,
+		},
+	).Sort([]string{"-" + CreateTimeKey})
+}
+
 // ByMostRecentNonignored finds all non-ignored versions within a project,
 // ordered by most recently created to oldest.
 func ByMostRecentNonignored(projectId string) db.Q {
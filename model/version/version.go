@@ -44,6 +44,35 @@ type Version struct {
 	// this field is omitted in the database
 	Errors   []string `bson:"errors,omitempty" json:"errors,omitempty"`
 	Warnings []string `bson:"warnings,omitempty" json:"warnings,omitempty"`
+
+	// Pinned versions are exempt from the project's retention policy
+	Pinned bool `bson:"pinned,omitempty" json:"pinned,omitempty"`
+
+	// SkippedTasks are the tasks that were not created because none of the
+	// files that the version changes match their paths
+	SkippedTasks []SkippedTask `bson:"skipped_tasks,omitempty" json:"skipped_tasks,omitempty"`
+
+	// Expansions are added to the expansions of every task in the version,
+	// such as the details of the upstream event that triggered it
+	Expansions map[string]string `bson:"expansions,omitempty" json:"expansions,omitempty"`
+
+	// TriggerID, TriggerType, TriggerProject and TriggerVersion link a
+	// version that another project triggered back to the task or build
+	// whose completion created it
+	TriggerID      string `bson:"trigger_id,omitempty" json:"trigger_id,omitempty"`
+	TriggerType    string `bson:"trigger_type,omitempty" json:"trigger_type,omitempty"`
+	TriggerProject string `bson:"trigger_project,omitempty" json:"trigger_project,omitempty"`
+	TriggerVersion string `bson:"trigger_version,omitempty" json:"trigger_version,omitempty"`
+
+	// GitTag is the tag in the project's repository that created the version
+	GitTag string `bson:"git_tag,omitempty" json:"git_tag,omitempty"`
+}
+
+// SkippedTask records a task that a version does not run, and why.
+type SkippedTask struct {
+	BuildVariant string `bson:"build_variant" json:"build_variant"`
+	TaskName     string `bson:"task_name" json:"task_name"`
+	Reason       string `bson:"reason" json:"reason"`
 }
 
 func (self *Version) UpdateBuildVariants() error {
@@ -57,6 +86,19 @@ func (self *Version) UpdateBuildVariants() error {
 	)
 }
 
+// SetPinned pins or unpins the version.
+func (self *Version) SetPinned(pinned bool) error {
+	self.Pinned = pinned
+	return UpdateOne(
+		bson.M{IdKey: self.Id},
+		bson.M{
+			"$set": bson.M{
+				PinnedKey: pinned,
+			},
+		},
+	)
+}
+
 func (self *Version) Insert() error {
 	return db.Insert(Collection, self)
 }
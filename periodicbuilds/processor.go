@@ -0,0 +1,100 @@
+package periodicbuilds
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/repotracker"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// Processor creates the versions of projects' periodic builds that are due.
+type Processor struct {
+	// BranchHead returns the most recent revision of the project's branch.
+	BranchHead func(ref *model.ProjectRef) (model.Revision, error)
+	// CreateVersion creates the project's version with the given id.
+	CreateVersion func(ref *model.ProjectRef, id string, metadata repotracker.VersionMetadata) (*version.Version, error)
+}
+
+// Process creates a version for each periodic build whose next run time has
+// passed, and schedules its next run even if the version could not be
+// created. Runs that were missed while the processor was not running are
+// skipped, rather than run one after another. Periodic builds that have
+// never been scheduled are scheduled without running.
+func (p *Processor) Process(now time.Time) error {
+	refs, err := model.FindAllProjectRefs()
+	if err != nil {
+		return errors.Wrap(err, "error finding project refs")
+	}
+
+	catcher := grip.NewCatcher()
+	for i := range refs {
+		ref := &refs[i]
+		if !ref.Enabled {
+			continue
+		}
+		for _, definition := range ref.PeriodicBuilds {
+			if !definition.NextRunTime.IsZero() && now.Before(definition.NextRunTime) {
+				continue
+			}
+			if !definition.NextRunTime.IsZero() {
+				// a run that fails is not retried, so that a build that
+				// keeps failing does not stop the schedule from moving on
+				catcher.Add(p.createVersion(ref, definition))
+			}
+
+			next, err := definition.NextRunAfter(now)
+			if err != nil {
+				catcher.Add(errors.Wrapf(err, "error scheduling periodic build %s of project %s",
+					definition.ID, ref.Identifier))
+				continue
+			}
+			catcher.Add(errors.Wrapf(ref.SetNextPeriodicBuild(definition.ID, next),
+				"error scheduling periodic build %s of project %s", definition.ID, ref.Identifier))
+		}
+	}
+	return catcher.Resolve()
+}
+
+// createVersion creates the version of a periodic build for its scheduled
+// run, unless it already exists.
+func (p *Processor) createVersion(ref *model.ProjectRef, definition model.PeriodicBuildDefinition) error {
+	id := util.CleanName(fmt.Sprintf("%v_periodic_%v_%v", ref.Identifier, definition.ID,
+		definition.NextRunTime.Format(build.IdTimeLayout)))
+	existing, err := version.FindOne(version.ById(id))
+	if err != nil {
+		return errors.Wrapf(err, "error finding version %s", id)
+	}
+	if existing != nil {
+		return nil
+	}
+
+	revision, err := p.BranchHead(ref)
+	if err != nil {
+		return errors.Wrapf(err, "error finding the head of project %s's branch", ref.Identifier)
+	}
+	if definition.Message != "" {
+		revision.RevisionMessage = definition.Message
+	}
+	metadata := repotracker.VersionMetadata{
+		Revision:   revision,
+		Requester:  evergreen.PeriodicBuildRequester,
+		ConfigFile: definition.ConfigFile,
+		Alias:      definition.Alias,
+		Expansions: map[string]string{
+			"periodic_build_id": definition.ID,
+		},
+	}
+	if _, err = p.CreateVersion(ref, id, metadata); err != nil {
+		return errors.Wrapf(err, "error creating version of periodic build %s of project %s",
+			definition.ID, ref.Identifier)
+	}
+	grip.Infof("created version %s for periodic build %s of project %s", id, definition.ID, ref.Identifier)
+	return nil
+}
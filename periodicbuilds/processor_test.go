@@ -0,0 +1,85 @@
+package periodicbuilds
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/repotracker"
+	"github.com/evergreen-ci/evergreen/testutil"
+	"github.com/pkg/errors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func init() {
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testutil.TestConfig()))
+}
+
+func TestProcess(t *testing.T) {
+	Convey("With a project that has periodic builds", t, func() {
+		testutil.HandleTestingErr(db.ClearCollections(model.ProjectRefCollection, version.Collection),
+			t, "error clearing collections")
+
+		now := time.Date(2017, time.March, 15, 14, 30, 0, 0, time.UTC)
+		ref := &model.ProjectRef{
+			Identifier: "proj",
+			Enabled:    true,
+			PeriodicBuilds: []model.PeriodicBuildDefinition{
+				{ID: "due", Cron: "0 * * * *", Message: "hourly soak", ConfigFile: "soak.yml",
+					NextRunTime: now.Add(-time.Minute)},
+				{ID: "later", Cron: "@daily", NextRunTime: now.Add(time.Hour)},
+				{ID: "new", Cron: "@weekly"},
+			},
+		}
+		So(ref.Insert(), ShouldBeNil)
+
+		created := map[string]repotracker.VersionMetadata{}
+		processor := &Processor{
+			BranchHead: func(*model.ProjectRef) (model.Revision, error) {
+				return model.Revision{Revision: "abcdef", RevisionMessage: "last commit"}, nil
+			},
+			CreateVersion: func(ref *model.ProjectRef, id string, metadata repotracker.VersionMetadata) (*version.Version, error) {
+				created[id] = metadata
+				v := &version.Version{Id: id, Identifier: ref.Identifier, Requester: metadata.Requester}
+				return v, v.Insert()
+			},
+		}
+
+		Convey("only the due build should run, and every build should be scheduled", func() {
+			So(processor.Process(now), ShouldBeNil)
+			So(len(created), ShouldEqual, 1)
+			for _, metadata := range created {
+				So(metadata.Requester, ShouldEqual, evergreen.PeriodicBuildRequester)
+				So(metadata.Revision.Revision, ShouldEqual, "abcdef")
+				So(metadata.Revision.RevisionMessage, ShouldEqual, "hourly soak")
+				So(metadata.ConfigFile, ShouldEqual, "soak.yml")
+			}
+
+			ref, err := model.FindOneProjectRef("proj")
+			So(err, ShouldBeNil)
+			So(ref.PeriodicBuilds[0].NextRunTime.Equal(time.Date(2017, time.March, 15, 15, 0, 0, 0, time.UTC)), ShouldBeTrue)
+			So(ref.PeriodicBuilds[1].NextRunTime.Equal(now.Add(time.Hour)), ShouldBeTrue)
+			So(ref.PeriodicBuilds[2].NextRunTime.Equal(time.Date(2017, time.March, 19, 0, 0, 0, 0, time.UTC)), ShouldBeTrue)
+
+			Convey("and the next run should not be created until it is due", func() {
+				created = map[string]repotracker.VersionMetadata{}
+				So(processor.Process(now.Add(time.Minute)), ShouldBeNil)
+				So(len(created), ShouldEqual, 0)
+			})
+		})
+
+		Convey("a build whose version cannot be created should still be rescheduled", func() {
+			processor.CreateVersion = func(*model.ProjectRef, string, repotracker.VersionMetadata) (*version.Version, error) {
+				return nil, errors.New("alias selects no tasks")
+			}
+			So(processor.Process(now), ShouldNotBeNil)
+
+			ref, err := model.FindOneProjectRef("proj")
+			So(err, ShouldBeNil)
+			So(ref.PeriodicBuilds[0].NextRunTime.Equal(time.Date(2017, time.March, 15, 15, 0, 0, 0, time.UTC)), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,70 @@
+package periodicbuilds
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/repotracker"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// Runner creates the versions of projects' periodic builds.
+type Runner struct{}
+
+const (
+	RunnerName  = "periodicbuilds"
+	Description = "create versions of projects on their periodic build schedules"
+)
+
+func (r *Runner) Name() string {
+	return RunnerName
+}
+
+func (r *Runner) Description() string {
+	return Description
+}
+
+func (r *Runner) Run(config *evergreen.Settings) error {
+	startTime := time.Now()
+	grip.Infoln("Starting periodic build processor at time", startTime)
+
+	processor := &Processor{
+		BranchHead: func(ref *model.ProjectRef) (model.Revision, error) {
+			return branchHead(config, ref)
+		},
+		CreateVersion: func(ref *model.ProjectRef, id string, metadata repotracker.VersionMetadata) (*version.Version, error) {
+			return repotracker.CreateVersionFromConfig(config, ref, id, metadata)
+		},
+	}
+	if err := processor.Process(startTime); err != nil {
+		err = errors.Wrap(err, "error processing periodic builds")
+		grip.Error(err)
+		return err
+	}
+
+	runtime := time.Since(startTime)
+	if err := model.SetProcessRuntimeCompleted(RunnerName, runtime); err != nil {
+		grip.Errorf("error updating process status: %+v", err)
+	}
+	grip.Infof("Periodic build processor took %s to run", runtime)
+	return nil
+}
+
+// branchHead returns the most recent revision of the project's branch.
+func branchHead(settings *evergreen.Settings, ref *model.ProjectRef) (model.Revision, error) {
+	poller, err := repotracker.NewRepoPoller(settings, ref)
+	if err != nil {
+		return model.Revision{}, errors.WithStack(err)
+	}
+	revisions, err := poller.GetRecentRevisions(1)
+	if err != nil {
+		return model.Revision{}, errors.WithStack(err)
+	}
+	if len(revisions) == 0 {
+		return model.Revision{}, errors.Errorf("branch %s has no commits", ref.Branch)
+	}
+	return revisions[0], nil
+}
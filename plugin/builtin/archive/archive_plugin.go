@@ -11,6 +11,9 @@ func init() {
 const (
 	TarGzPackCmdName   = "targz_pack"
 	TarGzUnpackCmdName = "targz_unpack"
+	ZipPackCmdName     = "zip_pack"
+	ZipUnpackCmdName   = "zip_unpack"
+	AutoExtractCmdName = "auto_extract"
 	ArchivePluginName  = "archive"
 )
 
@@ -32,5 +35,14 @@ func (self *ArchivePlugin) NewCommand(cmdName string) (plugin.Command, error) {
 	if cmdName == TarGzUnpackCmdName {
 		return &TarGzUnpackCommand{}, nil
 	}
+	if cmdName == ZipPackCmdName {
+		return &ZipPackCommand{}, nil
+	}
+	if cmdName == ZipUnpackCmdName {
+		return &ZipUnpackCommand{}, nil
+	}
+	if cmdName == AutoExtractCmdName {
+		return &AutoExtractCommand{}, nil
+	}
 	return nil, &plugin.ErrUnknownCommand{cmdName}
 }
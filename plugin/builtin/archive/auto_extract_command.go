@@ -0,0 +1,80 @@
+package archive
+
+import (
+	"os"
+
+	"github.com/evergreen-ci/evergreen/archive"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/mitchellh/mapstructure"
+	"github.com/mongodb/grip/slogger"
+	"github.com/pkg/errors"
+)
+
+// Plugin command responsible for unpacking an archive of any supported
+// format: a tarball that is gzip compressed or not compressed, or a
+// zip file. The format is detected from the file's contents.
+type AutoExtractCommand struct {
+	// the archive to unpack
+	Source string `mapstructure:"source" plugin:"expand"`
+	// the directory that the unpacked contents should be put into
+	DestDir string `mapstructure:"dest_dir" plugin:"expand"`
+}
+
+func (self *AutoExtractCommand) Name() string {
+	return AutoExtractCmdName
+}
+
+func (self *AutoExtractCommand) Plugin() string {
+	return ArchivePluginName
+}
+
+// ParseParams reads in the given parameters for the command.
+func (self *AutoExtractCommand) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, self); err != nil {
+		return errors.Wrapf(err, "error parsing '%v' params", self.Name())
+	}
+	return errors.Wrapf(validateUnpackParams(self.Source, self.DestDir),
+		"error validating '%v' params", self.Name())
+}
+
+// Execute unpacks the archive.
+func (self *AutoExtractCommand) Execute(pluginLogger plugin.Logger,
+	pluginCom plugin.PluginCommunicator,
+	conf *model.TaskConfig,
+	stop chan bool) error {
+
+	if err := plugin.ExpandValues(self, conf.Expansions); err != nil {
+		return errors.Wrap(err, "error expanding params")
+	}
+	self.Source, self.DestDir = workDirPaths(conf.WorkDir, self.Source, self.DestDir)
+
+	errChan := make(chan error)
+	go func() {
+		format, err := archive.DetectFormat(self.Source)
+		if err != nil {
+			errChan <- errors.WithStack(err)
+			return
+		}
+		pluginLogger.LogExecution(slogger.INFO, "Extracting %s archive %s into %s",
+			format, self.Source, self.DestDir)
+		errChan <- self.UnpackArchive()
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-stop:
+		pluginLogger.LogExecution(slogger.INFO, "Received signal to terminate"+
+			" execution of auto extract command")
+		return nil
+	}
+}
+
+// UnpackArchive unpacks the archive into the destination directory.
+func (self *AutoExtractCommand) UnpackArchive() error {
+	if err := os.MkdirAll(self.DestDir, 0755); err != nil {
+		return errors.Wrapf(err, "error creating destination dir %v", self.DestDir)
+	}
+	return errors.WithStack(archive.ExtractFile(self.Source, self.DestDir))
+}
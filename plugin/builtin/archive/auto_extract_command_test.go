@@ -0,0 +1,74 @@
+package archive_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/evergreen-ci/evergreen/plugin/builtin/archive"
+	"github.com/evergreen-ci/evergreen/plugin/plugintest"
+	"github.com/evergreen-ci/evergreen/testutil"
+	"github.com/evergreen-ci/evergreen/util"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestZipPackParseParams(t *testing.T) {
+	Convey("With a zip pack command", t, func() {
+		cmd := &ZipPackCommand{}
+
+		Convey("an unknown compression should cause an error", func() {
+			params := map[string]interface{}{
+				"target":      "a.zip",
+				"source_dir":  "s",
+				"include":     []string{"*"},
+				"compression": "bzip2",
+			}
+			So(cmd.ParseParams(params), ShouldNotBeNil)
+		})
+
+		Convey("a valid set of params should be parsed", func() {
+			params := map[string]interface{}{
+				"target":      "a.zip",
+				"source_dir":  "s",
+				"include":     []string{"*"},
+				"compression": "store",
+			}
+			So(cmd.ParseParams(params), ShouldBeNil)
+			So(cmd.Compression, ShouldEqual, "store")
+		})
+	})
+}
+
+func TestAutoExtractUnpackArchive(t *testing.T) {
+	Convey("With an archive built by the zip pack command", t, func() {
+		target := filepath.Join(testDataDir, "target.zip")
+		output := filepath.Join(testDataDir, "output")
+		testutil.HandleTestingErr(os.RemoveAll(target), t, "Error removing zip file")
+		testutil.HandleTestingErr(os.RemoveAll(output), t, "Error removing output directory")
+		defer os.RemoveAll(target)
+		defer os.RemoveAll(output)
+
+		zipPackCmd := &ZipPackCommand{}
+		So(zipPackCmd.ParseParams(map[string]interface{}{
+			"target":     target,
+			"source_dir": testDataDir,
+			"include":    []string{"targz_me/dir1/**"},
+		}), ShouldBeNil)
+		numFound, err := zipPackCmd.BuildArchive(&plugintest.MockLogger{})
+		So(err, ShouldBeNil)
+		So(numFound, ShouldEqual, 2)
+
+		Convey("the auto extract command should detect and unpack it", func() {
+			cmd := &AutoExtractCommand{}
+			So(cmd.ParseParams(map[string]interface{}{
+				"source":   target,
+				"dest_dir": output,
+			}), ShouldBeNil)
+			So(cmd.UnpackArchive(), ShouldBeNil)
+
+			exists, err := util.FileExists(filepath.Join(output, "targz_me/dir1/dir2/testfile.txt"))
+			So(err, ShouldBeNil)
+			So(exists, ShouldBeTrue)
+		})
+	})
+}
@@ -29,6 +29,9 @@ type TarGzPackCommand struct {
 	// a list of filename blobs to exclude,
 	// e.g. "*.zip", "results.out", "ignore/**"
 	ExcludeFiles []string `mapstructure:"exclude_files" plugin:"expand"`
+
+	// store symlinks as links instead of the files they point to
+	PreserveSymlinks bool `mapstructure:"preserve_symlinks"`
 }
 
 func (self *TarGzPackCommand) Name() string {
@@ -150,7 +153,11 @@ func (self *TarGzPackCommand) BuildArchive(pluginLogger plugin.Logger) (int, err
 	}()
 
 	// Build the archive
-	out, err := archive.BuildArchive(tarWriter, self.SourceDir, self.Include,
+	build := archive.BuildArchive
+	if self.PreserveSymlinks {
+		build = archive.BuildArchiveWithSymlinks
+	}
+	out, err := build(tarWriter, self.SourceDir, self.Include,
 		self.ExcludeFiles, log)
 	return out, errors.WithStack(err)
 }
@@ -77,15 +77,17 @@ func (self *TarGzUnpackCommand) Execute(pluginLogger plugin.Logger,
 }
 
 // UnpackArchive unpacks the archive. The target archive to unpack is
-// set for the command during parameter parsing.
+// set for the command during parameter parsing. Tarballs that are not
+// compressed are unpacked too.
 func (self *TarGzUnpackCommand) UnpackArchive() error {
 
 	// get a reader for the source file
-	f, _, tarReader, err := archive.TarGzReader(self.Source)
+	f, c, tarReader, err := archive.TarReader(self.Source)
 	if err != nil {
 		return errors.Wrapf(err, "error opening tar file %v for reading", self.Source)
 	}
 	defer f.Close()
+	defer c.Close()
 
 	// extract the actual tarball into the destination directory
 	if err := os.MkdirAll(self.DestDir, 0755); err != nil {
@@ -0,0 +1,140 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/evergreen-ci/evergreen/archive"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/send"
+	"github.com/mongodb/grip/slogger"
+	"github.com/pkg/errors"
+)
+
+// Plugin command responsible for creating a zip archive. Files keep their
+// permissions, and symlinks are stored as links.
+type ZipPackCommand struct {
+	// the zip file that will be created
+	Target string `mapstructure:"target" plugin:"expand"`
+
+	// the directory to compress
+	SourceDir string `mapstructure:"source_dir" plugin:"expand"`
+
+	// a list of filename blobs to include,
+	// e.g. "*.jar", "file.txt", "test_*"
+	Include []string `mapstructure:"include" plugin:"expand"`
+
+	// a list of filename blobs to exclude,
+	// e.g. "*.zip", "results.out", "ignore/**"
+	ExcludeFiles []string `mapstructure:"exclude_files" plugin:"expand"`
+
+	// how the files are compressed: "deflate" (the default) or "store"
+	Compression string `mapstructure:"compression" plugin:"expand"`
+}
+
+func (self *ZipPackCommand) Name() string {
+	return ZipPackCmdName
+}
+
+func (self *ZipPackCommand) Plugin() string {
+	return ArchivePluginName
+}
+
+// ParseParams reads in the given parameters for the command.
+func (self *ZipPackCommand) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, self); err != nil {
+		return errors.Wrapf(err, "error parsing '%v' params", self.Name())
+	}
+	if err := self.validateParams(); err != nil {
+		return errors.Wrapf(err, "error validating '%v' params", self.Name())
+	}
+	return nil
+}
+
+// Make sure a target and source dir are set, files are specified to be
+// included, and the compression is known.
+func (self *ZipPackCommand) validateParams() error {
+	if self.Target == "" {
+		return errors.New("target cannot be blank")
+	}
+	if self.SourceDir == "" {
+		return errors.New("source_dir cannot be blank")
+	}
+	if len(self.Include) == 0 {
+		return errors.New("include cannot be empty")
+	}
+	if self.Compression != "" && !util.SliceContains(archive.ValidZipCompressions, self.Compression) {
+		return errors.Errorf("compression must be one of %v", archive.ValidZipCompressions)
+	}
+	return nil
+}
+
+// Execute builds the archive.
+func (self *ZipPackCommand) Execute(pluginLogger plugin.Logger,
+	pluginCom plugin.PluginCommunicator,
+	conf *model.TaskConfig,
+	stop chan bool) error {
+
+	if err := plugin.ExpandValues(self, conf.Expansions); err != nil {
+		return errors.Wrap(err, "error expanding params")
+	}
+
+	if !filepath.IsAbs(self.SourceDir) {
+		self.SourceDir = filepath.Join(conf.WorkDir, self.SourceDir)
+	}
+	if !filepath.IsAbs(self.Target) {
+		self.Target = filepath.Join(conf.WorkDir, self.Target)
+	}
+
+	errChan := make(chan error)
+	filesArchived := -1
+	go func() {
+		var err error
+		filesArchived, err = self.BuildArchive(pluginLogger)
+		errChan <- errors.WithStack(err)
+	}()
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if filesArchived == 0 {
+			deleteErr := os.Remove(self.Target)
+			if deleteErr != nil {
+				pluginLogger.LogExecution(slogger.INFO, "Error deleting empty archive: %v", deleteErr)
+			}
+		}
+		return nil
+	case <-stop:
+		pluginLogger.LogExecution(slogger.INFO, "Received signal to terminate"+
+			" execution of zip pack command")
+		return nil
+	}
+}
+
+// BuildArchive builds the zip file.
+// Returns the number of files included in the archive (0 means empty archive).
+func (self *ZipPackCommand) BuildArchive(pluginLogger plugin.Logger) (int, error) {
+	log := &slogger.Logger{
+		Name:      "",
+		Appenders: []send.Sender{slogger.WrapAppender(&agentAppender{pluginLogger: pluginLogger})},
+	}
+
+	f, zipWriter, err := archive.ZipWriter(self.Target)
+	if err != nil {
+		return -1, errors.Wrapf(err, "error opening target archive file %s", self.Target)
+	}
+	defer func() {
+		grip.CatchError(zipWriter.Close())
+		grip.CatchError(f.Close())
+	}()
+
+	out, err := archive.BuildZipArchive(zipWriter, self.SourceDir, self.Include,
+		self.ExcludeFiles, self.Compression, log)
+	return out, errors.WithStack(err)
+}
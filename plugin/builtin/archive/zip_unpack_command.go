@@ -0,0 +1,95 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/evergreen-ci/evergreen/archive"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/mitchellh/mapstructure"
+	"github.com/mongodb/grip/slogger"
+	"github.com/pkg/errors"
+)
+
+// Plugin command responsible for unpacking a zip archive.
+type ZipUnpackCommand struct {
+	// the zip file to unpack
+	Source string `mapstructure:"source" plugin:"expand"`
+	// the directory that the unpacked contents should be put into
+	DestDir string `mapstructure:"dest_dir" plugin:"expand"`
+}
+
+func (self *ZipUnpackCommand) Name() string {
+	return ZipUnpackCmdName
+}
+
+func (self *ZipUnpackCommand) Plugin() string {
+	return ArchivePluginName
+}
+
+// ParseParams reads in the given parameters for the command.
+func (self *ZipUnpackCommand) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, self); err != nil {
+		return errors.Wrapf(err, "error parsing '%v' params", self.Name())
+	}
+	return errors.Wrapf(validateUnpackParams(self.Source, self.DestDir),
+		"error validating '%v' params", self.Name())
+}
+
+// Execute unpacks the archive.
+func (self *ZipUnpackCommand) Execute(pluginLogger plugin.Logger,
+	pluginCom plugin.PluginCommunicator,
+	conf *model.TaskConfig,
+	stop chan bool) error {
+
+	if err := plugin.ExpandValues(self, conf.Expansions); err != nil {
+		return errors.Wrap(err, "error expanding params")
+	}
+	self.Source, self.DestDir = workDirPaths(conf.WorkDir, self.Source, self.DestDir)
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- self.UnpackArchive()
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-stop:
+		pluginLogger.LogExecution(slogger.INFO, "Received signal to terminate"+
+			" execution of zip unpack command")
+		return nil
+	}
+}
+
+// UnpackArchive unpacks the zip file into the destination directory.
+func (self *ZipUnpackCommand) UnpackArchive() error {
+	if err := os.MkdirAll(self.DestDir, 0755); err != nil {
+		return errors.Wrapf(err, "error creating destination dir %v", self.DestDir)
+	}
+	return errors.WithStack(archive.ExtractZipFile(self.Source, self.DestDir))
+}
+
+// validateUnpackParams makes sure both source and dest dir are specified.
+func validateUnpackParams(source, destDir string) error {
+	if source == "" {
+		return errors.New("source cannot be blank")
+	}
+	if destDir == "" {
+		return errors.New("dest_dir cannot be blank")
+	}
+	return nil
+}
+
+// workDirPaths joins the relative paths of an archive and the directory it is
+// unpacked into to the working dir.
+func workDirPaths(workDir, source, destDir string) (string, string) {
+	if !filepath.IsAbs(source) {
+		source = filepath.Join(workDir, source)
+	}
+	if !filepath.IsAbs(destDir) {
+		destDir = filepath.Join(workDir, destDir)
+	}
+	return source, destDir
+}
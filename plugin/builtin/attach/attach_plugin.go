@@ -24,6 +24,7 @@ const (
 	AttachPluginName      = "attach"
 	AttachResultsCmd      = "results"
 	AttachXunitResultsCmd = "xunit_results"
+	AttachTestResultsCmd  = "test_results"
 
 	AttachResultsAPIEndpoint = "results"
 	AttachLogsAPIEndpoint    = "test_logs"
@@ -130,6 +131,8 @@ func (self *AttachPlugin) NewCommand(cmdName string) (plugin.Command,
 		return &AttachResultsCommand{}, nil
 	case AttachXunitResultsCmd:
 		return &AttachXUnitResultsCommand{}, nil
+	case AttachTestResultsCmd:
+		return &AttachTestResultsCommand{}, nil
 	default:
 		return nil, errors.Errorf("No such %v command: %v", AttachPluginName, cmdName)
 	}
@@ -0,0 +1,130 @@
+package attach
+
+import (
+	"os"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/evergreen-ci/evergreen/plugin/builtin/attach/testresults"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"github.com/mongodb/grip/slogger"
+	"github.com/pkg/errors"
+)
+
+// AttachTestResultsCommand reads test results written by a test runner in
+// TAP, 'go test -json', Cucumber JSON or JUnit XML format, and converts them to
+// a format MCI can use.
+type AttachTestResultsCommand struct {
+	// Files are the paths of the files to read, relative to the working
+	// directory. Supports globbing.
+	Files []string `mapstructure:"files" plugin:"expand"`
+
+	// Format is one of testresults.ValidFormats. By default it is detected
+	// from each file's contents.
+	Format string `mapstructure:"format" plugin:"expand"`
+}
+
+func (c *AttachTestResultsCommand) Name() string {
+	return AttachTestResultsCmd
+}
+
+func (c *AttachTestResultsCommand) Plugin() string {
+	return AttachPluginName
+}
+
+// ParseParams reads and validates the command parameters. This is required
+// to satisfy the 'Command' interface
+func (c *AttachTestResultsCommand) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, c); err != nil {
+		return errors.Wrapf(err, "error decoding '%s' params", c.Name())
+	}
+	if len(c.Files) == 0 {
+		return errors.Errorf("error validating '%s' params: must specify at least one file", c.Name())
+	}
+	if c.Format == "" {
+		c.Format = testresults.FormatAuto
+	}
+	if !util.SliceContains(testresults.ValidFormats, c.Format) {
+		return errors.Errorf("error validating '%s' params: format must be one of %v",
+			c.Name(), testresults.ValidFormats)
+	}
+	return nil
+}
+
+// Execute carries out the AttachTestResultsCommand command - this is required
+// to satisfy the 'Command' interface
+func (c *AttachTestResultsCommand) Execute(pluginLogger plugin.Logger,
+	pluginCom plugin.PluginCommunicator,
+	taskConfig *model.TaskConfig,
+	stop chan bool) error {
+
+	if err := plugin.ExpandValues(c, taskConfig.Expansions); err != nil {
+		return errors.Wrap(err, "error expanding params")
+	}
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- c.parseAndUploadResults(taskConfig, pluginLogger, pluginCom)
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-stop:
+		pluginLogger.LogExecution(slogger.INFO, "Received signal to terminate"+
+			" execution of attach test results command")
+		return nil
+	}
+}
+
+// parseAndUploadResults reads the results in each file, uploading the log of
+// each test as soon as it is read so that only the results themselves are
+// kept until they are all sent together.
+func (c *AttachTestResultsCommand) parseAndUploadResults(taskConfig *model.TaskConfig,
+	pluginLogger plugin.Logger, pluginCom plugin.PluginCommunicator) error {
+
+	reportFilePaths, err := getFilePaths(taskConfig.WorkDir, c.Files)
+	if err != nil {
+		return err
+	}
+	if len(reportFilePaths) == 0 {
+		return errors.Errorf("no test result files match %v", c.Files)
+	}
+
+	tests := []task.TestResult{}
+	handle := func(result *testresults.Result) error {
+		test, log := result.ToModelTestResultAndLog(taskConfig.Task)
+		if log != nil {
+			logId, err := SendJSONLogs(pluginLogger, pluginCom, log)
+			if err != nil {
+				pluginLogger.LogTask(slogger.WARN, "Error uploading logs for %v", log.Name)
+			} else {
+				test.LogId = logId
+				test.LineNum = 1
+			}
+		}
+		tests = append(tests, test)
+		return nil
+	}
+
+	for _, reportFileLoc := range reportFilePaths {
+		file, err := os.Open(reportFileLoc)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't open test result file '%s'", reportFileLoc)
+		}
+		found := len(tests)
+		err = testresults.Parse(file, c.Format, handle)
+		if closeErr := file.Close(); closeErr != nil {
+			pluginLogger.LogExecution(slogger.INFO, "Error closing file: %v", closeErr)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "error parsing test result file '%s'", reportFileLoc)
+		}
+		pluginLogger.LogTask(slogger.INFO, "Read %d test results from %s",
+			len(tests)-found, reportFileLoc)
+	}
+
+	return SendJSONResults(taskConfig, pluginLogger, pluginCom, &task.TestResults{Results: tests})
+}
@@ -0,0 +1,33 @@
+package attach_test
+
+import (
+	"testing"
+
+	. "github.com/evergreen-ci/evergreen/plugin/builtin/attach"
+	"github.com/evergreen-ci/evergreen/plugin/builtin/attach/testresults"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAttachTestResultsParseParams(t *testing.T) {
+	Convey("With an attach test results command", t, func() {
+		cmd := &AttachTestResultsCommand{}
+
+		Convey("missing files should cause an error", func() {
+			So(cmd.ParseParams(map[string]interface{}{}), ShouldNotBeNil)
+		})
+
+		Convey("an unknown format should cause an error", func() {
+			So(cmd.ParseParams(map[string]interface{}{
+				"files":  []string{"results.xml"},
+				"format": "nunit",
+			}), ShouldNotBeNil)
+		})
+
+		Convey("the format should be detected by default", func() {
+			So(cmd.ParseParams(map[string]interface{}{
+				"files": []string{"results/*.tap"},
+			}), ShouldBeNil)
+			So(cmd.Format, ShouldEqual, testresults.FormatAuto)
+		})
+	})
+}
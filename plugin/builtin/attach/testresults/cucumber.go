@@ -0,0 +1,118 @@
+package testresults
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/pkg/errors"
+)
+
+type cucumberFeature struct {
+	Name     string            `json:"name"`
+	URI      string            `json:"uri"`
+	Elements []cucumberElement `json:"elements"`
+}
+
+type cucumberElement struct {
+	Name   string         `json:"name"`
+	Type   string         `json:"type"`
+	Before []cucumberStep `json:"before"`
+	Steps  []cucumberStep `json:"steps"`
+	After  []cucumberStep `json:"after"`
+}
+
+type cucumberStep struct {
+	Keyword string `json:"keyword"`
+	Name    string `json:"name"`
+	Result  struct {
+		Status string `json:"status"`
+		// Duration is in nanoseconds
+		Duration     int64  `json:"duration"`
+		ErrorMessage string `json:"error_message"`
+	} `json:"result"`
+}
+
+// CucumberParser reads Cucumber's JSON report. Each scenario is a test named
+// for its feature, and its output is the outcome of each of its steps.
+type CucumberParser struct{}
+
+// Parse reads the report one feature at a time and hands each scenario to the
+// callback.
+func (p *CucumberParser) Parse(in io.Reader, handle func(*Result) error) error {
+	decoder := json.NewDecoder(in)
+	if token, err := decoder.Token(); err != nil {
+		return errors.Wrap(err, "error reading cucumber report")
+	} else if token != json.Delim('[') {
+		return errors.New("cucumber report is not a list of features")
+	}
+
+	for decoder.More() {
+		feature := cucumberFeature{}
+		if err := decoder.Decode(&feature); err != nil {
+			return errors.Wrap(err, "error reading cucumber feature")
+		}
+		suite := feature.Name
+		if suite == "" {
+			suite = feature.URI
+		}
+
+		// a background's steps are run before each of the scenarios after it
+		var background []cucumberStep
+		for _, element := range feature.Elements {
+			if element.Type == "background" {
+				background = element.Steps
+				continue
+			}
+			steps := append([]cucumberStep{}, element.Before...)
+			steps = append(steps, background...)
+			steps = append(steps, element.Steps...)
+			steps = append(steps, element.After...)
+			if err := handle(cucumberResult(suite, element.Name, steps)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cucumberResult sums up the steps of a scenario. It fails if any step failed,
+// is skipped if no step ran, and is otherwise skipped if any step is pending
+// or undefined.
+func cucumberResult(suite, name string, steps []cucumberStep) *Result {
+	result := &Result{Suite: suite, Name: name, Status: evergreen.TestSucceededStatus}
+	ran := false
+	for _, step := range steps {
+		result.Duration += time.Duration(step.Result.Duration)
+
+		status := step.Result.Status
+		switch status {
+		case "passed":
+			ran = true
+		case "failed", "ambiguous":
+			ran = true
+			result.Status = evergreen.TestFailedStatus
+		case "pending", "undefined":
+			if result.Status != evergreen.TestFailedStatus {
+				result.Status = evergreen.TestSkippedStatus
+			}
+		}
+
+		// hooks have no keyword or name, and are only worth showing on failure
+		if step.Keyword != "" || step.Name != "" || step.Result.ErrorMessage != "" {
+			result.Output = append(result.Output, fmt.Sprintf("%v%v: %v",
+				step.Keyword, step.Name, status))
+		}
+		if step.Result.ErrorMessage != "" {
+			result.Output = append(result.Output,
+				strings.Split(strings.TrimSpace(step.Result.ErrorMessage), "\n")...)
+		}
+	}
+	if !ran && result.Status == evergreen.TestSucceededStatus {
+		result.Status = evergreen.TestSkippedStatus
+	}
+	return result
+}
@@ -0,0 +1,108 @@
+package testresults
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/pkg/errors"
+)
+
+// goTestEvent is one line of the output of 'go test -json'.
+type goTestEvent struct {
+	Action  string  `json:"Action"`
+	Package string  `json:"Package"`
+	Test    string  `json:"Test"`
+	Elapsed float64 `json:"Elapsed"`
+	Output  string  `json:"Output"`
+}
+
+// goPackage tracks the tests of a package that are running.
+type goPackage struct {
+	output  []string
+	running map[string][]string
+	failed  bool
+}
+
+// GoJSONParser reads the events written by 'go test -json'. Each test is
+// named for its package, and a package that fails without a failing test,
+// such as one that does not build, is a failed result of its own.
+type GoJSONParser struct{}
+
+// Parse reads the events and hands each finished test to the callback.
+func (p *GoJSONParser) Parse(in io.Reader, handle func(*Result) error) error {
+	packages := map[string]*goPackage{}
+	getPackage := func(name string) *goPackage {
+		pkg, ok := packages[name]
+		if !ok {
+			pkg = &goPackage{running: map[string][]string{}}
+			packages[name] = pkg
+		}
+		return pkg
+	}
+
+	decoder := json.NewDecoder(in)
+	for {
+		event := goTestEvent{}
+		if err := decoder.Decode(&event); err == io.EOF {
+			break
+		} else if err != nil {
+			return errors.Wrap(err, "error reading test event")
+		}
+
+		pkg := getPackage(event.Package)
+		status := goTestStatus(event.Action)
+		switch {
+		case event.Action == "output" && event.Test == "":
+			pkg.output = append(pkg.output, strings.TrimRight(event.Output, "\n"))
+		case event.Action == "output":
+			pkg.running[event.Test] = append(pkg.running[event.Test], strings.TrimRight(event.Output, "\n"))
+		case status != "" && event.Test != "":
+			if status == evergreen.TestFailedStatus {
+				pkg.failed = true
+			}
+			result := &Result{
+				Suite:    event.Package,
+				Name:     event.Test,
+				Status:   status,
+				Duration: time.Duration(event.Elapsed * float64(time.Second)),
+				Output:   pkg.running[event.Test],
+			}
+			delete(pkg.running, event.Test)
+			if err := handle(result); err != nil {
+				return err
+			}
+		case status != "":
+			delete(packages, event.Package)
+			if status != evergreen.TestFailedStatus || pkg.failed {
+				continue
+			}
+			result := &Result{
+				Name:     event.Package,
+				Status:   status,
+				Duration: time.Duration(event.Elapsed * float64(time.Second)),
+				Output:   pkg.output,
+			}
+			if err := handle(result); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// goTestStatus returns the status of a test that finished with the action,
+// or the empty string if the action does not finish a test.
+func goTestStatus(action string) string {
+	switch action {
+	case "pass":
+		return evergreen.TestSucceededStatus
+	case "fail":
+		return evergreen.TestFailedStatus
+	case "skip":
+		return evergreen.TestSkippedStatus
+	}
+	return ""
+}
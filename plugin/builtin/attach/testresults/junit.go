@@ -0,0 +1,128 @@
+package testresults
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/pkg/errors"
+)
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Time      float64        `xml:"time,attr"`
+	Failures  []junitDetails `xml:"failure"`
+	Errors    []junitDetails `xml:"error"`
+	Skipped   *junitDetails  `xml:"skipped"`
+	SysOut    string         `xml:"system-out"`
+	SysErr    string         `xml:"system-err"`
+}
+
+type junitDetails struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// JUnitParser reads JUnit XML reports, including pytest's variant, which
+// nests its suites in a testsuites element, reports errors in setup and
+// teardown alongside a test's failure, and puts what a test printed in the
+// test case. Tests are named for their class, or for their suite if they have
+// no class.
+type JUnitParser struct{}
+
+// Parse reads the report one test case at a time and hands each to the
+// callback.
+func (p *JUnitParser) Parse(in io.Reader, handle func(*Result) error) error {
+	decoder := xml.NewDecoder(in)
+	suites := []string{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "error reading junit report")
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			switch element.Name.Local {
+			case "testsuite":
+				suites = append(suites, xmlAttr(element, "name"))
+			case "testcase":
+				testCase := junitTestCase{}
+				if err = decoder.DecodeElement(&testCase, &element); err != nil {
+					return errors.Wrap(err, "error reading junit test case")
+				}
+				suite := testCase.ClassName
+				if suite == "" && len(suites) > 0 {
+					suite = suites[len(suites)-1]
+				}
+				if err = handle(testCase.toResult(suite)); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			if element.Name.Local == "testsuite" && len(suites) > 0 {
+				suites = suites[:len(suites)-1]
+			}
+		}
+	}
+}
+
+func xmlAttr(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// toResult converts the test case, whose output is its failures and errors
+// followed by what it printed.
+func (tc *junitTestCase) toResult(suite string) *Result {
+	result := &Result{
+		Suite:    suite,
+		Name:     tc.Name,
+		Status:   evergreen.TestSucceededStatus,
+		Duration: time.Duration(tc.Time * float64(time.Second)),
+	}
+
+	switch {
+	case len(tc.Failures) > 0 || len(tc.Errors) > 0:
+		result.Status = evergreen.TestFailedStatus
+	case tc.Skipped != nil:
+		result.Status = evergreen.TestSkippedStatus
+	}
+
+	for _, failure := range tc.Failures {
+		result.Output = append(result.Output, failure.lines("FAILURE")...)
+	}
+	for _, testErr := range tc.Errors {
+		result.Output = append(result.Output, testErr.lines("ERROR")...)
+	}
+	if tc.Skipped != nil {
+		result.Output = append(result.Output, tc.Skipped.lines("SKIPPED")...)
+	}
+	for _, captured := range []struct{ name, output string }{{"stdout", tc.SysOut}, {"stderr", tc.SysErr}} {
+		if output := strings.TrimSpace(captured.output); output != "" {
+			result.Output = append(result.Output, fmt.Sprintf("captured %v:", captured.name))
+			result.Output = append(result.Output, strings.Split(output, "\n")...)
+		}
+	}
+	return result
+}
+
+func (d junitDetails) lines(kind string) []string {
+	lines := []string{fmt.Sprintf("%v: %v (%v)", kind, d.Message, d.Type)}
+	if content := strings.TrimSpace(d.Content); content != "" {
+		lines = append(lines, strings.Split(content, "\n")...)
+	}
+	return lines
+}
@@ -0,0 +1,152 @@
+// Package testresults parses test results written in the common formats of
+// test runners, so that they can be attached to a task without converting
+// them to Evergreen's own format first.
+package testresults
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/pkg/errors"
+)
+
+// Formats of test results that can be parsed.
+const (
+	FormatAuto     = "auto"
+	FormatTAP      = "tap"
+	FormatGoJSON   = "gotest_json"
+	FormatCucumber = "cucumber"
+	FormatJUnit    = "junit"
+)
+
+// ValidFormats are the formats that can be asked for by name.
+var ValidFormats = []string{FormatAuto, FormatTAP, FormatGoJSON, FormatCucumber, FormatJUnit}
+
+const (
+	// detectSize is how much of a file is read to detect its format.
+	detectSize = 512
+
+	// maxLineLength is the longest line of output that can be read.
+	maxLineLength = 1024 * 1024
+)
+
+// Result is a single test's result, as read by a Parser.
+type Result struct {
+	// Suite is the class, package or feature the test belongs to, if any
+	Suite string
+	Name  string
+	// Status is one of the evergreen test statuses
+	Status   string
+	Duration time.Duration
+	// Output is what the test logged, if the format records it
+	Output []string
+}
+
+// Parser reads test results in one format. Results are handed to the
+// callback as soon as each is read, so that large result files are never held
+// in memory; an error from the callback stops the parse.
+type Parser interface {
+	Parse(io.Reader, func(*Result) error) error
+}
+
+// NewParser returns a parser for the given format.
+func NewParser(format string) (Parser, error) {
+	switch format {
+	case FormatTAP:
+		return &TAPParser{}, nil
+	case FormatGoJSON:
+		return &GoJSONParser{}, nil
+	case FormatCucumber:
+		return &CucumberParser{}, nil
+	case FormatJUnit:
+		return &JUnitParser{}, nil
+	default:
+		return nil, errors.Errorf("unknown test result format '%s'", format)
+	}
+}
+
+// DetectFormat returns the format of the results that the reader holds,
+// without consuming them. XML is taken to be JUnit, a JSON array Cucumber,
+// a stream of JSON objects the output of 'go test -json', and anything else
+// the Test Anything Protocol.
+func DetectFormat(in *bufio.Reader) (string, error) {
+	start, err := in.Peek(detectSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return "", errors.Wrap(err, "problem reading test results")
+	}
+	for _, c := range start {
+		switch c {
+		case ' ', '\t', '\r', '\n', 0xef, 0xbb, 0xbf:
+			// skip leading whitespace and any byte order mark
+			continue
+		case '<':
+			return FormatJUnit, nil
+		case '[':
+			return FormatCucumber, nil
+		case '{':
+			return FormatGoJSON, nil
+		default:
+			return FormatTAP, nil
+		}
+	}
+	return FormatTAP, nil
+}
+
+// Parse reads the results in the given format, detecting the format first if
+// it is FormatAuto, and hands each result to the callback.
+func Parse(in io.Reader, format string, handle func(*Result) error) error {
+	buffered := bufio.NewReaderSize(in, detectSize)
+	if format == FormatAuto || format == "" {
+		var err error
+		if format, err = DetectFormat(buffered); err != nil {
+			return err
+		}
+	}
+	parser, err := NewParser(format)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(parser.Parse(buffered, handle), "problem parsing %s results", format)
+}
+
+// TestFile returns the name a result is stored under.
+func (r *Result) TestFile() string {
+	name := r.Name
+	if r.Suite != "" {
+		name = fmt.Sprintf("%v.%v", r.Suite, r.Name)
+	}
+	// replace spaces, dashes, etc. with underscores
+	return util.CleanForPath(name)
+}
+
+// ToModelTestResultAndLog converts a result into a task.TestResult and, if
+// the test logged any output, a model.TestLog for that output. Test results
+// record when they ran, but these formats do not all say when, so the end of
+// each test is taken to be now.
+func (r *Result) ToModelTestResultAndLog(t *task.Task) (task.TestResult, *model.TestLog) {
+	end := time.Now()
+	res := task.TestResult{
+		TestFile:  r.TestFile(),
+		Status:    r.Status,
+		StartTime: float64(end.Add(-r.Duration).UnixNano()) / float64(time.Second),
+		EndTime:   float64(end.UnixNano()) / float64(time.Second),
+	}
+	if len(r.Output) == 0 {
+		return res, nil
+	}
+
+	log := &model.TestLog{
+		Name:          res.TestFile,
+		Task:          t.Id,
+		TaskExecution: t.Execution,
+		Lines:         r.Output,
+	}
+	// update the URL of the result to the expected log URL
+	res.URL = log.URL()
+	return res, log
+}
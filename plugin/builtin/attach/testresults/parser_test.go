@@ -0,0 +1,139 @@
+package testresults
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// parseFile detects the format of a test data file and returns its results.
+func parseFile(t *testing.T, name string) []*Result {
+	file, err := os.Open(filepath.Join(testutil.GetDirectoryOfFile(), "testdata", name))
+	testutil.HandleTestingErr(err, t, "Error reading file")
+	defer file.Close()
+
+	results := []*Result{}
+	So(Parse(file, FormatAuto, func(r *Result) error {
+		results = append(results, r)
+		return nil
+	}), ShouldBeNil)
+	return results
+}
+
+func TestDetectFormat(t *testing.T) {
+	Convey("The format of results should be detected from their start", t, func() {
+		for text, format := range map[string]string{
+			"\n  <?xml version=\"1.0\"?><testsuites/>": FormatJUnit,
+			"[{\"name\": \"feature\"}]":                FormatCucumber,
+			"{\"Action\":\"run\"}":                     FormatGoJSON,
+			"TAP version 13\n1..1\nok 1":               FormatTAP,
+			"":                                         FormatTAP,
+		} {
+			detected, err := DetectFormat(bufio.NewReader(strings.NewReader(text)))
+			So(err, ShouldBeNil)
+			So(detected, ShouldEqual, format)
+		}
+	})
+}
+
+func TestTAPParser(t *testing.T) {
+	Convey("With TAP output", t, func() {
+		results := parseFile(t, "results.tap")
+		So(len(results), ShouldEqual, 5)
+
+		Convey("test points should be read with their directives", func() {
+			So(results[0].Name, ShouldEqual, "parses integers")
+			So(results[0].Status, ShouldEqual, evergreen.TestSucceededStatus)
+			So(results[1].Status, ShouldEqual, evergreen.TestFailedStatus)
+			So(results[2].Status, ShouldEqual, evergreen.TestSkippedStatus)
+			So(results[3].Status, ShouldEqual, evergreen.TestSilentlyFailedStatus)
+			So(results[4].Name, ShouldEqual, "test_5")
+		})
+
+		Convey("diagnostics should be the output of the test before them", func() {
+			So(len(results[0].Output), ShouldEqual, 0)
+			So(results[1].Output, ShouldContain, "  message: 'expected 2016-02-29'")
+		})
+	})
+}
+
+func TestGoJSONParser(t *testing.T) {
+	Convey("With the output of go test -json", t, func() {
+		results := parseFile(t, "results_gotest.json")
+		So(len(results), ShouldEqual, 4)
+
+		Convey("tests should be named for their package and keep their output", func() {
+			So(results[0].TestFile(), ShouldEqual, "example.com_calc.TestAdd")
+			So(results[0].Status, ShouldEqual, evergreen.TestSucceededStatus)
+			So(results[0].Duration.Seconds(), ShouldEqual, 0.5)
+			So(results[1].Status, ShouldEqual, evergreen.TestFailedStatus)
+			So(results[1].Output, ShouldResemble, []string{"    calc_test.go:20: division by zero"})
+			So(results[2].Status, ShouldEqual, evergreen.TestSkippedStatus)
+		})
+
+		Convey("a package that fails without a failing test should be a result", func() {
+			So(results[3].Name, ShouldEqual, "example.com/broken")
+			So(results[3].Status, ShouldEqual, evergreen.TestFailedStatus)
+			So(results[3].Output, ShouldResemble, []string{"broken.go:3: undefined: x"})
+		})
+	})
+}
+
+func TestCucumberParser(t *testing.T) {
+	Convey("With a cucumber report", t, func() {
+		results := parseFile(t, "results_cucumber.json")
+		So(len(results), ShouldEqual, 3)
+
+		Convey("scenarios should include the background's steps", func() {
+			So(results[0].Suite, ShouldEqual, "Login")
+			So(results[0].Status, ShouldEqual, evergreen.TestSucceededStatus)
+			So(results[0].Duration.Seconds(), ShouldEqual, 0.006)
+			So(results[0].Output[0], ShouldEqual, "Given a user: passed")
+		})
+
+		Convey("failed and undefined steps should decide the status", func() {
+			So(results[1].Status, ShouldEqual, evergreen.TestFailedStatus)
+			So(results[1].Output, ShouldContain, "but got none")
+			So(results[2].Status, ShouldEqual, evergreen.TestSkippedStatus)
+		})
+	})
+}
+
+func TestJUnitParser(t *testing.T) {
+	Convey("With a pytest junit report", t, func() {
+		results := parseFile(t, "results_pytest.xml")
+		So(len(results), ShouldEqual, 4)
+
+		Convey("test cases should be named for their class", func() {
+			So(results[0].TestFile(), ShouldEqual, "tests.test_math.test_add")
+			So(results[0].Status, ShouldEqual, evergreen.TestSucceededStatus)
+		})
+
+		Convey("failures, setup errors and skips should be read with their output", func() {
+			So(results[1].Status, ShouldEqual, evergreen.TestFailedStatus)
+			So(results[1].Output[0], ShouldEqual, "FAILURE: ZeroDivisionError: division by zero ()")
+			So(results[1].Output, ShouldContain, "dividing")
+			So(results[2].Status, ShouldEqual, evergreen.TestFailedStatus)
+			So(results[3].Status, ShouldEqual, evergreen.TestSkippedStatus)
+		})
+
+		Convey("and converted to results with logs", func() {
+			test, log := results[1].ToModelTestResultAndLog(&task.Task{Id: "t1", Execution: 2})
+			So(log, ShouldNotBeNil)
+			So(log.Task, ShouldEqual, "t1")
+			So(log.TaskExecution, ShouldEqual, 2)
+			So(test.URL, ShouldEqual, log.URL())
+
+			test, log = results[0].ToModelTestResultAndLog(&task.Task{Id: "t1"})
+			So(log, ShouldBeNil)
+			So(test.EndTime, ShouldBeGreaterThanOrEqualTo, test.StartTime)
+		})
+	})
+}
@@ -0,0 +1,89 @@
+package testresults
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/pkg/errors"
+)
+
+// a test point, e.g. "not ok 3 - parses dates # TODO leap years"
+var tapTestRegex = regexp.MustCompile(`^(ok|not ok)\b\s*(\d*)\s*-?\s*([^#]*?)\s*(?:#\s*(\S+)\s*(.*))?$`)
+
+// TAPParser reads results in the Test Anything Protocol. The diagnostics and
+// YAML blocks that follow a test point are the output of that test.
+type TAPParser struct{}
+
+// Parse reads TAP and hands each test point to the callback.
+func (p *TAPParser) Parse(in io.Reader, handle func(*Result) error) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxLineLength)
+
+	var pending *Result
+	flush := func() error {
+		if pending == nil {
+			return nil
+		}
+		result := pending
+		pending = nil
+		return handle(result)
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// subtests are indented, and summed up by their parent's test point
+		if matches := tapTestRegex.FindStringSubmatch(line); matches != nil {
+			if err := flush(); err != nil {
+				return err
+			}
+			pending = &Result{Name: matches[3], Status: tapStatus(matches[1], matches[4])}
+			if pending.Name == "" {
+				pending.Name = "test_" + matches[2]
+			}
+			if matches[4] != "" {
+				pending.Output = append(pending.Output, line)
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "Bail out!") {
+			if err := flush(); err != nil {
+				return err
+			}
+			// the tests that were not run are unknown, so the run fails
+			return handle(&Result{
+				Name:   "bail_out",
+				Status: evergreen.TestFailedStatus,
+				Output: []string{line},
+			})
+		}
+
+		if pending != nil && strings.TrimSpace(line) != "" {
+			pending.Output = append(pending.Output, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "error reading TAP output")
+	}
+	return flush()
+}
+
+// tapStatus returns the status of a test point. Tests with a SKIP directive
+// were not run, and failures with a TODO directive are expected.
+func tapStatus(result, directive string) string {
+	directive = strings.ToUpper(directive)
+	switch {
+	case strings.HasPrefix(directive, "SKIP"):
+		return evergreen.TestSkippedStatus
+	case strings.HasPrefix(directive, "TODO") && result == "not ok":
+		return evergreen.TestSilentlyFailedStatus
+	}
+	if result == "ok" {
+		return evergreen.TestSucceededStatus
+	}
+	return evergreen.TestFailedStatus
+}
@@ -0,0 +1,213 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/goamz/goamz/aws"
+	"github.com/pkg/errors"
+	ignore "github.com/sabhiram/go-git-ignore"
+)
+
+func init() {
+	plugin.Publish(&CachePlugin{})
+}
+
+const (
+	SaveCmdName     = "save"
+	RestoreCmdName  = "restore"
+	CachePluginName = "cache"
+
+	CacheStatsAPIEndpoint = "stats"
+)
+
+// CachePlugin holds commands that save directories of a task's working
+// directory under a key derived from its inputs, and restore them in later
+// tasks whose inputs are the same.
+type CachePlugin struct{}
+
+// Name returns the name of the plugin. Fulfills the Plugin interface.
+func (self *CachePlugin) Name() string {
+	return CachePluginName
+}
+
+// NewCommand takes a command name as a string and returns the requested command,
+// or an error if the command does not exist. Fulfills the Plugin interface.
+func (self *CachePlugin) NewCommand(cmdName string) (plugin.Command, error) {
+	switch cmdName {
+	case SaveCmdName:
+		return &SaveCommand{}, nil
+	case RestoreCmdName:
+		return &RestoreCommand{}, nil
+	default:
+		return nil, &plugin.ErrUnknownCommand{CommandName: cmdName}
+	}
+}
+
+func (self *CachePlugin) Configure(map[string]interface{}) error {
+	return nil
+}
+
+func (self *CachePlugin) GetAPIHandler() http.Handler {
+	r := http.NewServeMux()
+	r.HandleFunc(fmt.Sprintf("/%v", CacheStatsAPIEndpoint), CacheStatsHandler) // POST
+	r.HandleFunc("/", http.NotFound)
+	return r
+}
+
+// CacheStats reports whether a restore found a cached archive.
+type CacheStats struct {
+	Hit bool `json:"hit"`
+}
+
+// CacheStatsHandler counts a cache hit or miss for the task.
+func CacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	task := plugin.GetTask(r)
+	if task == nil {
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+	stats := &CacheStats{}
+	if err := util.ReadJSONInto(util.NewRequestReader(r), stats); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := task.IncCacheStats(stats.Hit); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	plugin.WriteJSON(w, http.StatusOK, "cache stats recorded")
+}
+
+// CacheParams are the parameters shared by the save and restore commands.
+// They name where archives are stored, and the inputs that key them.
+type CacheParams struct {
+	// Key is the prefix of the archive's key, which is followed by a hash
+	// of the inputs.
+	Key string `mapstructure:"key" plugin:"expand"`
+
+	// Inputs is a list of file patterns, relative to the working directory,
+	// whose contents are hashed into the key, e.g. "src/**.go" or "go.sum".
+	Inputs []string `mapstructure:"inputs" plugin:"expand"`
+
+	// Expansions names expansions whose values are hashed into the key.
+	Expansions []string `mapstructure:"expansions"`
+
+	// LocalDir is a directory to store archives in. Either it or Bucket is set.
+	LocalDir string `mapstructure:"local_dir" plugin:"expand"`
+
+	// Bucket is the s3 bucket to store archives in, under Prefix, using the
+	// AwsKey and AwsSecret credentials and the Permissions ACL.
+	Bucket      string `mapstructure:"bucket" plugin:"expand"`
+	Prefix      string `mapstructure:"prefix" plugin:"expand"`
+	AwsKey      string `mapstructure:"aws_key" plugin:"expand"`
+	AwsSecret   string `mapstructure:"aws_secret" plugin:"expand"`
+	Permissions string `mapstructure:"permissions"`
+
+	// store overrides the store the parameters describe, for tests
+	store Store
+}
+
+func (cp *CacheParams) validate() error {
+	if cp.Key == "" {
+		return errors.New("key cannot be blank")
+	}
+	if (cp.LocalDir == "") == (cp.Bucket == "") {
+		return errors.New("exactly one of local_dir and bucket must be set")
+	}
+	if cp.Bucket != "" && (cp.AwsKey == "" || cp.AwsSecret == "") {
+		return errors.New("aws_key and aws_secret cannot be blank when bucket is set")
+	}
+	return nil
+}
+
+// getStore returns the store the parameters describe.
+func (cp *CacheParams) getStore(conf *model.TaskConfig) Store {
+	if cp.store != nil {
+		return cp.store
+	}
+	if cp.Bucket != "" {
+		auth := &aws.Auth{AccessKey: cp.AwsKey, SecretKey: cp.AwsSecret}
+		return NewS3Store(auth, cp.Bucket, cp.Prefix, cp.Permissions)
+	}
+	if !filepath.IsAbs(cp.LocalDir) {
+		return &DirStore{Root: filepath.Join(conf.WorkDir, cp.LocalDir)}
+	}
+	return &DirStore{Root: cp.LocalDir}
+}
+
+// fullKey returns the key followed by the hash of the contents of the input
+// files, and of the values of the named expansions.
+func (cp *CacheParams) fullKey(conf *model.TaskConfig) (string, error) {
+	hash := sha256.New()
+
+	if len(cp.Inputs) > 0 {
+		files, err := inputFiles(conf.WorkDir, cp.Inputs)
+		if err != nil {
+			return "", errors.Wrap(err, "problem finding input files")
+		}
+		for _, file := range files {
+			// the name is hashed too, so that renaming an input changes the key
+			fmt.Fprintf(hash, "file %s\n", file)
+			if err = hashFile(hash, filepath.Join(conf.WorkDir, filepath.FromSlash(file))); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	names := append([]string{}, cp.Expansions...)
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(hash, "expansion %s=%s\n", name, conf.Expansions.Get(name))
+	}
+
+	return cp.Key + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// inputFiles returns the sorted paths, relative to the root and separated by
+// slashes, of the files under the root that match the gitignore style patterns.
+func inputFiles(root string, patterns []string) ([]string, error) {
+	ignorer, err := ignore.CompileIgnoreLines(patterns...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	files := []string{}
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if ignorer.MatchesPath(rel) {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, errors.WithStack(err)
+}
+
+func hashFile(hash io.Writer, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	_, err = io.Copy(hash, f)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/command"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/plugin/plugintest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func writeTestFile(t *testing.T, path, contents string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSaveAndRestore(t *testing.T) {
+	Convey("With a working directory and a local cache directory", t, func() {
+		root, err := ioutil.TempDir("", "cache")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(root)
+
+		workDir := filepath.Join(root, "work")
+		writeTestFile(t, filepath.Join(workDir, "src", "main.c"), "int main() {}")
+		writeTestFile(t, filepath.Join(workDir, "build", "main.o"), "object")
+		conf := &model.TaskConfig{
+			WorkDir:    workDir,
+			Expansions: command.NewExpansions(map[string]string{"compiler": "gcc"}),
+		}
+		params := CacheParams{
+			Key:        "build-",
+			Inputs:     []string{"src/*.c"},
+			Expansions: []string{"compiler"},
+			LocalDir:   filepath.Join(root, "cache"),
+		}
+		save := &SaveCommand{CacheParams: params, Paths: []string{"build/*"}}
+		restore := &RestoreCommand{CacheParams: params, RestoreKeys: []string{"build-"}}
+		logger := &plugintest.MockLogger{}
+
+		Convey("the key should change with the inputs and expansions", func() {
+			key, err := params.fullKey(conf)
+			So(err, ShouldBeNil)
+			So(key, ShouldStartWith, "build-")
+
+			conf.Expansions.Put("compiler", "clang")
+			otherKey, err := params.fullKey(conf)
+			So(err, ShouldBeNil)
+			So(otherKey, ShouldNotEqual, key)
+		})
+
+		Convey("saved files should be restored under the same key", func() {
+			So(save.Save(logger, conf), ShouldBeNil)
+			So(os.RemoveAll(filepath.Join(workDir, "build")), ShouldBeNil)
+
+			hit, err := restore.Restore(logger, conf)
+			So(err, ShouldBeNil)
+			So(hit, ShouldBeTrue)
+			contents, err := ioutil.ReadFile(filepath.Join(workDir, "build", "main.o"))
+			So(err, ShouldBeNil)
+			So(string(contents), ShouldEqual, "object")
+
+			Convey("and by a fallback prefix once the inputs change", func() {
+				writeTestFile(t, filepath.Join(workDir, "src", "main.c"), "int main() { return 1; }")
+				So(os.RemoveAll(filepath.Join(workDir, "build")), ShouldBeNil)
+
+				hit, err = restore.Restore(logger, conf)
+				So(err, ShouldBeNil)
+				So(hit, ShouldBeFalse)
+				_, err = os.Stat(filepath.Join(workDir, "build", "main.o"))
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("restoring with nothing saved should not fail", func() {
+			hit, err := restore.Restore(logger, conf)
+			So(err, ShouldBeNil)
+			So(hit, ShouldBeFalse)
+		})
+	})
+}
+
+func TestDirStoreConcurrentPuts(t *testing.T) {
+	Convey("With a local cache directory", t, func() {
+		root, err := ioutil.TempDir("", "cache-store")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(root)
+		store := &DirStore{Root: filepath.Join(root, "cache")}
+
+		Convey("concurrent puts of a key should leave one whole archive", func() {
+			contents := []string{}
+			for i := 0; i < 8; i++ {
+				content := strings.Repeat(fmt.Sprintf("archive %d\n", i), 10000)
+				contents = append(contents, content)
+				writeTestFile(t, filepath.Join(root, fmt.Sprintf("archive%d.tgz", i)), content)
+			}
+
+			var wg sync.WaitGroup
+			errs := make(chan error, len(contents))
+			for i := range contents {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs <- store.Put("key", filepath.Join(root, fmt.Sprintf("archive%d.tgz", i)))
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				So(err, ShouldBeNil)
+			}
+
+			restored := filepath.Join(root, "restored.tgz")
+			hit, err := store.Get("key", restored)
+			So(err, ShouldBeNil)
+			So(hit, ShouldBeTrue)
+			data, err := ioutil.ReadFile(restored)
+			So(err, ShouldBeNil)
+			So(contents, ShouldContain, string(data))
+
+			files, err := ioutil.ReadDir(store.Root)
+			So(err, ShouldBeNil)
+			So(len(files), ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/evergreen-ci/evergreen/archive"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/mitchellh/mapstructure"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/slogger"
+	"github.com/pkg/errors"
+)
+
+// RestoreCommand extracts the archive stored under the key derived from the
+// command's inputs into the working directory. If there is none, it falls
+// back on the most recent archive whose key starts with one of RestoreKeys.
+type RestoreCommand struct {
+	CacheParams `mapstructure:",squash" plugin:"expand"`
+
+	// RestoreKeys are key prefixes to try in order when nothing is saved
+	// under the full key, e.g. "build-${build_variant}-"
+	RestoreKeys []string `mapstructure:"restore_keys" plugin:"expand"`
+}
+
+func (self *RestoreCommand) Name() string {
+	return RestoreCmdName
+}
+
+func (self *RestoreCommand) Plugin() string {
+	return CachePluginName
+}
+
+// ParseParams reads in the given parameters for the command.
+func (self *RestoreCommand) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, self); err != nil {
+		return errors.Wrapf(err, "error parsing '%v' params", self.Name())
+	}
+	if err := self.validate(); err != nil {
+		return errors.Wrapf(err, "error validating '%v' params", self.Name())
+	}
+	return nil
+}
+
+// Execute restores the archive, and records whether the full key was found.
+func (self *RestoreCommand) Execute(pluginLogger plugin.Logger,
+	pluginCom plugin.PluginCommunicator,
+	conf *model.TaskConfig,
+	stop chan bool) error {
+
+	if err := plugin.ExpandValues(self, conf.Expansions); err != nil {
+		return errors.Wrap(err, "error expanding params")
+	}
+
+	errChan := make(chan error)
+	go func() {
+		restored, err := self.Restore(pluginLogger, conf)
+		if err == nil {
+			self.sendStats(pluginLogger, pluginCom, restored)
+		}
+		errChan <- err
+	}()
+
+	select {
+	case err := <-errChan:
+		return errors.WithStack(err)
+	case <-stop:
+		pluginLogger.LogExecution(slogger.INFO, "Received signal to terminate"+
+			" execution of cache restore command")
+		return nil
+	}
+}
+
+// Restore extracts the closest matching archive into the working directory,
+// and returns whether it was saved under the full key. Finding no archive
+// is not an error.
+func (self *RestoreCommand) Restore(pluginLogger plugin.Logger, conf *model.TaskConfig) (bool, error) {
+	key, err := self.fullKey(conf)
+	if err != nil {
+		return false, errors.Wrap(err, "error computing cache key")
+	}
+	store := self.getStore(conf)
+
+	tmp, err := ioutil.TempFile("", "cache")
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	grip.CatchError(tmp.Close())
+	defer os.Remove(tmp.Name())
+
+	found, err := store.Get(key, tmp.Name())
+	if err != nil {
+		return false, errors.Wrapf(err, "error getting cache key %s", key)
+	}
+	exact := found
+
+	for _, prefix := range self.RestoreKeys {
+		if found {
+			break
+		}
+		if key, err = store.Latest(prefix); err != nil {
+			return false, errors.Wrapf(err, "error finding cache keys starting with %s", prefix)
+		}
+		if key == "" {
+			continue
+		}
+		if found, err = store.Get(key, tmp.Name()); err != nil {
+			return false, errors.Wrapf(err, "error getting cache key %s", key)
+		}
+	}
+
+	if !found {
+		pluginLogger.LogTask(slogger.INFO, "No cached files found")
+		return false, nil
+	}
+
+	f, _, tarReader, err := archive.TarGzReader(tmp.Name())
+	if err != nil {
+		return false, errors.Wrapf(err, "error opening cache archive %s", key)
+	}
+	defer f.Close()
+	if err = archive.Extract(tarReader, conf.WorkDir); err != nil {
+		return false, errors.Wrapf(err, "error extracting cache archive %s", key)
+	}
+	pluginLogger.LogTask(slogger.INFO, "Restored cached files from cache key %v", key)
+	return exact, nil
+}
+
+// sendStats records a cache hit or miss for the task. Failing to record it
+// does not fail the command.
+func (self *RestoreCommand) sendStats(pluginLogger plugin.Logger, pluginCom plugin.PluginCommunicator, hit bool) {
+	resp, err := pluginCom.TaskPostJSON(CacheStatsAPIEndpoint, CacheStats{Hit: hit})
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		pluginLogger.LogExecution(slogger.WARN, "Error recording cache stats: %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/evergreen-ci/evergreen/archive"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/mitchellh/mapstructure"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/send"
+	"github.com/mongodb/grip/slogger"
+	"github.com/pkg/errors"
+)
+
+// SaveCommand archives files of the working directory and stores the archive
+// under a key derived from the command's inputs, unless one is already stored.
+type SaveCommand struct {
+	CacheParams `mapstructure:",squash" plugin:"expand"`
+
+	// Paths is a list of filename blobs, relative to the working directory,
+	// to archive, e.g. "build/**" or "*.o"
+	Paths []string `mapstructure:"paths" plugin:"expand"`
+
+	// Exclude is a list of filename blobs not to archive
+	Exclude []string `mapstructure:"exclude" plugin:"expand"`
+}
+
+func (self *SaveCommand) Name() string {
+	return SaveCmdName
+}
+
+func (self *SaveCommand) Plugin() string {
+	return CachePluginName
+}
+
+// ParseParams reads in the given parameters for the command.
+func (self *SaveCommand) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, self); err != nil {
+		return errors.Wrapf(err, "error parsing '%v' params", self.Name())
+	}
+	if err := self.validateParams(); err != nil {
+		return errors.Wrapf(err, "error validating '%v' params", self.Name())
+	}
+	return nil
+}
+
+func (self *SaveCommand) validateParams() error {
+	if len(self.Paths) == 0 {
+		return errors.New("paths cannot be empty")
+	}
+	return self.validate()
+}
+
+// Execute saves the archive.
+func (self *SaveCommand) Execute(pluginLogger plugin.Logger,
+	pluginCom plugin.PluginCommunicator,
+	conf *model.TaskConfig,
+	stop chan bool) error {
+
+	if err := plugin.ExpandValues(self, conf.Expansions); err != nil {
+		return errors.Wrap(err, "error expanding params")
+	}
+
+	errChan := make(chan error)
+	go func() {
+		errChan <- self.Save(pluginLogger, conf)
+	}()
+
+	select {
+	case err := <-errChan:
+		return errors.WithStack(err)
+	case <-stop:
+		pluginLogger.LogExecution(slogger.INFO, "Received signal to terminate"+
+			" execution of cache save command")
+		return nil
+	}
+}
+
+// Save archives the paths and stores the archive, unless one is already
+// stored under the same key.
+func (self *SaveCommand) Save(pluginLogger plugin.Logger, conf *model.TaskConfig) error {
+	key, err := self.fullKey(conf)
+	if err != nil {
+		return errors.Wrap(err, "error computing cache key")
+	}
+	store := self.getStore(conf)
+
+	exists, err := store.Exists(key)
+	if err != nil {
+		return errors.Wrapf(err, "error checking for cache key %s", key)
+	}
+	if exists {
+		pluginLogger.LogTask(slogger.INFO, "Cache key %v is already saved", key)
+		return nil
+	}
+
+	tmp, err := ioutil.TempFile("", "cache")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	grip.CatchError(tmp.Close())
+	defer os.Remove(tmp.Name())
+
+	filesArchived, err := self.buildArchive(pluginLogger, tmp.Name(), conf.WorkDir)
+	if err != nil {
+		return errors.Wrap(err, "error building cache archive")
+	}
+	if filesArchived == 0 {
+		pluginLogger.LogTask(slogger.WARN, "No files matched the paths to cache, so nothing was saved")
+		return nil
+	}
+
+	if err = store.Put(key, tmp.Name()); err != nil {
+		return errors.Wrapf(err, "error saving cache key %s", key)
+	}
+	pluginLogger.LogTask(slogger.INFO, "Saved %v files under cache key %v", filesArchived, key)
+	return nil
+}
+
+// buildArchive writes the paths under the root to a tgz archive, and returns
+// how many files it includes.
+func (self *SaveCommand) buildArchive(pluginLogger plugin.Logger, target, root string) (int, error) {
+	log := &slogger.Logger{
+		Name:      "",
+		Appenders: []send.Sender{slogger.WrapAppender(&agentAppender{pluginLogger})},
+	}
+
+	f, gz, tarWriter, err := archive.TarGzWriter(target)
+	if err != nil {
+		return -1, errors.Wrapf(err, "error opening target archive file %s", target)
+	}
+	defer func() {
+		grip.CatchError(tarWriter.Close())
+		grip.CatchError(gz.Close())
+		grip.CatchError(f.Close())
+	}()
+
+	out, err := archive.BuildArchive(tarWriter, root, self.Paths, self.Exclude, log)
+	return out, errors.WithStack(err)
+}
+
+// since archive.BuildArchive takes in a slogger.Logger
+type agentAppender struct {
+	pluginLogger plugin.Logger
+}
+
+// satisfy the slogger.Appender interface
+func (self *agentAppender) Append(log *slogger.Log) error {
+	self.pluginLogger.LogExecution(log.Level, slogger.FormatLog(log))
+	return nil
+}
@@ -0,0 +1,263 @@
+package cache
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/s3"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// archiveExtension is appended to a cache key to name its archive.
+const archiveExtension = ".tgz"
+
+// Store keeps cache archives by key.
+type Store interface {
+	// Exists returns whether an archive is stored under the key.
+	Exists(key string) (bool, error)
+	// Put stores the archive file under the key.
+	Put(key, file string) error
+	// Get copies the archive stored under the key to the file, and returns
+	// false if there is none.
+	Get(key, file string) (bool, error)
+	// Latest returns the most recently stored key that starts with the
+	// prefix, or "" if there is none.
+	Latest(prefix string) (string, error)
+}
+
+// validateKey makes sure a key names a file inside a store.
+func validateKey(key string) error {
+	if key == "" || path.Clean("/"+key) != "/"+key {
+		return errors.Errorf("invalid cache key '%s'", key)
+	}
+	return nil
+}
+
+// DirStore is a Store that keeps archives as files under a directory.
+type DirStore struct {
+	Root string
+}
+
+func (ds *DirStore) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(ds.Root, filepath.FromSlash(key)+archiveExtension), nil
+}
+
+func (ds *DirStore) Exists(key string) (bool, error) {
+	filename, err := ds.path(key)
+	if err != nil {
+		return false, err
+	}
+	return fileExists(filename)
+}
+
+func (ds *DirStore) Put(key, file string) error {
+	filename, err := ds.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// copy to a temporary file first, so that restores never see part of an
+	// archive, and concurrent saves of the key never write to the same file
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".tmp")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err = tmp.Close(); err == nil {
+		if err = copyFile(file, tmp.Name()); err == nil {
+			err = os.Chmod(tmp.Name(), 0644)
+		}
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filename)
+	}
+	if err != nil {
+		grip.Warning(os.Remove(tmp.Name()))
+	}
+	return errors.WithStack(err)
+}
+
+func (ds *DirStore) Get(key, file string) (bool, error) {
+	filename, err := ds.path(key)
+	if err != nil {
+		return false, err
+	}
+	if exists, err := fileExists(filename); err != nil || !exists {
+		return false, err
+	}
+	return true, copyFile(filename, file)
+}
+
+func (ds *DirStore) Latest(prefix string) (string, error) {
+	latest := ""
+	var latestTime time.Time
+	err := filepath.Walk(ds.Root, func(filename string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(filename, archiveExtension) {
+			return nil
+		}
+		rel, err := filepath.Rel(ds.Root, filename)
+		if err != nil {
+			return err
+		}
+		key := strings.TrimSuffix(filepath.ToSlash(rel), archiveExtension)
+		if strings.HasPrefix(key, prefix) && info.ModTime().After(latestTime) {
+			latest, latestTime = key, info.ModTime()
+		}
+		return nil
+	})
+	return latest, errors.WithStack(err)
+}
+
+// S3Store is a Store that keeps archives in an s3 bucket, under a prefix.
+type S3Store struct {
+	Bucket      *s3.Bucket
+	Prefix      string
+	Permissions string
+}
+
+// NewS3Store returns an S3Store for the named bucket.
+func NewS3Store(auth *aws.Auth, bucket, prefix, permissions string) *S3Store {
+	return &S3Store{
+		Bucket:      thirdparty.NewS3Session(auth, aws.USEast).Bucket(bucket),
+		Prefix:      prefix,
+		Permissions: permissions,
+	}
+}
+
+func (ss *S3Store) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return path.Join(ss.Prefix, key) + archiveExtension, nil
+}
+
+func (ss *S3Store) Exists(key string) (bool, error) {
+	remote, err := ss.path(key)
+	if err != nil {
+		return false, err
+	}
+	exists, err := ss.Bucket.Exists(remote)
+	return exists, errors.WithStack(err)
+}
+
+func (ss *S3Store) Put(key, file string) error {
+	remote, err := ss.path(key)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.Wrapf(ss.Bucket.PutReader(remote, f, info.Size(), "application/x-gzip",
+		s3.ACL(ss.Permissions), s3.Options{}), "problem putting %s to bucket", remote)
+}
+
+func (ss *S3Store) Get(key, file string) (bool, error) {
+	remote, err := ss.path(key)
+	if err != nil {
+		return false, err
+	}
+	reader, err := ss.Bucket.GetReader(remote)
+	if err != nil {
+		if s3Err, ok := err.(*s3.Error); ok && s3Err.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "problem getting %s from bucket", remote)
+	}
+	defer reader.Close()
+	return true, writeFile(reader, file)
+}
+
+func (ss *S3Store) Latest(prefix string) (string, error) {
+	base := ss.Prefix
+	if base != "" && !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	fullPrefix := base + prefix
+
+	latest := ""
+	var latestTime time.Time
+	marker := ""
+	for {
+		resp, err := ss.Bucket.List(fullPrefix, "", marker, 1000)
+		if err != nil {
+			return "", errors.Wrapf(err, "problem listing keys with prefix %s", fullPrefix)
+		}
+		for _, key := range resp.Contents {
+			if !strings.HasSuffix(key.Key, archiveExtension) {
+				continue
+			}
+			modified, err := time.Parse(time.RFC3339Nano, key.LastModified)
+			if err != nil {
+				continue
+			}
+			if modified.After(latestTime) {
+				latest, latestTime = key.Key, modified
+			}
+		}
+		if !resp.IsTruncated || len(resp.Contents) == 0 {
+			break
+		}
+		// the next page starts after the last key of this one, whatever it is
+		marker = resp.Contents[len(resp.Contents)-1].Key
+	}
+	if latest == "" {
+		return "", nil
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(latest, base), archiveExtension), nil
+}
+
+func fileExists(filename string) (bool, error) {
+	_, err := os.Stat(filename)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, errors.WithStack(err)
+}
+
+func copyFile(from, to string) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer in.Close()
+	return writeFile(in, to)
+}
+
+func writeFile(from io.Reader, to string) error {
+	out, err := os.Create(to)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err = io.Copy(out, from); err != nil {
+		out.Close()
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(out.Close())
+}
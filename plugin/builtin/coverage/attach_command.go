@@ -0,0 +1,160 @@
+package coverage
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"github.com/mongodb/grip/slogger"
+	"github.com/pkg/errors"
+)
+
+const (
+	attachRetries = 10
+	attachSleep   = 3 * time.Second
+)
+
+// AttachCommand reads coverage reports in go cover profile, Cobertura XML or
+// lcov format and sends the coverage of each source file to the server.
+type AttachCommand struct {
+	// Files are the paths of the reports to read, relative to the working
+	// directory. Supports globbing.
+	Files []string `mapstructure:"files" plugin:"expand"`
+
+	// Format is one of ValidFormats. By default it is detected from each
+	// report's contents.
+	Format string `mapstructure:"format" plugin:"expand"`
+
+	// StripPrefix is removed from the start of each source file's name,
+	// so that absolute paths and import paths show up relative to the
+	// repository.
+	StripPrefix string `mapstructure:"strip_prefix" plugin:"expand"`
+}
+
+// AttachData is the body of the request the command sends to the server.
+type AttachData struct {
+	Files []coverage.FileCoverage `json:"files"`
+}
+
+func (c *AttachCommand) Name() string {
+	return AttachCmd
+}
+
+func (c *AttachCommand) Plugin() string {
+	return CoveragePluginName
+}
+
+// ParseParams reads and validates the command parameters.
+func (c *AttachCommand) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, c); err != nil {
+		return errors.Wrapf(err, "error decoding '%s' params", c.Name())
+	}
+	if len(c.Files) == 0 {
+		return errors.Errorf("error validating '%s' params: must specify at least one file", c.Name())
+	}
+	if c.Format == "" {
+		c.Format = FormatAuto
+	}
+	if !util.SliceContains(ValidFormats, c.Format) {
+		return errors.Errorf("error validating '%s' params: format must be one of %v",
+			c.Name(), ValidFormats)
+	}
+	return nil
+}
+
+// Execute reads the reports and sends their coverage to the server.
+func (c *AttachCommand) Execute(pluginLogger plugin.Logger,
+	pluginCom plugin.PluginCommunicator,
+	taskConfig *model.TaskConfig,
+	stop chan bool) error {
+
+	if err := plugin.ExpandValues(c, taskConfig.Expansions); err != nil {
+		return errors.Wrap(err, "error expanding params")
+	}
+
+	errChan := make(chan error)
+	go func() {
+		data, err := c.parseReports(taskConfig.WorkDir, pluginLogger)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		errChan <- sendCoverage(pluginLogger, pluginCom, data)
+	}()
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			pluginLogger.LogTask(slogger.ERROR, "Attaching coverage failed: %v", err)
+		}
+		return err
+	case <-stop:
+		pluginLogger.LogExecution(slogger.INFO, "Received signal to terminate"+
+			" execution of attach coverage command")
+		return nil
+	}
+}
+
+func (c *AttachCommand) parseReports(workDir string, pluginLogger plugin.Logger) (*AttachData, error) {
+	paths := []string{}
+	for _, pattern := range c.Files {
+		matches, err := filepath.Glob(filepath.Join(workDir, pattern))
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad file pattern '%s'", pattern)
+		}
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		return nil, errors.Errorf("no coverage reports match %v", c.Files)
+	}
+
+	data := &AttachData{}
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't open coverage report '%s'", path)
+		}
+		files, err := Parse(file, c.Format, c.StripPrefix)
+		if closeErr := file.Close(); closeErr != nil {
+			pluginLogger.LogExecution(slogger.INFO, "Error closing file: %v", closeErr)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing coverage report '%s'", path)
+		}
+		pluginLogger.LogTask(slogger.INFO, "Read coverage of %d files from %s", len(files), path)
+		data.Files = append(data.Files, files...)
+	}
+	return data, nil
+}
+
+func sendCoverage(pluginLogger plugin.Logger, pluginCom plugin.PluginCommunicator, data *AttachData) error {
+	retriablePost := util.RetriableFunc(
+		func() error {
+			pluginLogger.LogTask(slogger.INFO, "Posting coverage of %d files", len(data.Files))
+			resp, err := pluginCom.TaskPostJSON(AttachDataRoute, data)
+			if resp != nil {
+				defer resp.Body.Close()
+			}
+			if err != nil {
+				return util.RetriableError{Failure: errors.WithStack(err)}
+			}
+			if resp.StatusCode != http.StatusOK {
+				err = errors.Errorf("unexpected status code %v", resp.StatusCode)
+				if resp.StatusCode == http.StatusBadRequest {
+					return err
+				}
+				return util.RetriableError{Failure: err}
+			}
+			return nil
+		},
+	)
+
+	_, err := util.Retry(retriablePost, attachRetries, attachSleep)
+	return errors.Wrap(err, "problem posting coverage")
+}
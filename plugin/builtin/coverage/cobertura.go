@@ -0,0 +1,63 @@
+package coverage
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/pkg/errors"
+)
+
+// CoberturaParser reads Cobertura XML reports, as written by coverage.py,
+// gcovr, Istanbul and the Java coverage tools. Only the lines listed
+// directly under each class are counted, since the lines under its methods
+// repeat them.
+type CoberturaParser struct{}
+
+type coberturaPackage struct {
+	Name    string           `xml:"name,attr"`
+	Classes []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Filename string          `xml:"filename,attr"`
+	Lines    []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number int   `xml:"number,attr"`
+	Hits   int64 `xml:"hits,attr"`
+}
+
+func (*CoberturaParser) Parse(in io.Reader) ([]coverage.FileCoverage, error) {
+	counter := newLineCounter()
+	decoder := xml.NewDecoder(in)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "problem reading XML")
+		}
+
+		// decode one package at a time rather than the whole report
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "package" {
+			continue
+		}
+		pkg := coberturaPackage{}
+		if err = decoder.DecodeElement(&pkg, &start); err != nil {
+			return nil, errors.Wrap(err, "problem reading package")
+		}
+		for _, class := range pkg.Classes {
+			if class.Filename == "" {
+				continue
+			}
+			for _, line := range class.Lines {
+				counter.add(class.Filename, pkg.Name, line.Number, line.Hits > 0)
+			}
+		}
+	}
+	return counter.files(), nil
+}
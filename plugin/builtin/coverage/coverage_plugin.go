@@ -0,0 +1,176 @@
+package coverage
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	plugin.Publish(&CoveragePlugin{})
+}
+
+const (
+	CoveragePluginName = "coverage"
+	AttachCmd          = "attach"
+
+	// AttachDataRoute is the API route the attach command posts to.
+	AttachDataRoute = "data"
+
+	// defaultHistoryLimit is how many commits of coverage history are
+	// shown when the request does not say.
+	defaultHistoryLimit = 50
+)
+
+// CoveragePlugin stores the code coverage of tasks, and shows how it
+// changes across commits and how patches change it.
+type CoveragePlugin struct{}
+
+// Name implements Plugin Interface.
+func (cp *CoveragePlugin) Name() string {
+	return CoveragePluginName
+}
+
+func (cp *CoveragePlugin) Configure(map[string]interface{}) error {
+	return nil
+}
+
+// NewCommand returns requested commands by name. Fulfills the Plugin interface.
+func (cp *CoveragePlugin) NewCommand(cmdName string) (plugin.Command, error) {
+	if cmdName == AttachCmd {
+		return &AttachCommand{}, nil
+	}
+	return nil, &plugin.ErrUnknownCommand{CommandName: cmdName}
+}
+
+// GetAPIHandler returns the route the agent sends coverage to.
+func (cp *CoveragePlugin) GetAPIHandler() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/"+AttachDataRoute, apiAttachCoverage).Methods("POST")
+	return r
+}
+
+// GetUIHandler returns the routes for a task's coverage and its history.
+func (cp *CoveragePlugin) GetUIHandler() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/task/{task_id}", uiGetTaskCoverage)
+	r.HandleFunc("/task/{task_id}/history", uiGetTaskCoverageHistory)
+	return r
+}
+
+// GetPanelConfig adds a panel with the task's coverage and, for patches, its
+// change from the base commit to the task page.
+func (cp *CoveragePlugin) GetPanelConfig() (*plugin.PanelConfig, error) {
+	return &plugin.PanelConfig{
+		Panels: []plugin.UIPanel{
+			{
+				Page:     plugin.TaskPage,
+				Position: plugin.PageCenter,
+				PanelHTML: "<div ng-include=\"'/plugin/coverage/static/partials/task_coverage_panel.html'\" " +
+					"ng-init='coverage=plugins.coverage' ng-show='plugins.coverage.coverage'></div>",
+				DataFunc: func(context plugin.UIContext) (interface{}, error) {
+					if context.Task == nil {
+						return nil, nil
+					}
+					return getTaskCoverage(context.Task)
+				},
+			},
+		},
+	}, nil
+}
+
+// TaskCoverageData is a task's coverage along with how a patch changed it.
+type TaskCoverageData struct {
+	Coverage *coverage.TaskCoverage `json:"coverage"`
+	Delta    *coverage.Delta        `json:"delta,omitempty"`
+}
+
+func getTaskCoverage(t *task.Task) (*TaskCoverageData, error) {
+	c, err := coverage.FindOne(coverage.ByTaskId(t.Id))
+	if err != nil {
+		return nil, errors.Wrap(err, "error finding coverage for task")
+	}
+	if c == nil {
+		return &TaskCoverageData{}, nil
+	}
+	delta, err := coverage.DeltaForTask(t)
+	if err != nil {
+		return nil, err
+	}
+	return &TaskCoverageData{Coverage: c, Delta: delta}, nil
+}
+
+func apiAttachCoverage(w http.ResponseWriter, r *http.Request) {
+	t := plugin.GetTask(r)
+	if t == nil {
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+	data := &AttachData{}
+	if err := util.ReadJSONInto(util.NewRequestReader(r), data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := coverage.Attach(t, data.Files); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	plugin.WriteJSON(w, http.StatusOK, "ok")
+}
+
+func findTask(w http.ResponseWriter, r *http.Request) *task.Task {
+	t, err := task.FindOne(task.ById(mux.Vars(r)["task_id"]))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+	if t == nil {
+		http.Error(w, "{}", http.StatusNotFound)
+		return nil
+	}
+	return t
+}
+
+func uiGetTaskCoverage(w http.ResponseWriter, r *http.Request) {
+	t := findTask(w, r)
+	if t == nil {
+		return
+	}
+	data, err := getTaskCoverage(t)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if data.Coverage == nil {
+		http.Error(w, "{}", http.StatusNotFound)
+		return
+	}
+	plugin.WriteJSON(w, http.StatusOK, data)
+}
+
+func uiGetTaskCoverageHistory(w http.ResponseWriter, r *http.Request) {
+	t := findTask(w, r)
+	if t == nil {
+		return
+	}
+	limit := defaultHistoryLimit
+	if l := r.FormValue("limit"); l != "" {
+		var err error
+		if limit, err = strconv.Atoi(l); err != nil || limit <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+	history, err := coverage.HistoryForTask(t, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	plugin.WriteJSON(w, http.StatusOK, history)
+}
@@ -0,0 +1,90 @@
+package coverage
+
+import (
+	"bufio"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/pkg/errors"
+)
+
+// GoParser reads the profiles written by 'go test -coverprofile'. Each line
+// after the mode is a block of statements:
+//
+//	name.go:line.column,line.column numberOfStatements count
+//
+// Profiles of several packages are often concatenated, which repeats the
+// mode line and, with -coverpkg, the blocks too. A repeated block is covered
+// if any of its counts is above zero.
+type GoParser struct{}
+
+type goBlock struct {
+	file     string
+	position string
+}
+
+func (*GoParser) Parse(in io.Reader) ([]coverage.FileCoverage, error) {
+	statements := map[goBlock]int{}
+	covered := map[goBlock]bool{}
+
+	scanner := bufio.NewScanner(in)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+
+		colon := strings.LastIndex(line, ":")
+		fields := strings.Fields(line[colon+1:])
+		if colon < 0 || len(fields) != 3 {
+			return nil, errors.Errorf("line %d is not a coverage block: '%s'", lineNum, line)
+		}
+		numStatements, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad number of statements on line %d", lineNum)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad count on line %d", lineNum)
+		}
+
+		block := goBlock{file: line[:colon], position: fields[0]}
+		statements[block] = numStatements
+		covered[block] = covered[block] || count > 0
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "problem reading coverage profile")
+	}
+
+	byFile := map[string]*coverage.FileCoverage{}
+	for block, numStatements := range statements {
+		f, ok := byFile[block.file]
+		if !ok {
+			// go profiles name files by import path, so their directory is
+			// the package
+			f = &coverage.FileCoverage{Name: block.file, Package: path.Dir(block.file)}
+			byFile[block.file] = f
+		}
+		f.Total += numStatements
+		if covered[block] {
+			f.Covered += numStatements
+		}
+	}
+
+	names := make([]string, 0, len(byFile))
+	for name := range byFile {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	files := make([]coverage.FileCoverage, 0, len(names))
+	for _, name := range names {
+		files = append(files, *byFile[name])
+	}
+	return files, nil
+}
@@ -0,0 +1,61 @@
+package coverage
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/pkg/errors"
+)
+
+// LcovParser reads lcov tracefiles. Each source file's record starts with
+// its name and has a line for every instrumented line:
+//
+//	SF:<path>
+//	DA:<line number>,<hits>[,<checksum>]
+//	end_of_record
+//
+// Function and branch records are ignored.
+type LcovParser struct{}
+
+func (*LcovParser) Parse(in io.Reader) ([]coverage.FileCoverage, error) {
+	counter := newLineCounter()
+	scanner := bufio.NewScanner(in)
+	file := ""
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "SF:"):
+			file = strings.TrimPrefix(line, "SF:")
+		case line == "end_of_record":
+			file = ""
+		case strings.HasPrefix(line, "DA:"):
+			if file == "" {
+				return nil, errors.Errorf("line %d is outside of a source file record", lineNum)
+			}
+			fields := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if len(fields) < 2 {
+				return nil, errors.Errorf("line %d is not a line record: '%s'", lineNum, line)
+			}
+			number, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return nil, errors.Wrapf(err, "bad line number on line %d", lineNum)
+			}
+			// some tools write hit counts too large for an int64 as
+			// floats, and all that matters is whether they are zero
+			hits, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "bad hit count on line %d", lineNum)
+			}
+			counter.add(file, "", number, hits > 0)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "problem reading tracefile")
+	}
+	return counter.files(), nil
+}
@@ -0,0 +1,165 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/pkg/errors"
+)
+
+// Formats of coverage reports that can be attached.
+const (
+	FormatAuto      = "auto"
+	FormatGo        = "go"
+	FormatCobertura = "cobertura"
+	FormatLcov      = "lcov"
+)
+
+// ValidFormats are the values the format parameter accepts.
+var ValidFormats = []string{FormatAuto, FormatGo, FormatCobertura, FormatLcov}
+
+// Parser reads a coverage report and returns the coverage of each source
+// file it mentions.
+type Parser interface {
+	Parse(io.Reader) ([]coverage.FileCoverage, error)
+}
+
+// NewParser returns the parser for a format other than auto.
+func NewParser(format string) (Parser, error) {
+	switch format {
+	case FormatGo:
+		return &GoParser{}, nil
+	case FormatCobertura:
+		return &CoberturaParser{}, nil
+	case FormatLcov:
+		return &LcovParser{}, nil
+	}
+	return nil, errors.Errorf("unknown coverage format '%s'", format)
+}
+
+// detectSize is how much of a report is read to detect its format.
+const detectSize = 4096
+
+// DetectFormat returns the format of the report that the reader holds,
+// without consuming it, from its first line: go cover profiles start with
+// their mode, Cobertura reports are XML, and lcov reports start with a test
+// name or a source file record.
+func DetectFormat(in *bufio.Reader) (string, error) {
+	start, err := in.Peek(detectSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return "", errors.Wrap(err, "problem reading coverage report")
+	}
+	// skip any byte order mark along with the leading whitespace
+	start = bytes.TrimLeft(start, " \t\r\n\xef\xbb\xbf")
+	switch {
+	case len(start) == 0:
+		return "", errors.New("coverage report is empty")
+	case bytes.HasPrefix(start, []byte("mode:")):
+		return FormatGo, nil
+	case start[0] == '<':
+		return FormatCobertura, nil
+	case bytes.HasPrefix(start, []byte("TN:")), bytes.HasPrefix(start, []byte("SF:")):
+		return FormatLcov, nil
+	}
+	if i := bytes.IndexByte(start, '\n'); i >= 0 {
+		start = start[:i]
+	}
+	return "", errors.Errorf("unrecognized coverage report starting with '%s'", start)
+}
+
+// Parse reads a report in the given format, detecting the format first if
+// it is FormatAuto, and strips prefix from the start of each file name.
+func Parse(in io.Reader, format, prefix string) ([]coverage.FileCoverage, error) {
+	buffered := bufio.NewReaderSize(in, detectSize)
+	if format == FormatAuto || format == "" {
+		var err error
+		if format, err = DetectFormat(buffered); err != nil {
+			return nil, err
+		}
+	}
+	parser, err := NewParser(format)
+	if err != nil {
+		return nil, err
+	}
+	files, err := parser.Parse(buffered)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem parsing %s coverage report", format)
+	}
+	if prefix != "" {
+		for i := range files {
+			files[i].Name = strings.TrimPrefix(files[i].Name, prefix)
+			files[i].Package = stripPackagePrefix(files[i].Package, prefix)
+		}
+	}
+	return files, nil
+}
+
+// stripPackagePrefix strips prefix from a package the way it is stripped
+// from the names of the package's files, so that the package the prefix
+// names becomes ".".
+func stripPackagePrefix(pkg, prefix string) string {
+	stripped := strings.TrimPrefix(pkg+"/", prefix)
+	if stripped == pkg+"/" {
+		return pkg
+	}
+	stripped = strings.TrimSuffix(stripped, "/")
+	if stripped == "" {
+		return "."
+	}
+	return stripped
+}
+
+// lineCounter collects which lines of each file were hit, for the formats
+// that report coverage by line. A line reported more than once is covered
+// if any report hit it.
+type lineCounter struct {
+	packages map[string]string
+	lines    map[string]map[int]bool
+}
+
+func newLineCounter() *lineCounter {
+	return &lineCounter{
+		packages: map[string]string{},
+		lines:    map[string]map[int]bool{},
+	}
+}
+
+func (lc *lineCounter) add(file, pkg string, line int, hit bool) {
+	if _, ok := lc.lines[file]; !ok {
+		lc.lines[file] = map[int]bool{}
+		if pkg == "" {
+			pkg = path.Dir(file)
+		}
+		lc.packages[file] = pkg
+	}
+	lc.lines[file][line] = lc.lines[file][line] || hit
+}
+
+func (lc *lineCounter) files() []coverage.FileCoverage {
+	names := make([]string, 0, len(lc.lines))
+	for name := range lc.lines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make([]coverage.FileCoverage, 0, len(names))
+	for _, name := range names {
+		f := coverage.FileCoverage{
+			Name:    name,
+			Package: lc.packages[name],
+			Total:   len(lc.lines[name]),
+		}
+		for _, hit := range lc.lines[name] {
+			if hit {
+				f.Covered++
+			}
+		}
+		files = append(files, f)
+	}
+	return files
+}
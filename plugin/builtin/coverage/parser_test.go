@@ -0,0 +1,115 @@
+package coverage
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/evergreen-ci/evergreen/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// parseFile detects the format of a test data file and returns the coverage
+// of the files in it.
+func parseFile(t *testing.T, name, prefix string) []coverage.FileCoverage {
+	file, err := os.Open(filepath.Join(testutil.GetDirectoryOfFile(), "testdata", name))
+	testutil.HandleTestingErr(err, t, "Error reading file")
+	defer file.Close()
+
+	files, err := Parse(file, FormatAuto, prefix)
+	So(err, ShouldBeNil)
+	return files
+}
+
+func TestDetectFormat(t *testing.T) {
+	Convey("The format of a report should be detected from its start", t, func() {
+		for text, format := range map[string]string{
+			"mode: atomic\na.go:1.1,2.2 1 1":       FormatGo,
+			"\n<?xml version=\"1.0\"?><coverage/>": FormatCobertura,
+			"TN:\nSF:a.js\nend_of_record":          FormatLcov,
+			"SF:a.js\nend_of_record":               FormatLcov,
+		} {
+			detected, err := DetectFormat(bufio.NewReader(strings.NewReader(text)))
+			So(err, ShouldBeNil)
+			So(detected, ShouldEqual, format)
+		}
+	})
+
+	Convey("Empty and unknown reports should be rejected", t, func() {
+		_, err := DetectFormat(bufio.NewReader(strings.NewReader(" \n")))
+		So(err, ShouldNotBeNil)
+		_, err = DetectFormat(bufio.NewReader(strings.NewReader("total: 50%")))
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestGoParser(t *testing.T) {
+	Convey("With a go cover profile of several packages", t, func() {
+		files := parseFile(t, "coverage_go.out", "github.com/evergreen-ci/evergreen/")
+		So(len(files), ShouldEqual, 3)
+
+		Convey("files should be named relative to the stripped prefix", func() {
+			So(files[0].Name, ShouldEqual, "db/query.go")
+			So(files[0].Package, ShouldEqual, "db")
+			So(files[1].Name, ShouldEqual, "util/math.go")
+			So(files[1].Package, ShouldEqual, "util")
+		})
+
+		Convey("statements should be counted once, covered if any profile covered them", func() {
+			So(files[0].Covered, ShouldEqual, 3)
+			So(files[0].Total, ShouldEqual, 3)
+			So(files[1].Covered, ShouldEqual, 4)
+			So(files[1].Total, ShouldEqual, 4)
+			So(files[2].Covered, ShouldEqual, 0)
+			So(files[2].Total, ShouldEqual, 1)
+		})
+	})
+
+	Convey("A malformed block should be an error", t, func() {
+		_, err := (&GoParser{}).Parse(strings.NewReader("mode: set\nmath.go 2 1\n"))
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestCoberturaParser(t *testing.T) {
+	Convey("With a Cobertura report", t, func() {
+		files := parseFile(t, "coverage_cobertura.xml", "")
+		So(len(files), ShouldEqual, 2)
+
+		Convey("only the lines of each class should be counted", func() {
+			So(files[0].Name, ShouldEqual, "app/models.py")
+			So(files[0].Package, ShouldEqual, "app")
+			So(files[0].Covered, ShouldEqual, 3)
+			So(files[0].Total, ShouldEqual, 4)
+			So(files[1].Name, ShouldEqual, "app/views.py")
+			So(files[1].Covered, ShouldEqual, 0)
+			So(files[1].Total, ShouldEqual, 2)
+		})
+	})
+}
+
+func TestLcovParser(t *testing.T) {
+	Convey("With an lcov tracefile", t, func() {
+		files := parseFile(t, "coverage.lcov", "src/")
+		So(len(files), ShouldEqual, 2)
+
+		Convey("records of the same file should be merged", func() {
+			So(files[0].Name, ShouldEqual, "index.js")
+			So(files[0].Package, ShouldEqual, ".")
+			So(files[0].Covered, ShouldEqual, 3)
+			So(files[0].Total, ShouldEqual, 3)
+			So(files[1].Name, ShouldEqual, "util/format.js")
+			So(files[1].Package, ShouldEqual, "util")
+			So(files[1].Covered, ShouldEqual, 0)
+			So(files[1].Total, ShouldEqual, 2)
+		})
+	})
+
+	Convey("A line outside of a source file record should be an error", t, func() {
+		_, err := (&LcovParser{}).Parse(strings.NewReader("DA:1,1\n"))
+		So(err, ShouldNotBeNil)
+	})
+}
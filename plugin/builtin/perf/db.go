@@ -0,0 +1,165 @@
+package perf
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/db/bsonutil"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// RegressionsCollection holds the regressions found in tasks' results.
+	RegressionsCollection = "perf_regressions"
+)
+
+// Regression is a metric of a task that was found to be worse than on
+// previous commits.
+type Regression struct {
+	Id                  bson.ObjectId `bson:"_id" json:"id"`
+	TaskId              string        `bson:"task_id" json:"task_id"`
+	TaskName            string        `bson:"task_name" json:"task_name"`
+	ProjectId           string        `bson:"project_id" json:"project_id"`
+	Variant             string        `bson:"variant" json:"variant"`
+	VersionId           string        `bson:"version_id" json:"version_id"`
+	Revision            string        `bson:"revision" json:"revision"`
+	RevisionOrderNumber int           `bson:"order" json:"order"`
+	CreateTime          time.Time     `bson:"create_time" json:"create_time"`
+
+	// Name is the name the results were sent under, and Metric is the name
+	// of the metric within them.
+	Name      string `bson:"name" json:"name"`
+	Metric    string `bson:"metric" json:"metric"`
+	Unit      string `bson:"unit" json:"unit"`
+	Direction string `bson:"direction" json:"direction"`
+
+	Method       string  `bson:"method" json:"method"`
+	BaselineMean float64 `bson:"baseline_mean" json:"baseline_mean"`
+	Mean         float64 `bson:"mean" json:"mean"`
+	Change       float64 `bson:"change" json:"change"`
+	Score        float64 `bson:"score,omitempty" json:"score,omitempty"`
+
+	// BaselineUpdate is set when a user accepts the regression as the new
+	// normal. Later commits are then only compared to results from this
+	// commit on.
+	BaselineUpdate bool      `bson:"baseline_update" json:"baseline_update"`
+	MarkedBy       string    `bson:"marked_by,omitempty" json:"marked_by,omitempty"`
+	MarkedAt       time.Time `bson:"marked_at,omitempty" json:"marked_at,omitempty"`
+}
+
+var (
+	// BSON fields for the regression struct
+	IdKey                  = bsonutil.MustHaveTag(Regression{}, "Id")
+	TaskIdKey              = bsonutil.MustHaveTag(Regression{}, "TaskId")
+	TaskNameKey            = bsonutil.MustHaveTag(Regression{}, "TaskName")
+	ProjectIdKey           = bsonutil.MustHaveTag(Regression{}, "ProjectId")
+	VariantKey             = bsonutil.MustHaveTag(Regression{}, "Variant")
+	RevisionOrderNumberKey = bsonutil.MustHaveTag(Regression{}, "RevisionOrderNumber")
+	NameKey                = bsonutil.MustHaveTag(Regression{}, "Name")
+	MetricKey              = bsonutil.MustHaveTag(Regression{}, "Metric")
+	BaselineUpdateKey      = bsonutil.MustHaveTag(Regression{}, "BaselineUpdate")
+	MarkedByKey            = bsonutil.MustHaveTag(Regression{}, "MarkedBy")
+	MarkedAtKey            = bsonutil.MustHaveTag(Regression{}, "MarkedAt")
+)
+
+// === Queries ===
+
+// ById returns a query for the regression with the given id.
+func ById(id bson.ObjectId) db.Q {
+	return db.Query(bson.M{IdKey: id})
+}
+
+// ByTaskId returns a query for the regressions found in a task, optionally
+// only for the results sent under the given name.
+func ByTaskId(taskId, name string) db.Q {
+	q := bson.M{TaskIdKey: taskId}
+	if name != "" {
+		q[NameKey] = name
+	}
+	return db.Query(q).Sort([]string{NameKey, MetricKey})
+}
+
+// LatestBaselineUpdate returns a query for the most recent regression of a
+// metric before the given revision order number that was marked as a
+// baseline update.
+func LatestBaselineUpdate(project, variant, taskName, name, metric string, beforeOrder int) db.Q {
+	return db.Query(bson.M{
+		ProjectIdKey:           project,
+		VariantKey:             variant,
+		TaskNameKey:            taskName,
+		NameKey:                name,
+		MetricKey:              metric,
+		BaselineUpdateKey:      true,
+		RevisionOrderNumberKey: bson.M{"$lt": beforeOrder},
+	}).Sort([]string{"-" + RevisionOrderNumberKey}).Limit(1)
+}
+
+// === DB Logic ===
+
+// Insert writes the regression to the database.
+func (r *Regression) Insert() error {
+	return db.Insert(RegressionsCollection, r)
+}
+
+// FindOneRegression returns a regression that satisfies the query, or nil
+// if there is none.
+func FindOneRegression(query db.Q) (*Regression, error) {
+	r := &Regression{}
+	err := db.FindOneQ(RegressionsCollection, query, r)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	return r, err
+}
+
+// FindRegressions returns all regressions that satisfy the query.
+func FindRegressions(query db.Q) ([]Regression, error) {
+	regressions := []Regression{}
+	err := db.FindAllQ(RegressionsCollection, query, &regressions)
+	return regressions, err
+}
+
+// ReplaceRegressions removes the regressions previously found in the task's
+// results with the given name, such as when a task is restarted, and inserts
+// the new ones. Baseline updates of the old regressions are kept for the new
+// regressions of the same metrics.
+func ReplaceRegressions(taskId, name string, regressions []Regression) error {
+	old, err := FindRegressions(ByTaskId(taskId, name))
+	if err != nil {
+		return errors.Wrap(err, "problem finding previous regressions")
+	}
+	marked := map[string]Regression{}
+	for _, r := range old {
+		if r.BaselineUpdate {
+			marked[r.Metric] = r
+		}
+	}
+
+	if err = db.RemoveAll(RegressionsCollection, bson.M{TaskIdKey: taskId, NameKey: name}); err != nil {
+		return errors.Wrap(err, "problem removing previous regressions")
+	}
+	for _, r := range regressions {
+		if m, ok := marked[r.Metric]; ok {
+			r.BaselineUpdate, r.MarkedBy, r.MarkedAt = true, m.MarkedBy, m.MarkedAt
+		}
+		if err = r.Insert(); err != nil {
+			return errors.Wrapf(err, "problem inserting regression of '%s'", r.Metric)
+		}
+	}
+	return nil
+}
+
+// SetBaselineUpdate marks or unmarks the regression as a baseline update on
+// behalf of the given user.
+func SetBaselineUpdate(id bson.ObjectId, userId string, baselineUpdate bool) error {
+	update := bson.M{"$set": bson.M{BaselineUpdateKey: baselineUpdate}}
+	if baselineUpdate {
+		update["$set"].(bson.M)[MarkedByKey] = userId
+		update["$set"].(bson.M)[MarkedAtKey] = time.Now()
+	} else {
+		update["$unset"] = bson.M{MarkedByKey: 1, MarkedAtKey: 1}
+	}
+	return db.Update(RegressionsCollection, bson.M{IdKey: id}, update)
+}
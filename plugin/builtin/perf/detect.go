@@ -0,0 +1,201 @@
+package perf
+
+import (
+	"math"
+
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/pkg/errors"
+)
+
+// Methods of detecting a regression.
+const (
+	// MethodThreshold compares a metric to the mean of its recent history,
+	// and reports a regression when it is worse by more than a percentage.
+	MethodThreshold = "threshold"
+
+	// MethodChangePoint looks for the point in a metric's history where its
+	// mean shifted the most, and reports a regression when that point is the
+	// newest commit, the shift is significant and it is for the worse.
+	MethodChangePoint = "changepoint"
+)
+
+// ValidMethods are the detection methods perf.send accepts.
+var ValidMethods = []string{MethodThreshold, MethodChangePoint}
+
+const (
+	// DefaultThreshold is the percent change the threshold method treats as
+	// a regression.
+	DefaultThreshold = 10.0
+
+	// DefaultSensitivity is how many standard deviations a shift in the mean
+	// has to be for the change point method to report it.
+	DefaultSensitivity = 3.0
+
+	// DefaultWindow is how many of the most recent commits are compared to
+	// the newest one.
+	DefaultWindow = 20
+
+	// minChangePointHistory is how many commits of history the change point
+	// method needs before it will report anything.
+	minChangePointHistory = 5
+)
+
+// Detection configures how regressions are detected.
+type Detection struct {
+	Method string `json:"method"`
+
+	// Threshold is the smallest percent change for the worse that is
+	// reported. The change point method ignores it when it is zero.
+	Threshold float64 `json:"threshold"`
+
+	// Sensitivity is used by the change point method, and is how many
+	// standard deviations a shift has to be to be reported.
+	Sensitivity float64 `json:"sensitivity"`
+
+	// Window is how many of the most recent commits of history are used.
+	Window int `json:"window"`
+}
+
+// Validate checks the detection settings, filling in defaults for the ones
+// that are not set.
+func (d *Detection) Validate() error {
+	if d.Method == "" {
+		d.Method = MethodThreshold
+	}
+	if !util.SliceContains(ValidMethods, d.Method) {
+		return errors.Errorf("detection method must be one of %v", ValidMethods)
+	}
+	if d.Threshold < 0 || d.Sensitivity < 0 || d.Window < 0 {
+		return errors.New("threshold, sensitivity and window must not be negative")
+	}
+	if d.Threshold == 0 && d.Method == MethodThreshold {
+		d.Threshold = DefaultThreshold
+	}
+	if d.Sensitivity == 0 {
+		d.Sensitivity = DefaultSensitivity
+	}
+	if d.Window == 0 {
+		d.Window = DefaultWindow
+	}
+	return nil
+}
+
+// Finding describes a metric that regressed.
+type Finding struct {
+	// BaselineMean is the mean of the history the metric was compared to.
+	BaselineMean float64
+	// Change is the percent change of the metric from the baseline.
+	Change float64
+	// Score is the size of the shift in standard deviations, for the
+	// change point method.
+	Score float64
+}
+
+// Detect compares the metric to the means of the same metric on previous
+// commits, oldest first, and returns a finding if it regressed.
+func (d *Detection) Detect(history []float64, metric *Metric) *Finding {
+	if len(history) > d.Window {
+		history = history[len(history)-d.Window:]
+	}
+	threshold := d.Threshold
+	if metric.Threshold > 0 {
+		threshold = metric.Threshold
+	}
+
+	switch d.Method {
+	case MethodThreshold:
+		if len(history) == 0 {
+			return nil
+		}
+		finding := newFinding(mean(history), metric)
+		if finding == nil || !isWorse(metric.Direction, finding.Change) || math.Abs(finding.Change) <= threshold {
+			return nil
+		}
+		return finding
+	case MethodChangePoint:
+		if len(history) < minChangePointHistory {
+			return nil
+		}
+		series := append(append([]float64{}, history...), metric.Mean)
+		split, score := mostLikelyChangePoint(series)
+		if split != len(series)-1 || score <= d.Sensitivity {
+			return nil
+		}
+		finding := newFinding(mean(history), metric)
+		if finding == nil || !isWorse(metric.Direction, finding.Change) || math.Abs(finding.Change) <= threshold {
+			return nil
+		}
+		finding.Score = score
+		return finding
+	}
+	return nil
+}
+
+func newFinding(baseline float64, metric *Metric) *Finding {
+	if baseline == 0 {
+		// there is no meaningful percent change from zero
+		return nil
+	}
+	return &Finding{
+		BaselineMean: baseline,
+		Change:       100 * (metric.Mean - baseline) / math.Abs(baseline),
+	}
+}
+
+func isWorse(direction string, change float64) bool {
+	if direction == HigherIsBetter {
+		return change < 0
+	}
+	return change > 0
+}
+
+// mostLikelyChangePoint finds where in the series its mean most likely
+// shifted. It returns the index of the first value after the shift, and the
+// size of the shift, weighted by the lengths of the two sides, in pooled
+// standard deviations.
+func mostLikelyChangePoint(series []float64) (int, float64) {
+	n := len(series)
+	bestSplit, bestScore := 0, 0.0
+	for k := 1; k < n; k++ {
+		left, right := series[:k], series[k:]
+		leftMean, rightMean := mean(left), mean(right)
+		diff := math.Abs(leftMean - rightMean)
+
+		// with only the two sides' own variation to go by, a shift within
+		// perfectly steady values is infinitely significant
+		sumSquares := sumOfSquares(left, leftMean) + sumOfSquares(right, rightMean)
+		var score float64
+		switch {
+		case diff == 0:
+			score = 0
+		case sumSquares == 0 || n <= 2:
+			score = math.Inf(1)
+		default:
+			stddev := math.Sqrt(sumSquares / float64(n-2))
+			score = diff / stddev * math.Sqrt(float64(k*(n-k))/float64(n))
+		}
+		if score > bestScore {
+			bestSplit, bestScore = k, score
+		}
+	}
+	return bestSplit, bestScore
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func sumOfSquares(values []float64, mean float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return sum
+}
@@ -0,0 +1,123 @@
+package perf
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestResultsValidate(t *testing.T) {
+	Convey("Valid results should have the means of their trials computed", t, func() {
+		results := &Results{Metrics: []Metric{
+			{Name: "insert", Unit: "ops/sec", Direction: HigherIsBetter, Trials: []float64{90, 100, 110}},
+			{Name: "latency", Unit: "ms", Direction: LowerIsBetter, Trials: []float64{5}},
+		}}
+		So(results.Validate(), ShouldBeNil)
+		So(results.Metric("insert").Mean, ShouldAlmostEqual, 100)
+		So(results.Metric("latency").Mean, ShouldAlmostEqual, 5)
+		So(results.Metric("missing"), ShouldBeNil)
+
+		Convey("and should be the same after being stored as task data", func() {
+			data, err := results.Data()
+			So(err, ShouldBeNil)
+			stored, err := ResultsFromData(data)
+			So(err, ShouldBeNil)
+			So(stored, ShouldResemble, results)
+		})
+	})
+
+	Convey("Invalid results should be rejected", t, func() {
+		for _, metrics := range [][]Metric{
+			{},
+			{{Direction: LowerIsBetter, Trials: []float64{1}}},
+			{{Name: "a", Direction: "sideways", Trials: []float64{1}}},
+			{{Name: "a", Direction: LowerIsBetter}},
+			{{Name: "a", Direction: LowerIsBetter, Trials: []float64{1}},
+				{Name: "a", Direction: LowerIsBetter, Trials: []float64{2}}},
+		} {
+			So((&Results{Metrics: metrics}).Validate(), ShouldNotBeNil)
+		}
+	})
+}
+
+func TestThresholdDetection(t *testing.T) {
+	Convey("With the threshold method and its defaults", t, func() {
+		detection := &Detection{Method: MethodThreshold}
+		So(detection.Validate(), ShouldBeNil)
+		So(detection.Threshold, ShouldEqual, DefaultThreshold)
+		history := []float64{100, 102, 98, 100}
+
+		Convey("a change for the worse past the threshold should be found", func() {
+			finding := detection.Detect(history, &Metric{Direction: LowerIsBetter, Mean: 115})
+			So(finding, ShouldNotBeNil)
+			So(finding.BaselineMean, ShouldAlmostEqual, 100)
+			So(finding.Change, ShouldAlmostEqual, 15)
+
+			finding = detection.Detect(history, &Metric{Direction: HigherIsBetter, Mean: 85})
+			So(finding, ShouldNotBeNil)
+			So(finding.Change, ShouldAlmostEqual, -15)
+		})
+
+		Convey("improvements and small changes should not be found", func() {
+			So(detection.Detect(history, &Metric{Direction: HigherIsBetter, Mean: 115}), ShouldBeNil)
+			So(detection.Detect(history, &Metric{Direction: LowerIsBetter, Mean: 105}), ShouldBeNil)
+		})
+
+		Convey("a metric's own threshold should override the command's", func() {
+			So(detection.Detect(history, &Metric{Direction: LowerIsBetter, Mean: 105, Threshold: 2}), ShouldNotBeNil)
+		})
+
+		Convey("only the most recent commits in the window should be compared", func() {
+			detection.Window = 2
+			So(detection.Detect([]float64{200, 200, 100, 100}, &Metric{Direction: LowerIsBetter, Mean: 115}), ShouldNotBeNil)
+		})
+
+		Convey("nothing should be found without history", func() {
+			So(detection.Detect(nil, &Metric{Direction: LowerIsBetter, Mean: 115}), ShouldBeNil)
+			So(detection.Detect([]float64{0}, &Metric{Direction: LowerIsBetter, Mean: 115}), ShouldBeNil)
+		})
+	})
+}
+
+func TestChangePointDetection(t *testing.T) {
+	Convey("With the change point method", t, func() {
+		detection := &Detection{Method: MethodChangePoint}
+		So(detection.Validate(), ShouldBeNil)
+		So(detection.Threshold, ShouldEqual, 0)
+		history := []float64{100, 101, 99, 100, 102, 98, 100}
+
+		Convey("a shift at the newest commit should be found", func() {
+			finding := detection.Detect(history, &Metric{Direction: LowerIsBetter, Mean: 120})
+			So(finding, ShouldNotBeNil)
+			So(finding.Change, ShouldAlmostEqual, 20)
+			So(finding.Score, ShouldBeGreaterThan, DefaultSensitivity)
+		})
+
+		Convey("noise and improvements should not be found", func() {
+			So(detection.Detect(history, &Metric{Direction: LowerIsBetter, Mean: 101}), ShouldBeNil)
+			So(detection.Detect(history, &Metric{Direction: HigherIsBetter, Mean: 120}), ShouldBeNil)
+		})
+
+		Convey("a shift earlier in the history should not be blamed on the newest commit", func() {
+			shifted := []float64{100, 100, 101, 99, 120, 121, 119, 120}
+			So(detection.Detect(shifted, &Metric{Direction: LowerIsBetter, Mean: 121}), ShouldBeNil)
+		})
+
+		Convey("too little history should find nothing", func() {
+			So(detection.Detect(history[:minChangePointHistory-1],
+				&Metric{Direction: LowerIsBetter, Mean: 200}), ShouldBeNil)
+		})
+
+		Convey("a shift in perfectly steady results should be infinitely significant", func() {
+			split, score := mostLikelyChangePoint([]float64{5, 5, 5, 5, 5, 6})
+			So(split, ShouldEqual, 5)
+			So(math.IsInf(score, 1), ShouldBeTrue)
+		})
+	})
+
+	Convey("Unknown methods and negative settings should be rejected", t, func() {
+		So((&Detection{Method: "guess"}).Validate(), ShouldNotBeNil)
+		So((&Detection{Threshold: -1}).Validate(), ShouldNotBeNil)
+	})
+}
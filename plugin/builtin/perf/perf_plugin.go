@@ -0,0 +1,277 @@
+package perf
+
+import (
+	"net/http"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/alerts"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/evergreen-ci/evergreen/plugin/builtin/taskdata"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/gorilla/mux"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func init() {
+	plugin.Publish(&PerfPlugin{})
+}
+
+const (
+	PerfPluginName = "perf"
+	SendCmd        = "send"
+
+	// SendDataRoute is the API route the send command posts to, followed by
+	// the name of the results.
+	SendDataRoute = "data"
+)
+
+// PerfPlugin stores the performance results of tasks alongside other task
+// data, detects regressions by comparing them to previous commits, and
+// alerts on them.
+type PerfPlugin struct{}
+
+// Name implements Plugin Interface.
+func (pp *PerfPlugin) Name() string {
+	return PerfPluginName
+}
+
+func (pp *PerfPlugin) Configure(map[string]interface{}) error {
+	return nil
+}
+
+// NewCommand returns requested commands by name. Fulfills the Plugin interface.
+func (pp *PerfPlugin) NewCommand(cmdName string) (plugin.Command, error) {
+	if cmdName == SendCmd {
+		return &SendCommand{}, nil
+	}
+	return nil, &plugin.ErrUnknownCommand{CommandName: cmdName}
+}
+
+// GetAPIHandler returns the route the agent sends results to.
+func (pp *PerfPlugin) GetAPIHandler() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/"+SendDataRoute+"/{name}", apiSendResults).Methods("POST")
+	return r
+}
+
+// GetUIHandler returns the routes for a task's results and regressions,
+// the history of its results, and marking regressions as baseline updates.
+func (pp *PerfPlugin) GetUIHandler() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/task/{task_id}/{name}", uiGetTaskResults)
+	r.HandleFunc("/history/{task_id}/{name}", uiGetResultsHistory)
+	r.HandleFunc("/regression/{regression_id}/baseline", uiSetBaselineUpdate).Methods("POST")
+	return r
+}
+
+// GetPanelConfig adds a panel listing the task's regressions to the task page.
+func (pp *PerfPlugin) GetPanelConfig() (*plugin.PanelConfig, error) {
+	return &plugin.PanelConfig{
+		Panels: []plugin.UIPanel{
+			{
+				Page:     plugin.TaskPage,
+				Position: plugin.PageCenter,
+				PanelHTML: "<div ng-include=\"'/plugin/perf/static/partials/task_perf_panel.html'\" " +
+					"ng-init='perf=plugins.perf' ng-show='plugins.perf.length'></div>",
+				DataFunc: func(context plugin.UIContext) (interface{}, error) {
+					if context.Task == nil {
+						return nil, nil
+					}
+					return FindRegressions(ByTaskId(context.Task.Id, ""))
+				},
+			},
+		},
+	}, nil
+}
+
+func apiSendResults(w http.ResponseWriter, r *http.Request) {
+	t := plugin.GetTask(r)
+	if t == nil {
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+	name := mux.Vars(r)["name"]
+	data := &SendData{}
+	if err := util.ReadJSONInto(util.NewRequestReader(r), data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := data.Results.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := data.Detection.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stored, err := data.Results.Data()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = taskdata.InsertTask(t, name, stored); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// patches are not compared to the mainline, since a patch's results
+	// would be compared to commits after its base
+	regressions := []Regression{}
+	if t.Requester != evergreen.PatchVersionRequester {
+		regressions, err = DetectRegressions(t, name, &data.Results, &data.Detection)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if len(regressions) > 0 {
+			if err = alerts.RunPerfRegressionTriggers(t, Describe(regressions)); err != nil {
+				grip.Errorf("problem alerting on regressions in task %s: %+v", t.Id, err)
+			}
+		}
+	}
+	plugin.WriteJSON(w, http.StatusOK, regressions)
+}
+
+func findTask(w http.ResponseWriter, r *http.Request) *task.Task {
+	t, err := task.FindOne(task.ById(mux.Vars(r)["task_id"]))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+	if t == nil {
+		http.Error(w, "{}", http.StatusNotFound)
+		return nil
+	}
+	return t
+}
+
+// TaskResultsData is a task's results along with the regressions in them.
+type TaskResultsData struct {
+	Results     *Results     `json:"results"`
+	Regressions []Regression `json:"regressions"`
+}
+
+func uiGetTaskResults(w http.ResponseWriter, r *http.Request) {
+	t := findTask(w, r)
+	if t == nil {
+		return
+	}
+	name := mux.Vars(r)["name"]
+	stored, err := taskdata.GetTaskById(t.Id, name)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			http.Error(w, "{}", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	results, err := ResultsFromData(stored.Data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	regressions, err := FindRegressions(ByTaskId(t.Id, name))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	plugin.WriteJSON(w, http.StatusOK, TaskResultsData{Results: results, Regressions: regressions})
+}
+
+// CommitMeans are the means of each metric of a task's results on a commit.
+type CommitMeans struct {
+	TaskId              string             `json:"task_id"`
+	Revision            string             `json:"revision"`
+	RevisionOrderNumber int                `json:"order"`
+	IsPatch             bool               `json:"is_patch"`
+	Means               map[string]float64 `json:"means"`
+}
+
+func uiGetResultsHistory(w http.ResponseWriter, r *http.Request) {
+	t := findTask(w, r)
+	if t == nil {
+		return
+	}
+	history, err := taskdata.GetTaskHistory(t, mux.Vars(r)["name"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	means := []CommitMeans{}
+	for _, entry := range history {
+		results, err := ResultsFromData(entry.Data)
+		if err != nil {
+			continue
+		}
+		commit := CommitMeans{
+			TaskId:              entry.TaskId,
+			Revision:            entry.Revision,
+			RevisionOrderNumber: entry.RevisionOrderNumber,
+			IsPatch:             entry.IsPatch,
+			Means:               map[string]float64{},
+		}
+		for _, m := range results.Metrics {
+			commit.Means[m.Name] = m.Mean
+		}
+		means = append(means, commit)
+	}
+	plugin.WriteJSON(w, http.StatusOK, means)
+}
+
+// BaselineUpdateRequest is the body of a request to mark or unmark a
+// regression as a baseline update.
+type BaselineUpdateRequest struct {
+	BaselineUpdate bool `json:"baseline_update"`
+}
+
+func uiSetBaselineUpdate(w http.ResponseWriter, r *http.Request) {
+	u := plugin.GetUser(r)
+	if u == nil {
+		http.Error(w, "must be logged in to update baselines", http.StatusUnauthorized)
+		return
+	}
+	id := mux.Vars(r)["regression_id"]
+	if !bson.IsObjectIdHex(id) {
+		http.Error(w, "invalid regression id", http.StatusBadRequest)
+		return
+	}
+	// the task page posts a plain form, other clients send JSON
+	isForm := r.Header.Get("Content-Type") == "application/x-www-form-urlencoded"
+	req := &BaselineUpdateRequest{}
+	if isForm {
+		req.BaselineUpdate = r.FormValue("baseline_update") == "true"
+	} else if err := util.ReadJSONInto(util.NewRequestReader(r), req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := SetBaselineUpdate(bson.ObjectIdHex(id), u.Id, req.BaselineUpdate)
+	if err == mgo.ErrNotFound {
+		http.Error(w, "regression not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, errors.Wrap(err, "problem updating regression").Error(), http.StatusInternalServerError)
+		return
+	}
+	regression, err := FindOneRegression(ById(bson.ObjectIdHex(id)))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if regression == nil {
+		http.Error(w, "regression not found", http.StatusNotFound)
+		return
+	}
+	if isForm {
+		http.Redirect(w, r, "/task/"+regression.TaskId, http.StatusFound)
+		return
+	}
+	plugin.WriteJSON(w, http.StatusOK, regression)
+}
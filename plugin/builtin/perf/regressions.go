@@ -0,0 +1,102 @@
+package perf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/plugin/builtin/taskdata"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// DetectRegressions compares each metric in a task's results to the same
+// metric on previous mainline commits, stores a regression for each one that
+// got worse and returns them. A metric is only compared to commits from its
+// latest baseline update on.
+func DetectRegressions(t *task.Task, name string, results *Results, detection *Detection) ([]Regression, error) {
+	history, err := taskdata.GetTaskHistory(t, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding results of previous commits")
+	}
+
+	regressions := []Regression{}
+	for i := range results.Metrics {
+		metric := &results.Metrics[i]
+		baseline, err := FindOneRegression(LatestBaselineUpdate(t.Project, t.BuildVariant,
+			t.DisplayName, name, metric.Name, t.RevisionOrderNumber))
+		if err != nil {
+			return nil, errors.Wrapf(err, "problem finding baseline of '%s'", metric.Name)
+		}
+		since := 0
+		if baseline != nil {
+			since = baseline.RevisionOrderNumber
+		}
+
+		finding := detection.Detect(metricHistory(history, metric.Name, since, t.RevisionOrderNumber), metric)
+		if finding == nil {
+			continue
+		}
+		regressions = append(regressions, Regression{
+			Id:                  bson.NewObjectId(),
+			TaskId:              t.Id,
+			TaskName:            t.DisplayName,
+			ProjectId:           t.Project,
+			Variant:             t.BuildVariant,
+			VersionId:           t.Version,
+			Revision:            t.Revision,
+			RevisionOrderNumber: t.RevisionOrderNumber,
+			CreateTime:          time.Now(),
+			Name:                name,
+			Metric:              metric.Name,
+			Unit:                metric.Unit,
+			Direction:           metric.Direction,
+			Method:              detection.Method,
+			BaselineMean:        finding.BaselineMean,
+			Mean:                metric.Mean,
+			Change:              finding.Change,
+			Score:               finding.Score,
+		})
+	}
+
+	if err = ReplaceRegressions(t.Id, name, regressions); err != nil {
+		return nil, err
+	}
+	return regressions, nil
+}
+
+// metricHistory returns the means of a metric on the mainline commits from
+// the since order number up to, but not including, the before order number,
+// oldest first.
+func metricHistory(history []taskdata.TaskJSON, metricName string, since, before int) []float64 {
+	means := []float64{}
+	for _, entry := range history {
+		if entry.IsPatch || entry.RevisionOrderNumber < since || entry.RevisionOrderNumber >= before {
+			continue
+		}
+		results, err := ResultsFromData(entry.Data)
+		if err != nil {
+			grip.Warningf("skipping results of task %s: %v", entry.TaskId, err)
+			continue
+		}
+		if metric := results.Metric(metricName); metric != nil {
+			means = append(means, metric.Mean)
+		}
+	}
+	return means
+}
+
+// Describe summarizes the regressions in one line, for alerts.
+func Describe(regressions []Regression) string {
+	parts := make([]string, 0, len(regressions))
+	for _, r := range regressions {
+		unit := ""
+		if r.Unit != "" {
+			unit = fmt.Sprintf(" (%s)", r.Unit)
+		}
+		parts = append(parts, fmt.Sprintf("%s%s %+.1f%%", r.Metric, unit, r.Change))
+	}
+	return strings.Join(parts, ", ")
+}
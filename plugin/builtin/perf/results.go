@@ -0,0 +1,109 @@
+package perf
+
+import (
+	"encoding/json"
+
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/pkg/errors"
+)
+
+// Directions say whether a metric improves as it goes up or as it goes down.
+const (
+	HigherIsBetter = "higher_is_better"
+	LowerIsBetter  = "lower_is_better"
+)
+
+// ValidDirections are the directions a metric can have.
+var ValidDirections = []string{HigherIsBetter, LowerIsBetter}
+
+// Metric is one named measurement of a task, taken over one or more trials.
+type Metric struct {
+	Name      string    `json:"name"`
+	Unit      string    `json:"unit"`
+	Direction string    `json:"direction"`
+	Trials    []float64 `json:"trials"`
+
+	// Mean is the mean of the trials. It is computed when the results are
+	// validated, so that it is stored alongside them.
+	Mean float64 `json:"mean"`
+
+	// Threshold, if set, overrides the percent change the command treats as a
+	// regression for this metric.
+	Threshold float64 `json:"threshold,omitempty"`
+}
+
+// Results are the metrics a task sends with perf.send, and the format of the
+// file the command reads:
+//
+//	{"metrics": [
+//	    {"name": "insert", "unit": "ops/sec", "direction": "higher_is_better",
+//	     "trials": [1021.5, 998.2, 1010.7]}
+//	]}
+type Results struct {
+	Metrics []Metric `json:"metrics"`
+}
+
+// Validate checks that every metric has a unique name, a direction and at
+// least one trial, and computes the mean of each metric's trials.
+func (r *Results) Validate() error {
+	if len(r.Metrics) == 0 {
+		return errors.New("results must have at least one metric")
+	}
+	names := map[string]bool{}
+	for i := range r.Metrics {
+		m := &r.Metrics[i]
+		if m.Name == "" {
+			return errors.Errorf("metric %d has no name", i)
+		}
+		if names[m.Name] {
+			return errors.Errorf("metric '%s' is listed more than once", m.Name)
+		}
+		names[m.Name] = true
+		if !util.SliceContains(ValidDirections, m.Direction) {
+			return errors.Errorf("metric '%s' has direction '%s', which is not one of %v",
+				m.Name, m.Direction, ValidDirections)
+		}
+		if len(m.Trials) == 0 {
+			return errors.Errorf("metric '%s' has no trials", m.Name)
+		}
+		if m.Threshold < 0 {
+			return errors.Errorf("metric '%s' has a negative threshold", m.Name)
+		}
+		m.Mean = mean(m.Trials)
+	}
+	return nil
+}
+
+// Metric returns the metric with the given name, or nil if there is none.
+func (r *Results) Metric(name string) *Metric {
+	for i := range r.Metrics {
+		if r.Metrics[i].Name == name {
+			return &r.Metrics[i]
+		}
+	}
+	return nil
+}
+
+// Data converts the results to the form the taskdata plugin stores, so that
+// json.get and json.get_history can read them too.
+func (r *Results) Data() (map[string]interface{}, error) {
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	data := map[string]interface{}{}
+	return data, errors.WithStack(json.Unmarshal(raw, &data))
+}
+
+// ResultsFromData reads results stored by the taskdata plugin.
+func ResultsFromData(data map[string]interface{}) (*Results, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	r := &Results{}
+	if err = json.Unmarshal(raw, r); err != nil {
+		return nil, errors.Wrap(err, "stored data is not performance results")
+	}
+	return r, nil
+}
@@ -0,0 +1,170 @@
+package perf
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"github.com/mongodb/grip/slogger"
+	"github.com/pkg/errors"
+)
+
+const (
+	sendRetries = 10
+	sendSleep   = 3 * time.Second
+)
+
+// SendCommand reads a file of performance results, sends them to the server
+// and reports the regressions the server finds in them. Regressions are
+// logged and alerted on, but do not fail the task.
+type SendCommand struct {
+	// File is the path of the results, relative to the working directory.
+	File string `mapstructure:"file" plugin:"expand"`
+
+	// ResultsName is what the results are stored under, so that one task
+	// can send several sets of results.
+	ResultsName string `mapstructure:"name" plugin:"expand"`
+
+	// Method, Threshold, Sensitivity and Window configure how regressions
+	// are detected. See Detection.
+	Method      string  `mapstructure:"method" plugin:"expand"`
+	Threshold   float64 `mapstructure:"threshold"`
+	Sensitivity float64 `mapstructure:"sensitivity"`
+	Window      int     `mapstructure:"window"`
+}
+
+// SendData is the body of the request the command sends to the server.
+type SendData struct {
+	Results   Results   `json:"results"`
+	Detection Detection `json:"detection"`
+}
+
+func (c *SendCommand) Name() string {
+	return SendCmd
+}
+
+func (c *SendCommand) Plugin() string {
+	return PerfPluginName
+}
+
+func (c *SendCommand) detection() *Detection {
+	return &Detection{
+		Method:      c.Method,
+		Threshold:   c.Threshold,
+		Sensitivity: c.Sensitivity,
+		Window:      c.Window,
+	}
+}
+
+// ParseParams reads and validates the command parameters.
+func (c *SendCommand) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, c); err != nil {
+		return errors.Wrapf(err, "error decoding '%s' params", c.Name())
+	}
+	if c.File == "" {
+		return errors.Errorf("error validating '%s' params: must specify a file", c.Name())
+	}
+	if c.ResultsName == "" {
+		return errors.Errorf("error validating '%s' params: must specify a name", c.Name())
+	}
+	if err := c.detection().Validate(); err != nil {
+		return errors.Wrapf(err, "error validating '%s' params", c.Name())
+	}
+	return nil
+}
+
+// Execute reads the results and sends them to the server.
+func (c *SendCommand) Execute(pluginLogger plugin.Logger,
+	pluginCom plugin.PluginCommunicator,
+	taskConfig *model.TaskConfig,
+	stop chan bool) error {
+
+	if err := plugin.ExpandValues(c, taskConfig.Expansions); err != nil {
+		return errors.Wrap(err, "error expanding params")
+	}
+
+	errChan := make(chan error)
+	go func() {
+		data, err := c.readResults(taskConfig.WorkDir)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		regressions, err := sendResults(pluginLogger, pluginCom, c.ResultsName, data)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		for _, r := range regressions {
+			pluginLogger.LogTask(slogger.WARN, "Performance regression in '%s': mean %v%s, "+
+				"%+.1f%% from a baseline of %v", r.Metric, r.Mean, r.Unit, r.Change, r.BaselineMean)
+		}
+		errChan <- nil
+	}()
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			pluginLogger.LogTask(slogger.ERROR, "Sending performance results failed: %v", err)
+		}
+		return err
+	case <-stop:
+		pluginLogger.LogExecution(slogger.INFO, "Received signal to terminate"+
+			" execution of perf send command")
+		return nil
+	}
+}
+
+func (c *SendCommand) readResults(workDir string) (*SendData, error) {
+	raw, err := ioutil.ReadFile(filepath.Join(workDir, c.File))
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't read results file '%s'", c.File)
+	}
+	data := &SendData{Detection: *c.detection()}
+	if err = json.Unmarshal(raw, &data.Results); err != nil {
+		return nil, errors.Wrapf(err, "results file '%s' is not valid JSON", c.File)
+	}
+	if err = data.Results.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid results in '%s'", c.File)
+	}
+	if err = data.Detection.Validate(); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func sendResults(pluginLogger plugin.Logger, pluginCom plugin.PluginCommunicator,
+	name string, data *SendData) ([]Regression, error) {
+	regressions := []Regression{}
+	retriablePost := util.RetriableFunc(
+		func() error {
+			pluginLogger.LogTask(slogger.INFO, "Posting %d performance metrics as '%s'",
+				len(data.Results.Metrics), name)
+			resp, err := pluginCom.TaskPostJSON(SendDataRoute+"/"+name, data)
+			if resp != nil {
+				defer resp.Body.Close()
+			}
+			if err != nil {
+				return util.RetriableError{Failure: errors.WithStack(err)}
+			}
+			if resp.StatusCode != http.StatusOK {
+				err = errors.Errorf("unexpected status code %v", resp.StatusCode)
+				if resp.StatusCode == http.StatusBadRequest {
+					return err
+				}
+				return util.RetriableError{Failure: err}
+			}
+			return errors.Wrap(util.ReadJSONInto(resp.Body, &regressions),
+				"problem reading regressions")
+		},
+	)
+
+	_, err := util.Retry(retriablePost, sendRetries, sendSleep)
+	return regressions, errors.Wrap(err, "problem posting performance results")
+}
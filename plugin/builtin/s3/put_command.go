@@ -1,7 +1,11 @@
 package s3
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -79,6 +83,32 @@ type S3PutCommand struct {
 	// the path specified in local_file does not exist. Defaults to false, which triggers errors
 	// for missing files.
 	Optional bool `mapstructure:"optional"`
+
+	// Expiration is the expiration class of the uploaded files, which sets
+	// how long they are kept before they are deleted from the bucket. It can
+	// be "short", "medium" or "long"; if unset, the files are kept forever.
+	Expiration string `mapstructure:"expiration" plugin:"expand"`
+
+	// Deduplicate, when set to true, skips uploading a file whose contents
+	// were already put to the bucket with the same permissions, and links to
+	// the earlier upload instead. The file is then not stored at remote_file.
+	Deduplicate bool `mapstructure:"deduplicate"`
+
+	// uploads records the files that were put, by local path
+	uploads map[string]upload
+	// findDuplicate looks up an earlier upload with the given content hash
+	findDuplicate func(hash string) (*artifact.File, error)
+	// recordUpload tells the API server that the given key is about to be
+	// put, so that it may be deleted when the file expires
+	recordUpload func(key string) error
+}
+
+// upload describes a local file that was put to s3.
+type upload struct {
+	hash string
+	// existing is the earlier upload of the same contents, if the file
+	// was deduplicated
+	existing *artifact.File
 }
 
 func (s3pc *S3PutCommand) Name() string {
@@ -129,6 +159,10 @@ func (s3pc *S3PutCommand) validateParams() error {
 	if !util.SliceContains(artifact.ValidVisibilities, s3pc.Visibility) {
 		return errors.Errorf("invalid visibility setting: %v", s3pc.Visibility)
 	}
+	if !plugin.IsExpandable(s3pc.Expiration) &&
+		!util.SliceContains(artifact.ValidExpirationClasses, s3pc.Expiration) {
+		return errors.Errorf("invalid expiration class: %v", s3pc.Expiration)
+	}
 
 	// make sure the bucket is valid
 	if err := validateS3BucketName(s3pc.Bucket); err != nil {
@@ -216,6 +250,17 @@ func (s3pc *S3PutCommand) Execute(log plugin.Logger,
 
 // Wrapper around the Put() function to retry it.
 func (s3pc *S3PutCommand) PutWithRetry(log plugin.Logger, com plugin.PluginCommunicator) error {
+	if s3pc.Deduplicate {
+		s3pc.findDuplicate = func(hash string) (*artifact.File, error) {
+			return s3pc.requestDuplicate(com, hash)
+		}
+	}
+	if s3pc.Expiration != artifact.ExpireNever {
+		s3pc.recordUpload = func(key string) error {
+			return s3pc.requestUpload(com, key)
+		}
+	}
+
 	retriablePut := util.RetriableFunc(
 		func() error {
 			filesList, err := s3pc.Put()
@@ -256,6 +301,7 @@ func (s3pc *S3PutCommand) Put() ([]string, error) {
 	var err error
 
 	filesList := []string{s3pc.LocalFile}
+	s3pc.uploads = map[string]upload{}
 
 	if s3pc.isMulti() {
 		filesList, err = util.BuildFileList(".", s3pc.LocalFilesIncludeFilter...)
@@ -279,6 +325,28 @@ func (s3pc *S3PutCommand) Put() ([]string, error) {
 			Host:   s3pc.Bucket,
 			Path:   remoteName,
 		}
+
+		// a file that cannot be hashed is left for the put to report
+		var hash string
+		if s3pc.findDuplicate != nil {
+			if hash, err = fileSHA256(fpath); err == nil {
+				existing, err := s3pc.findDuplicate(hash)
+				if err != nil {
+					return nil, errors.Wrapf(err, "problem checking for an earlier upload of %s", fpath)
+				}
+				if existing != nil {
+					s3pc.uploads[fpath] = upload{hash: hash, existing: existing}
+					continue
+				}
+			}
+		}
+
+		if s3pc.recordUpload != nil {
+			if err = s3pc.recordUpload(filepath.ToSlash(remoteName)); err != nil {
+				return nil, errors.Wrapf(err, "problem recording the upload of %s", fpath)
+			}
+		}
+
 		err := thirdparty.PutS3File(auth, fpath, s3URL.String(), s3pc.ContentType, s3pc.Permissions)
 		if err != nil {
 			if !s3pc.isMulti() {
@@ -289,10 +357,59 @@ func (s3pc *S3PutCommand) Put() ([]string, error) {
 			}
 			return nil, errors.WithStack(err)
 		}
+		s3pc.uploads[fpath] = upload{hash: hash}
 	}
 	return filesList, nil
 }
 
+// requestDuplicate asks the API server for an earlier upload to the bucket
+// with the given contents, and returns nil if there is none.
+func (s3pc *S3PutCommand) requestDuplicate(com plugin.PluginCommunicator, hash string) (*artifact.File, error) {
+	query := url.Values{}
+	query.Set("bucket", s3pc.Bucket)
+	query.Set("hash", hash)
+	query.Set("permissions", s3pc.Permissions)
+	resp, err := com.TaskGetJSON(fmt.Sprintf("%s?%s", S3DuplicateAPIEndpoint, query.Encode()))
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		file := &artifact.File{}
+		if err = util.ReadJSONInto(resp.Body, file); err != nil {
+			return nil, errors.Wrap(err, "problem reading earlier upload")
+		}
+		return file, nil
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, errors.Errorf("unexpected status code %d looking for an earlier upload", resp.StatusCode)
+	}
+}
+
+// requestUpload records the upload of the key with the API server. A key that
+// another task put first is still put, but the API server keeps it forever.
+func (s3pc *S3PutCommand) requestUpload(com plugin.PluginCommunicator, key string) error {
+	resp, err := com.TaskPostJSON(S3UploadAPIEndpoint, S3Upload{Bucket: s3pc.Bucket, FileKey: key})
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusConflict:
+		return nil
+	default:
+		return errors.Errorf("unexpected status code %d recording an upload", resp.StatusCode)
+	}
+}
+
 // AttachTaskFiles is responsible for sending the
 // specified file to the API Server. Does not support multiple file putting.
 func (s3pc *S3PutCommand) AttachTaskFiles(log plugin.Logger,
@@ -311,9 +428,22 @@ func (s3pc *S3PutCommand) AttachTaskFiles(log plugin.Logger,
 	}
 
 	file := &artifact.File{
-		Name:       displayName,
-		Link:       fileLink,
-		Visibility: s3pc.Visibility,
+		Name:            displayName,
+		Link:            fileLink,
+		Visibility:      s3pc.Visibility,
+		Bucket:          s3pc.Bucket,
+		FileKey:         remoteFileName,
+		Permissions:     s3pc.Permissions,
+		ExpirationClass: s3pc.Expiration,
+	}
+	if upload, ok := s3pc.uploads[localFile]; ok {
+		file.ContentHash = upload.hash
+		if upload.existing != nil {
+			log.LogExecution(slogger.INFO, "%v has the same contents as %v, which is linked instead",
+				localFile, upload.existing.Link)
+			file.Link = upload.existing.Link
+			file.FileKey = upload.existing.FileKey
+		}
 	}
 
 	err := com.PostTaskFiles([]*artifact.File{file})
@@ -323,3 +453,18 @@ func (s3pc *S3PutCommand) AttachTaskFiles(log plugin.Logger,
 	log.LogExecution(slogger.INFO, "API attach files call succeeded")
 	return nil
 }
+
+// fileSHA256 returns the hex encoded sha256 of a file's contents.
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -195,6 +195,23 @@ func TestS3PutValidateParams(t *testing.T) {
 
 			})
 
+			Convey("an invalid expiration class should cause an error", func() {
+
+				params := map[string]interface{}{
+					"aws_key":      "key",
+					"aws_secret":   "secret",
+					"local_file":   "local",
+					"remote_file":  "remote",
+					"bucket":       "bck",
+					"content_type": "application/x-tar",
+					"permissions":  "private",
+					"expiration":   "eventually",
+				}
+				So(cmd.ParseParams(params), ShouldNotBeNil)
+				So(cmd.validateParams(), ShouldNotBeNil)
+
+			})
+
 			Convey("a valid set of params should not cause an error", func() {
 
 				params := map[string]interface{}{
@@ -1,9 +1,13 @@
 package s3
 
 import (
+	"fmt"
+	"net/http"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/evergreen-ci/evergreen/model/artifact"
 	"github.com/evergreen-ci/evergreen/plugin"
 	"github.com/evergreen-ci/evergreen/util"
 	"github.com/goamz/goamz/s3"
@@ -22,8 +26,15 @@ const (
 	S3GetCmd     = "get"
 	S3PutCmd     = "put"
 	S3PluginName = "s3"
+
+	S3DuplicateAPIEndpoint = "duplicate"
+	S3UploadAPIEndpoint    = "upload"
 )
 
+// duplicateMargin is how long an earlier upload must be kept for beyond now
+// to be linked to, so that it is not deleted before the link is recorded.
+const duplicateMargin = 24 * time.Hour
+
 var (
 	// Regular expression for validating S3 bucket names
 	BucketNameRegex = regexp.MustCompile(`^[A-Za-z0-9_\-.]+$`)
@@ -46,6 +57,76 @@ func (self *S3Plugin) NewCommand(cmdName string) (plugin.Command, error) {
 	return nil, errors.Errorf("No such command: %v", cmdName)
 }
 
+func (self *S3Plugin) Configure(map[string]interface{}) error {
+	return nil
+}
+
+func (self *S3Plugin) GetAPIHandler() http.Handler {
+	r := http.NewServeMux()
+	r.HandleFunc(fmt.Sprintf("/%v", S3DuplicateAPIEndpoint), S3DuplicateHandler) // GET
+	r.HandleFunc(fmt.Sprintf("/%v", S3UploadAPIEndpoint), S3UploadHandler)       // POST
+	r.HandleFunc("/", http.NotFound)
+	return r
+}
+
+// S3DuplicateHandler returns an earlier upload to the "bucket" with the
+// contents given by the "hash" and "permissions", or 404 if there is none.
+func S3DuplicateHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, hash := r.FormValue("bucket"), r.FormValue("hash")
+	if bucket == "" || hash == "" {
+		http.Error(w, "bucket and hash must not be empty", http.StatusBadRequest)
+		return
+	}
+
+	file, err := artifact.FindDuplicate(bucket, hash, r.FormValue("permissions"), time.Now().Add(duplicateMargin))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if file == nil {
+		http.Error(w, "no earlier upload found", http.StatusNotFound)
+		return
+	}
+	plugin.WriteJSON(w, http.StatusOK, file)
+}
+
+// S3Upload names an object a task is about to put.
+type S3Upload struct {
+	Bucket  string `json:"bucket"`
+	FileKey string `json:"file_key"`
+}
+
+// S3UploadHandler records that the task is putting an object, so that the
+// object may be deleted when the task's files expire. It returns 409 if
+// another task put the object first, in which case it is never deleted.
+func S3UploadHandler(w http.ResponseWriter, r *http.Request) {
+	task := plugin.GetTask(r)
+	if task == nil {
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+	params := &S3Upload{}
+	if err := util.ReadJSONInto(util.NewRequestReader(r), params); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if params.Bucket == "" || params.FileKey == "" {
+		http.Error(w, "bucket and file_key must not be empty", http.StatusBadRequest)
+		return
+	}
+
+	upload, err := artifact.RecordUpload(params.Bucket, params.FileKey, task.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if upload.TaskId != task.Id {
+		http.Error(w, fmt.Sprintf("object was put by task %s", upload.TaskId), http.StatusConflict)
+		return
+	}
+	plugin.WriteJSON(w, http.StatusOK, "upload recorded")
+}
+
 func validateS3BucketName(bucket string) error {
 	// if it's an expandable string, we can't expand yet since we don't have
 	// access to the task config expansions. So, we defer till during runtime
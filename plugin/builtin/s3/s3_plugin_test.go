@@ -31,7 +31,8 @@ func init() {
 }
 
 func reset(t *testing.T) {
-	testutil.HandleTestingErr(db.ClearCollections(task.Collection, artifact.Collection, host.Collection),
+	testutil.HandleTestingErr(db.ClearCollections(task.Collection, artifact.Collection, artifact.UploadCollection,
+		host.Collection),
 		t, "error clearing test collections")
 }
 
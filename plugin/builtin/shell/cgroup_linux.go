@@ -0,0 +1,354 @@
+package shell
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+const (
+	// cgroupParent is the directory, relative to each hierarchy's mount
+	// point, under which the agent creates one cgroup per task.
+	cgroupParent = "evergreen"
+
+	// cpuPeriodMicros is the CFS scheduling period used to express
+	// CPU limits as a quota.
+	cpuPeriodMicros = 100000
+
+	// cgroupRemoveAttempts and cgroupRemoveInterval bound how long the
+	// agent waits for killed processes to leave a cgroup before removing it.
+	cgroupRemoveAttempts = 10
+	cgroupRemoveInterval = 100 * time.Millisecond
+)
+
+var (
+	// cgroupMountPoint is the root of the cgroup filesystem. It is a
+	// variable so that tests can point it at a scratch directory.
+	cgroupMountPoint = "/sys/fs/cgroup"
+
+	// cgroupV1Controllers are the legacy hierarchies the agent uses
+	// when the unified (v2) hierarchy is not available.
+	cgroupV1Controllers = []string{"memory", "cpu", "pids"}
+
+	cgroupMapping = newCgroupRegistry()
+)
+
+////////////////////////////////////////////////////////////////////////
+//
+// implementation of the internals of our cgroup registry
+//
+////////////////////////////////////////////////////////////////////////
+
+type cgroupRegistry struct {
+	groups map[string]*taskCgroup
+	mu     sync.Mutex
+}
+
+func newCgroupRegistry() *cgroupRegistry {
+	return &cgroupRegistry{
+		groups: make(map[string]*taskCgroup),
+	}
+}
+
+func (r *cgroupRegistry) get(taskId string) *taskCgroup {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.groups[taskId]
+}
+
+func (r *cgroupRegistry) add(taskId string, cg *taskCgroup) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.groups[taskId] = cg
+}
+
+func (r *cgroupRegistry) remove(taskId string) *taskCgroup {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cg := r.groups[taskId]
+	delete(r.groups, taskId)
+	return cg
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// Functions used to manage the cgroup of a task
+//
+////////////////////////////////////////////////////////////////////////
+
+// taskCgroup is the set of cgroup directories holding a task's processes.
+// With the unified hierarchy there is a single directory; with the legacy
+// hierarchies there is one per controller.
+type taskCgroup struct {
+	unified bool
+	paths   map[string]string
+}
+
+// CreateTaskCgroup creates a cgroup for the given task, applies the resource
+// limits to it, and registers it so that every process subsequently started by
+// the shell plugin for that task is placed inside it.
+func CreateTaskCgroup(taskId string, limits distro.ResourceLimits) error {
+	if limits.IsZero() {
+		return nil
+	}
+
+	name := cgroupName(taskId)
+	cg := &taskCgroup{paths: map[string]string{}}
+
+	if _, err := os.Stat(filepath.Join(cgroupMountPoint, "cgroup.controllers")); err == nil {
+		cg.unified = true
+		parent := filepath.Join(cgroupMountPoint, cgroupParent)
+		if err = os.MkdirAll(parent, 0755); err != nil {
+			return errors.Wrap(err, "problem creating parent cgroup")
+		}
+		// controllers must be enabled in every ancestor before a child can use them
+		for _, dir := range []string{cgroupMountPoint, parent} {
+			if err = writeCgroupFile(dir, "cgroup.subtree_control", "+memory +cpu +pids"); err != nil {
+				return errors.Wrapf(err, "problem enabling controllers in %s", dir)
+			}
+		}
+		cg.paths[""] = filepath.Join(parent, name)
+	} else {
+		for _, ctrl := range cgroupV1Controllers {
+			cg.paths[ctrl] = filepath.Join(cgroupMountPoint, ctrl, cgroupParent, name)
+		}
+	}
+
+	for _, path := range cg.paths {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			grip.Warning(cg.remove())
+			return errors.Wrapf(err, "problem creating cgroup for task %s", taskId)
+		}
+	}
+
+	if err := cg.setLimits(limits); err != nil {
+		grip.Warning(cg.remove())
+		return errors.Wrapf(err, "problem setting resource limits for task %s", taskId)
+	}
+
+	cgroupMapping.add(taskId, cg)
+	return nil
+}
+
+// cgroupWrapper returns the command prefix that starts a process inside the
+// task's cgroup, or nil if the task has none.
+func cgroupWrapper(taskId string) []string {
+	cg := cgroupMapping.get(taskId)
+	if cg == nil {
+		return nil
+	}
+	return cg.wrapper()
+}
+
+// RemoveTaskCgroup kills any processes remaining in the task's cgroup and
+// removes it. It is a noop if the task has no cgroup.
+func RemoveTaskCgroup(taskId string) error {
+	cg := cgroupMapping.remove(taskId)
+	if cg == nil {
+		return nil
+	}
+
+	// processes take a moment to exit after being killed, and the
+	// cgroup can only be removed once it is empty
+	for i := 0; i < cgroupRemoveAttempts; i++ {
+		pids, err := cg.killAll()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if len(pids) == 0 {
+			break
+		}
+		time.Sleep(cgroupRemoveInterval)
+	}
+	return errors.Wrapf(cg.remove(), "problem removing cgroup for task %s", taskId)
+}
+
+// TaskCgroupOOMKilled returns true if the kernel's OOM killer has killed any
+// process in the task's cgroup because it exceeded its memory limit.
+func TaskCgroupOOMKilled(taskId string) bool {
+	cg := cgroupMapping.get(taskId)
+	if cg == nil {
+		return false
+	}
+
+	var data []byte
+	var err error
+	if cg.unified {
+		data, err = ioutil.ReadFile(filepath.Join(cg.paths[""], "memory.events"))
+	} else {
+		data, err = ioutil.ReadFile(filepath.Join(cg.paths["memory"], "memory.oom_control"))
+	}
+	if err != nil {
+		return false
+	}
+
+	return parseOOMKillCount(data) > 0
+}
+
+// setLimits writes the limits into the interface files of the cgroup.
+func (cg *taskCgroup) setLimits(limits distro.ResourceLimits) error {
+	if cg.unified {
+		path := cg.paths[""]
+		if limits.MemoryLimitMB > 0 {
+			if err := writeCgroupFile(path, "memory.max", strconv.Itoa(limits.MemoryLimitMB*1024*1024)); err != nil {
+				return err
+			}
+		}
+		if limits.CPUPercent > 0 {
+			quota := fmt.Sprintf("%d %d", limits.CPUPercent*cpuPeriodMicros/100, cpuPeriodMicros)
+			if err := writeCgroupFile(path, "cpu.max", quota); err != nil {
+				return err
+			}
+		}
+		if limits.PidsLimit > 0 {
+			if err := writeCgroupFile(path, "pids.max", strconv.Itoa(limits.PidsLimit)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if limits.MemoryLimitMB > 0 {
+		if err := writeCgroupFile(cg.paths["memory"], "memory.limit_in_bytes", strconv.Itoa(limits.MemoryLimitMB*1024*1024)); err != nil {
+			return err
+		}
+	}
+	if limits.CPUPercent > 0 {
+		if err := writeCgroupFile(cg.paths["cpu"], "cpu.cfs_period_us", strconv.Itoa(cpuPeriodMicros)); err != nil {
+			return err
+		}
+		if err := writeCgroupFile(cg.paths["cpu"], "cpu.cfs_quota_us", strconv.Itoa(limits.CPUPercent*cpuPeriodMicros/100)); err != nil {
+			return err
+		}
+	}
+	if limits.PidsLimit > 0 {
+		if err := writeCgroupFile(cg.paths["pids"], "pids.max", strconv.Itoa(limits.PidsLimit)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cgroupJoinScript writes the pid of the shell running it into each of the
+// files named by its arguments up to "--", then execs the remaining
+// arguments. Since exec keeps the pid, the command starts inside the cgroup
+// and so does everything it forks. If a file cannot be written, the shell's
+// error and the file are reported on stderr, which goes to the task log, and
+// the command is not run.
+const cgroupJoinScript = `while [ "$1" != "--" ]; do ` +
+	`echo $$ > "$1" || { echo "failed to join task cgroup: could not write pid $$ to $1" >&2; exit 1; }; ` +
+	`shift; done; shift; exec "$@"`
+
+// wrapper returns a command prefix that starts a process inside the cgroup.
+func (cg *taskCgroup) wrapper() []string {
+	args := []string{"sh", "-c", cgroupJoinScript, "evergreen-cgroup"}
+	for _, path := range cg.paths {
+		args = append(args, filepath.Join(path, "cgroup.procs"))
+	}
+	return append(args, "--")
+}
+
+// pids returns the processes that are currently members of the cgroup.
+func (cg *taskCgroup) pids() ([]int, error) {
+	seen := map[int]bool{}
+	pids := []int{}
+	for _, path := range cg.paths {
+		data, err := ioutil.ReadFile(filepath.Join(path, "cgroup.procs"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.WithStack(err)
+		}
+		for _, pid := range parsePids(data) {
+			if !seen[pid] {
+				seen[pid] = true
+				pids = append(pids, pid)
+			}
+		}
+	}
+	return pids, nil
+}
+
+// killAll sends SIGKILL to every process in the cgroup, and returns the
+// pids it signaled.
+func (cg *taskCgroup) killAll() ([]int, error) {
+	pids, err := cg.pids()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pid := range pids {
+		if err = syscall.Kill(pid, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+			return nil, errors.Wrapf(err, "problem killing process %d", pid)
+		}
+	}
+	return pids, nil
+}
+
+// remove deletes the cgroup directories. The kernel only allows this once
+// the cgroup contains no processes.
+func (cg *taskCgroup) remove() error {
+	catcher := grip.NewCatcher()
+	for _, path := range cg.paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			catcher.Add(err)
+		}
+	}
+	return catcher.Resolve()
+}
+
+// cgroupName returns a directory name for the task's cgroup.
+func cgroupName(taskId string) string {
+	return strings.Replace(taskId, string(filepath.Separator), "_", -1)
+}
+
+func writeCgroupFile(dir, name, value string) error {
+	return errors.Wrapf(ioutil.WriteFile(filepath.Join(dir, name), []byte(value), 0644),
+		"problem writing '%s' to %s", value, filepath.Join(dir, name))
+}
+
+// parsePids parses the contents of a cgroup.procs file.
+func parsePids(data []byte) []int {
+	pids := []int{}
+	for _, field := range bytes.Fields(data) {
+		pid, err := strconv.Atoi(string(field))
+		if err != nil {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	return pids
+}
+
+// parseOOMKillCount extracts the "oom_kill" counter from the contents of a
+// memory.events (v2) or memory.oom_control (v1) file.
+func parseOOMKillCount(data []byte) int {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "oom_kill" {
+			count, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return 0
+			}
+			return count
+		}
+	}
+	return 0
+}
@@ -0,0 +1,100 @@
+package shell
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/command"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTaskCgroup(t *testing.T) {
+	Convey("With a scratch directory standing in for a unified cgroup hierarchy", t, func() {
+		root, err := ioutil.TempDir("", "cgroup")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(root)
+		So(ioutil.WriteFile(filepath.Join(root, "cgroup.controllers"), []byte("memory cpu pids"), 0644), ShouldBeNil)
+
+		oldMountPoint := cgroupMountPoint
+		cgroupMountPoint = root
+		defer func() { cgroupMountPoint = oldMountPoint }()
+
+		Convey("creating a task cgroup with no limits should be a noop", func() {
+			So(CreateTaskCgroup("nolimits", distro.ResourceLimits{}), ShouldBeNil)
+			So(cgroupMapping.get("nolimits"), ShouldBeNil)
+		})
+
+		Convey("a process that cannot join its cgroup should not run and should say why", func() {
+			cg := &taskCgroup{unified: true, paths: map[string]string{"": filepath.Join(root, "missing")}}
+			buf := &bytes.Buffer{}
+			localCmd := &command.LocalCommand{
+				CmdString:  "echo command-ran",
+				Stdout:     buf,
+				Stderr:     buf,
+				ScriptMode: true,
+				Prefix:     cg.wrapper(),
+			}
+			So(localCmd.Run(), ShouldNotBeNil)
+			So(buf.String(), ShouldContainSubstring, "failed to join task cgroup")
+			So(buf.String(), ShouldNotContainSubstring, "command-ran")
+		})
+
+		Convey("creating a task cgroup should write its limits", func() {
+			limits := distro.ResourceLimits{MemoryLimitMB: 512, CPUPercent: 150, PidsLimit: 64}
+			So(CreateTaskCgroup("task", limits), ShouldBeNil)
+			defer cgroupMapping.remove("task")
+
+			path := filepath.Join(root, cgroupParent, "task")
+			memory, err := ioutil.ReadFile(filepath.Join(path, "memory.max"))
+			So(err, ShouldBeNil)
+			So(string(memory), ShouldEqual, "536870912")
+			cpu, err := ioutil.ReadFile(filepath.Join(path, "cpu.max"))
+			So(err, ShouldBeNil)
+			So(string(cpu), ShouldEqual, "150000 100000")
+			pids, err := ioutil.ReadFile(filepath.Join(path, "pids.max"))
+			So(err, ShouldBeNil)
+			So(string(pids), ShouldEqual, "64")
+
+			Convey("and report OOM kills recorded by the kernel", func() {
+				So(TaskCgroupOOMKilled("task"), ShouldBeFalse)
+				events := "low 0\nhigh 0\nmax 3\noom 1\noom_kill 1\n"
+				So(ioutil.WriteFile(filepath.Join(path, "memory.events"), []byte(events), 0644), ShouldBeNil)
+				So(TaskCgroupOOMKilled("task"), ShouldBeTrue)
+			})
+
+			Convey("and start processes inside it before they run", func() {
+				buf := &bytes.Buffer{}
+				localCmd := &command.LocalCommand{
+					CmdString:  "echo $$",
+					Stdout:     buf,
+					Stderr:     buf,
+					ScriptMode: true,
+					Prefix:     cgroupWrapper("task"),
+				}
+				So(localCmd.Run(), ShouldBeNil)
+
+				pid := strconv.Itoa(localCmd.Cmd.Process.Pid)
+				So(buf.String(), ShouldEqual, pid+"\n")
+				procs, err := ioutil.ReadFile(filepath.Join(path, "cgroup.procs"))
+				So(err, ShouldBeNil)
+				So(string(bytes.TrimSpace(procs)), ShouldEqual, pid)
+			})
+		})
+	})
+}
+
+func TestParseOOMKillCount(t *testing.T) {
+	Convey("When parsing memory event counters", t, func() {
+		Convey("the oom_kill counter should be read from cgroup v1 output", func() {
+			So(parseOOMKillCount([]byte("oom_kill_disable 0\nunder_oom 0\noom_kill 2\n")), ShouldEqual, 2)
+		})
+		Convey("a missing counter should be treated as zero", func() {
+			So(parseOOMKillCount([]byte("oom_kill_disable 0\nunder_oom 0\n")), ShouldEqual, 0)
+		})
+	})
+}
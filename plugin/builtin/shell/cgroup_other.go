@@ -0,0 +1,19 @@
+//go:build !linux
+// +build !linux
+
+package shell
+
+import "github.com/evergreen-ci/evergreen/model/distro"
+
+// CreateTaskCgroup is a noop on platforms without cgroups; resource limits
+// are not enforced.
+func CreateTaskCgroup(taskId string, limits distro.ResourceLimits) error { return nil }
+
+// RemoveTaskCgroup is a noop on platforms without cgroups.
+func RemoveTaskCgroup(taskId string) error { return nil }
+
+// TaskCgroupOOMKilled always returns false on platforms without cgroups.
+func TaskCgroupOOMKilled(taskId string) bool { return false }
+
+// cgroupWrapper returns nil on platforms without cgroups.
+func cgroupWrapper(taskId string) []string { return nil }
@@ -191,6 +191,9 @@ func (sec *ShellExecCommand) Execute(pluginLogger plugin.Logger,
 		env = append(env, fmt.Sprintf("EVR_TASK_ID=%v", conf.Task.Id))
 		env = append(env, fmt.Sprintf("EVR_AGENT_PID=%v", os.Getpid()))
 		localCmd.Environment = env
+		// start the process inside the task's cgroup, if there is one, so
+		// that it and anything it forks are subject to the resource limits
+		localCmd.Prefix = cgroupWrapper(conf.Task.Id)
 		err = localCmd.Start()
 		if err == nil {
 			pluginLogger.LogSystem(slogger.DEBUG, "spawned shell process with pid %v", localCmd.Cmd.Process.Pid)
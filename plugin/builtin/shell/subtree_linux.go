@@ -10,10 +10,10 @@ import (
 	"github.com/mongodb/grip/slogger"
 )
 
-func trackProcess(key string, pid int, log plugin.Logger) {
-	// trackProcess is a noop on linux, because we detect all the processes to be killed in
-	// cleanup() and we don't need to do any special bookkeeping up-front.
-}
+// trackProcess is a noop on linux, because we detect all the processes to be killed in
+// cleanup() and we don't need to do any special bookkeeping up-front. Processes are
+// started inside the task's cgroup, if it has one, by the wrapper from cgroupWrapper.
+func trackProcess(key string, pid int, log plugin.Logger) {}
 
 // getEnv returns a slice of environment variables for the given pid, in the form
 // []string{"VAR1=FOO", "VAR2=BAR", ...}
@@ -38,6 +38,18 @@ func getEnv(pid int) ([]string, error) {
 }
 
 func cleanup(key string, log plugin.Logger) error {
+	// kill everything in the task's cgroup first; this catches processes that
+	// have cleared or changed their environment
+	if cg := cgroupMapping.get(key); cg != nil {
+		killed, err := cg.killAll()
+		if err != nil {
+			log.LogTask(slogger.INFO, "Killing processes in cgroup failed: %v", err)
+		}
+		for _, pid := range killed {
+			log.LogTask(slogger.INFO, "Killed process %v", pid)
+		}
+	}
+
 	pids, err := listProc()
 	if err != nil {
 		return err
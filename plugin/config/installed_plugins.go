@@ -18,6 +18,8 @@ package config
 // ===== PLUGINS INCLUDED WITH MCI =====
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/archive"
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/attach"
+import _ "github.com/evergreen-ci/evergreen/plugin/builtin/cache"
+import _ "github.com/evergreen-ci/evergreen/plugin/builtin/coverage"
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/expansions"
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/git"
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/helloworld"
@@ -26,4 +28,5 @@ import _ "github.com/evergreen-ci/evergreen/plugin/builtin/s3"
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/s3copy"
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/shell"
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/manifest"
+import _ "github.com/evergreen-ci/evergreen/plugin/builtin/perf"
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/taskdata"
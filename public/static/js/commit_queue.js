@@ -0,0 +1,20 @@
+mciModule.controller('CommitQueueCtrl',
+  ['$scope', '$window', '$http', 'notificationService',
+  function($scope, $window, $http, notifier) {
+
+  $scope.project = $window.project;
+  $scope.enabled = $window.commitQueueEnabled;
+  $scope.queue = $window.commitQueue || [];
+
+  $scope.remove = function(item) {
+    $http.delete('/commit_queue/' + $scope.project + '/' + item.patch_id).
+      success(function() {
+        $scope.queue = _.reject($scope.queue, function(other) {
+          return other.patch_id == item.patch_id;
+        });
+      }).
+      error(function(data) {
+        notifier.pushNotification('Error removing patch from the commit queue: ' + JSON.stringify(data), 'errorHeader');
+      });
+  };
+}]);
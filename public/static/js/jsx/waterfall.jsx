@@ -260,12 +260,45 @@ class Root extends React.Component{
           buildVariantFilter={this.state.buildVariantFilter}
           taskFilter={this.state.taskFilter}
         />
+        <GitTagVersions
+          versions={this.props.data.git_tag_versions}
+          userTz={this.props.userTz}
+        />
       </div>
     )
   }
 }
 
 
+// GitTagVersions lists the versions created for tags in the repository, which are kept apart from the grid of commits on the branch
+function GitTagVersions ({versions, userTz}) {
+  if (!versions || versions.length == 0) {
+    return null;
+  }
+  return (
+    <div className="row git-tag-versions">
+      <div className="col-xs-12">
+        <h4>Git Tag Versions</h4>
+        <ul className="list-unstyled">
+          {
+            versions.map(function(version){
+              return <GitTagVersion key={version.id} version={version} userTz={userTz} />;
+            })
+          }
+        </ul>
+      </div>
+    </div>
+  )
+}
+
+function GitTagVersion ({version, userTz}) {
+  var formatted_time = getFormattedTime(version.create_time, userTz, 'M/D/YY h:mm A');
+  return (
+    <li>
+      <a href={"/version/" + version.id}><strong>{version.git_tag}</strong></a> at <span className="githash">{version.revision.substring(0,7)}</span> - {version.status} - {formatted_time}
+    </li>
+  )
+}
 // Toolbar
 function Toolbar ({collapsed, 
   onCheck, 
@@ -0,0 +1,60 @@
+mciModule.controller('LogSearchCtrl', function($scope, $http, $window) {
+  $scope.project = $window.activeProject;
+  $scope.variants = _.pluck($scope.project.build_variants, 'name').sort();
+  $scope.taskNames = $scope.project.task_names.sort();
+  $scope.severities = [
+    {name: 'Any', value: ''},
+    {name: 'Error', value: 'E'},
+    {name: 'Warning', value: 'W'},
+    {name: 'Info', value: 'I'},
+    {name: 'Debug', value: 'D'}
+  ];
+
+  $scope.query = {text: '', variant: '', task: '', severity: '', since: '', until: ''};
+  $scope.results = null;
+  $scope.searching = false;
+  $scope.error = '';
+
+  // since and until are dates in the form yyyy-mm-dd, searched in UTC
+  var toRFC3339 = function(date, endOfDay) {
+    if (!date) {
+      return '';
+    }
+    return date + (endOfDay ? 'T23:59:59Z' : 'T00:00:00Z');
+  };
+
+  $scope.search = function() {
+    if (!$scope.query.text) {
+      return;
+    }
+    var params = {q: $scope.query.text};
+    if ($scope.query.variant) {
+      params.variant = $scope.query.variant;
+    }
+    if ($scope.query.task) {
+      params.task = $scope.query.task;
+    }
+    if ($scope.query.severity) {
+      params.severity = $scope.query.severity;
+    }
+    if ($scope.query.since) {
+      params.since = toRFC3339($scope.query.since, false);
+    }
+    if ($scope.query.until) {
+      params.until = toRFC3339($scope.query.until, true);
+    }
+
+    $scope.searching = true;
+    $scope.error = '';
+    $http.get('/rest/v1/projects/' + encodeURIComponent($scope.project.name) + '/log_search', {params: params})
+      .success(function(data) {
+        $scope.results = data;
+        $scope.searching = false;
+      })
+      .error(function(data) {
+        $scope.error = (data && data.message) || 'Error searching logs';
+        $scope.results = null;
+        $scope.searching = false;
+      });
+  };
+});
@@ -88,6 +88,25 @@ mciModule.controller('PatchController', function($scope, $filter, $window, notif
     }
   }
 
+  $scope.aliases = $window.aliases || {};
+  $scope.aliasNames = _.keys($scope.aliases).sort();
+
+  // Checks the tasks that the project alias selects, in addition to those
+  // that are already checked.
+  $scope.selectAlias = function(name){
+    _.each($scope.aliases[name] || [], function(vt){
+      var v = _.find($scope.variants, function(x){return x.id == vt.Variant});
+      if(!v){
+        return;
+      }
+      _.each(vt.Tasks, function(taskName){
+        if(_.has(v.tasks, taskName)){
+          v.tasks[taskName].checked = true;
+        }
+      })
+    })
+  }
+
   // Sends the current patch config to the server to save.
   $scope.save = function(){
     var data = {
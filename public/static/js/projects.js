@@ -44,6 +44,19 @@ mciModule.controller('ProjectCtrl', function($scope, $window, $http, $location)
     return !isNaN(Number(t)) && Number(t) >= 0
   }
 
+  // retentionPolicy returns the policy to save, or null so that the project
+  // uses the default policy when neither field is set
+  $scope.retentionPolicy = function(retention) {
+    if (!retention || ((retention.mainline_days === '' || retention.mainline_days == null) &&
+        (retention.patch_days === '' || retention.patch_days == null))) {
+      return null
+    }
+    return {
+      mainline_days: parseInt(retention.mainline_days) || 0,
+      patch_days: parseInt(retention.patch_days) || 0,
+    }
+  }
+
   $scope.isValidAlertDefinition = function(spec) {
     if (spec.startsWith("JIRA:") && spec.split(":").length < 3) {
         return false
@@ -106,6 +119,97 @@ mciModule.controller('ProjectCtrl', function($scope, $window, $http, $location)
     $scope.isDirty = true;
   }
 
+  $scope.new_alias = {};
+
+  // splitList splits a comma separated list, dropping empty entries
+  $scope.splitList = function(list) {
+    return _.filter(_.map((list || "").split(","), function(item) {
+      return item.trim();
+    }), function(item) {
+      return item != "";
+    });
+  }
+
+  // addAlias adds the alias being edited to the settingsFormData's list of aliases
+  $scope.addAlias = function(){
+    $scope.settingsFormData.aliases.push({
+      alias: $scope.new_alias.alias,
+      variant: $scope.new_alias.variant || "",
+      variant_tags: $scope.splitList($scope.new_alias.variant_tags),
+      tasks: $scope.splitList($scope.new_alias.tasks),
+      task_tags: $scope.splitList($scope.new_alias.task_tags),
+    });
+    $scope.new_alias = {};
+    $scope.isDirty = true;
+  }
+
+  // removeAlias removes the alias located at index
+  $scope.removeAlias = function(index){
+    $scope.settingsFormData.aliases.splice(index, 1);
+    $scope.isDirty = true;
+  }
+
+  $scope.aliasSelectionDisplay = function(regexes, tags) {
+    var parts = _.map(regexes || [], function(regex) { return "'" + regex + "'"; });
+    parts = parts.concat(_.map(tags || [], function(tag) { return "tagged '" + tag + "'"; }));
+    return parts.length ? parts.join(", ") : "none";
+  }
+
+  $scope.newTrigger = function() {
+    return {level: "task", status: "success"};
+  }
+  $scope.new_trigger = $scope.newTrigger();
+
+  // addTrigger adds the trigger being edited to the settingsFormData's list of triggers
+  $scope.addTrigger = function(){
+    $scope.settingsFormData.triggers.push($scope.new_trigger);
+    $scope.new_trigger = $scope.newTrigger();
+    $scope.isDirty = true;
+  }
+
+  // removeTrigger removes the trigger located at index
+  $scope.removeTrigger = function(index){
+    $scope.settingsFormData.triggers.splice(index, 1);
+    $scope.isDirty = true;
+  }
+
+  $scope.new_periodic_build = {};
+
+  // addPeriodicBuild adds the periodic build being edited to the settingsFormData's list
+  $scope.addPeriodicBuild = function(){
+    $scope.settingsFormData.periodic_builds.push($scope.new_periodic_build);
+    $scope.new_periodic_build = {};
+    $scope.isDirty = true;
+  }
+
+  // removePeriodicBuild removes the periodic build located at index
+  $scope.removePeriodicBuild = function(index){
+    $scope.settingsFormData.periodic_builds.splice(index, 1);
+    $scope.isDirty = true;
+  }
+
+  $scope.new_git_tag_version = {};
+
+  // addGitTagVersion adds the git tag definition being edited to the settingsFormData's list
+  $scope.addGitTagVersion = function(){
+    $scope.settingsFormData.git_tag_versions.push($scope.new_git_tag_version);
+    $scope.new_git_tag_version = {};
+    $scope.isDirty = true;
+  }
+
+  // removeGitTagVersion removes the git tag definition located at index
+  $scope.removeGitTagVersion = function(index){
+    $scope.settingsFormData.git_tag_versions.splice(index, 1);
+    $scope.isDirty = true;
+  }
+
+  $scope.triggerStatusDisplay = function(status) {
+    if (status == "*") {
+      return "any status";
+    }
+    return status == "failed" ? "a failure" : "success";
+  }
+
 
   $scope.addProject = function() {
     $scope.modalOpen = false;
@@ -153,11 +257,19 @@ mciModule.controller('ProjectCtrl', function($scope, $window, $http, $location)
           branch_name: $scope.projectRef.branch_name,
           owner_name: $scope.projectRef.owner_name,
           repo_name: $scope.projectRef.repo_name,
+          repo_kind: $scope.projectRef.repo_kind || "github",
+          repo_url: $scope.projectRef.repo_url,
           enabled: $scope.projectRef.enabled,
           private: $scope.projectRef.private,
           alert_config: $scope.projectRef.alert_config || {},
           repotracker_error: $scope.projectRef.repotracker_error || {},
           admins : $scope.projectRef.admins || [],
+          retention: $scope.projectRef.retention || {},
+          commit_queue_enabled: $scope.projectRef.commit_queue_enabled,
+          aliases: $scope.projectRef.aliases || [],
+          triggers: $scope.projectRef.triggers || [],
+          periodic_builds: $scope.projectRef.periodic_builds || [],
+          git_tag_versions: $scope.projectRef.git_tag_versions || [],
         };
 
         $scope.displayName = $scope.projectRef.display_name ? $scope.projectRef.display_name : $scope.projectRef.identifier;
@@ -192,12 +304,25 @@ mciModule.controller('ProjectCtrl', function($scope, $window, $http, $location)
 
   $scope.saveProject = function() {
     $scope.settingsFormData.batch_time = parseInt($scope.settingsFormData.batch_time)
+    $scope.settingsFormData.retention = $scope.retentionPolicy($scope.settingsFormData.retention)
     if ($scope.proj_var) {
       $scope.addProjectVar();
     }
     if ($scope.admin_name) {
       $scope.addAdmin();
     }
+    if ($scope.new_alias.alias) {
+      $scope.addAlias();
+    }
+    if ($scope.new_trigger.project) {
+      $scope.addTrigger();
+    }
+    if ($scope.new_periodic_build.cron) {
+      $scope.addPeriodicBuild();
+    }
+    if ($scope.new_git_tag_version.pattern) {
+      $scope.addGitTagVersion();
+    }
     $http.post('/project/' + $scope.settingsFormData.identifier, $scope.settingsFormData).
       success(function(data, status) {
         $scope.saveMessage = "Settings Saved.";
@@ -206,6 +331,7 @@ mciModule.controller('ProjectCtrl', function($scope, $window, $http, $location)
         $scope.isDirty = false;
       }).
       error(function(data, status, errorThrown) {
+        $scope.saveMessage = data;
         console.log(status);
       });
   };
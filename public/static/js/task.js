@@ -544,9 +544,63 @@ mciModule.controller('TaskLogCtrl', ['$scope', '$timeout', '$http', '$location',
   $scope.currentLogs = logSpec[2] || $scope.taskLogs;
 
   $scope.$watch('currentLogs', function() {
+    $scope.closeLogTail();
     $scope.getLogs();
   });
 
+  var formatLogEntry = function(entry) {
+    var msg = entry.m.replace(/&#34;/g, '"');
+    var date = new Date(entry.ts);
+    return {
+      message: msg,
+      severity: entry.s,
+      timestamp: date,
+      version: entry.v
+    };
+  };
+
+  // while the task is running, new log messages are pushed over a websocket
+  // instead of being polled for
+  $scope.openLogTail = function() {
+    if ($scope.logTail || !$window.WebSocket || $scope.currentLogs == $scope.eventLogs ||
+        ($scope.task.status != 'started' && $scope.task.status != 'dispatched')) {
+      return false;
+    }
+
+    var protocol = $window.location.protocol == 'https:' ? 'wss://' : 'ws://';
+    var socket = new $window.WebSocket(protocol + $window.location.host + '/task_log_tail/' +
+      $scope.taskId + '/' + $scope.task.execution + '?type=' + $scope.currentLogs);
+    socket.onmessage = function(event) {
+      // logs are kept newest first
+      var entries = _.map(JSON.parse(event.data), formatLogEntry).reverse();
+      $scope.$apply(function() {
+        $scope.logs = entries.concat($scope.logs instanceof Array ? $scope.logs : []);
+      });
+    };
+    socket.onclose = function() {
+      if ($scope.logTail !== socket) {
+        return;
+      }
+      // the stream ends when the task finishes; fall back to polling
+      $scope.logTail = null;
+      $scope.$apply(function() {
+        $scope.getLogs();
+      });
+    };
+    $scope.logTail = socket;
+    return true;
+  };
+
+  $scope.closeLogTail = function() {
+    if ($scope.logTail) {
+      var socket = $scope.logTail;
+      $scope.logTail = null;
+      socket.close();
+    }
+  };
+
+  $scope.$on('$destroy', $scope.closeLogTail);
+
   $scope.setCurrentLogs = function(currentLogs) {
     $scope.logs = 'Loading...';
     $scope.currentLogs = currentLogs;
@@ -572,19 +626,11 @@ mciModule.controller('TaskLogCtrl', ['$scope', '$timeout', '$http', '$location',
       } else {
         if (data && data.LogMessages) {
           //read the log messages out, and reverse their order (since they are returned backwards)
-          $scope.logs = _.map(data.LogMessages, function(entry) {
-            var msg = entry.m.replace(/&#34;/g, '"');
-            var date = new Date(entry.ts);
-            return {
-              message: msg,
-              severity: entry.s,
-              timestamp: date,
-              version: entry.v
-            };
-          });
+          $scope.logs = _.map(data.LogMessages, formatLogEntry);
         } else {
           $scope.logs = [];
         }
+        $scope.openLogTail();
       }
     }).
     error(function(jqXHR, status, errorThrown) {
@@ -597,7 +643,9 @@ mciModule.controller('TaskLogCtrl', ['$scope', '$timeout', '$http', '$location',
     }
 
     $scope.getLogsTimeout = $timeout(function() {
-      $scope.getLogs();
+      if (!$scope.logTail) {
+        $scope.getLogs();
+      }
     }, 5000);
   };
 
@@ -259,6 +259,10 @@ class Root extends React.Component{
           project: this.props.project, 
           buildVariantFilter: this.state.buildVariantFilter, 
           taskFilter: this.state.taskFilter}
+        ), 
+        React.createElement(GitTagVersions, {
+          versions: this.props.data.git_tag_versions, 
+          userTz: this.props.userTz}
         )
       )
     )
@@ -266,6 +270,35 @@ class Root extends React.Component{
 }
 
 
+// GitTagVersions lists the versions created for tags in the repository, which are kept apart from the grid of commits on the branch
+function GitTagVersions ({versions, userTz}) {
+  if (!versions || versions.length == 0) {
+    return null;
+  }
+  return (
+    React.createElement("div", {className: "row git-tag-versions"}, 
+      React.createElement("div", {className: "col-xs-12"}, 
+        React.createElement("h4", null, "Git Tag Versions"), 
+        React.createElement("ul", {className: "list-unstyled"}, 
+          
+            versions.map(function(version){
+              return React.createElement(GitTagVersion, {key: version.id, version: version, userTz: userTz});
+            })
+          
+        )
+      )
+    )
+  )
+}
+
+function GitTagVersion ({version, userTz}) {
+  var formatted_time = getFormattedTime(version.create_time, userTz, 'M/D/YY h:mm A');
+  return (
+    React.createElement("li", null, 
+      React.createElement("a", {href: "/version/" + version.id}, React.createElement("strong", null, version.git_tag)), " at ", React.createElement("span", {className: "githash"}, version.revision.substring(0,7)), " - ", version.status, " - ", formatted_time
+    )
+  )
+}
 // Toolbar
 function Toolbar ({collapsed, 
   onCheck, 
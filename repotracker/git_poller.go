@@ -0,0 +1,376 @@
+package repotracker
+
+import (
+	"bytes"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+const (
+	// separate the fields and records of the git log output with the ASCII
+	// unit and record separators, which commit messages do not contain
+	gitFieldSeparator  = "\x1f"
+	gitRecordSeparator = "\x1e"
+	gitLogFormat       = "--format=%H%x1f%an%x1f%ae%x1f%B%x1e"
+)
+
+var (
+	// revisions are only ever passed to git as full commit hashes, so that
+	// no revision can be mistaken for an option
+	gitRevisionRegex = regexp.MustCompile("^[0-9a-f]{40}$")
+	// scpLikeURLRegex matches the user@host:path form of ssh urls
+	scpLikeURLRegex = regexp.MustCompile(`^[\w.-]+@\w[\w.-]*:`)
+	gitURLSchemes   = []string{"https", "http", "ssh", "git", "file"}
+)
+
+// validateRevision checks that a revision is a full commit hash.
+func validateRevision(revision string) error {
+	if !gitRevisionRegex.MatchString(revision) {
+		return errors.Errorf("invalid revision '%s': must be a full commit hash", revision)
+	}
+	return nil
+}
+
+// validateRepoURL checks that a repository url is a local path, an scp-like
+// ssh url or a url with one of gitURLSchemes, so that git neither reads it
+// as an option nor runs a command to reach it.
+func validateRepoURL(repoURL string) error {
+	if strings.HasPrefix(repoURL, "-") {
+		return errors.Errorf("invalid repository url '%s'", repoURL)
+	}
+	if filepath.IsAbs(repoURL) || scpLikeURLRegex.MatchString(repoURL) {
+		return nil
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil || !util.SliceContains(gitURLSchemes, u.Scheme) ||
+		strings.HasPrefix(u.Host, "-") || strings.HasPrefix(u.User.String(), "-") {
+		return errors.Errorf("invalid repository url '%s': must be a local path or use one of %v",
+			repoURL, gitURLSchemes)
+	}
+	return nil
+}
+
+// DefaultMirrorDirectory is where mirror clones are kept if the repotracker
+// settings do not say otherwise.
+var DefaultMirrorDirectory = filepath.Join(os.TempDir(), "evergreen-repotracker")
+
+// GitRepositoryPoller is a RepoPoller for projects whose repositories are on
+// any git server. It keeps a bare mirror clone of the project's repository,
+// which it fetches into before looking for new revisions.
+type GitRepositoryPoller struct {
+	ProjectRef      *model.ProjectRef
+	MirrorDirectory string
+}
+
+// NewGitRepositoryPoller constructs and returns a pointer to a
+// GitRepositoryPoller struct that keeps its mirror clone in mirrorDirectory.
+func NewGitRepositoryPoller(projectRef *model.ProjectRef,
+	mirrorDirectory string) *GitRepositoryPoller {
+	if mirrorDirectory == "" {
+		mirrorDirectory = DefaultMirrorDirectory
+	}
+	return &GitRepositoryPoller{
+		ProjectRef:      projectRef,
+		MirrorDirectory: mirrorDirectory,
+	}
+}
+
+// mirrorPath returns the path of the project's mirror clone.
+func (gitPoller *GitRepositoryPoller) mirrorPath() string {
+	return filepath.Join(gitPoller.MirrorDirectory, gitPoller.ProjectRef.Identifier+".git")
+}
+
+// git runs a git command in the mirror clone and returns its output.
+func (gitPoller *GitRepositoryPoller) git(args ...string) (string, error) {
+	return runGit(append([]string{"--git-dir", gitPoller.mirrorPath()}, args...)...)
+}
+
+func runGit(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.Wrapf(err, "'git %s' failed: %s", strings.Join(args, " "),
+			strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// cloneMirror makes the project's mirror clone if it does not exist yet.
+// It returns whether the clone was made.
+func (gitPoller *GitRepositoryPoller) cloneMirror() (bool, error) {
+	if _, err := os.Stat(gitPoller.mirrorPath()); err == nil {
+		return false, nil
+	}
+	if gitPoller.ProjectRef.RepoURL == "" {
+		return false, errors.Errorf("project '%s' has no repository url",
+			gitPoller.ProjectRef.Identifier)
+	}
+	if err := validateRepoURL(gitPoller.ProjectRef.RepoURL); err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(gitPoller.MirrorDirectory, 0755); err != nil {
+		return false, errors.Wrap(err, "error making mirror directory")
+	}
+
+	grip.Infof("Cloning mirror of %s for project '%s'", gitPoller.ProjectRef.RepoURL,
+		gitPoller.ProjectRef.Identifier)
+	if _, err := runGit("clone", "--mirror", "--quiet", "--", gitPoller.ProjectRef.RepoURL,
+		gitPoller.mirrorPath()); err != nil {
+		// don't leave a partial clone to be mistaken for a mirror
+		grip.Warning(os.RemoveAll(gitPoller.mirrorPath()))
+		return false, err
+	}
+	return true, nil
+}
+
+// updateMirror makes the project's mirror clone or fetches the repository's
+// latest commits into it.
+func (gitPoller *GitRepositoryPoller) updateMirror() error {
+	cloned, err := gitPoller.cloneMirror()
+	if err != nil || cloned {
+		return err
+	}
+	// the repository url may have been changed since the clone was made
+	if gitPoller.ProjectRef.RepoURL != "" {
+		if err = validateRepoURL(gitPoller.ProjectRef.RepoURL); err != nil {
+			return err
+		}
+		if _, err = gitPoller.git("remote", "set-url", "--", "origin", gitPoller.ProjectRef.RepoURL); err != nil {
+			return err
+		}
+	}
+	_, err = gitPoller.git("fetch", "--prune", "--quiet", "origin")
+	return err
+}
+
+// branchRef returns the fully qualified ref of the project's branch.
+func (gitPoller *GitRepositoryPoller) branchRef() string {
+	return "refs/heads/" + gitPoller.ProjectRef.Branch
+}
+
+// parseGitLog parses the output of git log run with gitLogFormat into
+// revisions, the most recent first.
+func parseGitLog(out string) []model.Revision {
+	revisions := []model.Revision{}
+	for _, record := range strings.Split(out, gitRecordSeparator) {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), gitFieldSeparator, 4)
+		if len(fields) != 4 {
+			continue
+		}
+		revisions = append(revisions, model.Revision{
+			Author:          fields[1],
+			AuthorEmail:     fields[2],
+			RevisionMessage: strings.TrimSpace(fields[3]),
+			Revision:        fields[0],
+			CreateTime:      time.Now(),
+		})
+	}
+	return revisions
+}
+
+// GetRemoteConfig reads the project's configuration file as at the given
+// revision from the mirror clone.
+func (gitPoller *GitRepositoryPoller) GetRemoteConfig(revision string) (*model.Project, error) {
+	if err := validateRevision(revision); err != nil {
+		return nil, err
+	}
+	if _, err := gitPoller.cloneMirror(); err != nil {
+		return nil, err
+	}
+	if _, err := gitPoller.git("cat-file", "-e", "--", revision+"^{commit}"); err != nil {
+		return nil, errors.Wrapf(err, "revision '%s' not found", revision)
+	}
+
+	path := revision + ":" + gitPoller.ProjectRef.RemotePath
+	if _, err := gitPoller.git("cat-file", "-e", "--", path); err != nil {
+		return nil, thirdparty.NewFileNotFoundError(path)
+	}
+	projectFileBytes, err := gitPoller.git("cat-file", "blob", "--", path)
+	if err != nil {
+		return nil, err
+	}
+
+	projectConfig := &model.Project{}
+	err = model.LoadProjectInto([]byte(projectFileBytes), gitPoller.ProjectRef.Identifier, projectConfig)
+	if err != nil {
+		return nil, thirdparty.YAMLFormatError{Message: err.Error()}
+	}
+	return projectConfig, nil
+}
+
+// GetChangedFiles lists the files that the given revision changed, relative
+// to its first parent.
+func (gitPoller *GitRepositoryPoller) GetChangedFiles(revision string) ([]string, error) {
+	if err := validateRevision(revision); err != nil {
+		return nil, err
+	}
+	if _, err := gitPoller.cloneMirror(); err != nil {
+		return nil, err
+	}
+	// the trailing -- keeps git from reading the revision as a path
+	out, err := gitPoller.git("diff-tree", "--no-commit-id", "--name-only", "-r",
+		"--root", "-m", "--first-parent", revision, "--")
+	if err != nil {
+		return nil, errors.Wrapf(err, "error loading commit '%v'", revision)
+	}
+	files := []string{}
+	for _, file := range strings.Split(out, "\n") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// GetRevisionsSince fetches the repository's latest commits into the mirror
+// clone and returns the commits on the project's branch since the given
+// revision. If the revision is not on the branch, or is further back than
+// maxRevisionsToSearch commits, the project's repotracker error is set.
+func (gitPoller *GitRepositoryPoller) GetRevisionsSince(revision string,
+	maxRevisionsToSearch int) ([]model.Revision, error) {
+	if err := validateRevision(revision); err != nil {
+		return nil, err
+	}
+	if err := gitPoller.updateMirror(); err != nil {
+		return nil, err
+	}
+
+	branch := gitPoller.branchRef()
+	_, err := gitPoller.git("merge-base", "--is-ancestor", "--", revision, branch)
+	if err == nil && maxRevisionsToSearch > 0 {
+		var count string
+		count, err = gitPoller.git("rev-list", "--count", revision+".."+branch, "--")
+		if err == nil {
+			var n int
+			n, err = strconv.Atoi(strings.TrimSpace(count))
+			if err == nil && n > maxRevisionsToSearch {
+				err = errors.Errorf("revision %v is more than %d commits behind", revision,
+					maxRevisionsToSearch)
+			}
+		}
+	}
+	if err != nil {
+		return nil, gitPoller.setRevisionError(revision, err)
+	}
+
+	out, err := gitPoller.git("log", gitLogFormat, revision+".."+branch, "--")
+	if err != nil {
+		return nil, err
+	}
+	return parseGitLog(out), nil
+}
+
+// setRevisionError records on the project ref that the revision could not
+// be found on the branch, along with the merge base of the revision and the
+// branch if there is one.
+func (gitPoller *GitRepositoryPoller) setRevisionError(revision string, cause error) error {
+	var revisionError error
+	revisionDetails := &model.RepositoryErrorDetails{
+		Exists:          true,
+		InvalidRevision: revision[:10],
+	}
+	baseRevision, err := gitPoller.git("merge-base", "--", revision, gitPoller.branchRef())
+	if err != nil {
+		revisionError = errors.Wrapf(cause,
+			"unable to find a suggested merge base commit for revision %v, must fix on projects settings page",
+			revision)
+	} else {
+		revisionDetails.MergeBaseRevision = strings.TrimSpace(baseRevision)
+		revisionError = errors.Errorf("base revision, %v not found, suggested base revision, %v found, must confirm on project settings page",
+			revision, revisionDetails.MergeBaseRevision)
+	}
+
+	gitPoller.ProjectRef.RepotrackerError = revisionDetails
+	if err = gitPoller.ProjectRef.Upsert(); err != nil {
+		return errors.Wrap(err, "unable to update projectRef revision details")
+	}
+	return revisionError
+}
+
+// GetRecentRevisions fetches the repository's latest commits into the
+// mirror clone and returns the most recent 'maxRevisions' commits on the
+// project's branch.
+func (gitPoller *GitRepositoryPoller) GetRecentRevisions(maxRevisions int) ([]model.Revision, error) {
+	if err := gitPoller.updateMirror(); err != nil {
+		return nil, err
+	}
+	out, err := gitPoller.git("log", gitLogFormat, "-n", strconv.Itoa(maxRevisions), gitPoller.branchRef(), "--")
+	if err != nil {
+		return nil, err
+	}
+	return parseGitLog(out), nil
+}
+
+// gitTagFormat makes git for-each-ref list each tag's name, the object that
+// it points to, and, for annotated tags, the commit that the tag object
+// points to.
+const gitTagFormat = "--format=%(refname) %(objectname) %(*objectname)"
+
+// parseTagRefs parses the output of git for-each-ref run with gitTagFormat
+// into tags.
+func parseTagRefs(out string) []model.GitTag {
+	tags := []model.GitTag{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "refs/tags/") {
+			continue
+		}
+		tag := model.GitTag{
+			Name:     strings.TrimPrefix(fields[0], "refs/tags/"),
+			Revision: fields[1],
+		}
+		if len(fields) == 3 {
+			tag.Revision = fields[2]
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// GetTags fetches the repository's latest commits and tags into the mirror
+// clone and returns all of the tags in it. Listing the mirror's tags is
+// cheap, so known tags are returned too.
+func (gitPoller *GitRepositoryPoller) GetTags(known map[string]bool) ([]model.GitTag, error) {
+	if err := gitPoller.updateMirror(); err != nil {
+		return nil, err
+	}
+	out, err := gitPoller.git("for-each-ref", gitTagFormat, "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+	return parseTagRefs(out), nil
+}
+
+// GetRevision returns the details of the given revision from the mirror
+// clone.
+func (gitPoller *GitRepositoryPoller) GetRevision(revision string) (model.Revision, error) {
+	if err := validateRevision(revision); err != nil {
+		return model.Revision{}, err
+	}
+	if _, err := gitPoller.cloneMirror(); err != nil {
+		return model.Revision{}, err
+	}
+	out, err := gitPoller.git("log", gitLogFormat, "-n", "1", revision, "--")
+	if err != nil {
+		return model.Revision{}, errors.Wrapf(err, "error loading commit '%v'", revision)
+	}
+	revisions := parseGitLog(out)
+	if len(revisions) == 0 {
+		return model.Revision{}, errors.Errorf("revision '%s' not found", revision)
+	}
+	return revisions[0], nil
+}
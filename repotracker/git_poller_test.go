@@ -0,0 +1,187 @@
+package repotracker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/testutil"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// upstreamRepo is a local git repository that stands in for a project's
+// repository on a git server.
+type upstreamRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func (u *upstreamRepo) git(args ...string) string {
+	out, err := runGit(append([]string{"-C", u.dir, "-c", "user.name=Test Author",
+		"-c", "user.email=author@example.com"}, args...)...)
+	testutil.HandleTestingErr(err, u.t, "error running git in upstream repo")
+	return out
+}
+
+// commit writes the file and commits it, returning the new revision.
+func (u *upstreamRepo) commit(file, contents, message string) string {
+	testutil.HandleTestingErr(ioutil.WriteFile(filepath.Join(u.dir, file), []byte(contents), 0644),
+		u.t, "error writing %s", file)
+	u.git("add", file)
+	u.git("commit", "--quiet", "-m", message)
+	return u.head()
+}
+
+func (u *upstreamRepo) head() string {
+	out := u.git("rev-parse", "HEAD")
+	return out[:len(out)-1]
+}
+
+func TestGitRepositoryPoller(t *testing.T) {
+	Convey("With a project in a local git repository", t, func() {
+		testutil.HandleTestingErr(db.Clear(model.ProjectRefCollection), t,
+			"error clearing project refs")
+		tmp, err := ioutil.TempDir("", "git-poller")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(tmp)
+
+		upstream := &upstreamRepo{t: t, dir: filepath.Join(tmp, "upstream")}
+		So(os.Mkdir(upstream.dir, 0755), ShouldBeNil)
+		upstream.git("init", "--quiet")
+		upstream.git("checkout", "--quiet", "-b", "master")
+		first := upstream.commit("evergreen.yml", "tasks:\n- name: compile\n", "add project config")
+		second := upstream.commit("main.go", "package main\n", "add main\n\nwith a longer description")
+
+		projectRef := &model.ProjectRef{
+			Identifier: "local-git",
+			RepoKind:   model.GitRepoType,
+			RepoURL:    upstream.dir,
+			Branch:     "master",
+			RemotePath: "evergreen.yml",
+			Enabled:    true,
+			Tracked:    true,
+		}
+		So(projectRef.Insert(), ShouldBeNil)
+
+		settings := &evergreen.Settings{}
+		settings.RepoTracker.MirrorDirectory = filepath.Join(tmp, "mirrors")
+		poller, err := NewRepoPoller(settings, projectRef)
+		So(err, ShouldBeNil)
+		So(poller, ShouldHaveSameTypeAs, &GitRepositoryPoller{})
+
+		Convey("recent revisions should be listed most recent first", func() {
+			revisions, err := poller.GetRecentRevisions(10)
+			So(err, ShouldBeNil)
+			So(len(revisions), ShouldEqual, 2)
+			So(revisions[0].Revision, ShouldEqual, second)
+			So(revisions[0].RevisionMessage, ShouldEqual, "add main\n\nwith a longer description")
+			So(revisions[0].Author, ShouldEqual, "Test Author")
+			So(revisions[0].AuthorEmail, ShouldEqual, "author@example.com")
+			So(revisions[1].Revision, ShouldEqual, first)
+
+			revisions, err = poller.GetRecentRevisions(1)
+			So(err, ShouldBeNil)
+			So(len(revisions), ShouldEqual, 1)
+		})
+
+		Convey("revisions pushed after a revision should be fetched", func() {
+			_, err := poller.GetRecentRevisions(10)
+			So(err, ShouldBeNil)
+			third := upstream.commit("main.go", "package main\n\nfunc main() {}\n", "add func main")
+
+			revisions, err := poller.GetRevisionsSince(second, 10)
+			So(err, ShouldBeNil)
+			So(len(revisions), ShouldEqual, 1)
+			So(revisions[0].Revision, ShouldEqual, third)
+
+			revisions, err = poller.GetRevisionsSince(third, 10)
+			So(err, ShouldBeNil)
+			So(len(revisions), ShouldEqual, 0)
+
+			Convey("but not if there are more than the maximum to search", func() {
+				_, err = poller.GetRevisionsSince(first, 1)
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("a revision that is no longer on the branch should be a repotracker error", func() {
+			_, err := poller.GetRecentRevisions(10)
+			So(err, ShouldBeNil)
+			upstream.git("reset", "--quiet", "--hard", first)
+			upstream.commit("other.go", "package main\n", "rewrite history")
+
+			_, err = poller.GetRevisionsSince(second, 10)
+			So(err, ShouldNotBeNil)
+			So(projectRef.RepotrackerError, ShouldNotBeNil)
+			So(projectRef.RepotrackerError.InvalidRevision, ShouldEqual, second[:10])
+			So(projectRef.RepotrackerError.MergeBaseRevision, ShouldEqual, first)
+		})
+
+		Convey("the files changed by a revision should be listed", func() {
+			files, err := poller.GetChangedFiles(second)
+			So(err, ShouldBeNil)
+			So(files, ShouldResemble, []string{"main.go"})
+
+			files, err = poller.GetChangedFiles(first)
+			So(err, ShouldBeNil)
+			So(files, ShouldResemble, []string{"evergreen.yml"})
+		})
+
+		Convey("tags should be listed with the commits that they point to", func() {
+			upstream.git("tag", "r1.0", first)
+			upstream.git("tag", "-a", "-m", "release 1.1", "r1.1", second)
+
+			tags, err := poller.GetTags(nil)
+			So(err, ShouldBeNil)
+			So(tags, ShouldResemble, []model.GitTag{
+				{Name: "r1.0", Revision: first},
+				{Name: "r1.1", Revision: second},
+			})
+
+			revision, err := poller.GetRevision(second)
+			So(err, ShouldBeNil)
+			So(revision.Revision, ShouldEqual, second)
+			So(revision.Author, ShouldEqual, "Test Author")
+			So(revision.RevisionMessage, ShouldEqual, "add main\n\nwith a longer description")
+		})
+
+		Convey("the project config should be read as at a revision", func() {
+			project, err := poller.GetRemoteConfig(second)
+			So(err, ShouldBeNil)
+			So(len(project.Tasks), ShouldEqual, 1)
+			So(project.Tasks[0].Name, ShouldEqual, "compile")
+
+			projectRef.RemotePath = "missing.yml"
+			_, err = poller.GetRemoteConfig(second)
+			So(thirdparty.IsFileNotFound(err), ShouldBeTrue)
+		})
+	})
+}
+
+func TestValidateGitArguments(t *testing.T) {
+	Convey("Revisions should only be accepted as full commit hashes", t, func() {
+		So(validateRevision("0123456789abcdef0123456789abcdef01234567"), ShouldBeNil)
+		So(validateRevision("0123456789"), ShouldNotBeNil)
+		So(validateRevision("master"), ShouldNotBeNil)
+		So(validateRevision("--output=/tmp/file"), ShouldNotBeNil)
+		So(validateRevision("0123456789abcdef0123456789abcdef01234567 --all"), ShouldNotBeNil)
+	})
+
+	Convey("Repository urls should not be options or use unexpected schemes", t, func() {
+		So(validateRepoURL("https://github.com/evergreen-ci/evergreen.git"), ShouldBeNil)
+		So(validateRepoURL("ssh://git@github.com/evergreen-ci/evergreen.git"), ShouldBeNil)
+		So(validateRepoURL("git@github.com:evergreen-ci/evergreen.git"), ShouldBeNil)
+		So(validateRepoURL("/srv/git/evergreen.git"), ShouldBeNil)
+
+		So(validateRepoURL("--upload-pack=touch /tmp/pwned"), ShouldNotBeNil)
+		So(validateRepoURL("ext::sh -c touch% /tmp/pwned"), ShouldNotBeNil)
+		So(validateRepoURL("ssh://-oProxyCommand=touch/repo"), ShouldNotBeNil)
+		So(validateRepoURL("git@-oProxyCommand=touch:repo"), ShouldNotBeNil)
+		So(validateRepoURL("relative/path"), ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,130 @@
+package repotracker
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// trackGitTags creates a version for each new tag in the project's
+// repository that one of its git tag definitions matches. The first time
+// that the project's tags are tracked, the existing tags are recorded
+// without creating versions for them. Tags whose versions could not be
+// created are tried again the next time.
+func (repoTracker *RepoTracker) trackGitTags() error {
+	projectRef := repoTracker.ProjectRef
+	if len(projectRef.GitTagVersions) == 0 {
+		return nil
+	}
+
+	repository, err := model.FindRepository(projectRef.Identifier)
+	if err != nil {
+		return errors.Wrapf(err, "error finding repository '%v'", projectRef.Identifier)
+	}
+
+	if repository == nil || !repository.GitTagsTracked {
+		tags, err := repoTracker.GetTags(nil)
+		if err != nil {
+			return errors.Wrapf(err, "error fetching tags for repository %s", projectRef.Identifier)
+		}
+		names := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			names = append(names, tag.Name)
+		}
+		grip.Infof("recording %d existing tags of repository %s", len(names), projectRef.Identifier)
+		return errors.Wrapf(model.AddGitTags(projectRef.Identifier, names),
+			"error recording tags of repository %s", projectRef.Identifier)
+	}
+
+	known := map[string]bool{}
+	for _, name := range repository.GitTags {
+		known[name] = true
+	}
+	tags, err := repoTracker.GetTags(known)
+	if err != nil {
+		return errors.Wrapf(err, "error fetching tags for repository %s", projectRef.Identifier)
+	}
+	catcher := grip.NewCatcher()
+	seen := []string{}
+	for _, tag := range tags {
+		if known[tag.Name] {
+			continue
+		}
+		if definition := projectRef.FindGitTagVersion(tag.Name); definition != nil {
+			if err = repoTracker.createGitTagVersion(tag, definition); err != nil {
+				catcher.Add(err)
+				continue
+			}
+		}
+		seen = append(seen, tag.Name)
+	}
+	if len(seen) > 0 {
+		catcher.Add(errors.Wrapf(model.AddGitTags(projectRef.Identifier, seen),
+			"error recording tags of repository %s", projectRef.Identifier))
+	}
+	return catcher.Resolve()
+}
+
+// gitTagVersionId returns the id of the version of the project for the tag.
+// Characters of the tag other than letters and digits are escaped, so that
+// tags differing only in punctuation, like v1.2-rc and v1.2.rc, get
+// different versions.
+func gitTagVersionId(projectId, tag string) string {
+	escaped := bytes.Buffer{}
+	for i := 0; i < len(tag); i++ {
+		c := tag[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			escaped.WriteByte(c)
+		} else {
+			fmt.Fprintf(&escaped, "_%02x", c)
+		}
+	}
+	return fmt.Sprintf("%v_tag_%v", util.CleanName(projectId), escaped.String())
+}
+
+// createGitTagVersion creates the version of the project at the tag's
+// commit, unless it already exists.
+func (repoTracker *RepoTracker) createGitTagVersion(tag model.GitTag, definition *model.GitTagDefinition) error {
+	projectRef := repoTracker.ProjectRef
+	id := gitTagVersionId(projectRef.Identifier, tag.Name)
+	existing, err := version.FindOne(version.ById(id))
+	if err != nil {
+		return errors.Wrapf(err, "error finding version %s", id)
+	}
+	if existing != nil {
+		return nil
+	}
+
+	revision, err := repoTracker.GetRevision(tag.Revision)
+	if err != nil {
+		return errors.Wrapf(err, "error fetching the commit of tag %s", tag.Name)
+	}
+	metadata := VersionMetadata{
+		Revision:   revision,
+		Requester:  evergreen.GitTagRequester,
+		ConfigFile: definition.ConfigFile,
+		Alias:      definition.Alias,
+		Expansions: map[string]string{
+			"triggered_by_git_tag": tag.Name,
+		},
+		GitTag: tag.Name,
+	}
+
+	if definition.ConfigFile == "" {
+		_, err = repoTracker.createVersionFromConfig(projectRef, id, metadata)
+	} else {
+		_, err = CreateVersionFromConfig(repoTracker.Settings, projectRef, id, metadata)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error creating version of project %s for tag %s",
+			projectRef.Identifier, tag.Name)
+	}
+	grip.Infof("created version %s of project %s for tag %s", id, projectRef.Identifier, tag.Name)
+	return nil
+}
@@ -233,3 +233,44 @@ func (gRepoPoller *GithubRepositoryPoller) GetRecentRevisions(maxRevisions int)
 	}
 	return
 }
+
+// GetTags fetches the tags in the repository that are newer than the first
+// known tag. GitHub lists the newest tags first, so paging stops there.
+func (gRepoPoller *GithubRepositoryPoller) GetTags(known map[string]bool) ([]model.GitTag, error) {
+	tagsURL := fmt.Sprintf("https://api.github.com/repos/%v/%v/tags?per_page=100",
+		gRepoPoller.ProjectRef.Owner,
+		gRepoPoller.ProjectRef.Repo,
+	)
+
+	tags := []model.GitTag{}
+	for tagsURL != "" {
+		githubTags, header, err := thirdparty.GetGithubTags(gRepoPoller.OauthToken, tagsURL)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range githubTags {
+			if known[tag.Name] {
+				return tags, nil
+			}
+			tags = append(tags, model.GitTag{Name: tag.Name, Revision: tag.Commit.SHA})
+		}
+		tagsURL = thirdparty.NextGithubPageLink(header)
+	}
+	return tags, nil
+}
+
+// GetRevision fetches the details of the given revision.
+func (gRepoPoller *GithubRepositoryPoller) GetRevision(revision string) (model.Revision, error) {
+	commitEvent, err := thirdparty.GetCommitEvent(gRepoPoller.OauthToken,
+		gRepoPoller.ProjectRef.Owner, gRepoPoller.ProjectRef.Repo, revision)
+	if err != nil {
+		return model.Revision{}, errors.Wrapf(err, "error loading commit '%v'", revision)
+	}
+	return model.Revision{
+		Author:          commitEvent.Commit.Author.Name,
+		AuthorEmail:     commitEvent.Commit.Author.Email,
+		RevisionMessage: commitEvent.Commit.Message,
+		Revision:        commitEvent.SHA,
+		CreateTime:      time.Now(),
+	}, nil
+}
@@ -1,6 +1,8 @@
 package repotracker
 
 import (
+	"time"
+
 	"github.com/evergreen-ci/evergreen/model"
 )
 
@@ -9,6 +11,7 @@ import (
 type mockRepoPoller struct {
 	project   *model.Project
 	revisions []model.Revision
+	tags      []model.GitTag
 
 	ConfigGets uint
 	nextError  error
@@ -66,3 +69,22 @@ func (d *mockRepoPoller) GetRecentRevisions(maxRevisionsToSearch int) ([]model.R
 	}
 	return d.revisions, nil
 }
+
+func (d *mockRepoPoller) GetTags(known map[string]bool) ([]model.GitTag, error) {
+	if d.nextError != nil {
+		return nil, d.clearError()
+	}
+	return d.tags, nil
+}
+
+func (d *mockRepoPoller) GetRevision(revision string) (model.Revision, error) {
+	if d.nextError != nil {
+		return model.Revision{}, d.clearError()
+	}
+	for _, r := range d.revisions {
+		if r.Revision == revision {
+			return r, nil
+		}
+	}
+	return model.Revision{Revision: revision, CreateTime: time.Now()}, nil
+}
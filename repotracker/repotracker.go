@@ -55,6 +55,27 @@ type RepoPoller interface {
 	// project - with the most recent revision appearing as the first element in
 	// the slice.
 	GetRecentRevisions(numNewRepoRevisionsToFetch int) ([]model.Revision, error)
+
+	// Fetches the tags in the repository, along with the commits that they
+	// point to. Pollers that list the newest tags first may stop at the first
+	// tag in known, since the tags after it have been seen before.
+	GetTags(known map[string]bool) ([]model.GitTag, error)
+	// Fetches the details of the given revision.
+	GetRevision(revision string) (model.Revision, error)
+}
+
+// NewRepoPoller returns the RepoPoller for the kind of repository the
+// project is in.
+func NewRepoPoller(settings *evergreen.Settings, projectRef *model.ProjectRef) (RepoPoller, error) {
+	switch projectRef.RepoKind {
+	case model.GithubRepoType, "":
+		return NewGithubRepositoryPoller(projectRef, settings.Credentials[githubCredentialsKey]), nil
+	case model.GitRepoType:
+		return NewGitRepositoryPoller(projectRef, settings.RepoTracker.MirrorDirectory), nil
+	default:
+		return nil, errors.Errorf("project '%s' has unknown repository kind '%s'",
+			projectRef.Identifier, projectRef.RepoKind)
+	}
 }
 
 type projectConfigError struct {
@@ -134,6 +155,11 @@ func (repoTracker *RepoTracker) FetchRevisions(numNewRepoRevisionsToFetch int) e
 		}
 	}
 
+	// versions for new tags don't hold up tracking the branch
+	if err = repoTracker.trackGitTags(); err != nil {
+		grip.Errorf("error tracking tags for repository %s: %+v", projectRef, err)
+	}
+
 	// fetch the most recent, non-ignored version version to activate
 	activateVersion, err := version.FindOne(version.ByMostRecentNonignored(projectIdentifier))
 	if err != nil {
@@ -310,9 +336,11 @@ func (repoTracker *RepoTracker) StoreRevisions(revisions []model.Revision) (newe
 		}
 		v.Config = string(projectYamlBytes)
 
-		// "Ignore" a version if all changes are to ignored files
-		if len(project.Ignore) > 0 {
-			filenames, err := repoTracker.GetChangedFiles(revision)
+		// "Ignore" a version if all changes are to ignored files, and skip
+		// the variants and tasks whose paths none of the changes match
+		var filenames []string
+		if len(project.Ignore) > 0 || project.HasPathFilters() {
+			filenames, err = repoTracker.GetChangedFiles(revision)
 			if err != nil {
 				return nil, errors.Wrap(err, "error checking GitHub for ignored files")
 			}
@@ -322,7 +350,7 @@ func (repoTracker *RepoTracker) StoreRevisions(revisions []model.Revision) (newe
 		}
 
 		// We rebind newestVersion each iteration, so the last binding will be the newest version
-		err = errors.Wrapf(createVersionItems(v, ref, project),
+		err = errors.Wrapf(createVersionItems(v, ref, project, filenames),
 			"Error creating version items for %s in project %s",
 			v.Id, ref.Identifier)
 		if err != nil {
@@ -384,11 +412,13 @@ func (repoTracker *RepoTracker) GetProjectConfig(revision string) (*model.Projec
 		return nil, err
 	}
 
-	// check if project config is valid
+	// check if project config is valid, and still works with the project's
+	// aliases
 	verrs, err := validator.CheckProjectSyntax(project)
 	if err != nil {
 		return nil, err
 	}
+	verrs = append(verrs, validator.CheckProjectAliases(project, projectRef.Aliases)...)
 	if len(verrs) != 0 {
 		// We have syntax errors in the project.
 		// Format them, as we need to store + display them to the user
@@ -445,17 +475,39 @@ func NewVersionFromRevision(ref *model.ProjectRef, rev model.Revision) (*version
 }
 
 // createVersionItems populates and stores all the tasks and builds for a version according to
-// the given project config.
-func createVersionItems(v *version.Version, ref *model.ProjectRef, project *model.Project) error {
+// the given project config. Tasks whose paths none of the changed files match are skipped.
+func createVersionItems(v *version.Version, ref *model.ProjectRef, project *model.Project,
+	changedFiles []string) error {
 	// generate all task Ids so that we can easily reference them for dependencies
 	taskIdTable := model.NewTaskIdTable(project, v)
 
+	var runPairs model.TVPairSet
+	if len(changedFiles) > 0 && project.HasPathFilters() {
+		pairs := model.TVPairSet{}
+		for _, buildvariant := range project.BuildVariants {
+			if buildvariant.Disabled {
+				continue
+			}
+			for _, t := range buildvariant.Tasks {
+				pairs = append(pairs, model.TVPair{Variant: buildvariant.Name, TaskName: t.Name})
+			}
+		}
+		runPairs, v.SkippedTasks = project.FilterByChangedFiles(pairs, changedFiles)
+		taskIdTable = model.NewPatchTaskIdTable(project, v, runPairs)
+	}
+
 	// create all builds for the version
 	for _, buildvariant := range project.BuildVariants {
 		if buildvariant.Disabled {
 			continue
 		}
-		buildId, err := model.CreateBuildFromVersion(project, v, taskIdTable, buildvariant.Name, false, nil)
+		var taskNames []string
+		if runPairs != nil {
+			if taskNames = runPairs.TaskNames(buildvariant.Name); len(taskNames) == 0 {
+				continue
+			}
+		}
+		buildId, err := model.CreateBuildFromVersion(project, v, taskIdTable, buildvariant.Name, false, taskNames)
 		if err != nil {
 			return errors.WithStack(err)
 		}
@@ -189,6 +189,17 @@ func TestStoreRepositoryRevisions(t *testing.T) {
 			})
 		})
 
+		Convey("Project aliases that select no tasks in the configuration should be warned about", func() {
+			repoTracker.ProjectRef.Aliases = []model.ProjectAlias{
+				{Alias: "stale", Variant: "no-such-variant", Tasks: []string{".*"}},
+			}
+			v, err := repoTracker.StoreRevisions(revisions)
+			So(err, ShouldBeNil)
+			So(v, ShouldNotBeNil)
+			So(len(v.BuildVariants), ShouldBeGreaterThan, 0)
+			So(v.Warnings, ShouldResemble, []string{"alias 'stale' does not select any tasks in the project"})
+		})
+
 		Convey("If there is an error other than a config error while fetching a config, we should fail hard",
 			func() {
 				unexpectedError := errors.New("Something terrible has happened!!")
@@ -466,3 +477,76 @@ func createTestProject(override1, override2 *int) *model.Project {
 		},
 	}
 }
+
+func TestTrackGitTags(t *testing.T) {
+	Convey("With a project that creates versions for release tags", t, func() {
+		d := distro.Distro{Id: "test-distro-one"}
+		So(d.Insert(), ShouldBeNil)
+		d.Id = "test-distro-two"
+		So(d.Insert(), ShouldBeNil)
+
+		revisions := []model.Revision{*createTestRevision("release1", time.Now())}
+		poller := NewMockRepoPoller(createTestProject(nil, nil), revisions)
+		poller.tags = []model.GitTag{{Name: "r1.0", Revision: "release1"}}
+		repoTracker := RepoTracker{
+			testConfig,
+			&model.ProjectRef{
+				Identifier:     "testproject",
+				GitTagVersions: []model.GitTagDefinition{{Pattern: `r[0-9]+\.[0-9]+`}},
+			},
+			poller,
+		}
+
+		Convey("the tags that exist when tracking starts should not create versions", func() {
+			So(repoTracker.trackGitTags(), ShouldBeNil)
+			versions, err := version.Find(version.ByMostRecentlyCreatedForRequester("testproject",
+				evergreen.GitTagRequester))
+			So(err, ShouldBeNil)
+			So(len(versions), ShouldEqual, 0)
+
+			Convey("but new matching tags should", func() {
+				poller.tags = append(poller.tags,
+					model.GitTag{Name: "r1.1", Revision: "release1"},
+					model.GitTag{Name: "wip", Revision: "release1"})
+				So(repoTracker.trackGitTags(), ShouldBeNil)
+				versions, err = version.Find(version.ByMostRecentlyCreatedForRequester("testproject",
+					evergreen.GitTagRequester))
+				So(err, ShouldBeNil)
+				So(len(versions), ShouldEqual, 1)
+				So(versions[0].GitTag, ShouldEqual, "r1.1")
+				So(versions[0].Revision, ShouldEqual, "release1")
+				So(versions[0].Expansions["triggered_by_git_tag"], ShouldEqual, "r1.1")
+				So(len(versions[0].BuildIds), ShouldBeGreaterThan, 0)
+
+				repository, err := model.FindRepository("testproject")
+				So(err, ShouldBeNil)
+				So(repository.GitTags, ShouldContain, "wip")
+
+				Convey("and only once", func() {
+					So(repoTracker.trackGitTags(), ShouldBeNil)
+					count, err := version.Count(version.ByMostRecentlyCreatedForRequester("testproject",
+						evergreen.GitTagRequester))
+					So(err, ShouldBeNil)
+					So(count, ShouldEqual, 1)
+				})
+			})
+		})
+
+		Reset(func() {
+			dropTestDB(t)
+		})
+	})
+}
+
+func TestGitTagVersionId(t *testing.T) {
+	Convey("When naming the version of a git tag", t, func() {
+		Convey("tags differing only in punctuation should get different versions", func() {
+			So(gitTagVersionId("proj", "v1.2-rc"), ShouldNotEqual, gitTagVersionId("proj", "v1.2.rc"))
+			So(gitTagVersionId("proj", "v1_2"), ShouldNotEqual, gitTagVersionId("proj", "v1.2"))
+		})
+		Convey("letters and digits should be kept", func() {
+			So(gitTagVersionId("my-proj", "r10"), ShouldEqual, "my_proj_tag_r10")
+			So(gitTagVersionId("proj", "v1.2-rc"), ShouldEqual, "proj_tag_v1_2e2_2drc")
+		})
+	})
+}
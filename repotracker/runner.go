@@ -21,6 +21,12 @@ const (
 	// thirdparty/github.go/getGithubRateLimit
 	githubAPILimitCeiling = 20
 	githubCredentialsKey  = "github"
+
+	// DefaultPollInterval is how often the repotracker polls every project
+	// when pushes are tracked through webhooks, to catch up on any pushes
+	// whose webhooks were missed.
+	DefaultPollInterval = 15 * time.Minute
+	pollRuntimeName     = "repotracker_poll"
 )
 
 func (r *Runner) Name() string {
@@ -32,6 +38,20 @@ func (r *Runner) Description() string {
 }
 
 func (r *Runner) Run(config *evergreen.Settings) error {
+	if config.RepoTracker.GithubWebhookSecret != "" {
+		polled, err := polledRecently(config)
+		if err != nil {
+			err = errors.Wrap(err, "Error finding last poll")
+			grip.Error(err)
+			return err
+		}
+		if polled {
+			grip.Debug("Skipping repository tracker poll, pushes are tracked through webhooks")
+			return errors.Wrap(model.SetProcessRuntimeCompleted(RunnerName, 0),
+				"Error updating process status")
+		}
+	}
+
 	status, err := thirdparty.GetGithubAPIStatus()
 	if err != nil {
 		errM := errors.Wrap(err, "contacting github")
@@ -92,37 +112,61 @@ func (r *Runner) Run(config *evergreen.Settings) error {
 		return err
 	}
 
+	fetchProjectRevisions(config, allProjects)
+
+	runtime := time.Since(startTime)
+	if err = model.SetProcessRuntimeCompleted(RunnerName, runtime); err != nil {
+		err = errors.Wrap(err, "Error updating process status")
+		grip.Error(err)
+		return err
+	}
+	if err = model.SetProcessRuntimeCompleted(pollRuntimeName, runtime); err != nil {
+		err = errors.Wrap(err, "Error updating poll status")
+		grip.Error(err)
+		return err
+	}
+	grip.Infof("Repository tracker took %s to run", runtime)
+	return nil
+}
+
+// polledRecently returns whether the repotracker polled every project within
+// the poll interval.
+func polledRecently(config *evergreen.Settings) (bool, error) {
+	interval := time.Duration(config.RepoTracker.PollIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	lastPoll, err := model.FindProcessRuntime(pollRuntimeName)
+	if err != nil {
+		return false, err
+	}
+	return lastPoll != nil && time.Since(lastPoll.FinishedAt) < interval, nil
+}
+
+// fetchProjectRevisions fetches the new revisions of each of the projects
+// concurrently. The caller must hold the repotracker's global lock.
+func fetchProjectRevisions(config *evergreen.Settings, projectRefs []model.ProjectRef) {
 	numNewRepoRevisionsToFetch := config.RepoTracker.NumNewRepoRevisionsToFetch
 	if numNewRepoRevisionsToFetch <= 0 {
 		numNewRepoRevisionsToFetch = DefaultNumNewRepoRevisionsToFetch
 	}
 
 	var wg sync.WaitGroup
-	wg.Add(len(allProjects))
-	for _, projectRef := range allProjects {
+	wg.Add(len(projectRefs))
+	for _, projectRef := range projectRefs {
 		go func(projectRef model.ProjectRef) {
 			defer wg.Done()
 
-			tracker := &RepoTracker{
-				config,
-				&projectRef,
-				NewGithubRepositoryPoller(&projectRef, config.Credentials["github"]),
-			}
-
-			err = tracker.FetchRevisions(numNewRepoRevisionsToFetch)
+			poller, err := NewRepoPoller(config, &projectRef)
 			if err != nil {
 				grip.Errorln("Error fetching revisions:", err)
+				return
+			}
+			tracker := &RepoTracker{config, &projectRef, poller}
+			if err = tracker.FetchRevisions(numNewRepoRevisionsToFetch); err != nil {
+				grip.Errorln("Error fetching revisions:", err)
 			}
 		}(projectRef)
 	}
 	wg.Wait()
-
-	runtime := time.Since(startTime)
-	if err = model.SetProcessRuntimeCompleted(RunnerName, runtime); err != nil {
-		err = errors.Wrap(err, "Error updating process status")
-		grip.Error(err)
-		return err
-	}
-	grip.Infof("Repository tracker took %s to run", runtime)
-	return nil
 }
@@ -0,0 +1,185 @@
+package repotracker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// VersionMetadata describes a version that is created from a project's
+// config at a revision, rather than for a new commit to the project.
+type VersionMetadata struct {
+	Revision  model.Revision
+	Requester string
+	// ConfigFile overrides the path of the project's config file.
+	ConfigFile string
+	// Alias selects the variants and tasks that the version runs. Without
+	// one, every variant runs.
+	Alias string
+	// Expansions are added to the expansions of every task in the version.
+	Expansions map[string]string
+
+	// the upstream task or build of a version created by a trigger
+	TriggerID      string
+	TriggerType    string
+	TriggerProject string
+	TriggerVersion string
+
+	// the tag in the project's repository that a version was created for
+	GitTag string
+}
+
+var (
+	// build and task ids include the time that their version was created to
+	// the second, so versions of a project must not be created in the same
+	// second
+	lastCreatedMu sync.Mutex
+	lastCreated   = map[string]time.Time{}
+)
+
+// versionCreateTime returns a creation time for a new version of the
+// project, waiting until the second after the last version was created if
+// needed.
+func versionCreateTime(projectId string) time.Time {
+	lastCreatedMu.Lock()
+	defer lastCreatedMu.Unlock()
+	last := lastCreated[projectId]
+	if wait := last.Truncate(time.Second).Add(time.Second).Sub(time.Now()); wait > 0 {
+		time.Sleep(wait)
+	}
+	now := time.Now()
+	lastCreated[projectId] = now
+	return now
+}
+
+// CreateVersionFromConfig creates a version with the given id of the project
+// at the metadata's revision, and activates its builds. If the config has
+// errors, a stub version recording them is stored instead.
+func CreateVersionFromConfig(settings *evergreen.Settings, ref *model.ProjectRef, id string,
+	metadata VersionMetadata) (*version.Version, error) {
+	configRef := *ref
+	if metadata.ConfigFile != "" {
+		configRef.RemotePath = metadata.ConfigFile
+		configRef.LocalConfig = ""
+	}
+	poller, err := NewRepoPoller(settings, &configRef)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	tracker := &RepoTracker{Settings: settings, ProjectRef: &configRef, RepoPoller: poller}
+	return tracker.createVersionFromConfig(ref, id, metadata)
+}
+
+// createVersionFromConfig creates a version of the project using the
+// tracker's config file, which may not be the project's own.
+func (tracker *RepoTracker) createVersionFromConfig(ref *model.ProjectRef, id string,
+	metadata VersionMetadata) (*version.Version, error) {
+	rev := metadata.Revision
+	v := &version.Version{
+		Author:         rev.Author,
+		AuthorEmail:    rev.AuthorEmail,
+		Branch:         ref.Branch,
+		CreateTime:     versionCreateTime(ref.Identifier),
+		Id:             id,
+		Identifier:     ref.Identifier,
+		Message:        rev.RevisionMessage,
+		Owner:          ref.Owner,
+		RemotePath:     tracker.ProjectRef.RemotePath,
+		Repo:           ref.Repo,
+		RepoKind:       ref.RepoKind,
+		Requester:      metadata.Requester,
+		Revision:       rev.Revision,
+		Status:         evergreen.VersionCreated,
+		Expansions:     metadata.Expansions,
+		TriggerID:      metadata.TriggerID,
+		TriggerType:    metadata.TriggerType,
+		TriggerProject: metadata.TriggerProject,
+		TriggerVersion: metadata.TriggerVersion,
+		GitTag:         metadata.GitTag,
+	}
+
+	project, err := tracker.GetProjectConfig(rev.Revision)
+	if err != nil {
+		projectError, isProjectError := err.(projectConfigError)
+		if !isProjectError {
+			return nil, errors.Wrapf(err, "error getting config for project %s at %s",
+				ref.Identifier, rev.Revision)
+		}
+		v.Warnings = projectError.Warnings
+		if len(projectError.Errors) > 0 {
+			v.Errors = projectError.Errors
+			if err = v.Insert(); err != nil {
+				return nil, errors.Wrapf(err, "error storing stub version %s", v.Id)
+			}
+			return v, nil
+		}
+	}
+
+	projectYamlBytes, err := yaml.Marshal(project)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshaling config")
+	}
+	v.Config = string(projectYamlBytes)
+
+	var pairs model.TVPairSet
+	if metadata.Alias != "" {
+		aliases := ref.FindAliases(metadata.Alias)
+		if len(aliases) == 0 {
+			return nil, errors.Errorf("project %s has no alias '%s'", ref.Identifier, metadata.Alias)
+		}
+		if pairs, err = project.AliasPairs(aliases); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if len(pairs) == 0 {
+			return nil, errors.Errorf("alias '%s' of project %s selects no tasks",
+				metadata.Alias, ref.Identifier)
+		}
+	} else {
+		for _, bv := range project.BuildVariants {
+			if bv.Disabled {
+				continue
+			}
+			for _, t := range bv.Tasks {
+				pairs = append(pairs, model.TVPair{Variant: bv.Name, TaskName: t.Name})
+			}
+		}
+	}
+
+	taskIdTable := model.NewPatchTaskIdTable(project, v, pairs)
+	for _, bv := range project.BuildVariants {
+		taskNames := pairs.TaskNames(bv.Name)
+		if len(taskNames) == 0 {
+			continue
+		}
+		var buildId string
+		buildId, err = model.CreateBuildFromVersion(project, v, taskIdTable, bv.Name, true, taskNames)
+		if err != nil {
+			break
+		}
+		v.BuildIds = append(v.BuildIds, buildId)
+		v.BuildVariants = append(v.BuildVariants, version.BuildStatus{
+			BuildVariant: bv.Name,
+			Activated:    true,
+			ActivateAt:   v.CreateTime,
+			BuildId:      buildId,
+		})
+	}
+	if err == nil {
+		err = v.Insert()
+	}
+	if err != nil {
+		for _, buildStatus := range v.BuildVariants {
+			if buildErr := model.DeleteBuild(buildStatus.BuildId); buildErr != nil {
+				grip.Errorf("deleting build %s: %+v", buildStatus.BuildId, buildErr)
+			}
+		}
+		return nil, errors.Wrapf(err, "error creating version %s", v.Id)
+	}
+	return v, nil
+}
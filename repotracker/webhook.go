@@ -0,0 +1,135 @@
+package repotracker
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+const (
+	// GithubEventHeader is the header GitHub sends the type of a webhook
+	// event in.
+	GithubEventHeader = "X-GitHub-Event"
+	// GithubSignatureHeader is the header GitHub sends the HMAC signature of
+	// a webhook payload in.
+	GithubSignatureHeader = "X-Hub-Signature"
+
+	GithubPushEvent = "push"
+	GithubPingEvent = "ping"
+
+	githubSignaturePrefix = "sha1="
+	githubBranchRefPrefix = "refs/heads/"
+)
+
+// PushEvent is the part of a GitHub push webhook payload that the
+// repotracker needs.
+type PushEvent struct {
+	Ref     string `json:"ref"`
+	Before  string `json:"before"`
+	After   string `json:"after"`
+	Created bool   `json:"created"`
+	Deleted bool   `json:"deleted"`
+
+	Repository struct {
+		Name     string `json:"name"`
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// Owner returns the owner of the repository that was pushed to.
+func (e *PushEvent) Owner() string {
+	if i := strings.Index(e.Repository.FullName, "/"); i > 0 {
+		return e.Repository.FullName[:i]
+	}
+	return ""
+}
+
+// Branch returns the branch that was pushed to, or an empty string if the
+// push was to something other than a branch, such as a tag.
+func (e *PushEvent) Branch() string {
+	if !strings.HasPrefix(e.Ref, githubBranchRefPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(e.Ref, githubBranchRefPrefix)
+}
+
+// ValidateGithubSignature checks that the signature GitHub sent with a
+// webhook payload was computed with the given secret.
+func ValidateGithubSignature(secret string, payload []byte, signature string) error {
+	if !strings.HasPrefix(signature, githubSignaturePrefix) {
+		return errors.New("missing or malformed webhook signature")
+	}
+	sent, err := hex.DecodeString(strings.TrimPrefix(signature, githubSignaturePrefix))
+	if err != nil {
+		return errors.Wrap(err, "malformed webhook signature")
+	}
+
+	mac := hmac.New(sha1.New, []byte(secret))
+	_, err = mac.Write(payload)
+	if err != nil {
+		return errors.Wrap(err, "error computing webhook signature")
+	}
+	if !hmac.Equal(sent, mac.Sum(nil)) {
+		return errors.New("webhook signature does not match")
+	}
+	return nil
+}
+
+// ParsePushEvent parses a GitHub push webhook payload.
+func ParsePushEvent(payload []byte) (*PushEvent, error) {
+	event := &PushEvent{}
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, errors.Wrap(err, "error parsing push event")
+	}
+	if event.Ref == "" || event.Repository.Name == "" || event.Owner() == "" {
+		return nil, errors.New("push event is missing its ref or repository")
+	}
+	return event, nil
+}
+
+// TrackPushEvent fetches the new revisions of every tracked project that
+// builds the branch that was pushed to. Pushes that delete a branch, or that
+// are not to a branch, are ignored.
+func TrackPushEvent(settings *evergreen.Settings, event *PushEvent) error {
+	branch := event.Branch()
+	if branch == "" || event.Deleted {
+		grip.Debugf("Ignoring push to %s of %s", event.Ref, event.Repository.FullName)
+		return nil
+	}
+
+	projectRefs, err := model.FindTrackedProjectRefsByRepoAndBranch(event.Owner(),
+		event.Repository.Name, branch)
+	if err != nil {
+		return errors.Wrap(err, "Error finding projects for push event")
+	}
+	if len(projectRefs) == 0 {
+		grip.Debugf("No tracked projects build branch %s of %s", branch, event.Repository.FullName)
+		return nil
+	}
+
+	lockAcquired, err := db.WaitTillAcquireGlobalLock(RunnerName, db.LockTimeout)
+	if err != nil {
+		return errors.Wrap(err, "Error acquiring global lock")
+	}
+	if !lockAcquired {
+		return errors.New("Timed out acquiring global lock")
+	}
+	defer func() {
+		if err = db.ReleaseGlobalLock(RunnerName); err != nil {
+			grip.Errorln("Error releasing global lock:", err)
+		}
+	}()
+
+	grip.Infof("Tracking push of %s to branch %s of %s for %d projects",
+		event.After, branch, event.Repository.FullName, len(projectRefs))
+	fetchProjectRevisions(settings, projectRefs)
+	return nil
+}
@@ -0,0 +1,119 @@
+package repotracker
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const (
+	testWebhookSecret = "hook-secret"
+	// the signature GitHub sends with testdata/github_push_event.json when
+	// the webhook's secret is testWebhookSecret
+	testPushEventSignature = "sha1=3394d38ce23b0ab7423608377270e459f768193e"
+)
+
+func readWebhookPayload(t *testing.T, name string) []byte {
+	payload, err := ioutil.ReadFile(filepath.Join(testutil.GetDirectoryOfFile(), "testdata", name))
+	testutil.HandleTestingErr(err, t, "error reading %s", name)
+	return payload
+}
+
+func TestValidateGithubSignature(t *testing.T) {
+	Convey("With a recorded push event payload", t, func() {
+		payload := readWebhookPayload(t, "github_push_event.json")
+
+		Convey("the signature GitHub sent should be valid", func() {
+			So(ValidateGithubSignature(testWebhookSecret, payload, testPushEventSignature), ShouldBeNil)
+		})
+
+		Convey("a signature computed with another secret should be rejected", func() {
+			So(ValidateGithubSignature("other-secret", payload, testPushEventSignature), ShouldNotBeNil)
+		})
+
+		Convey("a modified payload should be rejected", func() {
+			modified := append([]byte{}, payload...)
+			modified[0] = ' '
+			So(ValidateGithubSignature(testWebhookSecret, modified, testPushEventSignature), ShouldNotBeNil)
+		})
+
+		Convey("missing and malformed signatures should be rejected", func() {
+			So(ValidateGithubSignature(testWebhookSecret, payload, ""), ShouldNotBeNil)
+			So(ValidateGithubSignature(testWebhookSecret, payload, "3394d38ce23b0ab7"), ShouldNotBeNil)
+			So(ValidateGithubSignature(testWebhookSecret, payload, "sha1=nothex"), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestParsePushEvent(t *testing.T) {
+	Convey("When parsing recorded push event payloads", t, func() {
+		Convey("a push to a branch should name the repository and branch", func() {
+			event, err := ParsePushEvent(readWebhookPayload(t, "github_push_event.json"))
+			So(err, ShouldBeNil)
+			So(event.Owner(), ShouldEqual, "evergreen-ci")
+			So(event.Repository.Name, ShouldEqual, "render")
+			So(event.Branch(), ShouldEqual, "master")
+			So(event.After, ShouldEqual, "9b1c4d6a28e0bd2b0f5e1e34ac8c1ff4a6e0b7d3")
+			So(event.Deleted, ShouldBeFalse)
+		})
+
+		Convey("a push of a tag should not name a branch", func() {
+			event, err := ParsePushEvent(readWebhookPayload(t, "github_push_tag_event.json"))
+			So(err, ShouldBeNil)
+			So(event.Branch(), ShouldEqual, "")
+		})
+
+		Convey("a branch deletion should be marked as deleted", func() {
+			event, err := ParsePushEvent(readWebhookPayload(t, "github_push_delete_event.json"))
+			So(err, ShouldBeNil)
+			So(event.Branch(), ShouldEqual, "feature")
+			So(event.Deleted, ShouldBeTrue)
+		})
+
+		Convey("a payload without a repository should be rejected", func() {
+			_, err := ParsePushEvent([]byte(`{"ref": "refs/heads/master"}`))
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestTrackPushEvent(t *testing.T) {
+	Convey("With a project that tracks another branch", t, func() {
+		testutil.HandleTestingErr(db.Clear(model.ProjectRefCollection), t,
+			"error clearing project refs")
+		other := &model.ProjectRef{
+			Identifier: "render-stable",
+			Owner:      "evergreen-ci",
+			Repo:       "render",
+			Branch:     "stable",
+			Enabled:    true,
+			Tracked:    true,
+		}
+		So(other.Insert(), ShouldBeNil)
+
+		Convey("pushes to other branches, tags and deletions should be ignored", func() {
+			for _, name := range []string{"github_push_event.json",
+				"github_push_tag_event.json", "github_push_delete_event.json"} {
+				event, err := ParsePushEvent(readWebhookPayload(t, name))
+				So(err, ShouldBeNil)
+				So(TrackPushEvent(testConfig, event), ShouldBeNil)
+			}
+		})
+
+		Convey("only tracked projects of the pushed branch should be found", func() {
+			projectRefs, err := model.FindTrackedProjectRefsByRepoAndBranch("evergreen-ci", "render", "stable")
+			So(err, ShouldBeNil)
+			So(len(projectRefs), ShouldEqual, 1)
+			So(projectRefs[0].Identifier, ShouldEqual, "render-stable")
+
+			projectRefs, err = model.FindTrackedProjectRefsByRepoAndBranch("evergreen-ci", "render", "master")
+			So(err, ShouldBeNil)
+			So(len(projectRefs), ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,162 @@
+// Package retention deletes the logs, events and artifact metadata of tasks
+// once they are older than their project's retention policy allows.
+package retention
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/logsearch"
+	"github.com/evergreen-ci/evergreen/logstore"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/model/event"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// DefaultBatchSize is used when the settings do not set a batch size.
+const DefaultBatchSize = 500
+
+// Stats counts the data that was deleted, or would be deleted in a dry run.
+type Stats struct {
+	Tasks     int
+	TaskLogs  int
+	TestLogs  int
+	Events    int
+	Artifacts int
+}
+
+func (s *Stats) add(other Stats) {
+	s.Tasks += other.Tasks
+	s.TaskLogs += other.TaskLogs
+	s.TestLogs += other.TestLogs
+	s.Events += other.Events
+	s.Artifacts += other.Artifacts
+}
+
+// Reaper deletes the data of tasks that are past their retention period.
+type Reaper struct {
+	Logs      *logstore.Logs
+	BatchSize int
+	// DryRun counts the data that would be deleted without deleting it.
+	DryRun bool
+}
+
+// ExpireProject deletes the data of up to BatchSize mainline and BatchSize
+// patch tasks of the project that finished longer ago than the policy keeps
+// them. Tasks of every version that is not a patch, such as versions made by
+// triggers, periodic builds and git tags, are kept as long as mainline ones.
+// Tasks of pinned versions are kept. An error expiring one task is returned
+// after the rest have been expired.
+func (r *Reaper) ExpireProject(ref *model.ProjectRef, policy evergreen.RetentionPolicy) (Stats, error) {
+	stats := Stats{}
+	if policy.MainlineDays <= 0 && policy.PatchDays <= 0 {
+		return stats, nil
+	}
+
+	pinned, err := version.Find(version.ByPinnedInProject(ref.Identifier).WithFields(version.IdKey))
+	if err != nil {
+		return stats, errors.Wrapf(err, "error finding pinned versions of project %s", ref.Identifier)
+	}
+	pinnedIds := []string{}
+	for _, v := range pinned {
+		pinnedIds = append(pinnedIds, v.Id)
+	}
+
+	catcher := grip.NewCatcher()
+	for _, class := range []struct {
+		requester interface{}
+		days      int
+	}{
+		{bson.M{"$ne": evergreen.PatchVersionRequester}, policy.MainlineDays},
+		{evergreen.PatchVersionRequester, policy.PatchDays},
+	} {
+		if class.days <= 0 {
+			continue
+		}
+		tasks, err := task.Find(db.Query(bson.M{
+			task.ProjectKey:     ref.Identifier,
+			task.RequesterKey:   class.requester,
+			task.StatusKey:      bson.M{"$in": evergreen.CompletedStatuses},
+			task.FinishTimeKey:  bson.M{"$lt": time.Now().Add(-time.Duration(class.days) * 24 * time.Hour)},
+			task.VersionKey:     bson.M{"$nin": pinnedIds},
+			task.DataExpiredKey: bson.M{"$ne": true},
+		}).WithFields(task.IdKey, task.ExecutionKey).Limit(r.BatchSize))
+		if err != nil {
+			catcher.Add(errors.Wrapf(err, "error finding expired tasks of project %s", ref.Identifier))
+			continue
+		}
+
+		// a task whose data cannot be expired does not stop the others
+		for i := range tasks {
+			taskStats, err := r.ExpireTask(&tasks[i])
+			stats.add(taskStats)
+			catcher.Add(errors.Wrapf(err, "error expiring data of task %s", tasks[i].Id))
+		}
+	}
+	return stats, catcher.Resolve()
+}
+
+// ExpireTask deletes the logs, events and artifact metadata of every
+// execution of a task, and marks its data as expired.
+func (r *Reaper) ExpireTask(t *task.Task) (Stats, error) {
+	stats := Stats{Tasks: 1}
+	var err error
+
+	stats.TaskLogs, stats.TestLogs, err = r.Logs.RemoveTask(t, r.DryRun)
+	if err != nil {
+		return stats, err
+	}
+
+	for _, coll := range []string{event.AllLogCollection, event.TaskLogCollection} {
+		count, err := event.Count(coll, event.ByResourceId(t.Id))
+		if err != nil {
+			return stats, errors.Wrapf(err, "error counting events in %s", coll)
+		}
+		stats.Events += count
+		if !r.DryRun && count > 0 {
+			if err = event.Remove(coll, event.ByResourceId(t.Id)); err != nil {
+				return stats, errors.Wrapf(err, "error removing events in %s", coll)
+			}
+		}
+	}
+
+	// entries with files that are still to be deleted from s3 are left for
+	// the artifact collector, which needs them to find the files
+	if stats.Artifacts, err = artifact.Count(artifact.ByTaskIdWithoutPendingExpiration(t.Id)); err != nil {
+		return stats, errors.Wrap(err, "error counting artifacts")
+	}
+
+	if r.DryRun {
+		return stats, nil
+	}
+	if stats.Artifacts > 0 {
+		if err = artifact.Remove(artifact.ByTaskIdWithoutPendingExpiration(t.Id)); err != nil {
+			return stats, errors.Wrap(err, "error removing artifacts")
+		}
+	}
+	// search results would otherwise link to logs that no longer exist
+	if err = logsearch.RemoveTaskLines(t.Id, t.Execution); err != nil {
+		return stats, err
+	}
+	return stats, errors.WithStack(t.SetDataExpired())
+}
+
+// ExpireEvents deletes host, distro and scheduler events older than days, and
+// returns how many there were.
+func (r *Reaper) ExpireEvents(days int) (int, error) {
+	if days <= 0 {
+		return 0, nil
+	}
+	query := event.NonTaskEventsOlderThan(time.Now().Add(-time.Duration(days) * 24 * time.Hour))
+	count, err := event.Count(event.AllLogCollection, query)
+	if err != nil || r.DryRun || count == 0 {
+		return count, errors.WithStack(err)
+	}
+	return count, errors.WithStack(event.Remove(event.AllLogCollection, query))
+}
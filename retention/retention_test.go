@@ -0,0 +1,153 @@
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/logstore"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/model/event"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var testConfig = testutil.TestConfig()
+
+func init() {
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testConfig))
+}
+
+func cleanUpLogDB() error {
+	session, _, err := db.GetGlobalSessionFactory().GetSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	_, err = session.DB(model.TaskLogDB).C(model.TaskLogCollection).RemoveAll(bson.M{})
+	return err
+}
+
+func insertTaskData(t *task.Task) {
+	So(t.Insert(), ShouldBeNil)
+	msg := &model.LogMessage{Type: model.TaskLogPrefix, Severity: model.LogInfoPrefix, Message: "output"}
+	So(msg.Insert(t.Id, 0), ShouldBeNil)
+	So((&model.TestLog{Name: "test", Task: t.Id, Lines: []string{"passed"}}).Insert(), ShouldBeNil)
+	event.LogTaskFinished(t.Id, "host", evergreen.TaskSucceeded)
+	So(artifact.Entry{TaskId: t.Id, Files: []artifact.File{{Name: "file", Link: "link"}}}.Upsert(), ShouldBeNil)
+}
+
+func TestExpireProject(t *testing.T) {
+	Convey("With tasks of a project that finished a while ago", t, func() {
+		testutil.HandleTestingErr(db.ClearCollections(task.Collection, version.Collection, model.TestLogCollection,
+			event.AllLogCollection, event.TaskLogCollection, artifact.Collection, logstore.ArchiveCollection),
+			t, "error clearing collections")
+		testutil.HandleTestingErr(cleanUpLogDB(), t, "error clearing task logs")
+
+		So((&version.Version{Id: "pinned", Identifier: "proj", Pinned: true}).Insert(), ShouldBeNil)
+		old := time.Now().Add(-10 * 24 * time.Hour)
+		tasks := []*task.Task{
+			{Id: "mainline", Project: "proj", Version: "v1", Requester: evergreen.RepotrackerVersionRequester,
+				Status: evergreen.TaskSucceeded, FinishTime: old},
+			{Id: "patch", Project: "proj", Version: "v2", Requester: evergreen.PatchVersionRequester,
+				Status: evergreen.TaskFailed, FinishTime: old},
+			{Id: "kept", Project: "proj", Version: "pinned", Requester: evergreen.RepotrackerVersionRequester,
+				Status: evergreen.TaskSucceeded, FinishTime: old},
+		}
+		for _, t := range tasks {
+			insertTaskData(t)
+		}
+
+		ref := &model.ProjectRef{Identifier: "proj"}
+		policy := evergreen.RetentionPolicy{MainlineDays: 30, PatchDays: 7}
+		reaper := &Reaper{Logs: &logstore.Logs{Database: logstore.MongoStorage{}}, BatchSize: 10}
+
+		Convey("a dry run should count the expired data without deleting it", func() {
+			reaper.DryRun = true
+			stats, err := reaper.ExpireProject(ref, policy)
+			So(err, ShouldBeNil)
+			So(stats, ShouldResemble, Stats{Tasks: 1, TaskLogs: 1, TestLogs: 1, Events: 1, Artifacts: 1})
+
+			count, err := model.CountTestLogsByTask("patch", 0)
+			So(err, ShouldBeNil)
+			So(count, ShouldEqual, 1)
+		})
+
+		Convey("only the data past the policy should be deleted", func() {
+			stats, err := reaper.ExpireProject(ref, policy)
+			So(err, ShouldBeNil)
+			So(stats.Tasks, ShouldEqual, 1)
+
+			count, err := model.CountTaskLogs("patch", 0)
+			So(err, ShouldBeNil)
+			So(count, ShouldEqual, 0)
+			count, err = artifact.Count(artifact.ByTaskId("patch"))
+			So(err, ShouldBeNil)
+			So(count, ShouldEqual, 0)
+			expired, err := task.FindOne(task.ById("patch"))
+			So(err, ShouldBeNil)
+			So(expired.DataExpired, ShouldBeTrue)
+
+			count, err = model.CountTaskLogs("mainline", 0)
+			So(err, ShouldBeNil)
+			So(count, ShouldEqual, 1)
+
+			Convey("and pinned versions should keep their data", func() {
+				stats, err = reaper.ExpireProject(ref, evergreen.RetentionPolicy{MainlineDays: 1})
+				So(err, ShouldBeNil)
+				So(stats.Tasks, ShouldEqual, 1)
+
+				count, err = model.CountTaskLogs("kept", 0)
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 1)
+				count, err = event.Count(event.AllLogCollection, event.ByResourceId("kept"))
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 1)
+			})
+		})
+
+		Convey("versions that are not patches should be expired as mainline versions", func() {
+			for _, requester := range []string{evergreen.TriggerRequester,
+				evergreen.PeriodicBuildRequester, evergreen.GitTagRequester} {
+				insertTaskData(&task.Task{Id: requester, Project: "proj", Version: "v3", Requester: requester,
+					Status: evergreen.TaskSucceeded, FinishTime: old})
+			}
+
+			stats, err := reaper.ExpireProject(ref, evergreen.RetentionPolicy{MainlineDays: 1})
+			So(err, ShouldBeNil)
+			So(stats.Tasks, ShouldEqual, 4)
+			for _, id := range []string{"mainline", evergreen.TriggerRequester,
+				evergreen.PeriodicBuildRequester, evergreen.GitTagRequester} {
+				expired, err := task.FindOne(task.ById(id))
+				So(err, ShouldBeNil)
+				So(expired.DataExpired, ShouldBeTrue)
+			}
+			kept, err := task.FindOne(task.ById("patch"))
+			So(err, ShouldBeNil)
+			So(kept.DataExpired, ShouldBeFalse)
+		})
+
+		Convey("a task whose data cannot be expired should not stop the others", func() {
+			// the log was archived to a backend that is not configured
+			broken := &task.Task{Id: "broken", Project: "proj", Version: "v1", Requester: evergreen.RepotrackerVersionRequester,
+				Status: evergreen.TaskSucceeded, FinishTime: old.Add(-time.Hour)}
+			insertTaskData(broken)
+			So((&logstore.Archive{Key: "broken", TaskId: "broken", Backend: "elsewhere"}).Upsert(), ShouldBeNil)
+
+			stats, err := reaper.ExpireProject(ref, evergreen.RetentionPolicy{MainlineDays: 1})
+			So(err, ShouldNotBeNil)
+			So(stats.Tasks, ShouldEqual, 2)
+			notExpired, err := task.FindOne(task.ById("broken"))
+			So(err, ShouldBeNil)
+			So(notExpired.DataExpired, ShouldBeFalse)
+			expired, err := task.FindOne(task.ById("mainline"))
+			So(err, ShouldBeNil)
+			So(expired.DataExpired, ShouldBeTrue)
+		})
+	})
+}
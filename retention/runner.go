@@ -0,0 +1,105 @@
+package retention
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/logstore"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+)
+
+// Runner deletes data that is older than its project's retention policy.
+type Runner struct{}
+
+const (
+	RunnerName  = "retention"
+	Description = "delete logs, events and artifact metadata past their project's retention policy"
+)
+
+func (r *Runner) Name() string {
+	return RunnerName
+}
+
+func (r *Runner) Description() string {
+	return Description
+}
+
+func (r *Runner) Run(config *evergreen.Settings) error {
+	startTime := time.Now()
+	grip.Infoln("Starting retention process at time", startTime)
+
+	if err := expireData(config); err != nil {
+		err = errors.Wrap(err, "error expiring data")
+		grip.Error(err)
+		return err
+	}
+
+	runtime := time.Since(startTime)
+	if err := model.SetProcessRuntimeCompleted(RunnerName, runtime); err != nil {
+		grip.Errorf("error updating process status: %+v", err)
+	}
+	grip.Infof("Retention process took %s to run", runtime)
+	return nil
+}
+
+func expireData(config *evergreen.Settings) error {
+	logs, err := logstore.New(config)
+	if err != nil {
+		return errors.Wrap(err, "error configuring log storage")
+	}
+	reaper := &Reaper{
+		Logs:      logs,
+		BatchSize: config.Retention.BatchSize,
+		DryRun:    config.Retention.DryRun,
+	}
+	if reaper.BatchSize == 0 {
+		reaper.BatchSize = DefaultBatchSize
+	}
+
+	refs, err := model.FindAllProjectRefs()
+	if err != nil {
+		return errors.Wrap(err, "error finding projects")
+	}
+
+	total := Stats{}
+	for i := range refs {
+		policy := refs[i].GetRetentionPolicy(config.Retention.Default)
+		stats, err := reaper.ExpireProject(&refs[i], policy)
+		total.add(stats)
+		logStats(refs[i].Identifier, reaper.DryRun, stats)
+		if err != nil {
+			// the next run will retry the project
+			grip.Errorf("error expiring data of project %s: %+v", refs[i].Identifier, err)
+		}
+	}
+
+	events, err := reaper.ExpireEvents(config.Retention.EventLogDays)
+	if err != nil {
+		return errors.Wrap(err, "error expiring events")
+	}
+	total.Events += events
+	logStats("", reaper.DryRun, total)
+	return nil
+}
+
+func logStats(project string, dryRun bool, stats Stats) {
+	if stats == (Stats{}) && project != "" {
+		return
+	}
+	fields := message.Fields{
+		"runner":    RunnerName,
+		"dry_run":   dryRun,
+		"tasks":     stats.Tasks,
+		"task_logs": stats.TaskLogs,
+		"test_logs": stats.TestLogs,
+		"events":    stats.Events,
+		"artifacts": stats.Artifacts,
+	}
+	if project != "" {
+		fields["project"] = project
+	}
+	grip.Info(fields)
+}
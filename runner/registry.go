@@ -6,12 +6,20 @@ package runner
 import (
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/alerts"
+	"github.com/evergreen-ci/evergreen/artifactgc"
+	"github.com/evergreen-ci/evergreen/commitqueue"
+	"github.com/evergreen-ci/evergreen/githubstatus"
 	"github.com/evergreen-ci/evergreen/hostinit"
+	"github.com/evergreen-ci/evergreen/logsearch"
+	"github.com/evergreen-ci/evergreen/logstore"
 	"github.com/evergreen-ci/evergreen/monitor"
 	"github.com/evergreen-ci/evergreen/notify"
+	"github.com/evergreen-ci/evergreen/periodicbuilds"
 	"github.com/evergreen-ci/evergreen/repotracker"
+	"github.com/evergreen-ci/evergreen/retention"
 	"github.com/evergreen-ci/evergreen/scheduler"
 	"github.com/evergreen-ci/evergreen/taskrunner"
+	"github.com/evergreen-ci/evergreen/trigger"
 )
 
 // ProcessRunner wraps a basic Run method that allows various processes in Evergreen
@@ -35,5 +43,13 @@ var (
 		&taskrunner.Runner{},
 		&alerts.QueueProcessor{},
 		&scheduler.Runner{},
+		&logstore.Runner{},
+		&logsearch.Runner{},
+		&retention.Runner{},
+		&artifactgc.Runner{},
+		&githubstatus.Runner{},
+		&commitqueue.Runner{},
+		&trigger.Runner{},
+		&periodicbuilds.Runner{},
 	}
 )
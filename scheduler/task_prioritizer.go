@@ -171,8 +171,8 @@ func (self *CmpBasedTaskComparator) Swap(i, j int) {
 }
 
 // Split the tasks, based on the requester field.
-// Returns two slices - the tasks requested by the repotracker, and the tasks
-// requested in a patch.
+// Returns two slices - the tasks requested by the repotracker, by another
+// project's trigger, or by a periodic build, and the tasks requested in a patch.
 func (self *CmpBasedTaskComparator) splitTasksByRequester(
 	allTasks []task.Task) *CmpBasedTaskQueues {
 
@@ -184,7 +184,10 @@ func (self *CmpBasedTaskComparator) splitTasksByRequester(
 		switch {
 		case task.Priority > evergreen.MaxTaskPriority:
 			priorityTasks = append(priorityTasks, task)
-		case task.Requester == evergreen.RepotrackerVersionRequester:
+		case task.Requester == evergreen.RepotrackerVersionRequester,
+			task.Requester == evergreen.TriggerRequester,
+			task.Requester == evergreen.PeriodicBuildRequester,
+			task.Requester == evergreen.GitTagRequester:
 			repoTrackerTasks = append(repoTrackerTasks, task)
 		case task.Requester == evergreen.PatchVersionRequester:
 			patchTasks = append(patchTasks, task)
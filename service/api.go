@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/codegangsta/negroni"
 	"github.com/evergreen-ci/evergreen"
@@ -77,6 +78,10 @@ func NewAPIServer(settings *evergreen.Settings, plugins []plugin.APIPlugin) (*AP
 		return nil, errors.WithStack(err)
 	}
 
+	if err = model.EnsureTaskLogIndexes(); err != nil {
+		return nil, errors.Wrap(err, "problem creating task log indexes")
+	}
+
 	as := &APIServer{
 		Render:       render.New(render.Options{}),
 		UserManager:  authManager,
@@ -335,6 +340,16 @@ func (as *APIServer) AttachResults(w http.ResponseWriter, r *http.Request) {
 		as.LoggedError(w, r, http.StatusBadRequest, err)
 		return
 	}
+	// flag the results of quarantined tests, so that their failures don't
+	// fail the task
+	projectRef, err := model.FindOneProjectRef(t.Project)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if projectRef != nil {
+		projectRef.MarkQuarantinedResults(t.DisplayName, results.Results)
+	}
 	// set test result of task
 	if err := t.SetResults(results.Results); err != nil {
 		as.LoggedError(w, r, http.StatusInternalServerError, err)
@@ -379,6 +394,36 @@ func (as *APIServer) AttachFiles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// files expire relative to when the task started, so that posting the
+	// same files again yields the same entries
+	start := t.StartTime
+	if util.IsZeroTime(start) {
+		start = time.Now()
+	}
+	for i, file := range entry.Files {
+		if !util.SliceContains(artifact.ValidExpirationClasses, file.ExpirationClass) {
+			message := fmt.Sprintf("Invalid expiration class '%v' for file %v", file.ExpirationClass, file.Name)
+			as.WriteJSON(w, http.StatusBadRequest, message)
+			return
+		}
+		if file.ExpirationClass != artifact.ExpireNever && file.Bucket != "" && file.FileKey != "" {
+			owned, err := ownsS3File(t.Id, file.Bucket, file.FileKey)
+			if err != nil {
+				as.LoggedError(w, r, http.StatusInternalServerError, err)
+				return
+			}
+			if !owned {
+				grip.Warningf("keeping %s/%s of task %s forever: the task did not put it",
+					file.Bucket, file.FileKey, t.Id)
+				entry.Files[i].ExpirationClass = artifact.ExpireNever
+				file.ExpirationClass = artifact.ExpireNever
+			}
+		}
+		entry.Files[i].ExpireAt = artifact.ExpirationTime(file.ExpirationClass, start,
+			as.Settings.Artifacts.ExpirationDays)
+		entry.Files[i].Expired = false
+	}
+
 	if err := entry.Upsert(); err != nil {
 		message := fmt.Sprintf("Error updating artifact file info for task %v: %v", t.Id, err)
 		grip.Error(message)
@@ -388,6 +433,24 @@ func (as *APIServer) AttachFiles(w http.ResponseWriter, r *http.Request) {
 	as.WriteJSON(w, http.StatusOK, fmt.Sprintf("Artifact files for task %v successfully attached", t.Id))
 }
 
+// ownsS3File returns whether a task may set when an s3 object is deleted: the
+// task must have recorded putting the object, or the object must be one that
+// other files still link to, whose deletion waits for them anyway.
+func ownsS3File(taskId, bucket, key string) (bool, error) {
+	upload, err := artifact.FindUpload(bucket, key)
+	if err != nil {
+		return false, errors.Wrap(err, "error finding the upload of the file")
+	}
+	if upload != nil && upload.TaskId == taskId {
+		return true, nil
+	}
+	links, err := artifact.Count(artifact.ByLiveS3File(bucket, key, time.Now()))
+	if err != nil {
+		return false, errors.Wrap(err, "error counting links to the file")
+	}
+	return links > 0, nil
+}
+
 // AppendTaskLog appends the received logs to the task's internal logs.
 func (as *APIServer) AppendTaskLog(w http.ResponseWriter, r *http.Request) {
 	t := MustHaveTask(r)
@@ -400,7 +463,15 @@ func (as *APIServer) AppendTaskLog(w http.ResponseWriter, r *http.Request) {
 	taskLog.TaskId = t.Id
 	taskLog.Execution = t.Execution
 
-	if err := taskLog.Insert(); err != nil {
+	// chunks of a log stream that the agent sends here after the stream
+	// failed may already have been stored over the stream
+	var err error
+	if taskLog.Sequence > 0 {
+		_, err = taskLog.InsertChunk()
+	} else {
+		err = taskLog.Insert()
+	}
+	if err != nil {
 		as.LoggedError(w, r, http.StatusInternalServerError, err)
 		return
 	}
@@ -686,7 +757,8 @@ func (as *APIServer) listVariants(w http.ResponseWriter, r *http.Request) {
 }
 
 // validateProjectConfig returns a slice containing a list of any errors
-// found in validating the given project configuration
+// found in validating the given project configuration. If a project is
+// given, its aliases are checked against the configuration too.
 func (as *APIServer) validateProjectConfig(w http.ResponseWriter, r *http.Request) {
 	body := util.NewRequestReader(r)
 	defer body.Close()
@@ -709,6 +781,18 @@ func (as *APIServer) validateProjectConfig(w http.ResponseWriter, r *http.Reques
 		return
 	}
 	semanticErrs := validator.CheckProjectSemantics(project)
+	if id := r.URL.Query().Get("project"); id != "" {
+		projectRef, err := model.FindOneProjectRef(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if projectRef == nil {
+			http.Error(w, fmt.Sprintf("project '%v' not found", id), http.StatusNotFound)
+			return
+		}
+		semanticErrs = append(semanticErrs, validator.CheckProjectAliases(project, projectRef.Aliases)...)
+	}
 	if len(syntaxErrs)+len(semanticErrs) != 0 {
 		as.WriteJSON(w, http.StatusBadRequest, append(syntaxErrs, semanticErrs...))
 		return
@@ -782,6 +866,7 @@ func (as *APIServer) Handler() (http.Handler, error) {
 
 	r := root.PathPrefix("/api/2/").Subrouter()
 	r.HandleFunc("/", home)
+	r.HandleFunc("/hooks/github", as.githubHook).Methods("POST")
 
 	apiRootOld := root.PathPrefix("/api/").Subrouter()
 
@@ -812,6 +897,12 @@ func (as *APIServer) Handler() (http.Handler, error) {
 	patchPath.HandleFunc("/{patchId:\\w+}/modules", requireUser(as.deletePatchModule, nil)).Methods("DELETE")
 	patchPath.HandleFunc("/{patchId:\\w+}/modules", requireUser(as.updatePatchModule, nil)).Methods("POST")
 
+	// Commit queues
+	commitQueuePath := apiRootOld.PathPrefix("/commit_queue/{projectId}").Subrouter()
+	commitQueuePath.HandleFunc("", requireUser(as.listCommitQueue, nil)).Methods("GET")
+	commitQueuePath.HandleFunc("", requireUser(as.enqueuePatch, nil)).Methods("PUT")
+	commitQueuePath.HandleFunc("/{patchId:\\w+}", requireUser(as.dequeuePatch, nil)).Methods("DELETE")
+
 	// Routes for operating on existing spawn hosts - get info, terminate, etc.
 	spawn := apiRootOld.PathPrefix("/spawn/").Subrouter()
 	spawn.HandleFunc("/{instance_id:[\\w_\\-\\@]+}/", requireUser(as.hostInfo, nil)).Methods("GET")
@@ -849,6 +940,7 @@ func (as *APIServer) Handler() (http.Handler, error) {
 	taskRouter.HandleFunc("/new_start", as.checkTask(true, as.checkHost(as.StartTask))).Methods("POST")
 
 	taskRouter.HandleFunc("/log", as.checkTask(true, as.checkHost(as.AppendTaskLog))).Methods("POST")
+	taskRouter.HandleFunc("/log_stream", as.checkTask(true, as.checkHost(as.StreamTaskLog))).Methods("GET")
 	taskRouter.HandleFunc("/heartbeat", as.checkTask(true, as.checkHost(as.Heartbeat))).Methods("POST")
 	taskRouter.HandleFunc("/results", as.checkTask(true, as.checkHost(as.AttachResults))).Methods("POST")
 	taskRouter.HandleFunc("/test_logs", as.checkTask(true, as.checkHost(as.AttachTestLog))).Methods("POST")
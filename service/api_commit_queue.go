@@ -0,0 +1,236 @@
+package service
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/auth"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/commitqueue"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+// RestCommitQueueItem is a commit queue item along with the patch it
+// queues and the state of the patch that tests it.
+type RestCommitQueueItem struct {
+	commitqueue.Item
+	Description string `json:"description"`
+	Author      string `json:"author"`
+	TestStatus  string `json:"test_status,omitempty"`
+	TestVersion string `json:"test_version,omitempty"`
+}
+
+// commitQueueError is an error whose HTTP status is known.
+type commitQueueError struct {
+	status int
+	err    error
+}
+
+func (e *commitQueueError) Error() string {
+	return e.err.Error()
+}
+
+func newCommitQueueError(status int, err error) *commitQueueError {
+	return &commitQueueError{status: status, err: err}
+}
+
+// getCommitQueueItems returns the items in the project's commit queue, in
+// the order they will be merged.
+func getCommitQueueItems(projectId string) ([]RestCommitQueueItem, error) {
+	cq, err := commitqueue.FindOne(commitqueue.ById(projectId))
+	if err != nil {
+		return nil, errors.Wrap(err, "error finding commit queue")
+	}
+	items := []RestCommitQueueItem{}
+	if cq == nil {
+		return items, nil
+	}
+
+	for _, item := range cq.Queue {
+		info := RestCommitQueueItem{Item: item}
+		if patch.IsValidId(item.PatchId) {
+			p, err := patch.FindOne(patch.ById(patch.NewId(item.PatchId)).
+				WithFields(patch.DescriptionKey, patch.AuthorKey))
+			if err != nil {
+				return nil, errors.Wrapf(err, "error finding patch %s", item.PatchId)
+			}
+			if p != nil {
+				info.Description, info.Author = p.Description, p.Author
+			}
+		}
+		if patch.IsValidId(item.TestPatchId) {
+			testPatch, err := patch.FindOne(patch.ById(patch.NewId(item.TestPatchId)).
+				WithFields(patch.StatusKey, patch.VersionKey))
+			if err != nil {
+				return nil, errors.Wrapf(err, "error finding patch %s", item.TestPatchId)
+			}
+			if testPatch != nil {
+				info.TestStatus, info.TestVersion = testPatch.Status, testPatch.Version
+			}
+		}
+		items = append(items, info)
+	}
+	return items, nil
+}
+
+// isProjectAdmin returns whether the user administers the project.
+func isProjectAdmin(settings *evergreen.Settings, dbUser *user.DBUser, projectRef *model.ProjectRef) bool {
+	return auth.IsSuperUser(settings.SuperUsers, dbUser) || isAdmin(dbUser, projectRef)
+}
+
+// enqueuePatch adds the patch to the back of its project's commit queue,
+// returning its position. Since a merge pushes to the project's branch with
+// the server's GitHub credentials, only project admins can queue patches. A
+// pull request's patch is only merged once it has been approved on GitHub.
+func enqueuePatch(settings *evergreen.Settings, dbUser *user.DBUser, projectId, patchId string) (int, error) {
+	if !patch.IsValidId(patchId) {
+		return -1, newCommitQueueError(http.StatusBadRequest, errors.Errorf("'%s' is not a valid patch id", patchId))
+	}
+	p, err := patch.FindOne(patch.ById(patch.NewId(patchId)))
+	if err != nil {
+		return -1, errors.Wrapf(err, "error finding patch %s", patchId)
+	}
+	if p == nil || p.Project != projectId {
+		return -1, newCommitQueueError(http.StatusNotFound,
+			errors.Errorf("project '%s' has no patch %s", projectId, patchId))
+	}
+
+	projectRef, err := model.FindOneProjectRef(projectId)
+	if err != nil {
+		return -1, errors.WithStack(err)
+	}
+	if projectRef == nil || !projectRef.CommitQueueEnabled {
+		return -1, newCommitQueueError(http.StatusBadRequest,
+			errors.Errorf("project '%s' does not have a commit queue", projectId))
+	}
+	if !isProjectAdmin(settings, dbUser, projectRef) {
+		return -1, newCommitQueueError(http.StatusUnauthorized,
+			errors.New("only a project admin can queue a patch"))
+	}
+
+	if len(p.VariantsTasks) == 0 && (len(p.BuildVariants) == 0 || len(p.Tasks) == 0) {
+		return -1, newCommitQueueError(http.StatusBadRequest, errors.New("patch has no tasks to test"))
+	}
+	for _, modulePatch := range p.Patches {
+		if modulePatch.ModuleName != "" && len(modulePatch.PatchSet.Summary) > 0 {
+			return -1, newCommitQueueError(http.StatusBadRequest,
+				errors.Errorf("changes to module '%s' cannot be merged", modulePatch.ModuleName))
+		}
+	}
+
+	position, err := commitqueue.Enqueue(projectId, commitqueue.Item{
+		PatchId:     patchId,
+		EnqueuedBy:  dbUser.Id,
+		EnqueueTime: time.Now(),
+	})
+	if err != nil {
+		return -1, newCommitQueueError(http.StatusBadRequest, err)
+	}
+	return position, nil
+}
+
+// dequeuePatch removes the patch from the project's commit queue, and
+// cancels its test patch. The user who queued the patch, its author, and
+// project admins can remove it.
+func dequeuePatch(settings *evergreen.Settings, dbUser *user.DBUser, projectId, patchId string) error {
+	cq, err := commitqueue.FindOne(commitqueue.ById(projectId))
+	if err != nil {
+		return errors.Wrap(err, "error finding commit queue")
+	}
+	position := -1
+	if cq != nil {
+		position = cq.Position(patchId)
+	}
+	if position < 0 {
+		return newCommitQueueError(http.StatusNotFound,
+			errors.Errorf("patch %s is not in the commit queue of project '%s'", patchId, projectId))
+	}
+	item := cq.Queue[position]
+
+	projectRef, err := model.FindOneProjectRef(projectId)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	authorized := item.EnqueuedBy == dbUser.Id ||
+		(projectRef != nil && isProjectAdmin(settings, dbUser, projectRef))
+	if !authorized && patch.IsValidId(patchId) {
+		p, err := patch.FindOne(patch.ById(patch.NewId(patchId)).WithFields(patch.AuthorKey))
+		if err != nil {
+			return errors.Wrapf(err, "error finding patch %s", patchId)
+		}
+		authorized = p != nil && p.Author == dbUser.Id
+	}
+	if !authorized {
+		return newCommitQueueError(http.StatusUnauthorized,
+			errors.New("only the patch's author, the user who queued it, or a project admin can remove it"))
+	}
+
+	if _, err = commitqueue.Remove(projectId, patchId); err != nil {
+		return err
+	}
+	if !patch.IsValidId(item.TestPatchId) {
+		return nil
+	}
+	testPatch, err := patch.FindOne(patch.ById(patch.NewId(item.TestPatchId)))
+	if err != nil {
+		return errors.Wrapf(err, "error finding test patch %s", item.TestPatchId)
+	}
+	if testPatch != nil && testPatch.Status != evergreen.PatchSucceeded && testPatch.Status != evergreen.PatchFailed {
+		return errors.WithStack(model.CancelPatch(testPatch, dbUser.Id))
+	}
+	return nil
+}
+
+// commitQueueErrorStatus returns the HTTP status to reply to a failed commit
+// queue request with.
+func commitQueueErrorStatus(err error) int {
+	if cqErr, ok := err.(*commitQueueError); ok {
+		return cqErr.status
+	}
+	return http.StatusInternalServerError
+}
+
+func (as *APIServer) listCommitQueue(w http.ResponseWriter, r *http.Request) {
+	projectId := mux.Vars(r)["projectId"]
+	items, err := getCommitQueueItems(projectId)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	as.WriteJSON(w, http.StatusOK, items)
+}
+
+func (as *APIServer) enqueuePatch(w http.ResponseWriter, r *http.Request) {
+	dbUser := MustHaveUser(r)
+	data := struct {
+		PatchId string `json:"patch_id"`
+	}{}
+	if err := util.ReadJSONInto(util.NewRequestReader(r), &data); err != nil {
+		as.LoggedError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	position, err := enqueuePatch(&as.Settings, dbUser, mux.Vars(r)["projectId"], data.PatchId)
+	if err != nil {
+		as.LoggedError(w, r, commitQueueErrorStatus(err), err)
+		return
+	}
+	as.WriteJSON(w, http.StatusOK, struct {
+		Position int `json:"position"`
+	}{position})
+}
+
+func (as *APIServer) dequeuePatch(w http.ResponseWriter, r *http.Request) {
+	dbUser := MustHaveUser(r)
+	vars := mux.Vars(r)
+	if err := dequeuePatch(&as.Settings, dbUser, vars["projectId"], vars["patchId"]); err != nil {
+		as.LoggedError(w, r, commitQueueErrorStatus(err), err)
+		return
+	}
+	as.WriteJSON(w, http.StatusOK, "patch removed from commit queue")
+}
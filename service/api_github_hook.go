@@ -0,0 +1,67 @@
+package service
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/evergreen-ci/evergreen/repotracker"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// githubHook receives GitHub webhooks. A push to a branch that a project
+// tracks fetches that project's new revisions, without waiting for the
+// repotracker to poll, and pull requests against the branch are patched.
+// The payload must be signed with the configured webhook secret.
+func (as *APIServer) githubHook(w http.ResponseWriter, r *http.Request) {
+	secret := as.Settings.RepoTracker.GithubWebhookSecret
+	if secret == "" {
+		http.Error(w, "GitHub webhooks are not configured", http.StatusNotFound)
+		return
+	}
+
+	body := util.NewRequestReader(r)
+	defer body.Close()
+	payload, err := ioutil.ReadAll(body)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusBadRequest, errors.Wrap(err, "error reading webhook payload"))
+		return
+	}
+	if err = repotracker.ValidateGithubSignature(secret, payload,
+		r.Header.Get(repotracker.GithubSignatureHeader)); err != nil {
+		as.LoggedError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	switch event := r.Header.Get(repotracker.GithubEventHeader); event {
+	case repotracker.GithubPingEvent:
+		as.WriteJSON(w, http.StatusOK, struct {
+			Message string `json:"message"`
+		}{"pong"})
+	case repotracker.GithubPushEvent:
+		pushEvent, err := repotracker.ParsePushEvent(payload)
+		if err != nil {
+			as.LoggedError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		// GitHub gives up on webhooks that take more than a few seconds,
+		// so the revisions are fetched after replying
+		settings := as.Settings
+		go func() {
+			if err := repotracker.TrackPushEvent(&settings, pushEvent); err != nil {
+				grip.Errorf("Error tracking push to %s of %s: %+v", pushEvent.Ref,
+					pushEvent.Repository.FullName, err)
+			}
+		}()
+		w.WriteHeader(http.StatusAccepted)
+	case GithubPullRequestEvent:
+		as.pullRequestHook(w, r, payload)
+	case GithubIssueCommentEvent:
+		as.issueCommentHook(w, r, payload)
+	default:
+		grip.Debugf("Ignoring GitHub '%s' webhook", event)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
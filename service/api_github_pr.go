@@ -0,0 +1,251 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+const (
+	// GithubPullRequestEvent and GithubIssueCommentEvent are the webhook
+	// events for changes to pull requests and comments on them.
+	GithubPullRequestEvent  = "pull_request"
+	GithubIssueCommentEvent = "issue_comment"
+
+	// prRetryComment is the pull request comment that tests a pull request
+	// again.
+	prRetryComment = "evergreen retry"
+
+	githubOpenState = "open"
+)
+
+// testedPullRequestActions are the pull request webhook actions that test
+// the pull request's head.
+var testedPullRequestActions = map[string]bool{
+	"opened":      true,
+	"synchronize": true,
+	"reopened":    true,
+}
+
+// isTrustedPullRequest returns whether a pull request may be tested without
+// being retried by an authorized user, which is when its author has write
+// access to the repository or its branch is in the repository itself. Pull
+// requests from anyone else's forks would otherwise run untrusted code with
+// the project's credentials.
+func isTrustedPullRequest(pr *thirdparty.GithubPullRequest) bool {
+	return thirdparty.IsGithubWriteAssociation(pr.AuthorAssociation) ||
+		(pr.Head.Repo.FullName != "" && pr.Head.Repo.FullName == pr.Base.Repo.FullName)
+}
+
+// pullRequestHook tests pull requests from trusted authors that are opened,
+// reopened or pushed to. Other pull requests wait for an authorized user to
+// retry them.
+func (as *APIServer) pullRequestHook(w http.ResponseWriter, r *http.Request, payload []byte) {
+	event := &thirdparty.GithubPullRequestEvent{}
+	if err := json.Unmarshal(payload, event); err != nil {
+		as.LoggedError(w, r, http.StatusBadRequest, errors.Wrap(err, "error parsing pull request event"))
+		return
+	}
+	if !testedPullRequestActions[event.Action] {
+		grip.Debugf("Ignoring '%s' action on pull request #%d of %s", event.Action,
+			event.Number, event.PullRequest.Base.Repo.FullName)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if !isTrustedPullRequest(&event.PullRequest) {
+		grip.Infof("Not testing pull request #%d of %s by '%s' until an authorized user retries it",
+			event.Number, event.PullRequest.Base.Repo.FullName, event.PullRequest.User.Login)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	as.createPullRequestPatchesAsync(&event.PullRequest)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// issueCommentHook tests a pull request again when a user with write access
+// to its repository comments "evergreen retry" on it.
+func (as *APIServer) issueCommentHook(w http.ResponseWriter, r *http.Request, payload []byte) {
+	event := &thirdparty.GithubIssueCommentEvent{}
+	if err := json.Unmarshal(payload, event); err != nil {
+		as.LoggedError(w, r, http.StatusBadRequest, errors.Wrap(err, "error parsing issue comment event"))
+		return
+	}
+	if event.Action != "created" || event.Issue.PullRequest == nil ||
+		strings.ToLower(strings.TrimSpace(event.Comment.Body)) != prRetryComment {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	pr, err := thirdparty.GetGithubPullRequest(as.Settings.Credentials["github"],
+		event.Repository.Owner.Login, event.Repository.Name, event.Issue.Number)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError, errors.Wrapf(err,
+			"error getting pull request #%d of %s", event.Issue.Number, event.Repository.FullName))
+		return
+	}
+	if pr.State != githubOpenState {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if !thirdparty.IsGithubWriteAssociation(event.Comment.AuthorAssociation) {
+		grip.Infof("Ignoring retry of pull request #%d of %s by unauthorized user '%s'",
+			pr.Number, event.Repository.FullName, event.Comment.User.Login)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	as.createPullRequestPatchesAsync(pr)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// createPullRequestPatchesAsync creates the pull request's patches after
+// the webhook has been replied to, since GitHub gives up on webhooks that
+// take more than a few seconds.
+func (as *APIServer) createPullRequestPatchesAsync(pr *thirdparty.GithubPullRequest) {
+	settings := as.Settings
+	go func() {
+		if err := createPullRequestPatches(&settings, pr); err != nil {
+			grip.Errorf("Error creating patches for pull request #%d of %s: %+v",
+				pr.Number, pr.Base.Repo.FullName, err)
+		}
+	}()
+}
+
+// createPullRequestPatches creates and finalizes a patch of the pull
+// request's changes for every project that builds the branch the pull
+// request is against and declares pr_aliases. Older patches of the pull
+// request are cancelled.
+func createPullRequestPatches(settings *evergreen.Settings, pr *thirdparty.GithubPullRequest) error {
+	owner, repo := pr.Base.Repo.Owner.Login, pr.Base.Repo.Name
+	projectRefs, err := model.FindTrackedProjectRefsByRepoAndBranch(owner, repo, pr.Base.Ref)
+	if err != nil {
+		return errors.Wrap(err, "error finding projects for pull request")
+	}
+
+	// only projects that have opted in to testing pull requests are patched
+	testedProjectRefs := []model.ProjectRef{}
+	for _, projectRef := range projectRefs {
+		project, err := model.FindProject("", &projectRef)
+		if err != nil {
+			grip.Errorf("Error loading project '%s': %+v", projectRef.Identifier, err)
+			continue
+		}
+		if len(project.PRAliases) > 0 {
+			testedProjectRefs = append(testedProjectRefs, projectRef)
+		}
+	}
+	if len(testedProjectRefs) == 0 {
+		grip.Debugf("No projects test pull requests against branch %s of %s", pr.Base.Ref,
+			pr.Base.Repo.FullName)
+		return nil
+	}
+
+	oauthToken := settings.Credentials["github"]
+	mergeBase, err := thirdparty.GetGithubMergeBase(oauthToken, owner, repo, pr.Base.Ref, pr.Head.SHA)
+	if err != nil {
+		return errors.Wrap(err, "error finding merge base")
+	}
+	diff, err := thirdparty.GetGithubPullRequestDiff(oauthToken, owner, repo, pr.Number)
+	if err != nil {
+		return errors.Wrap(err, "error getting pull request diff")
+	}
+	if len(diff) > patch.SizeLimit {
+		return errors.Errorf("pull request diff is larger than the patch size limit")
+	}
+
+	catcher := grip.NewCatcher()
+	for i := range testedProjectRefs {
+		catcher.Add(errors.Wrapf(createPullRequestPatch(settings, &testedProjectRefs[i], pr, mergeBase, diff),
+			"error creating patch for project '%s'", testedProjectRefs[i].Identifier))
+	}
+	return catcher.Resolve()
+}
+
+// createPullRequestPatch creates and finalizes a patch of the pull request's
+// diff for the project, running the variants and tasks that the project's
+// pr_aliases select.
+func createPullRequestPatch(settings *evergreen.Settings, projectRef *model.ProjectRef,
+	pr *thirdparty.GithubPullRequest, mergeBase, diff string) error {
+	request := &PatchAPIRequest{
+		ProjectId:    projectRef.Identifier,
+		Githash:      mergeBase,
+		PatchContent: diff,
+		Description: fmt.Sprintf("%s pull request #%d by %s: %s", pr.Base.Repo.FullName,
+			pr.Number, pr.User.Login, pr.Title),
+	}
+	project, patchDoc, err := request.CreatePatch(false, settings.Credentials["github"],
+		&user.DBUser{Id: evergreen.GithubPatchUser}, settings)
+	if err != nil {
+		return errors.Wrap(err, "invalid patch")
+	}
+
+	pairs, err := project.PRAliasPairs()
+	if err != nil {
+		return errors.Wrap(err, "invalid pr_aliases")
+	}
+	if len(pairs) == 0 {
+		grip.Infof("The pr_aliases of project '%s' select no tasks for pull request #%d",
+			projectRef.Identifier, pr.Number)
+		return nil
+	}
+	pairs = model.IncludePatchDependencies(project, pairs)
+	patchDoc.SyncVariantsTasks(model.TVPairsToVariantTasks(pairs))
+	patchDoc.GithubPatchData = &patch.GithubPatch{
+		PRNumber:   pr.Number,
+		BaseOwner:  pr.Base.Repo.Owner.Login,
+		BaseRepo:   pr.Base.Repo.Name,
+		BaseBranch: pr.Base.Ref,
+		HeadOwner:  pr.Head.Repo.Owner.Login,
+		HeadRepo:   pr.Head.Repo.Name,
+		HeadHash:   pr.Head.SHA,
+		Author:     pr.User.Login,
+	}
+
+	if err = cancelPullRequestPatches(projectRef.Identifier, patchDoc.GithubPatchData); err != nil {
+		return err
+	}
+	if err = patchDoc.Insert(); err != nil {
+		return errors.Wrap(err, "error inserting patch")
+	}
+	if _, err = model.FinalizePatch(patchDoc, settings); err != nil {
+		return errors.Wrap(err, "error finalizing patch")
+	}
+	grip.Infof("Created patch %s of pull request #%d of %s for project '%s'", patchDoc.Id.Hex(),
+		pr.Number, pr.Base.Repo.FullName, projectRef.Identifier)
+	return nil
+}
+
+// cancelPullRequestPatches cancels the project's unfinished patches of the
+// pull request, which a newer patch supersedes. Their statuses are no longer
+// posted.
+func cancelPullRequestPatches(projectId string, data *patch.GithubPatch) error {
+	patches, err := patch.Find(patch.ByGithubPR(projectId, data.BaseOwner, data.BaseRepo, data.PRNumber))
+	if err != nil {
+		return errors.Wrap(err, "error finding older patches of pull request")
+	}
+	for i := range patches {
+		p := &patches[i]
+		if p.Status == evergreen.PatchSucceeded || p.Status == evergreen.PatchFailed {
+			continue
+		}
+		if err = model.CancelPatch(p, evergreen.GithubPatchUser); err != nil {
+			return errors.Wrapf(err, "error cancelling patch %s", p.Id.Hex())
+		}
+		if p.Version != "" {
+			if err = p.SetGithubStatuses(p.GithubPatchData.Statuses, true); err != nil {
+				return errors.Wrapf(err, "error finishing statuses of patch %s", p.Id.Hex())
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIsTrustedPullRequest(t *testing.T) {
+	Convey("With a pull request against a repository", t, func() {
+		pr := &thirdparty.GithubPullRequest{Number: 1}
+		pr.Base.Repo.FullName = "evergreen-ci/evergreen"
+		pr.Head.Repo.FullName = "someone/evergreen"
+
+		Convey("a fork by a user with write access should be trusted", func() {
+			for _, association := range []string{"OWNER", "MEMBER", "COLLABORATOR"} {
+				pr.AuthorAssociation = association
+				So(isTrustedPullRequest(pr), ShouldBeTrue)
+			}
+		})
+
+		Convey("a fork by anyone else should not be trusted", func() {
+			for _, association := range []string{"CONTRIBUTOR", "FIRST_TIME_CONTRIBUTOR", "NONE", ""} {
+				pr.AuthorAssociation = association
+				So(isTrustedPullRequest(pr), ShouldBeFalse)
+			}
+		})
+
+		Convey("a branch of the repository itself should be trusted", func() {
+			pr.AuthorAssociation = "NONE"
+			pr.Head.Repo.FullName = pr.Base.Repo.FullName
+			So(isTrustedPullRequest(pr), ShouldBeTrue)
+		})
+
+		Convey("a pull request whose fork was deleted should not be trusted", func() {
+			pr.AuthorAssociation = "NONE"
+			pr.Head.Repo.FullName = ""
+			So(isTrustedPullRequest(pr), ShouldBeFalse)
+		})
+	})
+}
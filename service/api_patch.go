@@ -38,6 +38,20 @@ type PatchAPIRequest struct {
 	BuildVariants []string
 	Tasks         []string
 	Description   string
+	// Alias names the project alias that selects the patch's variants and
+	// tasks, instead of BuildVariants and Tasks.
+	Alias string
+	// Modules are the changes to the project's modules that are submitted
+	// along with the patch.
+	Modules []PatchAPIModule
+}
+
+// PatchAPIModule is the changes to one of a project's modules, against the
+// module's base commit.
+type PatchAPIModule struct {
+	Module  string `json:"module"`
+	Patch   string `json:"patch"`
+	Githash string `json:"githash"`
 }
 
 func getSummaries(patchContent string) ([]patch.Summary, error) {
@@ -97,7 +111,7 @@ func (pr *PatchAPIRequest) CreatePatch(finalize bool, oauthToken string,
 		return nil, nil, err
 	}
 
-	if finalize && (len(pr.BuildVariants) == 0 || pr.BuildVariants[0] == "") {
+	if finalize && pr.Alias == "" && (len(pr.BuildVariants) == 0 || pr.BuildVariants[0] == "") {
 		return nil, nil, errors.New("no buildvariants specified")
 	}
 
@@ -115,6 +129,7 @@ func (pr *PatchAPIRequest) CreatePatch(finalize bool, oauthToken string,
 		Status:        evergreen.PatchCreated,
 		BuildVariants: pr.BuildVariants,
 		Tasks:         pr.Tasks,
+		Alias:         pr.Alias,
 		Patches: []patch.ModulePatch{
 			{
 				ModuleName: "",
@@ -156,11 +171,46 @@ func (pr *PatchAPIRequest) CreatePatch(finalize bool, oauthToken string,
 		}
 	}
 
+	// an alias selects the variants and tasks in place of the request's
+	if pr.Alias != "" {
+		var pairs []model.TVPair
+		pairs, err = project.PatchAliasPairs(projectRef, pr.Alias)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "could not resolve alias '%v'", pr.Alias)
+		}
+		if len(pairs) == 0 {
+			return nil, nil, errors.Errorf("alias '%v' selects no tasks", pr.Alias)
+		}
+		patchDoc.SyncVariantsTasks(model.TVPairsToVariantTasks(pairs))
+	}
+
+	modulePatches := make([]patch.ModulePatch, 0, len(pr.Modules))
+	seenModules := map[string]bool{}
+	for _, m := range pr.Modules {
+		if seenModules[m.Module] {
+			return nil, nil, errors.Errorf("changes to module %v were submitted more than once", m.Module)
+		}
+		seenModules[m.Module] = true
+		var modulePatch *patch.ModulePatch
+		modulePatch, err = pr.createModulePatch(project, m, oauthToken)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid changes to module %v", m.Module)
+		}
+		modulePatches = append(modulePatches, *modulePatch)
+	}
+
 	// write the patch content into a GridFS file under a new ObjectId after validating.
 	err = db.WriteGridFile(patch.GridFSPrefix, patchFileId, strings.NewReader(pr.PatchContent))
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to write patch file to db")
 	}
+	for i, m := range pr.Modules {
+		err = db.WriteGridFile(patch.GridFSPrefix, modulePatches[i].PatchSet.PatchFileId, strings.NewReader(m.Patch))
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to write patch file for module %v to db", m.Module)
+		}
+	}
+	patchDoc.Patches = append(patchDoc.Patches, modulePatches...)
 
 	// add the project config
 	projectYamlBytes, err := yaml.Marshal(project)
@@ -168,8 +218,13 @@ func (pr *PatchAPIRequest) CreatePatch(finalize bool, oauthToken string,
 		return nil, nil, errors.Wrap(err, "error marshaling patched config")
 	}
 
-	// set the patch number based on patch author
-	patchDoc.PatchNumber, err = dbUser.IncPatchNumber()
+	// set the patch number based on patch author; pull requests have no user
+	// to count their patches
+	if dbUser.Id == evergreen.GithubPatchUser {
+		patchDoc.PatchNumber, err = patch.GetNewGithubPatchNumber()
+	} else {
+		patchDoc.PatchNumber, err = dbUser.IncPatchNumber()
+	}
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "error computing patch num")
 	}
@@ -180,6 +235,44 @@ func (pr *PatchAPIRequest) CreatePatch(finalize bool, oauthToken string,
 	return project, patchDoc, nil
 }
 
+// createModulePatch checks the changes to one of the project's modules and
+// returns the module's part of the patch, whose content is yet to be written.
+func (pr *PatchAPIRequest) createModulePatch(project *model.Project, m PatchAPIModule,
+	oauthToken string) (*patch.ModulePatch, error) {
+	module, err := project.GetModuleByName(m.Module)
+	if err != nil || module == nil {
+		return nil, errors.Errorf("no module named %v", m.Module)
+	}
+	if len(m.Githash) != 40 {
+		return nil, errors.New("invalid githash")
+	}
+	if len(m.Patch) > patch.SizeLimit {
+		return nil, errors.New("patch is too large")
+	}
+
+	repoOwner, repo := module.GetRepoOwnerAndName()
+	commitInfo, err := thirdparty.GetCommitEvent(oauthToken, repoOwner, repo, m.Githash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find base revision %v", m.Githash)
+	}
+	if commitInfo == nil {
+		return nil, errors.Errorf("commit hash %v doesn't seem to exist", m.Githash)
+	}
+
+	summaries, err := getSummaries(m.Patch)
+	if err != nil {
+		return nil, err
+	}
+	return &patch.ModulePatch{
+		ModuleName: m.Module,
+		Githash:    m.Githash,
+		PatchSet: patch.PatchSet{
+			PatchFileId: bson.NewObjectId().Hex(),
+			Summary:     summaries,
+		},
+	}, nil
+}
+
 // submitPatch creates the Patch document, adds the patched project config to it,
 // and saves the patches to GridFS to be retrieved
 func (as *APIServer) submitPatch(w http.ResponseWriter, r *http.Request) {
@@ -199,17 +292,20 @@ func (as *APIServer) submitPatch(w http.ResponseWriter, r *http.Request) {
 			PatchContent:  r.FormValue("patch"),
 			BuildVariants: strings.Split(r.FormValue("buildvariants"), ","),
 			Description:   r.FormValue("desc"),
+			Alias:         r.FormValue("alias"),
 		}
 		finalize = strings.ToLower(r.FormValue("finalize")) == "true"
 	} else {
 		data := struct {
-			Description string   `json:"desc"`
-			Project     string   `json:"project"`
-			Patch       string   `json:"patch"`
-			Githash     string   `json:"githash"`
-			Variants    string   `json:"buildvariants"`
-			Tasks       []string `json:"tasks"`
-			Finalize    bool     `json:"finalize"`
+			Description string           `json:"desc"`
+			Project     string           `json:"project"`
+			Patch       string           `json:"patch"`
+			Githash     string           `json:"githash"`
+			Variants    string           `json:"buildvariants"`
+			Tasks       []string         `json:"tasks"`
+			Finalize    bool             `json:"finalize"`
+			Alias       string           `json:"alias"`
+			Modules     []PatchAPIModule `json:"modules"`
 		}{}
 		if err := util.ReadJSONInto(util.NewRequestReader(r), &data); err != nil {
 			as.LoggedError(w, r, http.StatusBadRequest, err)
@@ -228,6 +324,8 @@ func (as *APIServer) submitPatch(w http.ResponseWriter, r *http.Request) {
 			BuildVariants: strings.Split(data.Variants, ","),
 			Tasks:         data.Tasks,
 			Description:   data.Description,
+			Alias:         data.Alias,
+			Modules:       data.Modules,
 		}
 	}
 
@@ -239,7 +337,7 @@ func (as *APIServer) submitPatch(w http.ResponseWriter, r *http.Request) {
 	}
 
 	//expand tasks and build variants and include dependencies
-	if len(patchDoc.BuildVariants) == 1 && patchDoc.BuildVariants[0] == "all" {
+	if patchDoc.Alias == "" && len(patchDoc.BuildVariants) == 1 && patchDoc.BuildVariants[0] == "all" {
 		patchDoc.BuildVariants = []string{}
 		for _, buildVariant := range project.BuildVariants {
 			if buildVariant.Disabled {
@@ -249,7 +347,7 @@ func (as *APIServer) submitPatch(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if len(patchDoc.Tasks) == 1 && patchDoc.Tasks[0] == "all" {
+	if patchDoc.Alias == "" && len(patchDoc.Tasks) == 1 && patchDoc.Tasks[0] == "all" {
 		patchDoc.Tasks = []string{}
 		for _, t := range project.Tasks {
 			if t.Patchable != nil && !(*t.Patchable) {
@@ -260,10 +358,14 @@ func (as *APIServer) submitPatch(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var pairs []model.TVPair
-	for _, v := range patchDoc.BuildVariants {
-		for _, t := range patchDoc.Tasks {
-			if project.FindTaskForVariant(t, v) != nil {
-				pairs = append(pairs, model.TVPair{v, t})
+	if patchDoc.Alias != "" {
+		pairs = model.VariantTasksToTVPairs(patchDoc.VariantsTasks)
+	} else {
+		for _, v := range patchDoc.BuildVariants {
+			for _, t := range patchDoc.Tasks {
+				if project.FindTaskForVariant(t, v) != nil {
+					pairs = append(pairs, model.TVPair{v, t})
+				}
 			}
 		}
 	}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+func (uis *UIServer) commitQueuePage(w http.ResponseWriter, r *http.Request) {
+	projCtx := MustHaveProjectContext(r)
+	if projCtx.ProjectRef == nil {
+		http.Error(w, "Project not found", http.StatusNotFound)
+		return
+	}
+
+	items, err := getCommitQueueItems(projCtx.ProjectRef.Identifier)
+	if err != nil {
+		uis.LoggedError(w, r, http.StatusInternalServerError,
+			errors.Wrap(err, "Error finding commit queue"))
+		return
+	}
+
+	uis.WriteHTML(w, http.StatusOK, struct {
+		ProjectData projectContext
+		User        *user.DBUser
+		Flashes     []interface{}
+		Enabled     bool
+		Items       []RestCommitQueueItem
+	}{projCtx, GetUser(r), []interface{}{}, projCtx.ProjectRef.CommitQueueEnabled, items},
+		"base", "commit_queue.html", "base_angular.html", "menu.html")
+}
+
+func (uis *UIServer) removeFromCommitQueue(w http.ResponseWriter, r *http.Request) {
+	projCtx := MustHaveProjectContext(r)
+	if projCtx.ProjectRef == nil {
+		http.Error(w, "Project not found", http.StatusNotFound)
+		return
+	}
+
+	err := dequeuePatch(&uis.Settings, MustHaveUser(r), projCtx.ProjectRef.Identifier, mux.Vars(r)["patch_id"])
+	if err != nil {
+		uis.LoggedError(w, r, commitQueueErrorStatus(err), err)
+		return
+	}
+	uis.WriteJSON(w, http.StatusOK, "patch removed from commit queue")
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/evergreen-ci/evergreen/model/user"
+)
+
+// logSearchPage renders the page for searching a project's task and test logs.
+func (uis *UIServer) logSearchPage(w http.ResponseWriter, r *http.Request) {
+	projCtx := MustHaveProjectContext(r)
+	if projCtx.Project == nil {
+		uis.ProjectNotFound(projCtx, w, r)
+		return
+	}
+
+	data := struct {
+		ProjectData projectContext
+		User        *user.DBUser
+		Project     UIProject
+	}{projCtx, GetUser(r), newUIProject(projCtx.Project)}
+
+	uis.WriteHTML(w, http.StatusOK, data, "base", "log_search.html", "base_angular.html", "menu.html")
+}
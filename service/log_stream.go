@@ -0,0 +1,196 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/gorilla/mux"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+	"golang.org/x/net/websocket"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// LogTailInterval is how often the UI's log tail checks for new log messages.
+var LogTailInterval = time.Second
+
+// StreamTaskLog receives the task's log messages from the agent over a
+// websocket. The agent sends the log in chunks with increasing sequence
+// numbers, and each chunk is acknowledged once it is stored. When the stream
+// is opened, the last stored sequence number is sent so that the agent
+// resends only the chunks the server does not have.
+func (as *APIServer) StreamTaskLog(w http.ResponseWriter, r *http.Request) {
+	t := MustHaveTask(r)
+
+	handler := func(ws *websocket.Conn) {
+		defer ws.Close()
+
+		last, err := model.FindLastTaskLogSequence(t.Id, t.Execution)
+		if err != nil {
+			grip.Errorf("problem finding log sequence for task %s: %+v", t.Id, err)
+			return
+		}
+		if err = websocket.JSON.Send(ws, apimodels.LogStreamAck{Sequence: last}); err != nil {
+			grip.Warningf("problem starting log stream for task %s: %+v", t.Id, err)
+			return
+		}
+
+		for {
+			taskLog := &model.TaskLog{}
+			if err = websocket.JSON.Receive(ws, taskLog); err != nil {
+				if err != io.EOF {
+					grip.Warningf("problem reading log stream for task %s: %+v", t.Id, err)
+				}
+				return
+			}
+
+			// chunks that were resent after a reconnect are already stored,
+			// and the agent may have sent others without the stream since
+			// the stream was opened
+			if taskLog.Sequence > last {
+				taskLog.Id = ""
+				taskLog.TaskId = t.Id
+				taskLog.Execution = t.Execution
+				taskLog.MessageCount = len(taskLog.Messages)
+				if last, err = taskLog.InsertChunk(); err != nil {
+					// the agent resends unacknowledged chunks when it reconnects
+					grip.Errorf("problem storing log for task %s: %+v", t.Id, err)
+					return
+				}
+			}
+
+			if err = websocket.JSON.Send(ws, apimodels.LogStreamAck{Sequence: last}); err != nil {
+				grip.Warningf("problem acknowledging log for task %s: %+v", t.Id, err)
+				return
+			}
+		}
+	}
+
+	// the agent authenticates with the task secret rather than an origin
+	websocket.Server{Handler: handler}.ServeHTTP(w, r)
+}
+
+// taskLogTail streams new log messages of a running task to the UI over a
+// websocket, as arrays of log messages. The stream ends once the task is
+// finished and its log has been sent.
+func (uis *UIServer) taskLogTail(w http.ResponseWriter, r *http.Request) {
+	projCtx := MustHaveProjectContext(r)
+	if projCtx.Task == nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	taskId := projCtx.Task.Id
+
+	execution, err := strconv.Atoi(mux.Vars(r)["execution"])
+	if err != nil {
+		http.Error(w, "Invalid execution number", http.StatusBadRequest)
+		return
+	}
+
+	logType := r.FormValue("type")
+	if logType == "" {
+		logType = AllLogsType
+	}
+	logTypeFilter := []string{}
+	if logType != AllLogsType {
+		logTypeFilter = []string{logType}
+	}
+
+	// restrict access if the user is not logged in
+	if GetUser(r) == nil {
+		if logType == AllLogsType {
+			logTypeFilter = []string{model.TaskLogPrefix}
+		}
+		if logType == model.AgentLogPrefix || logType == model.SystemLogPrefix {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	// only messages logged after the page loaded its logs are sent
+	var lastId bson.ObjectId
+	recent, err := model.FindMostRecentTaskLogs(taskId, execution, 1)
+	if err != nil {
+		uis.LoggedError(w, r, http.StatusInternalServerError, errors.Wrap(err, "Error getting log data"))
+		return
+	}
+	if len(recent) > 0 {
+		lastId = recent[0].Id
+	}
+
+	handler := func(ws *websocket.Conn) {
+		defer ws.Close()
+
+		// the client sends nothing, so a failed read means it went away
+		gone := make(chan struct{})
+		go func() {
+			defer close(gone)
+			var msg string
+			for websocket.Message.Receive(ws, &msg) == nil {
+			}
+		}()
+
+		ticker := time.NewTicker(LogTailInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-gone:
+				return
+			case <-ticker.C:
+			}
+
+			// check the status first, so that logs stored before the task
+			// finished are sent before the stream ends
+			t, err := task.FindOne(task.ById(taskId))
+			if err != nil || t == nil {
+				grip.Warningf("problem finding task %s for log tail: %+v", taskId, err)
+				return
+			}
+			running := t.Execution == execution && util.SliceContains(evergreen.AbortableStatuses, t.Status)
+
+			logs, err := model.FindTaskLogsAfterId(taskId, execution, lastId)
+			if err != nil {
+				grip.Warningf("problem tailing logs for task %s: %+v", taskId, err)
+				return
+			}
+			for _, taskLog := range logs {
+				lastId = taskLog.Id
+				messages := filterLogMessages(taskLog.Messages, logTypeFilter)
+				if len(messages) == 0 {
+					continue
+				}
+				if err = websocket.JSON.Send(ws, messages); err != nil {
+					return
+				}
+			}
+
+			if !running {
+				return
+			}
+		}
+	}
+
+	websocket.Handler(handler).ServeHTTP(w, r)
+}
+
+// filterLogMessages returns the messages whose type is one of types, or all
+// messages if types is empty.
+func filterLogMessages(messages []model.LogMessage, types []string) []model.LogMessage {
+	if len(types) == 0 {
+		return messages
+	}
+	filtered := []model.LogMessage{}
+	for _, msg := range messages {
+		if util.SliceContains(types, msg.Type) {
+			filtered = append(filtered, msg)
+		}
+	}
+	return filtered
+}
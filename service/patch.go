@@ -68,14 +68,28 @@ func (uis *UIServer) patchPage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// resolve the project's aliases so that they can be applied to the selection
+	aliases := map[string][]patch.VariantTasks{}
+	if projCtx.ProjectRef != nil {
+		for _, name := range projCtx.ProjectRef.AliasNames() {
+			pairs, err := projCtx.Project.PatchAliasPairs(projCtx.ProjectRef, name)
+			if err != nil {
+				grip.Warningf("resolving alias '%s' of project %s: %+v", name, projCtx.ProjectRef.Identifier, err)
+				continue
+			}
+			aliases[name] = model.TVPairsToVariantTasks(pairs)
+		}
+	}
+
 	uis.WriteHTML(w, http.StatusOK, struct {
 		ProjectData projectContext
 		User        *user.DBUser
 		Version     *uiVersion
 		Variants    map[string]model.BuildVariant
 		Tasks       []interface{}
+		Aliases     map[string][]patch.VariantTasks
 		CanEdit     bool
-	}{projCtx, currentUser, versionAsUI, variantMappings, tasksList, uis.canEditPatch(currentUser, projCtx.Patch)}, "base",
+	}{projCtx, currentUser, versionAsUI, variantMappings, tasksList, aliases, uis.canEditPatch(currentUser, projCtx.Patch)}, "base",
 		"patch_version.html", "base_angular.html", "menu.html")
 }
 
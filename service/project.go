@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
+	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/alerts"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/user"
 	"github.com/evergreen-ci/evergreen/util"
+	"github.com/evergreen-ci/evergreen/validator"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 	"gopkg.in/mgo.v2/bson"
@@ -52,7 +55,7 @@ func (uis *UIServer) projectsPage(w http.ResponseWriter, r *http.Request) {
 
 	// construct a json-marshaling friendly representation of our supported triggers
 	allTaskTriggers := []interface{}{}
-	for _, taskTrigger := range alerts.AvailableTaskFailTriggers {
+	for _, taskTrigger := range append(alerts.AvailableTaskFailTriggers, alerts.AvailableTaskPerfTriggers...) {
 		allTaskTriggers = append(allTaskTriggers, struct {
 			Id      string `json:"id"`
 			Display string `json:"display"`
@@ -129,18 +132,26 @@ func (uis *UIServer) modifyProject(w http.ResponseWriter, r *http.Request) {
 	}
 
 	responseRef := struct {
-		Identifier         string            `json:"id"`
-		DisplayName        string            `json:"display_name"`
-		RemotePath         string            `json:"remote_path"`
-		BatchTime          int               `json:"batch_time"`
-		DeactivatePrevious bool              `json:"deactivate_previous"`
-		Branch             string            `json:"branch_name"`
-		ProjVarsMap        map[string]string `json:"project_vars"`
-		Enabled            bool              `json:"enabled"`
-		Private            bool              `json:"private"`
-		Owner              string            `json:"owner_name"`
-		Repo               string            `json:"repo_name"`
-		Admins             []string          `json:"admins"`
+		Identifier         string                          `json:"id"`
+		DisplayName        string                          `json:"display_name"`
+		RemotePath         string                          `json:"remote_path"`
+		BatchTime          int                             `json:"batch_time"`
+		DeactivatePrevious bool                            `json:"deactivate_previous"`
+		Branch             string                          `json:"branch_name"`
+		ProjVarsMap        map[string]string               `json:"project_vars"`
+		Enabled            bool                            `json:"enabled"`
+		Private            bool                            `json:"private"`
+		Owner              string                          `json:"owner_name"`
+		Repo               string                          `json:"repo_name"`
+		RepoKind           string                          `json:"repo_kind"`
+		RepoURL            string                          `json:"repo_url"`
+		Admins             []string                        `json:"admins"`
+		Retention          *evergreen.RetentionPolicy      `json:"retention"`
+		CommitQueueEnabled bool                            `json:"commit_queue_enabled"`
+		Aliases            []model.ProjectAlias            `json:"aliases"`
+		Triggers           []model.TriggerDefinition       `json:"triggers"`
+		PeriodicBuilds     []model.PeriodicBuildDefinition `json:"periodic_builds"`
+		GitTagVersions     []model.GitTagDefinition        `json:"git_tag_versions"`
 		AlertConfig        map[string][]struct {
 			Provider string                 `json:"provider"`
 			Settings map[string]interface{} `json:"settings"`
@@ -152,6 +163,27 @@ func (uis *UIServer) modifyProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if retention := responseRef.Retention; retention != nil && (retention.MainlineDays < 0 || retention.PatchDays < 0) {
+		http.Error(w, "Retention days must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	if responseRef.RepoKind == "" {
+		responseRef.RepoKind = model.GithubRepoType
+	}
+	if !util.SliceContains(model.ValidRepoTypes, responseRef.RepoKind) {
+		http.Error(w, fmt.Sprintf("Invalid repository kind '%v'", responseRef.RepoKind), http.StatusBadRequest)
+		return
+	}
+	if responseRef.RepoKind == model.GitRepoType && responseRef.RepoURL == "" {
+		http.Error(w, "Repository URL is required for git repositories", http.StatusBadRequest)
+		return
+	}
+	if responseRef.CommitQueueEnabled && responseRef.RepoKind != model.GithubRepoType {
+		http.Error(w, "The commit queue can only merge into GitHub repositories", http.StatusBadRequest)
+		return
+	}
+
 	projectRef.DisplayName = responseRef.DisplayName
 	projectRef.RemotePath = responseRef.RemotePath
 	projectRef.BatchTime = responseRef.BatchTime
@@ -161,9 +193,47 @@ func (uis *UIServer) modifyProject(w http.ResponseWriter, r *http.Request) {
 	projectRef.Owner = responseRef.Owner
 	projectRef.DeactivatePrevious = responseRef.DeactivatePrevious
 	projectRef.Repo = responseRef.Repo
+	projectRef.RepoKind = responseRef.RepoKind
+	projectRef.RepoURL = responseRef.RepoURL
 	projectRef.Admins = responseRef.Admins
+	projectRef.Retention = responseRef.Retention
+	projectRef.CommitQueueEnabled = responseRef.CommitQueueEnabled
+	projectRef.Aliases = responseRef.Aliases
+	projectRef.Triggers = responseRef.Triggers
+	projectRef.GitTagVersions = responseRef.GitTagVersions
 	projectRef.Identifier = id
 
+	// check the aliases against the project's last known good config
+	project, err := model.FindProject("", projectRef)
+	if err != nil {
+		uis.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	for _, aliasErr := range validator.CheckProjectAliases(project, projectRef.Aliases) {
+		if aliasErr.Level == validator.Error {
+			http.Error(w, fmt.Sprintf("Invalid aliases: %v", aliasErr.Message), http.StatusBadRequest)
+			return
+		}
+	}
+
+	allRefs, err := model.FindAllProjectRefs()
+	if err != nil {
+		uis.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if err = model.ValidateTriggers(projectRef, allRefs); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid triggers: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err = projectRef.SetPeriodicBuilds(responseRef.PeriodicBuilds, time.Now()); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid periodic builds: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err = projectRef.ValidateGitTagVersions(); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid git tag versions: %v", err), http.StatusBadRequest)
+		return
+	}
+
 	projectRef.Alerts = map[string][]model.AlertConfig{}
 	for triggerId, alerts := range responseRef.AlertConfig {
 		//TODO validate the triggerID, provider, and settings.
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/logsearch"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+)
+
+const (
+	defaultLogSearchResults = 100
+	maxLogSearchResults     = 1000
+)
+
+// RestLogSearchResult is a log line that matched a search, with a link to it.
+type RestLogSearchResult struct {
+	logsearch.LogLine
+	Url string `json:"url"`
+}
+
+// searchLogs returns the indexed log lines of a project that contain the text
+// in the "q" parameter. Results may be filtered by "variant", "task",
+// "severity" and a "since" and "until" time in RFC3339 format.
+func (restapi restAPI) searchLogs(w http.ResponseWriter, r *http.Request) {
+	projCtx := MustHaveRESTContext(r)
+	if projCtx.ProjectRef == nil {
+		restapi.WriteJSON(w, http.StatusNotFound, responseError{Message: "error finding project"})
+		return
+	}
+
+	opts := logsearch.SearchOptions{
+		Project:      projCtx.ProjectRef.Identifier,
+		Text:         r.FormValue("q"),
+		BuildVariant: r.FormValue("variant"),
+		TaskName:     r.FormValue("task"),
+		Severities:   util.GetStringArrayValue(r, "severity", []string{}),
+	}
+	if opts.Text == "" {
+		restapi.WriteJSON(w, http.StatusBadRequest, responseError{Message: "search text 'q' must not be empty"})
+		return
+	}
+
+	var err error
+	opts.Limit, err = util.GetIntValue(r, "limit", defaultLogSearchResults)
+	if err != nil || opts.Limit <= 0 || opts.Limit > maxLogSearchResults {
+		restapi.WriteJSON(w, http.StatusBadRequest, responseError{Message: "invalid value for field 'limit'"})
+		return
+	}
+	for field, t := range map[string]*time.Time{"since": &opts.Since, "until": &opts.Until} {
+		if value := r.FormValue(field); value != "" {
+			if *t, err = time.Parse(time.RFC3339, value); err != nil {
+				restapi.WriteJSON(w, http.StatusBadRequest, responseError{Message: "invalid format for field '" + field + "'"})
+				return
+			}
+		}
+	}
+
+	lines, err := logsearch.Search(opts)
+	if err != nil {
+		grip.Errorf("error searching logs of project %s: %+v", opts.Project, err)
+		restapi.WriteJSON(w, http.StatusInternalServerError, responseError{Message: "error searching logs"})
+		return
+	}
+
+	root := restapi.GetSettings().Ui.Url
+	results := []RestLogSearchResult{}
+	for _, line := range lines {
+		results = append(results, RestLogSearchResult{LogLine: line, Url: root + line.URL()})
+	}
+	restapi.WriteJSON(w, http.StatusOK, results)
+}
@@ -53,6 +53,8 @@ type RestTask struct {
 	MinQueuePos         int                   `json:"min_queue_pos"`
 	PatchNumber         int                   `json:"patch_number,omitempty"`
 	PatchId             string                `json:"patch_id,omitempty"`
+	CacheHits           int                   `json:"cache_hits"`
+	CacheMisses         int                   `json:"cache_misses"`
 
 	// Artifacts and binaries
 	Files []taskFile `json:"files"`
@@ -74,8 +76,9 @@ type taskTestLogURL struct {
 }
 
 type taskFile struct {
-	Name string `json:"name"`
-	URL  string `json:"url"`
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Expired bool   `json:"expired,omitempty"`
 }
 
 type taskTestResultsByName map[string]taskTestResult
@@ -118,6 +121,8 @@ func (restapi restAPI) getTaskInfo(w http.ResponseWriter, r *http.Request) {
 	destTask.RevisionOrderNumber = srcTask.RevisionOrderNumber
 	destTask.Requester = srcTask.Requester
 	destTask.Status = srcTask.Status
+	destTask.CacheHits = srcTask.CacheHits
+	destTask.CacheMisses = srcTask.CacheMisses
 	destTask.Aborted = srcTask.Aborted
 	destTask.TimeTaken = srcTask.TimeTaken
 	destTask.ExpectedDuration = srcTask.ExpectedDuration
@@ -163,8 +168,9 @@ func (restapi restAPI) getTaskInfo(w http.ResponseWriter, r *http.Request) {
 	for _, entry := range entries {
 		for _, _file := range entry.Files {
 			file := taskFile{
-				Name: _file.Name,
-				URL:  _file.Link,
+				Name:    _file.Name,
+				URL:     _file.Link,
+				Expired: _file.Expired,
 			}
 			destTask.Files = append(destTask.Files, file)
 		}
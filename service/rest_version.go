@@ -61,6 +61,7 @@ type restVersion struct {
 	RemotePath          string    `json:"remote_path"`
 	Requester           string    `json:"requester"`
 	Config              string    `json:"config,omitempty"`
+	Pinned              bool      `json:"pinned"`
 }
 
 type versionLessInfo struct {
@@ -114,6 +115,7 @@ func copyVersion(srcVersion *version.Version, destVersion *restVersion) {
 	destVersion.RemotePath = srcVersion.RemotePath
 	destVersion.Requester = srcVersion.Requester
 	destVersion.Config = srcVersion.Config
+	destVersion.Pinned = srcVersion.Pinned
 }
 
 // Returns a JSON response of an array with the NumRecentVersions
@@ -275,6 +277,7 @@ func (restapi restAPI) modifyVersionInfo(w http.ResponseWriter, r *http.Request)
 
 	input := struct {
 		Activated *bool `json:"activated"`
+		Pinned    *bool `json:"pinned"`
 	}{}
 
 	body := util.NewRequestReader(r)
@@ -298,6 +301,15 @@ func (restapi restAPI) modifyVersionInfo(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	// pinned versions keep their data regardless of the retention policy
+	if input.Pinned != nil {
+		if err := v.SetPinned(*input.Pinned); err != nil {
+			msg := fmt.Sprintf("Error setting pinned state of version '%v'", v.Id)
+			restapi.WriteJSON(w, http.StatusInternalServerError, responseError{Message: msg})
+			return
+		}
+	}
+
 	restapi.getVersionInfo(w, r)
 }
 
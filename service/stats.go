@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/build"
 	"github.com/evergreen-ci/evergreen/model/patch"
 	"github.com/evergreen-ci/evergreen/model/task"
@@ -70,27 +71,31 @@ func (uis *UIServer) taskTimingPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	currentProject := UIProject{projCtx.Project.Identifier, []UIBuildVariant{}, []string{}}
+	data := struct {
+		ProjectData projectContext
+		User        *user.DBUser
+		Project     UIProject
+	}{projCtx, GetUser(r), newUIProject(projCtx.Project)}
+
+	uis.WriteHTML(w, http.StatusOK, data, "base", "task_timing.html", "base_angular.html", "menu.html")
+}
+
+// newUIProject lists the build variants and tasks of the project.
+func newUIProject(project *model.Project) UIProject {
+	currentProject := UIProject{project.Identifier, []UIBuildVariant{}, []string{}}
 
 	// populate buildVariants by iterating over the build variants tasks
-	for _, bv := range projCtx.Project.BuildVariants {
+	for _, bv := range project.BuildVariants {
 		newBv := UIBuildVariant{bv.Name, []string{}}
 		for _, task := range bv.Tasks {
 			newBv.TaskNames = append(newBv.TaskNames, task.Name)
 		}
 		currentProject.BuildVariants = append(currentProject.BuildVariants, newBv)
 	}
-	for _, task := range projCtx.Project.Tasks {
+	for _, task := range project.Tasks {
 		currentProject.TaskNames = append(currentProject.TaskNames, task.Name)
 	}
-
-	data := struct {
-		ProjectData projectContext
-		User        *user.DBUser
-		Project     UIProject
-	}{projCtx, GetUser(r), currentProject}
-
-	uis.WriteHTML(w, http.StatusOK, data, "base", "task_timing.html", "base_angular.html", "menu.html")
+	return currentProject
 }
 
 // taskTimingJSON sends over the task data for a certain task of a certain build variant
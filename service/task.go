@@ -10,6 +10,7 @@ import (
 
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/logstore"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/event"
 	"github.com/evergreen-ci/evergreen/model/host"
@@ -293,7 +294,7 @@ const DefaultLogMessages = 100 // passed as a limit, so 0 means don't limit
 
 const AllLogsType = "ALL"
 
-func getTaskLogs(taskId string, execution int, limit int, logType string,
+func getTaskLogs(logs *logstore.Logs, taskId string, execution int, limit int, logType string,
 	loggedIn bool) ([]model.LogMessage, error) {
 
 	logTypeFilter := []string{}
@@ -311,7 +312,7 @@ func getTaskLogs(taskId string, execution int, limit int, logType string,
 		}
 	}
 
-	return model.FindMostRecentLogMessages(taskId, execution, limit, []string{},
+	return logs.FindMostRecentLogMessages(taskId, execution, limit, []string{},
 		logTypeFilter)
 }
 
@@ -487,7 +488,7 @@ func (uis *UIServer) taskLog(w http.ResponseWriter, r *http.Request) {
 		uis.WriteJSON(w, http.StatusOK, loggedEvents)
 		return
 	} else {
-		taskLogs, err := getTaskLogs(projCtx.Task.Id, execution, DefaultLogMessages, logType, GetUser(r) != nil)
+		taskLogs, err := getTaskLogs(uis.Logs, projCtx.Task.Id, execution, DefaultLogMessages, logType, GetUser(r) != nil)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -529,7 +530,7 @@ func (uis *UIServer) taskLogRaw(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	channel, err := model.GetRawTaskLogChannel(projCtx.Task.Id, execution, []string{}, logTypeFilter)
+	channel, err := uis.Logs.GetRawTaskLogChannel(projCtx.Task.Id, execution, []string{}, logTypeFilter)
 	if err != nil {
 		uis.LoggedError(w, r, http.StatusInternalServerError, errors.Wrap(err, "Error getting log data"))
 		return
@@ -661,7 +662,7 @@ func (uis *UIServer) testLog(w http.ResponseWriter, r *http.Request) {
 	var err error
 
 	if logId != "" { // direct link to a log document by its ID
-		testLog, err = model.FindOneTestLogById(logId)
+		testLog, err = uis.Logs.FindOneTestLogById(logId)
 		if err != nil {
 			uis.LoggedError(w, r, http.StatusInternalServerError, err)
 			return
@@ -676,7 +677,7 @@ func (uis *UIServer) testLog(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		testLog, err = model.FindOneTestLog(testName, taskID, taskExec)
+		testLog, err = uis.Logs.FindOneTestLog(testName, taskID, taskExec)
 		if err != nil {
 			uis.LoggedError(w, r, http.StatusInternalServerError, err)
 			return
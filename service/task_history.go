@@ -348,6 +348,36 @@ func (uis *UIServer) taskHistoryTestNames(w http.ResponseWriter, r *http.Request
 	uis.WriteJSON(w, http.StatusOK, results)
 }
 
+// taskHistoryFlakyTests returns how flaky each test of the task has been on the
+// project's most recent commits, most flaky first. The optional 'revisions'
+// query parameter is how many commits are considered.
+func (uis *UIServer) taskHistoryFlakyTests(w http.ResponseWriter, r *http.Request) {
+	projCtx := MustHaveProjectContext(r)
+	if projCtx.Project == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	params := &model.TestFlakinessParameters{
+		Project:   projCtx.Project.Identifier,
+		TaskNames: []string{mux.Vars(r)["task_name"]},
+	}
+	if revisions := r.FormValue("revisions"); revisions != "" {
+		var err error
+		if params.Revisions, err = strconv.Atoi(revisions); err != nil || params.Revisions <= 0 {
+			http.Error(w, "revisions must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	results, err := model.GetTestFlakiness(params)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error finding flaky tests: `%v`", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	uis.WriteJSON(w, http.StatusOK, results)
+}
+
 // drawerParams contains the parameters from a request to populate a task or version history drawer.
 type drawerParams struct {
 	anchorId string
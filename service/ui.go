@@ -12,6 +12,7 @@ import (
 	"github.com/evergreen-ci/evergreen/apiv3/route"
 	"github.com/evergreen-ci/evergreen/auth"
 	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/logstore"
 	"github.com/evergreen-ci/evergreen/plugin"
 	"github.com/evergreen-ci/evergreen/util"
 	"github.com/evergreen-ci/render"
@@ -47,7 +48,9 @@ type UIServer struct {
 	Settings        evergreen.Settings
 	CookieStore     *sessions.CookieStore
 	PluginTemplates map[string]*htmlTemplate.Template
-	clientConfig    *evergreen.ClientConfig
+	// Logs reads task and test logs wherever they are stored
+	Logs         *logstore.Logs
+	clientConfig *evergreen.ClientConfig
 	plugin.PanelManager
 }
 
@@ -73,6 +76,12 @@ func NewUIServer(settings *evergreen.Settings, home string) (*UIServer, error) {
 	}
 	uis.clientConfig = clientConfig
 
+	logs, err := logstore.New(settings)
+	if err != nil {
+		return nil, err
+	}
+	uis.Logs = logs
+
 	uis.CookieStore = sessions.NewCookieStore([]byte(settings.Ui.Secret))
 
 	uis.PluginTemplates = map[string]*htmlTemplate.Template{}
@@ -133,6 +142,7 @@ func (uis *UIServer) NewRouter() (*mux.Router, error) {
 	r.HandleFunc("/json/task_log/{task_id}", uis.loadCtx(uis.taskLog))
 	r.HandleFunc("/json/task_log/{task_id}/{execution}", uis.loadCtx(uis.taskLog))
 	r.HandleFunc("/task_log_raw/{task_id}/{execution}", uis.loadCtx(uis.taskLogRaw))
+	r.HandleFunc("/task_log_tail/{task_id}/{execution}", uis.loadCtx(uis.taskLogTail))
 
 	// Test Logs
 	r.HandleFunc("/test_log/{task_id}/{task_execution}/{test_name}", uis.loadCtx(uis.testLog))
@@ -171,6 +181,7 @@ func (uis *UIServer) NewRouter() (*mux.Router, error) {
 	r.HandleFunc("/task_history/{project_id}/{task_name}", uis.loadCtx(uis.taskHistoryPage))
 	r.HandleFunc("/task_history/{project_id}/{task_name}/pickaxe", uis.loadCtx(uis.taskHistoryPickaxe))
 	r.HandleFunc("/task_history/{project_id}/{task_name}/test_names", uis.loadCtx(uis.taskHistoryTestNames))
+	r.HandleFunc("/task_history/{project_id}/{task_name}/flaky_tests", uis.loadCtx(uis.taskHistoryFlakyTests))
 
 	// History Drawer Endpoints
 	r.HandleFunc("/history/tasks/{task_id}/{window}", uis.loadCtx(uis.taskHistoryDrawer))
@@ -182,6 +193,10 @@ func (uis *UIServer) NewRouter() (*mux.Router, error) {
 	// Task queues
 	r.HandleFunc("/task_queue/", uis.loadCtx(uis.allTaskQueues))
 
+	// Commit queues
+	r.HandleFunc("/commit_queue/{project_id}", requireLogin(uis.loadCtx(uis.commitQueuePage))).Methods("GET")
+	r.HandleFunc("/commit_queue/{project_id}/{patch_id}", requireLogin(uis.loadCtx(uis.removeFromCommitQueue))).Methods("DELETE")
+
 	// Scheduler
 	r.HandleFunc("/scheduler/distro/{distro_id}", uis.loadCtx(uis.getSchedulerPage))
 	r.HandleFunc("/scheduler/distro/{distro_id}/logs", uis.loadCtx(uis.getSchedulerLogs))
@@ -219,6 +234,10 @@ func (uis *UIServer) NewRouter() (*mux.Router, error) {
 	r.HandleFunc("/json/task_timing/{project_id}/{build_variant}/{request}/{task_name}", requireLogin(uis.loadCtx(uis.taskTimingJSON))).Methods("GET")
 	r.HandleFunc("/json/task_timing/{project_id}/{build_variant}/{request}", requireLogin(uis.loadCtx(uis.taskTimingJSON))).Methods("GET")
 
+	// Log search
+	r.HandleFunc("/log_search", uis.loadCtx(uis.logSearchPage)).Methods("GET")
+	r.HandleFunc("/log_search/{project_id}", uis.loadCtx(uis.logSearchPage)).Methods("GET")
+
 	// Project routes
 	r.HandleFunc("/projects", requireLogin(uis.loadCtx(uis.projectsPage))).Methods("GET")
 	r.HandleFunc("/project/{project_id}", uis.loadCtx(uis.requireAdmin(uis.projectPage))).Methods("GET")
@@ -21,6 +21,10 @@ const (
 	// including rolled-up ones.
 	VersionItemsToCreate = 5
 
+	// GitTagVersionsToShow is the number of the most recent git tag
+	// versions shown beside the waterfall.
+	GitTagVersionsToShow = 10
+
 	// SkipQueryParam is the string field for the skip value in the URL
 	// (how many versions to skip).
 	SkipQueryParam = "skip"
@@ -69,6 +73,20 @@ type waterfallData struct {
 	CurrentSkip       int                `json:"current_skip"`        // number of versions skipped so far
 	PreviousPageCount int                `json:"previous_page_count"` // number of versions on previous page
 	CurrentTime       int64              `json:"current_time"`        // time used to calculate the eta of started task
+
+	// GitTagVersions are the project's most recent versions for tags in its
+	// repository, which are shown apart from the commits on its branch
+	GitTagVersions []waterfallGitTagVersion `json:"git_tag_versions"`
+}
+
+// waterfallGitTagVersion is a version that a tag in the project's repository
+// created.
+type waterfallGitTagVersion struct {
+	Id         string    `json:"id"`
+	GitTag     string    `json:"git_tag"`
+	Revision   string    `json:"revision"`
+	Status     string    `json:"status"`
+	CreateTime time.Time `json:"create_time"`
 }
 
 // waterfallBuildVariant stores the Id and DisplayName for a given build
@@ -656,9 +674,40 @@ func (uis *UIServer) waterfallPage(w http.ResponseWriter, r *http.Request) {
 	// pass it the current time
 	finalData.CurrentTime = time.Now().UnixNano()
 
+	finalData.GitTagVersions, err = getGitTagVersions(projCtx.Project.Identifier)
+	if err != nil {
+		uis.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
 	uis.WriteHTML(w, http.StatusOK, struct {
 		ProjectData projectContext
 		User        *user.DBUser
 		Data        waterfallData
 	}{projCtx, GetUser(r), finalData}, "base", "waterfall.html", "base_angular.html", "menu.html")
 }
+
+// getGitTagVersions returns the project's most recent versions that were
+// created for tags in its repository.
+func getGitTagVersions(projectId string) ([]waterfallGitTagVersion, error) {
+	versions, err := version.Find(
+		version.ByMostRecentlyCreatedForRequester(projectId, evergreen.GitTagRequester).
+			WithFields(version.IdKey, version.GitTagKey, version.RevisionKey,
+				version.StatusKey, version.CreateTimeKey).
+			Limit(GitTagVersionsToShow))
+	if err != nil {
+		return nil, errors.Wrap(err, "error finding git tag versions")
+	}
+
+	gitTagVersions := make([]waterfallGitTagVersion, 0, len(versions))
+	for _, v := range versions {
+		gitTagVersions = append(gitTagVersions, waterfallGitTagVersion{
+			Id:         v.Id,
+			GitTag:     v.GitTag,
+			Revision:   v.Revision,
+			Status:     v.Status,
+			CreateTime: v.CreateTime,
+		})
+	}
+	return gitTagVersions, nil
+}
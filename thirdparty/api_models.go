@@ -41,6 +41,12 @@ func (nfe FileNotFoundError) Error() string {
 	return fmt.Sprintf("Requested file at %v not found", nfe.filepath)
 }
 
+// NewFileNotFoundError returns an error for the remote configuration file at
+// the given path.
+func NewFileNotFoundError(filepath string) FileNotFoundError {
+	return FileNotFoundError{filepath}
+}
+
 func IsFileNotFound(err error) bool {
 	_, ok := err.(FileNotFoundError)
 	return ok
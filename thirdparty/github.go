@@ -79,6 +79,47 @@ func GetGithubCommits(oauthToken, commitsURL string) (
 	return
 }
 
+// GetGithubTags gets a page of a repository's tags. The header of the
+// response holds the link to the next page.
+func GetGithubTags(oauthToken, tagsURL string) (
+	githubTags []GithubTag, header http.Header, err error) {
+	resp, err := tryGithubGet(oauthToken, tagsURL)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if resp == nil {
+		errMsg := fmt.Sprintf("nil response from url '%v'", tagsURL)
+		grip.Error(errMsg)
+		return nil, nil, APIResponseError{errMsg}
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("error querying '%v': %v", tagsURL, err)
+		grip.Error(errMsg)
+		return nil, nil, APIResponseError{errMsg}
+	}
+
+	header = resp.Header
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, ResponseReadError{err.Error()}
+	}
+
+	grip.Debugf("Github API response: %s. %d bytes", resp.Status, len(respBody))
+
+	if resp.StatusCode != http.StatusOK {
+		requestError := APIRequestError{}
+		if err = json.Unmarshal(respBody, &requestError); err != nil {
+			return nil, nil, APIRequestError{Message: string(respBody)}
+		}
+		return nil, nil, requestError
+	}
+
+	if err = json.Unmarshal(respBody, &githubTags); err != nil {
+		return nil, nil, APIUnmarshalError{string(respBody), err.Error()}
+	}
+	return
+}
+
 func GetGithubAPIStatus() (string, error) {
 	req, err := http.NewRequest(evergreen.MethodGet, fmt.Sprintf("%v/api/status.json", GithubStatusBase), nil)
 	if err != nil {
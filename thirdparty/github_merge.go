@@ -0,0 +1,180 @@
+package thirdparty
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// GithubTreeEntry is a file in a tree to create with the GitHub git data API.
+// An entry whose SHA is nil deletes the file from the base tree.
+type GithubTreeEntry struct {
+	Path string  `json:"path"`
+	Mode string  `json:"mode"`
+	Type string  `json:"type"`
+	SHA  *string `json:"sha"`
+}
+
+// GithubGitAuthor is the author of a commit made with the GitHub git data API.
+type GithubGitAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// githubWrite sends a request that changes a repository to the GitHub API,
+// and unmarshals the response into out if it is not nil. Unlike reads,
+// writes are not retried.
+func githubWrite(method, url, oauthToken string, data, out interface{}) error {
+	grip.Infof("Attempting GitHub API %s at '%s'", method, url)
+	resp, err := githubRequest(method, url, oauthToken, data)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("error calling github %s on '%v': %v", method, url, err)
+		grip.Error(errMsg)
+		return APIResponseError{errMsg}
+	}
+	respBody, err := readGithubResponse(resp)
+	if err != nil {
+		return err
+	}
+	if out != nil {
+		if err = json.Unmarshal(respBody, out); err != nil {
+			return APIUnmarshalError{string(respBody), err.Error()}
+		}
+	}
+	return nil
+}
+
+// MergeGithubPullRequest squash merges a pull request via an API call to
+// GitHub. The merge fails if the head of the pull request is no longer
+// headRevision.
+func MergeGithubPullRequest(oauthToken, repoOwner, repo string, number int, headRevision string) error {
+	mergeURL := fmt.Sprintf("%v/repos/%v/%v/pulls/%v/merge", GithubAPIBase, repoOwner, repo, number)
+	data := struct {
+		SHA         string `json:"sha"`
+		MergeMethod string `json:"merge_method"`
+	}{headRevision, "squash"}
+	return errors.Wrapf(githubWrite("PUT", mergeURL, oauthToken, data, nil),
+		"error merging pull request #%d", number)
+}
+
+// GetGithubTreeModes gets the mode of every file in a revision's tree via an
+// API call to GitHub, keyed by path.
+func GetGithubTreeModes(oauthToken, repoOwner, repo, revision string) (map[string]string, error) {
+	treeURL := fmt.Sprintf("%v/repos/%v/%v/git/trees/%v?recursive=1", GithubAPIBase, repoOwner, repo, revision)
+
+	resp, err := tryGithubGet(oauthToken, treeURL)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("error querying '%v': %v", treeURL, err)
+		grip.Error(errMsg)
+		return nil, APIResponseError{errMsg}
+	}
+	respBody, err := readGithubResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := struct {
+		Tree      []GithubTreeEntry `json:"tree"`
+		Truncated bool              `json:"truncated"`
+	}{}
+	if err = json.Unmarshal(respBody, &tree); err != nil {
+		return nil, APIUnmarshalError{string(respBody), err.Error()}
+	}
+	if tree.Truncated {
+		return nil, errors.Errorf("tree of revision %v is too large to list", revision)
+	}
+	modes := map[string]string{}
+	for _, entry := range tree.Tree {
+		modes[entry.Path] = entry.Mode
+	}
+	return modes, nil
+}
+
+// CreateGithubBlob stores a file's contents in a repository via an API call
+// to GitHub, returning the blob's SHA.
+func CreateGithubBlob(oauthToken, repoOwner, repo string, content []byte) (string, error) {
+	blobURL := fmt.Sprintf("%v/repos/%v/%v/git/blobs", GithubAPIBase, repoOwner, repo)
+	data := struct {
+		Content  []byte `json:"content"`
+		Encoding string `json:"encoding"`
+	}{content, "base64"}
+	blob := struct {
+		SHA string `json:"sha"`
+	}{}
+	if err := githubWrite("POST", blobURL, oauthToken, data, &blob); err != nil {
+		return "", errors.Wrap(err, "error creating blob")
+	}
+	return blob.SHA, nil
+}
+
+// CreateGithubCommit commits changes to the files of the parent revision via
+// API calls to GitHub, returning the new commit's SHA. The commit is not on
+// any branch until a branch is updated to it.
+func CreateGithubCommit(oauthToken, repoOwner, repo, parent string, entries []GithubTreeEntry,
+	message string, author *GithubGitAuthor) (string, error) {
+	parentCommit, err := GetCommitEvent(oauthToken, repoOwner, repo, parent)
+	if err != nil {
+		return "", errors.Wrapf(err, "error getting commit %v", parent)
+	}
+	if parentCommit == nil {
+		return "", errors.Errorf("commit %v does not exist", parent)
+	}
+
+	treeURL := fmt.Sprintf("%v/repos/%v/%v/git/trees", GithubAPIBase, repoOwner, repo)
+	treeData := struct {
+		BaseTree string            `json:"base_tree"`
+		Tree     []GithubTreeEntry `json:"tree"`
+	}{parentCommit.Commit.Tree.SHA, entries}
+	tree := struct {
+		SHA string `json:"sha"`
+	}{}
+	if err = githubWrite("POST", treeURL, oauthToken, treeData, &tree); err != nil {
+		return "", errors.Wrap(err, "error creating tree")
+	}
+
+	commitURL := fmt.Sprintf("%v/repos/%v/%v/git/commits", GithubAPIBase, repoOwner, repo)
+	commitData := struct {
+		Message string           `json:"message"`
+		Tree    string           `json:"tree"`
+		Parents []string         `json:"parents"`
+		Author  *GithubGitAuthor `json:"author,omitempty"`
+	}{message, tree.SHA, []string{parent}, author}
+	commit := struct {
+		SHA string `json:"sha"`
+	}{}
+	if err = githubWrite("POST", commitURL, oauthToken, commitData, &commit); err != nil {
+		return "", errors.Wrap(err, "error creating commit")
+	}
+	return commit.SHA, nil
+}
+
+// UpdateGithubBranch moves a branch to a revision via an API call to GitHub.
+// The update fails unless it fast-forwards the branch.
+func UpdateGithubBranch(oauthToken, repoOwner, repo, branch, revision string) error {
+	refURL := fmt.Sprintf("%v/repos/%v/%v/git/refs/heads/%v", GithubAPIBase, repoOwner, repo, branch)
+	data := struct {
+		SHA   string `json:"sha"`
+		Force bool   `json:"force"`
+	}{revision, false}
+	return errors.Wrapf(githubWrite("PATCH", refURL, oauthToken, data, nil),
+		"error updating branch %v", branch)
+}
+
+// CommentOnGithubIssue comments on an issue or pull request via an API call
+// to GitHub.
+func CommentOnGithubIssue(oauthToken, repoOwner, repo string, number int, body string) error {
+	commentURL := fmt.Sprintf("%v/repos/%v/%v/issues/%v/comments", GithubAPIBase, repoOwner, repo, number)
+	data := struct {
+		Body string `json:"body"`
+	}{body}
+	return errors.Wrapf(githubWrite("POST", commentURL, oauthToken, data, nil),
+		"error commenting on #%d", number)
+}
@@ -32,6 +32,11 @@ type BranchEvent struct {
 	Links    Link
 }
 
+type GithubTag struct {
+	Name   string
+	Commit Tree
+}
+
 type GithubCommit struct {
 	Url       string
 	SHA       string
@@ -169,3 +174,73 @@ type GitHubCompareResponse struct {
 	AheadBy         int             `json:"ahead_by"`
 	Status          string          `json:"status"`
 }
+
+// GithubPullRequest is a pull request as returned by the GitHub API and sent
+// in pull request webhooks.
+type GithubPullRequest struct {
+	Number  int                  `json:"number"`
+	Title   string               `json:"title"`
+	State   string               `json:"state"`
+	HtmlUrl string               `json:"html_url"`
+	User    GithubLoginUser      `json:"user"`
+	Head    GithubPullRequestRef `json:"head"`
+	Base    GithubPullRequestRef `json:"base"`
+	// AuthorAssociation is the author's relationship to the base repository
+	AuthorAssociation string `json:"author_association"`
+}
+
+// GithubPullRequestRef is the head or base branch of a pull request.
+type GithubPullRequestRef struct {
+	Ref  string           `json:"ref"`
+	SHA  string           `json:"sha"`
+	Repo GithubRepository `json:"repo"`
+}
+
+// GithubRepository is a repository as described by the GitHub API.
+type GithubRepository struct {
+	Name     string          `json:"name"`
+	FullName string          `json:"full_name"`
+	Owner    GithubLoginUser `json:"owner"`
+}
+
+// GithubReview is a review of a pull request.
+type GithubReview struct {
+	User  GithubLoginUser `json:"user"`
+	State string          `json:"state"`
+	// AuthorAssociation is the reviewer's relationship to the base repository
+	AuthorAssociation string `json:"author_association"`
+}
+
+// GithubStatus is a commit status to post to GitHub.
+type GithubStatus struct {
+	State       string `json:"state"`
+	TargetUrl   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Context     string `json:"context"`
+}
+
+// GithubPullRequestEvent is the payload of a GitHub pull_request webhook.
+type GithubPullRequestEvent struct {
+	Action      string            `json:"action"`
+	Number      int               `json:"number"`
+	PullRequest GithubPullRequest `json:"pull_request"`
+}
+
+// GithubIssueCommentEvent is the payload of a GitHub issue_comment webhook.
+// Comments on pull requests are sent as comments on issues whose
+// PullRequest is set.
+type GithubIssueCommentEvent struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Number      int `json:"number"`
+		PullRequest *struct {
+			Url string `json:"url"`
+		} `json:"pull_request"`
+	} `json:"issue"`
+	Comment struct {
+		Body              string          `json:"body"`
+		User              GithubLoginUser `json:"user"`
+		AuthorAssociation string          `json:"author_association"`
+	} `json:"comment"`
+	Repository GithubRepository `json:"repository"`
+}
@@ -0,0 +1,233 @@
+package thirdparty
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+const (
+	// commit states accepted by the GitHub statuses API
+	GithubStatusPending = "pending"
+	GithubStatusSuccess = "success"
+	GithubStatusFailure = "failure"
+	GithubStatusError   = "error"
+
+	// states of pull request reviews
+	GithubReviewApproved         = "APPROVED"
+	GithubReviewChangesRequested = "CHANGES_REQUESTED"
+	GithubReviewDismissed        = "DISMISSED"
+
+	githubDiffMediaType = "application/vnd.github.v3.diff"
+)
+
+// githubWriteAssociations are the relationships to a repository of the people
+// who can write to it.
+var githubWriteAssociations = map[string]bool{
+	"OWNER":        true,
+	"MEMBER":       true,
+	"COLLABORATOR": true,
+}
+
+// IsGithubWriteAssociation returns whether someone with the given
+// author_association to a repository can write to it.
+func IsGithubWriteAssociation(association string) bool {
+	return githubWriteAssociations[association]
+}
+
+// readGithubResponse reads the body of a GitHub API response, turning
+// unsuccessful responses into errors.
+func readGithubResponse(resp *http.Response) ([]byte, error) {
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, ResponseReadError{err.Error()}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		requestError := APIRequestError{}
+		if err = json.Unmarshal(respBody, &requestError); err != nil {
+			return nil, APIRequestError{Message: string(respBody)}
+		}
+		return nil, requestError
+	}
+	return respBody, nil
+}
+
+// GetGithubPullRequest gets a pull request via an API call to GitHub.
+func GetGithubPullRequest(oauthToken, repoOwner, repo string, number int) (*GithubPullRequest, error) {
+	pullURL := fmt.Sprintf("%v/repos/%v/%v/pulls/%v", GithubAPIBase, repoOwner, repo, number)
+
+	resp, err := tryGithubGet(oauthToken, pullURL)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("error querying '%v': %v", pullURL, err)
+		grip.Error(errMsg)
+		return nil, APIResponseError{errMsg}
+	}
+	respBody, err := readGithubResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	pullRequest := &GithubPullRequest{}
+	if err = json.Unmarshal(respBody, pullRequest); err != nil {
+		return nil, APIUnmarshalError{string(respBody), err.Error()}
+	}
+	return pullRequest, nil
+}
+
+// GetGithubPullRequestReviews gets the reviews of a pull request, oldest
+// first, via an API call to GitHub.
+func GetGithubPullRequestReviews(oauthToken, repoOwner, repo string, number int) ([]GithubReview, error) {
+	reviewsURL := fmt.Sprintf("%v/repos/%v/%v/pulls/%v/reviews?per_page=100",
+		GithubAPIBase, repoOwner, repo, number)
+
+	reviews := []GithubReview{}
+	for reviewsURL != "" {
+		resp, err := tryGithubGet(oauthToken, reviewsURL)
+		if err != nil {
+			if resp != nil {
+				grip.CatchError(resp.Body.Close())
+			}
+			errMsg := fmt.Sprintf("error querying '%v': %v", reviewsURL, err)
+			grip.Error(errMsg)
+			return nil, APIResponseError{errMsg}
+		}
+		respBody, err := readGithubResponse(resp)
+		grip.CatchError(resp.Body.Close())
+		if err != nil {
+			return nil, err
+		}
+
+		page := []GithubReview{}
+		if err = json.Unmarshal(respBody, &page); err != nil {
+			return nil, APIUnmarshalError{string(respBody), err.Error()}
+		}
+		reviews = append(reviews, page...)
+		reviewsURL = NextGithubPageLink(resp.Header)
+	}
+	return reviews, nil
+}
+
+// IsGithubPullRequestApproved returns whether the reviews of a pull request,
+// oldest first, approve it: at least one reviewer's latest review approves
+// it, and no reviewer's latest review requests changes. Only the reviews of
+// people who can write to the repository count.
+func IsGithubPullRequestApproved(reviews []GithubReview) bool {
+	latest := map[string]string{}
+	for _, review := range reviews {
+		if !IsGithubWriteAssociation(review.AuthorAssociation) {
+			continue
+		}
+		// comments neither approve nor request changes
+		if review.State == GithubReviewApproved || review.State == GithubReviewChangesRequested ||
+			review.State == GithubReviewDismissed {
+			latest[review.User.Login] = review.State
+		}
+	}
+	approved := false
+	for _, state := range latest {
+		if state == GithubReviewChangesRequested {
+			return false
+		}
+		approved = approved || state == GithubReviewApproved
+	}
+	return approved
+}
+
+// GetGithubPullRequestDiff gets the diff of a pull request against the merge
+// base of its head and base branches via an API call to GitHub.
+func GetGithubPullRequestDiff(oauthToken, repoOwner, repo string, number int) (string, error) {
+	pullURL := fmt.Sprintf("%v/repos/%v/%v/pulls/%v", GithubAPIBase, repoOwner, repo, number)
+
+	var diff []byte
+	retriableGet := util.RetriableFunc(
+		func() error {
+			req, err := http.NewRequest("GET", pullURL, nil)
+			if err != nil {
+				return err
+			}
+			if len(oauthToken) > 0 {
+				if !strings.HasPrefix(oauthToken, "token ") {
+					return errors.New("Invalid oauth token given")
+				}
+				req.Header.Add("Authorization", oauthToken)
+			}
+			req.Header.Add("Accept", githubDiffMediaType)
+
+			resp, err := (&http.Client{}).Do(req)
+			if err != nil {
+				grip.Errorf("failed trying to call github GET on %s: %+v", pullURL, err)
+				return util.RetriableError{Failure: err}
+			}
+			defer resp.Body.Close()
+			diff, err = readGithubResponse(resp)
+			return err
+		},
+	)
+
+	retryFail, err := util.Retry(retriableGet, NumGithubRetries, GithubSleepTimeSecs*time.Second)
+	if err != nil {
+		if retryFail {
+			grip.Errorf("Github GET on %v used up all retries.", pullURL)
+		}
+		return "", errors.WithStack(err)
+	}
+	return string(diff), nil
+}
+
+// GetGithubMergeBase gets the merge base of two revisions via an API call to
+// GitHub.
+func GetGithubMergeBase(oauthToken, repoOwner, repo, baseRevision, headRevision string) (string, error) {
+	compareURL := fmt.Sprintf("%v/repos/%v/%v/compare/%v...%v",
+		GithubAPIBase, repoOwner, repo, baseRevision, headRevision)
+
+	resp, err := tryGithubGet(oauthToken, compareURL)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("error getting merge base commit response for url, %v: %v", compareURL, err)
+		grip.Error(errMsg)
+		return "", APIResponseError{errMsg}
+	}
+	respBody, err := readGithubResponse(resp)
+	if err != nil {
+		return "", err
+	}
+
+	compareResponse := &GitHubCompareResponse{}
+	if err = json.Unmarshal(respBody, compareResponse); err != nil {
+		return "", APIUnmarshalError{string(respBody), err.Error()}
+	}
+	if compareResponse.MergeBaseCommit.SHA == "" {
+		return "", errors.Errorf("no merge base of %v and %v", baseRevision, headRevision)
+	}
+	return compareResponse.MergeBaseCommit.SHA, nil
+}
+
+// SetGithubCommitStatus posts a commit status to a revision via an API call
+// to GitHub.
+func SetGithubCommitStatus(oauthToken, repoOwner, repo, revision string, status *GithubStatus) error {
+	statusURL := fmt.Sprintf("%v/repos/%v/%v/statuses/%v", GithubAPIBase, repoOwner, repo, revision)
+
+	resp, err := tryGithubPost(statusURL, oauthToken, status)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("error posting status to '%v': %v", statusURL, err)
+		grip.Error(errMsg)
+		return APIResponseError{errMsg}
+	}
+	_, err = readGithubResponse(resp)
+	return err
+}
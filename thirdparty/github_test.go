@@ -55,3 +55,47 @@ func TestCheckGithubAPILimit(t *testing.T) {
 		So(err, ShouldBeNil)
 	})
 }
+
+func TestIsGithubPullRequestApproved(t *testing.T) {
+	review := func(login, state string) GithubReview {
+		return GithubReview{User: GithubLoginUser{Login: login}, State: state, AuthorAssociation: "MEMBER"}
+	}
+	Convey("When checking the reviews of a pull request", t, func() {
+		Convey("no reviews should not approve it", func() {
+			So(IsGithubPullRequestApproved(nil), ShouldBeFalse)
+		})
+
+		Convey("comments alone should not approve it", func() {
+			reviews := []GithubReview{review("a", "COMMENTED")}
+			So(IsGithubPullRequestApproved(reviews), ShouldBeFalse)
+		})
+
+		Convey("an approval should approve it", func() {
+			reviews := []GithubReview{review("a", GithubReviewApproved), review("a", "COMMENTED")}
+			So(IsGithubPullRequestApproved(reviews), ShouldBeTrue)
+		})
+
+		Convey("outstanding requested changes should block an approval", func() {
+			reviews := []GithubReview{review("a", GithubReviewApproved), review("b", GithubReviewChangesRequested)}
+			So(IsGithubPullRequestApproved(reviews), ShouldBeFalse)
+		})
+
+		Convey("a reviewer's latest review should replace their earlier ones", func() {
+			reviews := []GithubReview{review("a", GithubReviewChangesRequested), review("a", GithubReviewApproved)}
+			So(IsGithubPullRequestApproved(reviews), ShouldBeTrue)
+
+			reviews = append(reviews, review("a", GithubReviewDismissed))
+			So(IsGithubPullRequestApproved(reviews), ShouldBeFalse)
+		})
+
+		Convey("reviews by people who cannot write to the repository should not count", func() {
+			outsider := review("b", GithubReviewApproved)
+			outsider.AuthorAssociation = "CONTRIBUTOR"
+			So(IsGithubPullRequestApproved([]GithubReview{outsider}), ShouldBeFalse)
+
+			outsider.State = GithubReviewChangesRequested
+			reviews := []GithubReview{review("a", GithubReviewApproved), outsider}
+			So(IsGithubPullRequestApproved(reviews), ShouldBeTrue)
+		})
+	})
+}
@@ -262,6 +262,13 @@ func GetS3File(auth *aws.Auth, s3URL string) (io.ReadCloser, error) {
 	return bucket.GetReader(urlParsed.Path)
 }
 
+// DeleteS3File deletes a file from an s3 bucket. Deleting a file that does
+// not exist is not an error.
+func DeleteS3File(auth *aws.Auth, bucket, key string) error {
+	session := NewS3Session(auth, aws.USEast)
+	return errors.Wrapf(session.Bucket(bucket).Del(key), "problem deleting %s from bucket %s", key, bucket)
+}
+
 //Taken from https://github.com/mitchellh/goamz/blob/master/s3/sign.go
 //Modified to access the headers/params on an HTTP req directly.
 func SignAWSRequest(auth aws.Auth, canonicalPath string, req *http.Request) {
@@ -0,0 +1,247 @@
+package trigger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/repotracker"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// upstreamRequesters are the requesters of the upstream tasks and builds
+// that can fire triggers. Including triggered versions lets triggers chain.
+var upstreamRequesters = []string{
+	evergreen.RepotrackerVersionRequester,
+	evergreen.TriggerRequester,
+}
+
+// Processor creates downstream versions for the upstream tasks and builds
+// that fire the triggers of projects.
+type Processor struct {
+	// CreateVersion creates the downstream project's version with the given id.
+	CreateVersion func(ref *model.ProjectRef, id string, metadata repotracker.VersionMetadata) (*version.Version, error)
+}
+
+// event is an upstream task or build that finished.
+type event struct {
+	Id       string
+	Type     string
+	Project  string
+	Variant  string
+	TaskName string
+	Status   string
+	Version  string
+	Revision string
+}
+
+// downstreamTrigger is a trigger along with the project that defines it.
+type downstreamTrigger struct {
+	ref     *model.ProjectRef
+	trigger model.TriggerDefinition
+}
+
+// Process creates the downstream versions of the upstream tasks and builds
+// that finished after the given time. Each downstream project gets at most
+// one version for each upstream task or build, from the first of its
+// triggers that the task or build fires.
+func (p *Processor) Process(since time.Time) error {
+	refs, err := model.FindAllProjectRefs()
+	if err != nil {
+		return errors.Wrap(err, "error finding project refs")
+	}
+
+	triggers := map[string][]downstreamTrigger{}
+	upstreams := []string{}
+	for i := range refs {
+		ref := &refs[i]
+		if !ref.Enabled {
+			continue
+		}
+		for _, t := range ref.Triggers {
+			if _, ok := triggers[t.Project]; !ok {
+				upstreams = append(upstreams, t.Project)
+			}
+			triggers[t.Project] = append(triggers[t.Project], downstreamTrigger{ref: ref, trigger: t})
+		}
+	}
+
+	catcher := grip.NewCatcher()
+	for _, upstream := range upstreams {
+		events, err := findEvents(upstream, triggers[upstream], since)
+		if err != nil {
+			catcher.Add(errors.Wrapf(err, "error finding finished tasks and builds of project %s", upstream))
+			continue
+		}
+		for _, e := range events {
+			fired := map[string]bool{}
+			for _, dt := range triggers[upstream] {
+				if fired[dt.ref.Identifier] || dt.trigger.Level != e.Type ||
+					!dt.trigger.Matches(e.Variant, e.TaskName, e.Status) {
+					continue
+				}
+				fired[dt.ref.Identifier] = true
+				catcher.Add(p.createDownstreamVersion(dt, e))
+			}
+		}
+	}
+	return catcher.Resolve()
+}
+
+// findEvents returns the tasks and builds of the upstream project that
+// finished after the given time, at the levels that its triggers watch.
+func findEvents(upstream string, triggers []downstreamTrigger, since time.Time) ([]event, error) {
+	levels := map[string]bool{}
+	for _, dt := range triggers {
+		levels[dt.trigger.Level] = true
+	}
+
+	events := []event{}
+	for _, requester := range upstreamRequesters {
+		if levels[model.TriggerLevelTask] {
+			tasks, err := task.Find(task.ByRecentlyFinished(since, upstream, requester))
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			for _, t := range tasks {
+				events = append(events, event{
+					Id:       t.Id,
+					Type:     model.TriggerLevelTask,
+					Project:  t.Project,
+					Variant:  t.BuildVariant,
+					TaskName: t.DisplayName,
+					Status:   t.Status,
+					Version:  t.Version,
+					Revision: t.Revision,
+				})
+			}
+		}
+		if levels[model.TriggerLevelBuild] {
+			builds, err := build.Find(build.ByFinishedAfter(since, upstream, requester))
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			for _, b := range builds {
+				events = append(events, event{
+					Id:       b.Id,
+					Type:     model.TriggerLevelBuild,
+					Project:  b.Project,
+					Variant:  b.BuildVariant,
+					Status:   b.Status,
+					Version:  b.Version,
+					Revision: b.Revision,
+				})
+			}
+		}
+	}
+	return events, nil
+}
+
+// createDownstreamVersion creates the version that the upstream event fires,
+// unless it already exists. The version runs at the most recent revision
+// that the repotracker found for the downstream project.
+func (p *Processor) createDownstreamVersion(dt downstreamTrigger, e event) error {
+	ref := dt.ref
+	id := util.CleanName(fmt.Sprintf("%v_%v", ref.Identifier, e.Id))
+	existing, err := version.FindOne(version.ById(id))
+	if err != nil {
+		return errors.Wrapf(err, "error finding version %s", id)
+	}
+	if existing != nil {
+		return nil
+	}
+
+	latest, err := version.FindOne(version.ByMostRecentForRequester(ref.Identifier,
+		evergreen.RepotrackerVersionRequester))
+	if err != nil {
+		return errors.Wrapf(err, "error finding the latest version of project %s", ref.Identifier)
+	}
+	if latest == nil {
+		grip.Warningf("not triggering project %s, which has no versions, for %s %s",
+			ref.Identifier, e.Type, e.Id)
+		return nil
+	}
+
+	expansions, err := triggerExpansions(e)
+	if err != nil {
+		return err
+	}
+	metadata := repotracker.VersionMetadata{
+		Revision: model.Revision{
+			Author:          latest.Author,
+			AuthorEmail:     latest.AuthorEmail,
+			RevisionMessage: latest.Message,
+			Revision:        latest.Revision,
+			CreateTime:      latest.CreateTime,
+		},
+		Requester:      evergreen.TriggerRequester,
+		ConfigFile:     dt.trigger.ConfigFile,
+		Alias:          dt.trigger.Alias,
+		Expansions:     expansions,
+		TriggerID:      e.Id,
+		TriggerType:    e.Type,
+		TriggerProject: e.Project,
+		TriggerVersion: e.Version,
+	}
+
+	if _, err = p.CreateVersion(ref, id, metadata); err != nil {
+		return errors.Wrapf(err, "error creating version of project %s for %s %s",
+			ref.Identifier, e.Type, e.Id)
+	}
+	grip.Infof("created version %s of project %s for %s %s of project %s",
+		id, ref.Identifier, e.Type, e.Id, e.Project)
+	return nil
+}
+
+var nonExpansionChars = regexp.MustCompile("[^A-Za-z0-9_]+")
+
+// triggerExpansions returns the expansions that describe the upstream event
+// to the downstream version's tasks. Each of the event's artifacts is linked
+// from a trigger_artifact_<name> expansion, whose name is prefixed by the
+// task that uploaded it for build events.
+func triggerExpansions(e event) (map[string]string, error) {
+	expansions := map[string]string{
+		"trigger_event_identifier": e.Id,
+		"trigger_event_type":       e.Type,
+		"trigger_project":          e.Project,
+		"trigger_build_variant":    e.Variant,
+		"trigger_status":           e.Status,
+		"trigger_revision":         e.Revision,
+		"trigger_version":          e.Version,
+	}
+	if e.TaskName != "" {
+		expansions["trigger_task_name"] = e.TaskName
+	}
+
+	query := artifact.ByTaskId(e.Id)
+	if e.Type == model.TriggerLevelBuild {
+		query = artifact.ByBuildId(e.Id)
+	}
+	entries, err := artifact.FindAll(query)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error finding artifacts of %s %s", e.Type, e.Id)
+	}
+	for _, entry := range entries {
+		for _, file := range entry.Files {
+			if file.Expired || file.Visibility == artifact.None {
+				continue
+			}
+			name := file.Name
+			if e.Type == model.TriggerLevelBuild {
+				name = entry.TaskDisplayName + "_" + name
+			}
+			name = strings.Trim(nonExpansionChars.ReplaceAllString(name, "_"), "_")
+			expansions["trigger_artifact_"+name] = file.Link
+		}
+	}
+	return expansions, nil
+}
@@ -0,0 +1,119 @@
+package trigger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/repotracker"
+	"github.com/evergreen-ci/evergreen/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func init() {
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testutil.TestConfig()))
+}
+
+func TestProcess(t *testing.T) {
+	Convey("With a project triggered by the tasks and builds of another", t, func() {
+		testutil.HandleTestingErr(db.ClearCollections(model.ProjectRefCollection, task.Collection,
+			build.Collection, version.Collection, artifact.Collection), t, "error clearing collections")
+
+		now := time.Now()
+		core := &model.ProjectRef{Identifier: "core", Enabled: true}
+		app := &model.ProjectRef{
+			Identifier: "app",
+			Enabled:    true,
+			Triggers: []model.TriggerDefinition{
+				{Project: "core", Level: model.TriggerLevelTask, TaskName: "package", ConfigFile: "downstream.yml"},
+				{Project: "core", Level: model.TriggerLevelBuild, Variant: "linux", Alias: "smoke"},
+			},
+		}
+		So(core.Insert(), ShouldBeNil)
+		So(app.Insert(), ShouldBeNil)
+
+		latest := &version.Version{
+			Id:                  "app_v1",
+			Identifier:          "app",
+			Requester:           evergreen.RepotrackerVersionRequester,
+			Revision:            "abcdef",
+			Author:              "someone",
+			RevisionOrderNumber: 1,
+		}
+		So(latest.Insert(), ShouldBeNil)
+
+		for _, t := range []task.Task{
+			{Id: "core_package", Project: "core", BuildVariant: "linux", DisplayName: "package",
+				Status: evergreen.TaskSucceeded, Version: "core_v1", Revision: "123456",
+				Requester: evergreen.RepotrackerVersionRequester, FinishTime: now},
+			{Id: "core_test", Project: "core", BuildVariant: "linux", DisplayName: "test",
+				Status: evergreen.TaskSucceeded, Version: "core_v1", Revision: "123456",
+				Requester: evergreen.RepotrackerVersionRequester, FinishTime: now},
+			{Id: "core_package_failed", Project: "core", BuildVariant: "windows", DisplayName: "package",
+				Status: evergreen.TaskFailed, Version: "core_v1", Revision: "123456",
+				Requester: evergreen.RepotrackerVersionRequester, FinishTime: now},
+		} {
+			So(t.Insert(), ShouldBeNil)
+		}
+		b := &build.Build{Id: "core_linux", Project: "core", BuildVariant: "linux",
+			Status: evergreen.BuildSucceeded, Version: "core_v1", Revision: "123456",
+			Requester: evergreen.RepotrackerVersionRequester, FinishTime: now, TimeTaken: time.Minute}
+		So(b.Insert(), ShouldBeNil)
+		So(artifact.Entry{
+			TaskId:          "core_package",
+			TaskDisplayName: "package",
+			BuildId:         "core_linux",
+			Files: []artifact.File{
+				{Name: "Release Tarball", Link: "http://s3/core.tgz", Visibility: artifact.Public},
+				{Name: "Hidden", Link: "http://s3/hidden", Visibility: artifact.None},
+			},
+		}.Upsert(), ShouldBeNil)
+
+		created := map[string]repotracker.VersionMetadata{}
+		processor := &Processor{
+			CreateVersion: func(ref *model.ProjectRef, id string, metadata repotracker.VersionMetadata) (*version.Version, error) {
+				created[id] = metadata
+				v := &version.Version{Id: id, Identifier: ref.Identifier, Requester: metadata.Requester}
+				return v, v.Insert()
+			},
+		}
+
+		Convey("the matching task and build should each create a version", func() {
+			So(processor.Process(now.Add(-time.Minute)), ShouldBeNil)
+			So(len(created), ShouldEqual, 2)
+
+			taskVersion := created["app_core_package"]
+			So(taskVersion.Requester, ShouldEqual, evergreen.TriggerRequester)
+			So(taskVersion.Revision.Revision, ShouldEqual, "abcdef")
+			So(taskVersion.ConfigFile, ShouldEqual, "downstream.yml")
+			So(taskVersion.TriggerType, ShouldEqual, model.TriggerLevelTask)
+			So(taskVersion.TriggerVersion, ShouldEqual, "core_v1")
+			So(taskVersion.Expansions["trigger_revision"], ShouldEqual, "123456")
+			So(taskVersion.Expansions["trigger_task_name"], ShouldEqual, "package")
+			So(taskVersion.Expansions["trigger_artifact_Release_Tarball"], ShouldEqual, "http://s3/core.tgz")
+			So(taskVersion.Expansions, ShouldNotContainKey, "trigger_artifact_Hidden")
+
+			buildVersion := created["app_core_linux"]
+			So(buildVersion.Alias, ShouldEqual, "smoke")
+			So(buildVersion.Expansions["trigger_event_type"], ShouldEqual, model.TriggerLevelBuild)
+			So(buildVersion.Expansions["trigger_artifact_package_Release_Tarball"], ShouldEqual, "http://s3/core.tgz")
+
+			Convey("and processing them again should not create them twice", func() {
+				created = map[string]repotracker.VersionMetadata{}
+				So(processor.Process(now.Add(-time.Minute)), ShouldBeNil)
+				So(len(created), ShouldEqual, 0)
+			})
+		})
+
+		Convey("tasks and builds that finished before the time should be ignored", func() {
+			So(processor.Process(now.Add(time.Minute)), ShouldBeNil)
+			So(len(created), ShouldEqual, 0)
+		})
+	})
+}
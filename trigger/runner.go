@@ -0,0 +1,71 @@
+package trigger
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/repotracker"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+// Runner creates the versions of projects that other projects trigger.
+type Runner struct{}
+
+const (
+	RunnerName  = "trigger"
+	Description = "create versions of projects triggered by other projects"
+
+	// the finished tasks and builds that a run looks at overlap with those of
+	// the previous run, so that none are missed; the versions they already
+	// created are not created again
+	runOverlap = 5 * time.Minute
+	// the furthest back a run looks
+	maxLookback = 24 * time.Hour
+	// how far back the first run looks
+	firstLookback = time.Hour
+)
+
+func (r *Runner) Name() string {
+	return RunnerName
+}
+
+func (r *Runner) Description() string {
+	return Description
+}
+
+func (r *Runner) Run(config *evergreen.Settings) error {
+	startTime := time.Now()
+	grip.Infoln("Starting trigger processor at time", startTime)
+
+	since := startTime.Add(-firstLookback)
+	lastRun, err := model.FindProcessRuntime(RunnerName)
+	if err != nil {
+		grip.Errorf("error finding the last run of the trigger processor: %+v", err)
+	} else if lastRun != nil {
+		since = lastRun.FinishedAt.Add(-lastRun.Runtime - runOverlap)
+	}
+	if oldest := startTime.Add(-maxLookback); since.Before(oldest) {
+		since = oldest
+	}
+
+	processor := &Processor{
+		CreateVersion: func(ref *model.ProjectRef, id string, metadata repotracker.VersionMetadata) (*version.Version, error) {
+			return repotracker.CreateVersionFromConfig(config, ref, id, metadata)
+		},
+	}
+	if err = processor.Process(since); err != nil {
+		err = errors.Wrap(err, "error processing triggers")
+		grip.Error(err)
+		return err
+	}
+
+	runtime := time.Since(startTime)
+	if err = model.SetProcessRuntimeCompleted(RunnerName, runtime); err != nil {
+		grip.Errorf("error updating process status: %+v", err)
+	}
+	grip.Infof("Trigger processor took %s to run", runtime)
+	return nil
+}
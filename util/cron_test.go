@@ -0,0 +1,47 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCronSchedule(t *testing.T) {
+	Convey("With a time on a Wednesday afternoon", t, func() {
+		now := time.Date(2017, time.March, 15, 14, 30, 20, 0, time.UTC)
+
+		Convey("a nightly schedule should run at the next midnight", func() {
+			schedule, err := ParseCron("@daily")
+			So(err, ShouldBeNil)
+			So(schedule.Next(now), ShouldResemble, time.Date(2017, time.March, 16, 0, 0, 0, 0, time.UTC))
+		})
+
+		Convey("steps, ranges, and lists should be matched", func() {
+			schedule, err := ParseCron("*/15 9-17 * * 1,3,5")
+			So(err, ShouldBeNil)
+			So(schedule.Next(now), ShouldResemble, time.Date(2017, time.March, 15, 14, 45, 0, 0, time.UTC))
+			So(schedule.Next(time.Date(2017, time.March, 15, 17, 50, 0, 0, time.UTC)),
+				ShouldResemble, time.Date(2017, time.March, 17, 9, 0, 0, 0, time.UTC))
+		})
+
+		Convey("a weekly schedule should run on the next Sunday", func() {
+			schedule, err := ParseCron("0 6 * * 7")
+			So(err, ShouldBeNil)
+			So(schedule.Next(now), ShouldResemble, time.Date(2017, time.March, 19, 6, 0, 0, 0, time.UTC))
+		})
+
+		Convey("restricted days of the month and of the week should match either", func() {
+			schedule, err := ParseCron("0 0 1 * 5")
+			So(err, ShouldBeNil)
+			So(schedule.Next(now), ShouldResemble, time.Date(2017, time.March, 17, 0, 0, 0, 0, time.UTC))
+		})
+
+		Convey("invalid expressions should be errors", func() {
+			for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+				_, err := ParseCron(expr)
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+}
@@ -117,3 +117,17 @@ func RetryGeometricBackoff(attemptFunc RetriableFunc, maxTries int,
 	sleep time.Duration) (bool, error) {
 	return doRetry(geometricBackoffCalc, attemptFunc, maxTries, sleep)
 }
+
+// DoublingBackoff returns how long to wait before retrying work that has
+// already failed the given number of times: minDelay after the first failure,
+// doubling with each further failure up to maxDelay.
+func DoublingBackoff(minDelay, maxDelay time.Duration, failures int) time.Duration {
+	delay := minDelay
+	for i := 0; i < failures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
@@ -156,3 +156,17 @@ func TestGeometricRetryUntilSuccess(t *testing.T) {
 		})
 	})
 }
+
+func TestDoublingBackoff(t *testing.T) {
+	Convey("When backing off work that keeps failing", t, func() {
+		Convey("the delay should double with each failure", func() {
+			So(DoublingBackoff(time.Hour, 24*time.Hour, 0), ShouldEqual, time.Hour)
+			So(DoublingBackoff(time.Hour, 24*time.Hour, 1), ShouldEqual, 2*time.Hour)
+			So(DoublingBackoff(time.Hour, 24*time.Hour, 3), ShouldEqual, 8*time.Hour)
+		})
+		Convey("the delay should not exceed the maximum", func() {
+			So(DoublingBackoff(time.Hour, 24*time.Hour, 5), ShouldEqual, 24*time.Hour)
+			So(DoublingBackoff(time.Hour, 24*time.Hour, 1000), ShouldEqual, 24*time.Hour)
+		})
+	})
+}
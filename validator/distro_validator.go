@@ -19,6 +19,7 @@ var distroSyntaxValidators = []distroValidator{
 	ensureValidSSHOptions,
 	ensureValidExpansions,
 	ensureStaticHostsAreNotSpawnable,
+	ensureValidResourceLimits,
 }
 
 // CheckDistro checks if the distro configuration syntax is valid. Returns
@@ -156,3 +157,12 @@ func ensureValidSSHOptions(d *distro.Distro, s *evergreen.Settings) []Validation
 	}
 	return nil
 }
+
+// ensureValidResourceLimits checks that no resource limit is negative.
+func ensureValidResourceLimits(d *distro.Distro, s *evergreen.Settings) []ValidationError {
+	l := d.ResourceLimits
+	if l.MemoryLimitMB < 0 || l.CPUPercent < 0 || l.PidsLimit < 0 {
+		return []ValidationError{{Error, fmt.Sprintf("distro '%v' cannot have negative resource limits", d.Id)}}
+	}
+	return nil
+}
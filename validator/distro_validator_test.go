@@ -238,3 +238,21 @@ func TestEnsureValidSSHOptions(t *testing.T) {
 		})
 	})
 }
+
+func TestEnsureValidResourceLimits(t *testing.T) {
+	Convey("When validating a distro's resource limits...", t, func() {
+		Convey("if any limit is negative, an error should be returned", func() {
+			d := &distro.Distro{
+				ResourceLimits: distro.ResourceLimits{MemoryLimitMB: 1024, CPUPercent: -100},
+			}
+			err := ensureValidResourceLimits(d, conf)
+			So(len(err), ShouldEqual, 1)
+		})
+		Convey("if no limit is negative, no error should be returned", func() {
+			d := &distro.Distro{
+				ResourceLimits: distro.ResourceLimits{MemoryLimitMB: 1024, PidsLimit: 500},
+			}
+			So(ensureValidResourceLimits(d, conf), ShouldBeNil)
+		})
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/db"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/build"
@@ -232,6 +233,32 @@ func TestFinalizePatch(t *testing.T) {
 				So(len(tasks), ShouldEqual, 1)
 			})
 
+			Convey("a patch whose changes every task skips should succeed without builds", func() {
+				project, err := GetPatchedProject(configPatch, patchTestConfig)
+				So(err, ShouldBeNil)
+				for i := range project.BuildVariants {
+					project.BuildVariants[i].IgnorePaths = []string{"*"}
+				}
+				yamlBytes, err := yaml.Marshal(project)
+				So(err, ShouldBeNil)
+				configPatch.PatchedConfig = string(yamlBytes)
+				version, err := model.FinalizePatch(configPatch, patchTestConfig)
+				So(err, ShouldBeNil)
+				So(version, ShouldNotBeNil)
+				So(len(version.BuildIds), ShouldEqual, 0)
+				So(version.Status, ShouldEqual, evergreen.PatchSucceeded)
+
+				builds, err := build.Find(build.All)
+				So(err, ShouldBeNil)
+				So(len(builds), ShouldEqual, 0)
+
+				// its succeeded status is what gets posted to a pull request
+				dbPatch, err := patch.FindOne(patch.ById(configPatch.Id))
+				So(err, ShouldBeNil)
+				So(dbPatch.Status, ShouldEqual, evergreen.PatchSucceeded)
+				So(dbPatch.Activated, ShouldBeTrue)
+			})
+
 			Reset(func() {
 				So(db.Clear(distro.Collection), ShouldBeNil)
 			})
@@ -52,6 +52,8 @@ var projectSyntaxValidators = []projectValidator{
 	checkAllDependenciesSpec,
 	validateProjectTaskNames,
 	validateProjectTaskIdsAndTags,
+	validateTaskResourceLimits,
+	validatePRAliases,
 }
 
 // Functions used to validate the semantics of a project configuration file.
@@ -313,6 +315,7 @@ func ensureHasNecessaryProjectFields(project *model.Project) []ValidationError {
 
 	if project.CommandType != "" {
 		if project.CommandType != model.SystemCommandType &&
+			project.CommandType != model.SetupCommandType &&
 			project.CommandType != model.TestCommandType {
 			errs = append(errs,
 				ValidationError{
@@ -492,6 +495,7 @@ func validateCommands(section string, project *model.Project, registry plugin.Re
 		}
 		if cmd.Type != "" {
 			if cmd.Type != model.SystemCommandType &&
+				cmd.Type != model.SetupCommandType &&
 				cmd.Type != model.TestCommandType {
 				msg := fmt.Sprintf("%v section in '%v': invalid command type: '%v'", section, command, cmd.Type)
 				errs = append(errs, ValidationError{Message: msg})
@@ -597,6 +601,81 @@ func validateProjectTaskNames(project *model.Project) []ValidationError {
 	return errs
 }
 
+// validateTaskResourceLimits ensures that no task sets a negative resource limit.
+func validateTaskResourceLimits(project *model.Project) []ValidationError {
+	errs := []ValidationError{}
+	for _, task := range project.Tasks {
+		l := task.ResourceLimits
+		if l == nil {
+			continue
+		}
+		if l.MemoryLimitMB < 0 || l.CPUPercent < 0 || l.PidsLimit < 0 {
+			errs = append(errs,
+				ValidationError{
+					Message: fmt.Sprintf("task '%v' in project '%v' "+
+						"cannot have negative resource limits", task.Name, project.Identifier),
+				},
+			)
+		}
+	}
+	return errs
+}
+
+// validatePRAliases ensures that the pr_aliases are valid regular
+// expressions, and warns about aliases that select no tasks.
+func validatePRAliases(project *model.Project) []ValidationError {
+	errs := []ValidationError{}
+	for _, alias := range project.PRAliases {
+		if len(alias.Tasks) == 0 {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("pr alias for variant '%v' must list tasks", alias.Variant),
+			})
+			continue
+		}
+		if _, _, err := alias.Compile(); err != nil {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("pr alias for variant '%v' is invalid: %v", alias.Variant, err),
+			})
+			continue
+		}
+		aliasProject := &model.Project{
+			BuildVariants: project.BuildVariants,
+			Tasks:         project.Tasks,
+			PRAliases:     []model.PRAlias{alias},
+		}
+		if pairs, _ := aliasProject.PRAliasPairs(); len(pairs) == 0 {
+			errs = append(errs, ValidationError{
+				Level: Warning,
+				Message: fmt.Sprintf("pr alias for variant '%v' and tasks '%v' does not select any tasks",
+					alias.Variant, strings.Join(alias.Tasks, "', '")),
+			})
+		}
+	}
+	return errs
+}
+
+// CheckProjectAliases ensures that a project's aliases are complete and
+// have valid regular expressions, and warns about aliases that select no
+// tasks in the project's config.
+func CheckProjectAliases(project *model.Project, aliases []model.ProjectAlias) []ValidationError {
+	errs := []ValidationError{}
+	for _, alias := range aliases {
+		if err := alias.Validate(); err != nil {
+			errs = append(errs, ValidationError{
+				Message: err.Error(),
+			})
+			continue
+		}
+		if pairs, _ := project.AliasPairs([]model.ProjectAlias{alias}); len(pairs) == 0 {
+			errs = append(errs, ValidationError{
+				Level:   Warning,
+				Message: fmt.Sprintf("alias '%v' does not select any tasks in the project", alias.Alias),
+			})
+		}
+	}
+	return errs
+}
+
 // validateProjectTaskIdsAndTags ensures that task tags and ids only contain valid characters
 func validateProjectTaskIdsAndTags(project *model.Project) []ValidationError {
 	errs := []ValidationError{}
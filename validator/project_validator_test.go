@@ -791,6 +791,28 @@ func TestValidateProjectTaskIdsAndTags(t *testing.T) {
 	})
 }
 
+func TestValidateTaskResourceLimits(t *testing.T) {
+	Convey("When validating a project", t, func() {
+		Convey("ensure negative resource limits throw an error", func() {
+			project := &model.Project{
+				Tasks: []model.ProjectTask{
+					{Name: "compile", ResourceLimits: &distro.ResourceLimits{MemoryLimitMB: -1}},
+					{Name: "test", ResourceLimits: &distro.ResourceLimits{PidsLimit: 100}},
+				},
+			}
+			So(len(validateTaskResourceLimits(project)), ShouldEqual, 1)
+		})
+		Convey("ensure tasks without resource limits do not throw an error", func() {
+			project := &model.Project{
+				Tasks: []model.ProjectTask{
+					{Name: "compile"},
+				},
+			}
+			So(validateTaskResourceLimits(project), ShouldResemble, []ValidationError{})
+		})
+	})
+}
+
 func TestCheckTaskCommands(t *testing.T) {
 	Convey("When validating a project", t, func() {
 		Convey("ensure tasks that do not have at least one command throw "+
@@ -1440,3 +1462,82 @@ func TestEnsureHasNecessaryBVFields(t *testing.T) {
 		})
 	})
 }
+
+func TestValidatePRAliases(t *testing.T) {
+	Convey("When validating a project's pr aliases", t, func() {
+		project := &model.Project{
+			Tasks: []model.ProjectTask{{Name: "compile"}, {Name: "test"}},
+			BuildVariants: []model.BuildVariant{
+				{
+					Name:  "linux",
+					Tasks: []model.BuildVariantTask{{Name: "compile"}, {Name: "test"}},
+				},
+			},
+		}
+		Convey("no error should be returned for aliases that select tasks", func() {
+			project.PRAliases = []model.PRAlias{{Variant: "lin.*", Tasks: []string{"compile"}}}
+			So(validatePRAliases(project), ShouldResemble, []ValidationError{})
+		})
+		Convey("an error should be returned for an alias with no tasks", func() {
+			project.PRAliases = []model.PRAlias{{Variant: "linux"}}
+			errs := validatePRAliases(project)
+			So(len(errs), ShouldEqual, 1)
+			So(errs[0].Level, ShouldEqual, Error)
+		})
+		Convey("an error should be returned for an invalid regex", func() {
+			project.PRAliases = []model.PRAlias{{Variant: "linux(", Tasks: []string{".*"}}}
+			errs := validatePRAliases(project)
+			So(len(errs), ShouldEqual, 1)
+			So(errs[0].Level, ShouldEqual, Error)
+		})
+		Convey("a warning should be returned for an alias that selects nothing", func() {
+			project.PRAliases = []model.PRAlias{{Variant: "linux", Tasks: []string{"lint"}}}
+			errs := validatePRAliases(project)
+			So(len(errs), ShouldEqual, 1)
+			So(errs[0].Level, ShouldEqual, Warning)
+		})
+	})
+}
+
+func TestCheckProjectAliases(t *testing.T) {
+	Convey("When validating a project's aliases", t, func() {
+		project := &model.Project{
+			Tasks: []model.ProjectTask{{Name: "compile"}, {Name: "test", Tags: []string{"smoke"}}},
+			BuildVariants: []model.BuildVariant{
+				{
+					Name:  "linux",
+					Tags:  []string{"primary"},
+					Tasks: []model.BuildVariantTask{{Name: "compile"}, {Name: "test"}},
+				},
+			},
+		}
+		Convey("no error should be returned for aliases that select tasks by name or tag", func() {
+			errs := CheckProjectAliases(project, []model.ProjectAlias{
+				{Alias: "compile", Variant: "lin.*", Tasks: []string{"compile"}},
+				{Alias: "smoke", VariantTags: []string{"primary"}, TaskTags: []string{"smoke"}},
+			})
+			So(errs, ShouldResemble, []ValidationError{})
+		})
+		Convey("an error should be returned for an alias without a name, variants, or tasks", func() {
+			errs := CheckProjectAliases(project, []model.ProjectAlias{
+				{Variant: "linux", Tasks: []string{"compile"}},
+				{Alias: "none", Tasks: []string{"compile"}},
+				{Alias: "none", Variant: "linux"},
+			})
+			So(len(errs), ShouldEqual, 3)
+			for _, err := range errs {
+				So(err.Level, ShouldEqual, Error)
+			}
+		})
+		Convey("an error should be returned for an invalid regex", func() {
+			errs := CheckProjectAliases(project, []model.ProjectAlias{{Alias: "bad", Variant: "linux(", Tasks: []string{".*"}}})
+			So(len(errs), ShouldEqual, 1)
+			So(errs[0].Level, ShouldEqual, Error)
+		})
+		Convey("a warning should be returned for an alias that selects nothing", func() {
+			errs := CheckProjectAliases(project, []model.ProjectAlias{{Alias: "lint", Variant: "linux", TaskTags: []string{"lint"}}})
+			So(len(errs), ShouldEqual, 1)
+			So(errs[0].Level, ShouldEqual, Warning)
+		})
+	})
+}